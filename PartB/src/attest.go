@@ -0,0 +1,209 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// SigningConfig holds the server's attestation key, a secret reference to a base64 ed25519 key
+// Either the 32 byte seed or the 64 byte private key is accepted, "attest keygen" prints a new one
+type SigningConfig struct {
+	PrivateKey string `json:"private_key"`
+}
+
+// Signer attests samples with one key
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// NewSigner creates a Signer for key
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// newSignerFromConfig resolves the configured key, returning nil when signing isn't configured
+func newSignerFromConfig(cfg SigningConfig) (*Signer, error) {
+	if cfg.PrivateKey == "" {
+		return nil, nil
+	}
+	value, err := resolveSecret(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("signing.private_key: %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("signing.private_key: not valid base64")
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return NewSigner(ed25519.NewKeyFromSeed(raw)), nil
+	case ed25519.PrivateKeySize:
+		return NewSigner(ed25519.PrivateKey(raw)), nil
+	default:
+		return nil, fmt.Errorf("signing.private_key: %d bytes, want a %d byte seed or a %d byte key",
+			len(raw), ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// attestationMessage returns the bytes an attestation signs
+func attestationMessage(s Sample) ([]byte, error) {
+	s.Attestation = nil
+	return json.Marshal(s)
+}
+
+// Sign returns s with an attestation attached
+func (sg *Signer) Sign(s Sample) (Sample, error) {
+	msg, err := attestationMessage(s)
+	if err != nil {
+		return s, fmt.Errorf("encoding the sample failed: %v", err)
+	}
+	s.Attestation = &Attestation{
+		Algorithm: "ed25519",
+		PublicKey: base64.StdEncoding.EncodeToString(sg.key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(sg.key, msg)),
+	}
+	return s, nil
+}
+
+// verifyAttestation checks the attestation on s; a non-empty want also pins the key it must be signed with
+func verifyAttestation(s Sample, want string) error {
+	a := s.Attestation
+	if a == nil {
+		return fmt.Errorf("the sample isn't signed")
+	}
+	if a.Algorithm != "ed25519" {
+		return fmt.Errorf("unsupported algorithm %q", a.Algorithm)
+	}
+	if want != "" && a.PublicKey != want {
+		return fmt.Errorf("signed with %s, not the expected key", a.PublicKey)
+	}
+	pub, err := base64.StdEncoding.DecodeString(a.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(a.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding")
+	}
+	msg, err := attestationMessage(s)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), msg, sig) {
+		return fmt.Errorf("signature doesn't match, the sample was changed or signed with another key")
+	}
+	return nil
+}
+
+// attestVerifyFlags are the flags of "attest verify"
+type attestVerifyFlags struct {
+	key string
+}
+
+func (f *attestVerifyFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("attest verify")
+	fs.StringVar(&f.key, "key", "", "public key the sample must be signed with (base64)")
+	return fs
+}
+
+// runAttest implements the "attest" command
+// keygen prints a new key pair, verify checks a signed response read from a file or stdin,
+// and sign writes FILE.sig for a remote sources document or a release's checksums, see remote.go and selfupdate.go
+func runAttest(args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: attest keygen, attest verify [-key PUBLIC_KEY] FILE|-, or attest sign FILE")
+	}
+	switch args[0] {
+	case "keygen":
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		fmt.Println(tr("attest.private_key", base64.StdEncoding.EncodeToString(priv.Seed())))
+		fmt.Println(tr("attest.public_key", base64.StdEncoding.EncodeToString(pub)))
+		fmt.Println(tr("attest.store_key"))
+		return nil
+	case "verify":
+		var flags attestVerifyFlags
+		fs := flags.flagSet()
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return usageErrorf("usage: attest verify [-key PUBLIC_KEY] FILE|-")
+		}
+		var data []byte
+		var err error
+		if fs.Arg(0) == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(fs.Arg(0))
+		}
+		if err != nil {
+			return fmt.Errorf("reading the sample failed: %v", err)
+		}
+		// Unknown fields would be dropped when the message is rebuilt, so they are refused rather than left unsigned
+		var s Sample
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&s); err != nil {
+			return fmt.Errorf("parsing the sample failed: %v", err)
+		}
+		if err := verifyAttestation(s, flags.key); err != nil {
+			return err
+		}
+		fmt.Println(tr("attest.verified", s.RateAUD, s.Time.Format("2006-01-02 15:04:05 MST"), s.Attestation.PublicKey))
+		if flags.key == "" {
+			fmt.Println(tr("attest.key_unchecked"))
+		}
+		return nil
+	case "sign":
+		if len(args) != 2 {
+			return usageErrorf("usage: attest sign FILE")
+		}
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		sg, err := newSignerFromConfig(cfg.Signing)
+		if err != nil {
+			return err
+		}
+		if sg == nil {
+			return fmt.Errorf("set signing.private_key to the key to sign with, \"attest keygen\" makes one")
+		}
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("reading %s failed: %v", args[1], err)
+		}
+		// The signature covers the exact bytes, so the file must be published as it is now
+		// A .json file is a remote sources document and is checked first; anything else, such as a
+		// release's checksums.txt, is signed as it is
+		if strings.HasSuffix(args[1], ".json") {
+			if _, err := parseRemoteSources(data); err != nil {
+				return err
+			}
+		}
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(sg.key, data))
+		if err := os.WriteFile(args[1]+".sig", []byte(sig+"\n"), 0o644); err != nil {
+			return err
+		}
+		fmt.Println(tr("attest.signed", args[1]+".sig", base64.StdEncoding.EncodeToString(sg.key.Public().(ed25519.PublicKey))))
+		return nil
+	default:
+		return usageErrorf("unknown attest command: %s", args[0])
+	}
+}
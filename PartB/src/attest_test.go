@@ -0,0 +1,84 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAttestation(t *testing.T) {
+	// A fixed seed, so the key is the same on every run
+	seed := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("s", ed25519.SeedSize)))
+	signer, err := newSignerFromConfig(SigningConfig{PrivateKey: seed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := base64.StdEncoding.EncodeToString(signer.key.Public().(ed25519.PublicKey))
+
+	server := NewServer(newFakeConverter(t), nil)
+	server.signer = signer
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/rate", nil))
+	// Verified as a client would, from the JSON served
+	var served Sample
+	if err := json.Unmarshal(rec.Body.Bytes(), &served); err != nil {
+		t.Fatalf("/rate: %v\n%s", err, rec.Body)
+	}
+	if err := verifyAttestation(served, pub); err != nil {
+		t.Fatalf("the served sample doesn't verify: %v", err)
+	}
+
+	_, other, _ := ed25519.GenerateKey(nil)
+	resigned, _ := NewSigner(other).Sign(served)
+	badSig := served
+	sig, _ := base64.StdEncoding.DecodeString(served.Attestation.Signature)
+	sig[0] ^= 1
+	badSig.Attestation = &Attestation{Algorithm: "ed25519", PublicKey: pub, Signature: base64.StdEncoding.EncodeToString(sig)}
+	changed := served
+	changed.RateAUD *= 2
+	unsigned := served
+	unsigned.Attestation = nil
+
+	tests := []struct {
+		name   string
+		sample Sample
+		key    string
+		want   string
+	}{
+		{"bad signature", badSig, pub, "signature doesn't match"},
+		{"changed rate", changed, pub, "signature doesn't match"},
+		{"another key", resigned, pub, "not the expected key"},
+		{"unsigned", unsigned, "", "isn't signed"},
+	}
+	for _, tt := range tests {
+		if err := verifyAttestation(tt.sample, tt.key); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: %v, want an error with %q", tt.name, err, tt.want)
+		}
+	}
+	// Without -key any valid signature passes, which is why attest verify warns about it
+	if err := verifyAttestation(resigned, ""); err != nil {
+		t.Errorf("a valid signature by another key without -key: %v", err)
+	}
+}
+
+func TestSignerConfig(t *testing.T) {
+	if sg, err := newSignerFromConfig(SigningConfig{}); sg != nil || err != nil {
+		t.Fatalf("no key configured: %v, %v", sg, err)
+	}
+	_, priv, _ := ed25519.GenerateKey(nil)
+	sg, err := newSignerFromConfig(SigningConfig{PrivateKey: base64.StdEncoding.EncodeToString(priv)})
+	if err != nil || !sg.key.Equal(priv) {
+		t.Fatalf("a 64 byte key: %v", err)
+	}
+	if _, err := newSignerFromConfig(SigningConfig{PrivateKey: base64.StdEncoding.EncodeToString([]byte("short"))}); err == nil {
+		t.Fatal("a 5 byte key was accepted")
+	}
+}
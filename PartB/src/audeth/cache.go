@@ -0,0 +1,60 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import (
+	"sync"
+	"time"
+)
+
+// RateCache stores the latest aggregate on behalf of the Converter
+// Lock/Unlock let several processes sharing one cache agree on a single refresher
+type RateCache interface {
+	Get() (Sample, bool, error)
+	Set(s Sample, ttl time.Duration) error
+	// Lock tries to become the refresher for up to ttl; false means someone else already is
+	Lock(ttl time.Duration) (bool, error)
+	Unlock() error
+}
+
+// MemoryRateCache is the default in-process cache
+// The Converter's own mutex already serialises refreshes, so Lock always succeeds
+type MemoryRateCache struct {
+	clock Clock
+
+	mu      sync.Mutex
+	sample  Sample
+	expires time.Time
+}
+
+// NewMemoryRateCache creates an empty cache expiring its rate on clock
+func NewMemoryRateCache(clock Clock) *MemoryRateCache {
+	return &MemoryRateCache{clock: clock}
+}
+
+func (m *MemoryRateCache) Get() (Sample, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sample.RateAUD <= 0 || !m.clock.Now().Before(m.expires) {
+		return Sample{}, false, nil
+	}
+	return m.sample.Clone(), true, nil
+}
+
+func (m *MemoryRateCache) Set(s Sample, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sample, m.expires = s.Clone(), m.clock.Now().Add(ttl)
+	return nil
+}
+
+func (m *MemoryRateCache) Lock(ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (m *MemoryRateCache) Unlock() error {
+	return nil
+}
@@ -0,0 +1,25 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import "time"
+
+// Clock is where time-dependent code gets the time from: cache expiry, staleness, retry loops and waits
+// Tests swap in a fake (see audethtest.FakeClock) with WithClock to move time forward without sleeping
+// Network timeouts are left on the real clock, sockets can't be fooled anyway
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	// After is time.After on this clock, for a wait that also selects on a context
+	After(d time.Duration) <-chan time.Time
+}
+
+// RealClock is the system clock, the one a Converter uses unless given another
+type RealClock struct{}
+
+func (RealClock) Now() time.Time                         { return time.Now() }
+func (RealClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (RealClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
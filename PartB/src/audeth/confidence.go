@@ -0,0 +1,66 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import "time"
+
+// A quote's confidence is four factors from 0 to 1 multiplied together:
+//   - sources: the share of the sources asked that answered, scaled down when fewer than confidentSources did
+//   - dispersion: 1 with the quotes all equal, falling to 0 as they spread to confidenceSpreadPct apart
+//   - latency: 1 for instant answers, falling to 0.5 as their mean reaches confidenceSlowLatency
+//   - staleness: 1 for a rate fetched just now, falling to 0 as it reaches confidenceStaleAge
+//
+// The first three are fixed when the rate is fetched and kept with it; staleness is applied when the rate is used
+const (
+	confidentSources      = 3
+	confidenceSpreadPct   = 5.0
+	confidenceSlowLatency = 10 * time.Second // the HTTP client's own timeout
+	confidenceStaleAge    = time.Hour
+)
+
+// fetchConfidence scores the results of a fetch that began at started
+func fetchConfidence(results []PriceResult, started time.Time) float64 {
+	var lo, hi, sum float64
+	var waited time.Duration
+	ok := 0
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if ok == 0 || r.Price < lo {
+			lo = r.Price
+		}
+		if ok == 0 || r.Price > hi {
+			hi = r.Price
+		}
+		sum += r.Price
+		if !r.At.IsZero() && r.At.After(started) {
+			waited += r.At.Sub(started)
+		}
+		ok++
+	}
+	if ok == 0 || sum <= 0 {
+		return 0
+	}
+
+	sources := float64(ok) / float64(len(results))
+	if ok < confidentSources {
+		sources *= float64(ok) / confidentSources
+	}
+	spread := (hi - lo) / (sum / float64(ok)) * 100
+	dispersion := 1 - min(spread/confidenceSpreadPct, 1)
+	latency := 1 - 0.5*min(float64(waited/time.Duration(ok))/float64(confidenceSlowLatency), 1)
+	return sources * dispersion * latency
+}
+
+// At is q as of now: its confidence lowered by how long ago it was fetched
+func (q Quote) At(now time.Time) Quote {
+	age := now.Sub(q.Time)
+	if age > 0 {
+		q.Confidence *= 1 - min(float64(age)/float64(confidenceStaleAge), 1)
+	}
+	return q
+}
@@ -0,0 +1,376 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+// Package audeth is the converter the audeth command is built on, for programs that want ETH/AUD rates of their own
+// A Converter queries the price sources and an FX provider, averages them into an AUD rate and caches it;
+// the audeth command adds its config file, history and outputs on top, and audethtest has fakes for testing with it
+package audeth
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTTL is how long an aggregated rate is reused before refetching, unless the caller says otherwise
+const DefaultTTL = 60 * time.Second
+
+// DefaultFetchLimit is how many sources are queried at once unless FetchOptions.Limit says otherwise
+const DefaultFetchLimit = 8
+
+// refreshLockTTL bounds how long one process may hold the shared refresh lock,
+// and how long the others wait for it before fetching themselves
+const refreshLockTTL = 15 * time.Second
+
+// Converter owns the price fetchers and caches the aggregated AUD rate for a TTL
+// It is safe to share between goroutines, e.g. HTTP handlers, without any locking of your own:
+// the settings never change once NewConverter returns, the current rate is swapped atomically,
+// and the mutex only serialises refreshes, so readers of a fresh rate never wait on it
+type Converter struct {
+	fetchers []PriceFetcher
+	fx       FXProvider
+	ttl      time.Duration
+	cache    RateCache
+	clock    Clock
+	opts     FetchOptions
+	stale    time.Duration     // how long past the TTL a rate may still be served while it is refetched
+	say      Messages          // see WithMessages
+	redact   func(error) error // see WithRedaction
+
+	mu         sync.Mutex
+	last       atomic.Pointer[Sample] // the newest rate seen, readable without waiting on mu
+	refreshing atomic.Bool            // a background refresh is running
+
+	lock        time.Duration // how long a rate is locked for, see WithRateLock
+	lockMu      sync.Mutex
+	locked      *Sample
+	lockedUntil time.Time
+
+	subMu    sync.Mutex // guards the subscribers, see Subscribe
+	subs     map[chan Quote]struct{}
+	stopPoll context.CancelFunc // stops the refreshes kept up for the subscribers
+}
+
+// ConverterOption changes one setting while NewConverter builds the Converter
+// Options are the only way to configure it, so nothing can alter a Converter that is already in use
+type ConverterOption func(*Converter)
+
+// WithFetchOptions sets the concurrency limit, quorum and deadline of each refresh
+func WithFetchOptions(opts FetchOptions) ConverterOption {
+	return func(c *Converter) { c.opts = opts }
+}
+
+// WithMinConfidence refuses rates with a confidence below threshold, in place of the fetch options' MinConfidence;
+// zero leaves the fetch options' own
+func WithMinConfidence(threshold float64) ConverterOption {
+	return func(c *Converter) {
+		if threshold > 0 {
+			c.opts.MinConfidence = threshold
+		}
+	}
+}
+
+// WithStaleWhileRevalidate serves an expired rate for up to d longer while it is refetched in the background
+func WithStaleWhileRevalidate(d time.Duration) ConverterOption {
+	return func(c *Converter) { c.stale = d }
+}
+
+// WithRateLock keeps each rate for d from when it is first used, whatever the TTL, so every conversion
+// in that window gets the identical rate; the next one after it locks a new rate
+func WithRateLock(d time.Duration) ConverterOption {
+	return func(c *Converter) { c.lock = d }
+}
+
+// WithClock runs the Converter, and the in-memory cache it makes when given none, on clock instead of the system's
+func WithClock(clock Clock) ConverterOption {
+	return func(c *Converter) { c.clock = clock }
+}
+
+// WithFX replaces the FX provider NewConverter was given, e.g. with a fixed rate to price ETH in USD instead of AUD
+func WithFX(fx FXProvider) ConverterOption {
+	return func(c *Converter) { c.fx = fx }
+}
+
+// Messages is told what the Converter has to report as it works: each source's answer and the trouble it
+// works around, such as an unreachable cache; key names the message and args fill it in, see WithMessages
+type Messages func(key string, args ...any)
+
+// WithMessages passes the Converter's messages to say instead of dropping them
+// The keys are the ones in the audeth command's locales, e.g. "source.price" with the source's name and price,
+// or "warning.cache_unavailable" with the error; a time among args is a time.Time, for say to format as it likes
+func WithMessages(say Messages) ConverterOption {
+	return func(c *Converter) {
+		if say != nil {
+			c.say = say
+		}
+	}
+}
+
+// WithRedaction has every source and FX error passed through redact before a Sample keeps it or a caller sees it,
+// e.g. to take API keys out of the URLs in them; redact is given nil for a source that didn't fail
+func WithRedaction(redact func(error) error) ConverterOption {
+	return func(c *Converter) {
+		if redact != nil {
+			c.redact = redact
+		}
+	}
+}
+
+// WithFetcherWrapper replaces the fetchers with wrap's result, e.g. to put streams in front of them
+func WithFetcherWrapper(wrap func([]PriceFetcher) []PriceFetcher) ConverterOption {
+	return func(c *Converter) { c.fetchers = wrap(c.fetchers) }
+}
+
+// NewConverter creates a Converter; a ttl of zero disables caching of the aggregate
+// A nil cache means the rate is cached in memory for this process only
+// The FX provider is called as is, wrap it in a cache of its own to give the fiat leg its own TTL
+// The fetchers slice is copied, so changing it afterwards doesn't reach the Converter
+func NewConverter(fetchers []PriceFetcher, fx FXProvider, ttl time.Duration, cache RateCache, options ...ConverterOption) *Converter {
+	c := &Converter{
+		fetchers: slices.Clone(fetchers),
+		fx:       fx,
+		ttl:      ttl,
+		cache:    cache,
+		clock:    RealClock{},
+		opts:     FetchOptions{Limit: DefaultFetchLimit},
+		say:      func(string, ...any) {},
+		redact:   func(err error) error { return err },
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.cache == nil {
+		c.cache = NewMemoryRateCache(c.clock)
+	}
+	return c
+}
+
+// Rate returns the aggregated ETH price in AUD plus the per-source results behind it
+// Within the TTL the cached value is returned; otherwise the sources are queried again
+// With stale-while-revalidate, an expired rate inside the stale window is returned straight away
+// and refetched in the background, so callers don't wait for the exchanges
+func (c *Converter) Rate() (float64, []PriceResult, error) {
+	s, err := c.Sample()
+	return s.RateAUD, sampleResults(s), err
+}
+
+// Sample is Rate returning the whole sample, including when it was fetched
+// On an error the sample still lists what each source returned, with a zero rate,
+// except for a ConfidenceError, which comes with the rate that fell short of the minimum
+func (c *Converter) Sample() (Sample, error) {
+	var s Sample
+	var err error
+	if c.lock > 0 {
+		s, err = c.lockedSample()
+	} else {
+		s, err = c.sample()
+	}
+	if err == nil && c.opts.MinConfidence > 0 {
+		if q := s.Quote().At(c.clock.Now()); q.Confidence < c.opts.MinConfidence {
+			return s, ConfidenceError{Got: q.Confidence, Min: c.opts.MinConfidence}
+		}
+	}
+	return s, err
+}
+
+// Quote is Rate as a Quote, with the bid, ask, FX rate and confidence that went with the rate, the confidence as of now
+func (c *Converter) Quote() (Quote, error) {
+	s, err := c.Sample()
+	return s.Quote().At(c.clock.Now()), err
+}
+
+// LockedUntil is when the locked rate is let go; zero without a rate lock or before a rate is locked
+func (c *Converter) LockedUntil() time.Time {
+	c.lockMu.Lock()
+	defer c.lockMu.Unlock()
+	return c.lockedUntil
+}
+
+// Fetchers, FX and FetchOptions are what the Converter was built with once every option was applied,
+// e.g. for listing its sources and their weights
+func (c *Converter) Fetchers() []PriceFetcher {
+	return slices.Clone(c.fetchers)
+}
+
+func (c *Converter) FX() FXProvider {
+	return c.fx
+}
+
+func (c *Converter) FetchOptions() FetchOptions {
+	return c.opts
+}
+
+// lockedSample returns the locked rate until its window ends, then locks the rate in force at that point
+// Starting and ending a lock are both reported, so a batch can tell which amounts shared a rate
+func (c *Converter) lockedSample() (Sample, error) {
+	c.lockMu.Lock()
+	defer c.lockMu.Unlock()
+	now := c.clock.Now()
+	if c.locked != nil && now.Before(c.lockedUntil) {
+		return c.locked.Clone(), nil
+	}
+	if c.locked != nil {
+		c.say("rate.lock_expired", c.lockedUntil)
+	}
+	s, err := c.sample()
+	if err != nil {
+		c.locked, c.lockedUntil = nil, time.Time{}
+		return s, err
+	}
+	locked := s.Clone()
+	c.locked, c.lockedUntil = &locked, now.Add(c.lock)
+	c.say("rate.locked", s.RateAUD, c.lockedUntil, c.lock)
+	return s, nil
+}
+
+func (c *Converter) sample() (Sample, error) {
+	if s, ok := c.fresh(); ok {
+		return s, nil
+	}
+	if c.stale > 0 {
+		if s, ok := c.staleRate(); ok {
+			return s, nil
+		}
+	}
+	return c.refresh()
+}
+
+// fresh returns this process's latest rate while it is within the TTL, without taking any lock
+// Every caller gets its own copy of the results, so nothing they do can change the shared sample
+func (c *Converter) fresh() (Sample, bool) {
+	last := c.last.Load()
+	if c.ttl <= 0 || last == nil || c.clock.Now().Sub(last.Time) >= c.ttl {
+		return Sample{}, false
+	}
+	return last.Clone(), true
+}
+
+// staleRate returns the fresh cached rate, or the last one seen if it is within the stale window
+// In the second case a background refresh is started unless one is already running
+func (c *Converter) staleRate() (Sample, bool) {
+	if s, ok := c.cached(); ok {
+		return s, true
+	}
+	last := c.last.Load()
+	if last == nil || c.clock.Now().Sub(last.Time) > c.ttl+c.stale {
+		return Sample{}, false
+	}
+	if c.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer c.refreshing.Store(false)
+			if _, err := c.refresh(); err != nil {
+				c.say("warning.background_refresh", last.Time, err)
+			}
+		}()
+	}
+	return last.Clone(), true
+}
+
+// Refresh fetches a new rate even when the cached one is still fresh, e.g. for a refresher running on a schedule
+// Another process already refreshing a shared cache is waited for rather than repeated
+func (c *Converter) Refresh() (Sample, error) {
+	return c.fetchRate(true)
+}
+
+// refresh returns the cached rate or fetches a new one
+func (c *Converter) refresh() (Sample, error) {
+	return c.fetchRate(false)
+}
+
+// fetchRate is refresh, and Refresh with force
+// The lock is held during the fetch, so concurrent callers share one refresh instead of each fetching
+// With a shared cache, the cache's own lock does the same job across processes
+func (c *Converter) fetchRate(force bool) (Sample, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl > 0 {
+		if !force {
+			if s, ok := c.cached(); ok {
+				return s, nil
+			}
+		}
+
+		owner, err := c.cache.Lock(refreshLockTTL)
+		if err != nil {
+			c.say("warning.cache_lock", err)
+		} else if !owner {
+			// Another process is refreshing, wait for it to publish instead of fetching too
+			if s, ok := c.waitForRefresh(); ok {
+				return s, nil
+			}
+		} else {
+			defer c.cache.Unlock()
+		}
+	}
+
+	quote, results, err := c.fetchAndCalculatePrice(context.Background())
+	if err != nil {
+		return newSample(c.clock.Now(), Quote{Aggregation: c.opts.aggregation()}, results), err
+	}
+	// Swapping the pointer publishes the new rate to staleRate readers in one step
+	sample := newSample(c.clock.Now(), quote, results)
+	c.storeLast(sample)
+	if c.ttl > 0 {
+		if err := c.cache.Set(sample, c.ttl); err != nil {
+			c.say("warning.update_cache", err)
+		}
+	}
+	return sample, nil
+}
+
+// cached returns the cached sample if there is one, treating cache errors as a miss
+// A hit also becomes the current rate, so a rate shared by another process is read lock-free next time
+func (c *Converter) cached() (Sample, bool) {
+	s, ok, err := c.cache.Get()
+	if err != nil {
+		c.say("warning.cache_unavailable", err)
+		return Sample{}, false
+	}
+	if ok {
+		c.storeLast(s)
+	}
+	return s, ok
+}
+
+// Cached is the rate in the cache without fetching one, e.g. to save in a snapshot; false when there is none
+func (c *Converter) Cached() (Sample, bool) {
+	return c.cached()
+}
+
+// Restore puts s back in the cache for what is left of its TTL, e.g. from a snapshot
+// A sample already past the TTL, or a Converter without one, leaves the cache as it is
+func (c *Converter) Restore(s Sample) error {
+	if c.ttl <= 0 {
+		return nil
+	}
+	if left := c.ttl - c.clock.Now().Sub(s.Time); left > 0 {
+		return c.cache.Set(s, left)
+	}
+	return nil
+}
+
+// waitForRefresh polls the cache until another process has stored a fresh rate
+func (c *Converter) waitForRefresh() (Sample, bool) {
+	deadline := c.clock.Now().Add(refreshLockTTL)
+	for c.clock.Now().Before(deadline) {
+		c.clock.Sleep(200 * time.Millisecond)
+		if s, ok := c.cached(); ok {
+			return s, true
+		}
+	}
+	return Sample{}, false
+}
+
+// Convert returns how much ETH the given AUD amount buys at the current rate
+func (c *Converter) Convert(aud float64) (float64, error) {
+	rate, _, err := c.Rate()
+	if err != nil {
+		return 0, err
+	}
+	return aud / rate, nil
+}
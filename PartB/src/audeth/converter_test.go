@@ -0,0 +1,141 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// newFakeConverter builds a converter over two fake sources quoting 3000 and 3100 USD, at 1.5 AUD per USD
+func newFakeConverter(t *testing.T, options ...ConverterOption) *Converter {
+	t.Helper()
+	fetchers := []PriceFetcher{audethtest.NewFakeFetcher("A", 3000), audethtest.NewFakeFetcher("B", 3100)}
+	return NewConverter(fetchers, audethtest.FakeFX{Rate: 1.5}, time.Minute, nil, options...)
+}
+
+func TestQuoteAggregationOfOldSamples(t *testing.T) {
+	// A sample recorded before the aggregation was kept was a mean
+	if got := (Sample{RateAUD: 5000}).Quote().Aggregation; got != "mean" {
+		t.Errorf("got %q, want mean", got)
+	}
+}
+
+func TestWithMinConfidence(t *testing.T) {
+	// Two sources is fewer than confidentSources, so the confidence is well under 0.9
+	c := newFakeConverter(t, WithFetchOptions(FetchOptions{Limit: 2, MinConfidence: 0.1}), WithMinConfidence(0.9))
+	_, err := c.Sample()
+	var low ConfidenceError
+	if !errors.As(err, &low) || low.Min != 0.9 {
+		t.Fatalf("got %v, want a ConfidenceError against 0.9", err)
+	}
+	// Zero keeps the fetch options' own minimum
+	c = newFakeConverter(t, WithFetchOptions(FetchOptions{Limit: 2, MinConfidence: 0.1}), WithMinConfidence(0))
+	if _, err := c.Sample(); err != nil {
+		t.Errorf("got %v with a minimum of 0.1", err)
+	}
+}
+
+func TestSampleIsACopy(t *testing.T) {
+	lockWindow := WithRateLock(time.Minute)
+	for _, c := range []*Converter{newFakeConverter(t), newFakeConverter(t, lockWindow)} {
+		first, err := c.Sample()
+		if err != nil {
+			t.Fatal(err)
+		}
+		first.Sources[0].Name, first.Sources[0].USD = "changed", 1
+		// The second comes from the cached rate, which the first must not have reached
+		second, err := c.Sample()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if second.Sources[0].Name == "changed" || second.Sources[0].USD == 1 {
+			t.Errorf("changing one sample changed the converter's: %+v", second.Sources[0])
+		}
+	}
+}
+
+// aggregatorFunc stands in for a fetch.aggregate expression
+type aggregatorFunc func(env map[string]any) (float64, error)
+
+func (f aggregatorFunc) EvalNumber(env map[string]any) (float64, error) { return f(env) }
+
+func TestAggregateUSD(t *testing.T) {
+	mean := aggregatorFunc(func(env map[string]any) (float64, error) {
+		quotes, sources := env["quotes"].([]any), env["sources"].(map[string]any)
+		if len(quotes) != len(sources) {
+			return 0, errors.New("the quotes and sources don't match")
+		}
+		var sum float64
+		for _, q := range quotes {
+			sum += q.(float64)
+		}
+		return sum / float64(len(quotes)), nil
+	})
+	results := []PriceResult{{Name: "Kraken", Price: 3290}, {Name: "Coinbase", Price: 3300}, {Name: "Bitfinex", Price: 1, Err: errors.New("non-OK status code: 503")}}
+	if got, err := aggregateUSD(results, mean); err != nil || got != 3295 {
+		t.Errorf("got %v, %v, want 3295", got, err)
+	}
+	zero := aggregatorFunc(func(map[string]any) (float64, error) { return 0, nil })
+	if _, err := aggregateUSD(results, zero); err == nil {
+		t.Error("an aggregate of 0 was taken as a price")
+	}
+	if _, err := aggregateUSD(results[2:], mean); err == nil {
+		t.Error("got a price with no valid quotes")
+	}
+}
+
+// stuckFX never answers by itself; with a context it gives up when the context ends
+type stuckFX struct {
+	withContext bool
+	release     chan struct{}
+}
+
+func (f stuckFX) Name() string { return "Stuck" }
+
+func (f stuckFX) FetchRate() (float64, error) {
+	<-f.release
+	return 1.5, nil
+}
+
+func (f stuckFX) FetchRateContext(ctx context.Context) (float64, error) {
+	if !f.withContext {
+		return f.FetchRate()
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-f.release:
+		return 1.5, nil
+	}
+}
+
+func TestFXLegHonoursTheDeadline(t *testing.T) {
+	for _, withContext := range []bool{true, false} {
+		fx := stuckFX{withContext: withContext, release: make(chan struct{})}
+		c := NewConverter([]PriceFetcher{audethtest.NewFakeFetcher("A", 3000)}, fx, 0, nil,
+			WithFetchOptions(FetchOptions{Deadline: 50 * time.Millisecond}))
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := c.Rate()
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			var fetch FetchError
+			if !errors.As(err, &fetch) || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("with context %v: got %v, want a FetchError for the deadline", withContext, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("with context %v: the fetch waited on the FX provider past its deadline", withContext)
+		}
+		close(fx.release)
+	}
+}
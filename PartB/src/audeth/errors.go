@@ -0,0 +1,46 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import "fmt"
+
+// The typed errors below tell the reasons a Converter has no rate apart; errors.As finds them through any %w wrapping
+// The guards they name are set with FetchOptions, which the audeth command reads from the fetch section of its config
+
+// FetchError means no rate could be put together from the sources
+type FetchError struct {
+	Err error
+}
+
+func (e FetchError) Error() string { return e.Err.Error() }
+func (e FetchError) Unwrap() error { return e.Err }
+
+// QuorumError means too few sources answered to trust the average
+type QuorumError struct {
+	Got, Want int
+}
+
+func (e QuorumError) Error() string {
+	return fmt.Sprintf("only %d sources answered, fetch.min_sources needs %d", e.Got, e.Want)
+}
+
+// DivergenceError means the source quotes were too far apart to average
+type DivergenceError struct {
+	SpreadPct, MaxPct float64
+}
+
+func (e DivergenceError) Error() string {
+	return fmt.Sprintf("sources disagree by %.2f%%, more than fetch.max_divergence of %.2f%%", e.SpreadPct, e.MaxPct)
+}
+
+// ConfidenceError means the rate's confidence, see confidence.go, was below fetch.min_confidence
+type ConfidenceError struct {
+	Got, Min float64
+}
+
+func (e ConfidenceError) Error() string {
+	return fmt.Sprintf("the rate's confidence is %.0f%%, below fetch.min_confidence of %.0f%%", e.Got*100, e.Min*100)
+}
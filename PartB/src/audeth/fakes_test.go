@@ -0,0 +1,166 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// These tests use the converter only as another program would, through its exported names and audethtest
+
+func TestCacheExpiresOnTheClock(t *testing.T) {
+	clock := audethtest.NewFakeClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	source := audethtest.NewFakeFetcher("A", 3000)
+	c := audeth.NewConverter([]audeth.PriceFetcher{source}, audethtest.FakeFX{Rate: 1.5}, time.Minute, nil, audeth.WithClock(clock))
+
+	if rate, _, err := c.Rate(); err != nil || rate != 4500 {
+		t.Fatalf("got %v, %v, want 4500", rate, err)
+	}
+	source.SetPrice(3100)
+	clock.Advance(59 * time.Second)
+	if rate, _, _ := c.Rate(); rate != 4500 || source.Calls() != 1 {
+		t.Errorf("within the TTL: got %v after %d fetches, want the cached 4500 after 1", rate, source.Calls())
+	}
+	clock.Advance(time.Second)
+	if rate, _, _ := c.Rate(); rate != 4650 || source.Calls() != 2 {
+		t.Errorf("at the TTL: got %v after %d fetches, want 4650 after 2", rate, source.Calls())
+	}
+}
+
+func TestLatencyOnTheClock(t *testing.T) {
+	clock := audethtest.NewFakeClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	slow := audethtest.NewFakeFetcher("Slow", 3100).SetLatency(time.Minute).SetClock(clock)
+	fetchers := []audeth.PriceFetcher{audethtest.NewFakeFetcher("A", 3000), slow, audethtest.NewFakeFetcher("C", 3300)}
+
+	// The quorum is reached without the slow source, so the fetch returns with the clock never moved
+	quorum := audeth.NewConverter(fetchers, audethtest.FakeFX{Rate: 1.5}, 0, nil, audeth.WithClock(clock),
+		audeth.WithFetchOptions(audeth.FetchOptions{Quorum: 2}))
+	if rate, _, err := quorum.Rate(); err != nil || rate != 4725 {
+		t.Fatalf("with a quorum of 2: got %v, %v, want 4725", rate, err)
+	}
+
+	// Without one the fetch waits for the slow source until the clock has moved its latency on
+	// A fresh clock, as the cut short wait above is still on the first one
+	clock = audethtest.NewFakeClock(clock.Now())
+	slow.SetClock(clock)
+	all := audeth.NewConverter(fetchers, audethtest.FakeFX{Rate: 1.5}, 0, nil, audeth.WithClock(clock))
+	done := make(chan float64, 1)
+	go func() {
+		rate, _, _ := all.Rate()
+		done <- rate
+	}()
+	waitForWaiters(t, clock)
+	select {
+	case rate := <-done:
+		t.Fatalf("got %v before the slow source's latency was up", rate)
+	default:
+	}
+	clock.Advance(time.Minute)
+	select {
+	case rate := <-done:
+		if rate != 4700 {
+			t.Errorf("got %v, want 4700", rate)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slow source didn't answer once the clock moved on")
+	}
+}
+
+func TestFailuresReachTheCaller(t *testing.T) {
+	up := audethtest.NewFakeFetcher("Up", 3000)
+	down := audethtest.NewFakeFetcher("Down", 3000).FailAlways(audethtest.ErrInjected)
+	var said []string
+	c := audeth.NewConverter([]audeth.PriceFetcher{up, down}, audethtest.FakeFX{Rate: 1.5}, 0, nil,
+		audeth.WithFetchOptions(audeth.FetchOptions{MinSources: 2}),
+		audeth.WithMessages(func(key string, args ...any) { said = append(said, key) }))
+
+	_, results, err := c.Rate()
+	var quorum audeth.QuorumError
+	if !errors.As(err, &quorum) || quorum.Got != 1 || quorum.Want != 2 {
+		t.Fatalf("got %v, want a QuorumError for 1 of 2", err)
+	}
+	// The results come back from the sample, which keeps what each error said rather than the error itself
+	if len(results) != 2 || results[1].Err == nil || results[1].Err.Error() != audethtest.ErrInjected.Error() {
+		t.Errorf("the failing source's result is %+v, want its error", results)
+	}
+	if len(said) != 2 || said[0] != "source.price" || said[1] != "source.error" {
+		t.Errorf("got messages %v, want source.price then source.error", said)
+	}
+
+	// A failing FX provider is a FetchError, whatever the sources did
+	c = audeth.NewConverter([]audeth.PriceFetcher{up}, audethtest.FakeFX{Err: audethtest.ErrInjected}, 0, nil)
+	var fetch audeth.FetchError
+	if _, err := c.Convert(100); !errors.As(err, &fetch) || !errors.Is(err, audethtest.ErrInjected) {
+		t.Errorf("got %v, want a FetchError wrapping the FX error", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	clock := audethtest.NewFakeClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	source := audethtest.NewFakeFetcher("A", 3000)
+	c := audeth.NewConverter([]audeth.PriceFetcher{source}, audethtest.FakeFX{Rate: 1.5}, time.Minute, nil, audeth.WithClock(clock))
+	if _, _, err := c.Rate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	quotes, err := c.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := nextQuote(t, quotes); q.Rate != 4500 {
+		t.Errorf("the first quote is %v, want the current 4500", q.Rate)
+	}
+
+	// The subscription refreshes once a TTL by itself; the first refresh finds the rate still cached
+	waitForWaiters(t, clock)
+	source.SetPrice(3100)
+	clock.Advance(time.Minute)
+	if q := nextQuote(t, quotes); q.Rate != 4650 || source.Calls() != 2 {
+		t.Errorf("after a TTL got %v after %d fetches, want 4650 after 2", q.Rate, source.Calls())
+	}
+
+	cancel()
+	select {
+	case _, open := <-quotes:
+		if open {
+			t.Error("got another quote after the context ended, want the channel closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the channel wasn't closed when the context ended")
+	}
+}
+
+// nextQuote waits a few seconds of real time for a quote, the subscription's refreshes running on their own goroutine
+func nextQuote(t *testing.T, quotes <-chan audeth.Quote) audeth.Quote {
+	t.Helper()
+	select {
+	case q, ok := <-quotes:
+		if !ok {
+			t.Fatal("the channel was closed")
+		}
+		return q
+	case <-time.After(5 * time.Second):
+		t.Fatal("no quote came")
+	}
+	return audeth.Quote{}
+}
+
+// waitForWaiters waits until something is waiting on clock, so advancing it isn't lost
+func waitForWaiters(t *testing.T, clock *audethtest.FakeClock) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); clock.Waiters() == 0; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("nothing waited on the clock")
+		}
+	}
+}
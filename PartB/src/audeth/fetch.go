@@ -0,0 +1,346 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/core"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+	"golang.org/x/sync/errgroup"
+)
+
+// PriceResult holds the price and any error from a price fetch
+// Struct bundles related data (price, error, and source) for organized error handling and result tracking
+type PriceResult struct {
+	Price float64
+	Err   error
+	Name  string    // Add name to track which API provided the result
+	At    time.Time // when the source answered
+	Quote string    // what an exchange quoted in before it was turned into USD, "" for USD itself
+	Raw   float64   // the price as quoted, set along with Quote
+
+	Bid, Ask float64 // the best bid and ask in USD, from sources whose ticker has them
+	AUD      float64 // the price in AUD at the FX rate fetched with it, 0 when that failed
+}
+
+// Good feature: Interfaces in Go are satisfied implicitly, encouraging decoupling and flexible architecture
+// This promotes modular code without needing explicit declarations
+type PriceFetcher interface {
+	FetchPrice() (float64, error)
+	Name() string
+}
+
+// FXProvider supplies the fiat leg of the conversion: how many AUD one USD buys
+// It mirrors PriceFetcher so providers can be swapped the same way exchanges are
+type FXProvider interface {
+	FetchRate() (float64, error)
+	Name() string
+}
+
+// aggregateUSD combines the valid USD quotes with aggregate instead of the mean
+// The errors name fetch.aggregate, which is where the audeth command's aggregate comes from
+func aggregateUSD(results []PriceResult, aggregate Aggregator) (float64, error) {
+	quotes := []any{}
+	sources := map[string]any{}
+	for _, r := range results {
+		if r.Err == nil {
+			quotes = append(quotes, r.Price)
+			sources[r.Name] = r.Price
+		}
+	}
+	if len(quotes) == 0 {
+		return 0, fmt.Errorf("no valid prices found")
+	}
+	usd, err := aggregate.EvalNumber(map[string]any{"quotes": quotes, "sources": sources})
+	if err != nil {
+		return 0, fmt.Errorf("fetch.aggregate: %v", err)
+	}
+	if usd <= 0 {
+		return 0, fmt.Errorf("fetch.aggregate gave %v, not a price", usd)
+	}
+	return usd, nil
+}
+
+// calculateAverageAndConvertToAUD takes a slice of price results and returns the average in AUD
+// usdToAUD is how many AUD one USD buys, supplied by an FXProvider
+// weights, by lower-case source name, count some sources more than others; a source without one counts once
+func calculateAverageAndConvertToAUD(results []PriceResult, usdToAUD float64, weights map[string]float64) (float64, error) {
+	var sum, total float64
+	var count int
+
+	// Calculate the weighted average of valid prices
+	for _, result := range results {
+		if result.Err == nil {
+			weight, ok := weights[strings.ToLower(result.Name)]
+			if !ok {
+				weight = 1
+			}
+			sum += result.Price * weight
+			total += weight
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, fmt.Errorf("no valid prices found")
+	}
+
+	averageUSD := sum / total
+	audPrice := averageUSD * usdToAUD
+
+	return audPrice, nil
+}
+
+// FetchOptions bounds one round of fetching
+// Limit caps how many sources are queried at once, Quorum stops early once that many have answered,
+// and Deadline gives up on sources that haven't answered in time; zero values mean no bound
+// MinSources and MaxDivergence are guards on the answers: too few of them, or quotes further apart
+// than MaxDivergence percent, fail the fetch instead of producing a rate
+// MinConfidence is a guard on the rate's confidence, from 0 to 1
+type FetchOptions struct {
+	Limit    int
+	Quorum   int
+	Deadline time.Duration
+
+	MinSources    int
+	MaxDivergence float64
+	MinConfidence float64 // checked whenever the rate is used, as it falls with age, see Converter.Sample
+
+	USDTPeg float64 // US dollars per USDT, 0 for the usual 1
+
+	Aggregate Aggregator         // combines the USD quotes instead of their mean when set
+	Weights   map[string]float64 // each source's share of the mean by lower-case name, 1 when missing
+}
+
+// Aggregator combines the quotes of a fetch into one USD price, in place of their mean
+// EvalNumber is given the valid quotes as quotes, a list in fetch order, and sources, a map from source name to quote;
+// the audeth command's fetch.aggregate expressions are Aggregators
+type Aggregator interface {
+	EvalNumber(env map[string]any) (float64, error)
+}
+
+// aggregation is how the rate is made from the quotes, as a Quote reports it: "mean", or "custom" with Aggregate
+func (o FetchOptions) aggregation() string {
+	if o.Aggregate != nil {
+		return "custom"
+	}
+	return "mean"
+}
+
+// QuotedFetcher is implemented by fetchers whose price isn't in USD, such as ETH/USDT or ETH/AUD
+// Fetchers without it are taken to quote USD, which every source did before
+type QuotedFetcher interface {
+	QuoteCurrency() string
+}
+
+// QuoteCurrency is the currency f quotes in
+func QuoteCurrency(f PriceFetcher) string {
+	if q, ok := f.(QuotedFetcher); ok {
+		return q.QuoteCurrency()
+	}
+	return "USD"
+}
+
+// ContextFetcher is implemented by fetchers that can abandon a request when its context ends
+// Fetchers without it still work, they just run to completion after a quorum is reached
+type ContextFetcher interface {
+	FetchPriceContext(ctx context.Context) (float64, error)
+}
+
+// ContextFXProvider is an FXProvider that can abandon its request when its context ends, as ContextFetcher is for prices
+// Without it the FX leg runs to completion, and a fetch with a deadline gives up waiting for it when the deadline passes
+type ContextFXProvider interface {
+	FetchRateContext(ctx context.Context) (float64, error)
+}
+
+// FetchRate asks fx for its rate, through FetchRateContext when it has one
+func FetchRate(ctx context.Context, fx FXProvider) (float64, error) {
+	if cf, ok := fx.(ContextFXProvider); ok {
+		return cf.FetchRateContext(ctx)
+	}
+	return fx.FetchRate()
+}
+
+// QuoteFetcher is a ContextFetcher that also returns the best bid and ask, when the source has them
+type QuoteFetcher interface {
+	FetchQuoteContext(ctx context.Context) (parsers.Quote, error)
+}
+
+// fetchAndCalculatePrice handles all the price fetching and calculation logic using an errgroup
+// Good feature: Go's concurrency model with goroutines makes parallel API calls simple and efficient
+// errgroup bounds how many run at once, and the shared context cancels the stragglers on quorum or deadline
+// Each goroutine writes only its own slot of the results slice, so no channel or lock is needed to collect them
+// The per-source results are returned too so they can be recorded in the history
+// The Quote has the rate with the FX rate, bid and ask it went with, and a zero rate on an error
+func (c *Converter) fetchAndCalculatePrice(ctx context.Context) (Quote, []PriceResult, error) {
+	fetchers, fx, opts := c.fetchers, c.fx, c.opts
+	started := c.clock.Now()
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+	quoteCtx, quorumReached := context.WithCancel(ctx)
+	defer quorumReached()
+
+	// The FX rate doesn't depend on the quotes, so fetch it alongside them instead of afterwards
+	// It is bound by the deadline but not by the quorum, which only cuts the quotes short
+	type fxResult struct {
+		rate float64
+		err  error
+	}
+	fxDone := make(chan fxResult, 1)
+	go func() {
+		rate, err := FetchRate(ctx, fx)
+		fxDone <- fxResult{rate, err}
+	}()
+
+	results := make([]PriceResult, len(fetchers))
+	skipped := make([]bool, len(fetchers))
+	var answered atomic.Int32
+	g := new(errgroup.Group)
+	if opts.Limit > 0 {
+		g.SetLimit(opts.Limit)
+	}
+	for i, f := range fetchers {
+		g.Go(func() error {
+			// A source still queued when the quorum is reached is never called
+			if quoteCtx.Err() != nil {
+				results[i] = PriceResult{Err: quoteCtx.Err(), Name: f.Name()}
+				skipped[i] = ctx.Err() == nil
+				return nil
+			}
+			var q parsers.Quote
+			var err error
+			if qf, ok := f.(QuoteFetcher); ok {
+				q, err = qf.FetchQuoteContext(quoteCtx)
+			} else if cf, ok := f.(ContextFetcher); ok {
+				q.Last, err = cf.FetchPriceContext(quoteCtx)
+			} else {
+				q.Last, err = f.FetchPrice()
+			}
+			price := q.Last
+			results[i] = PriceResult{Price: price, Err: c.redact(err), Name: f.Name(), At: c.clock.Now(), Bid: q.Bid, Ask: q.Ask}
+			if currency := QuoteCurrency(f); currency != "USD" && err == nil {
+				results[i].Quote, results[i].Raw = currency, price
+			}
+			// Failing once the quorum cancelled the context means it was cut short, not broken
+			skipped[i] = err != nil && quoteCtx.Err() != nil && ctx.Err() == nil
+			if err == nil && opts.Quorum > 0 && int(answered.Add(1)) == opts.Quorum {
+				quorumReached()
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	var fxRate fxResult
+	select {
+	case fxRate = <-fxDone:
+	case <-ctx.Done():
+		// A rate that is already in still counts; a provider without FetchRateContext is left to finish on its own
+		select {
+		case fxRate = <-fxDone:
+		default:
+			fxRate.err = ctx.Err()
+		}
+	}
+	usdToAUD, fxErr := fxRate.rate, fxRate.err
+
+	// Quotes in USDT or AUD are turned into USD now that the FX rate is known
+	for i, r := range results {
+		if r.Quote == "" {
+			continue
+		}
+		if results[i].Price, results[i].Err = core.ToUSD(r.Raw, r.Quote, usdToAUD, opts.USDTPeg); results[i].Err != nil {
+			results[i].Quote = ""
+			continue
+		}
+		// The bid and ask move by the same factor as the price
+		factor := results[i].Price / r.Raw
+		results[i].Bid, results[i].Ask = r.Bid*factor, r.Ask*factor
+	}
+
+	// Each quote in AUD is what --breakdown and /quotes show
+	if fxErr == nil {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].AUD = results[i].Price * usdToAUD
+			}
+		}
+	}
+
+	// Sources cut short because the quorum was reached didn't fail, so they are left out entirely
+	// A deadline, on the other hand, counts against the sources that missed it
+	var kept []PriceResult
+	for i, r := range results {
+		if skipped[i] {
+			c.say("source.skipped", r.Name, opts.Quorum)
+			continue
+		}
+		switch {
+		case r.Err != nil:
+			c.say("source.error", r.Name, r.Err)
+		case r.Quote != "":
+			c.say("source.quoted", r.Name, r.Quote, r.Raw, r.Price)
+		default:
+			c.say("source.price", r.Name, r.Price)
+		}
+		kept = append(kept, r)
+	}
+
+	if fxErr != nil {
+		return Quote{}, kept, FetchError{c.redact(fxErr)}
+	}
+	if err := checkAnswers(kept, opts); err != nil {
+		return Quote{}, kept, err
+	}
+	if opts.Aggregate != nil {
+		usd, err := aggregateUSD(kept, opts.Aggregate)
+		if err != nil {
+			return Quote{}, kept, FetchError{err}
+		}
+		return newQuote(c.clock.Now(), started, usd*usdToAUD, usdToAUD, opts.aggregation(), kept), kept, nil
+	}
+	avgAUD, err := calculateAverageAndConvertToAUD(kept, usdToAUD, opts.Weights)
+	if err != nil {
+		return Quote{}, kept, FetchError{err}
+	}
+	return newQuote(c.clock.Now(), started, avgAUD, usdToAUD, opts.aggregation(), kept), kept, nil
+}
+
+// checkAnswers applies the min_sources and max_divergence guards to the quotes that came back
+// Divergence is the gap between the highest and lowest quote as a percentage of their mean, as in the reports
+func checkAnswers(results []PriceResult, opts FetchOptions) error {
+	var min, max, sum float64
+	ok := 0
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if ok == 0 || r.Price < min {
+			min = r.Price
+		}
+		if ok == 0 || r.Price > max {
+			max = r.Price
+		}
+		sum += r.Price
+		ok++
+	}
+	if opts.MinSources > 0 && ok > 0 && ok < opts.MinSources {
+		return QuorumError{Got: ok, Want: opts.MinSources}
+	}
+	if opts.MaxDivergence > 0 && ok > 1 {
+		if spread := (max - min) / (sum / float64(ok)) * 100; spread > opts.MaxDivergence {
+			return DivergenceError{SpreadPct: spread, MaxPct: opts.MaxDivergence}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import (
+	"cmp"
+	"time"
+)
+
+// Quote is the ETH price in AUD together with what it was made from
+// It is what a fetch returns and what /quotes serves; a Sample keeps the parts that aren't derived from its sources,
+// and the JSON names are the ones a Sample and a ConversionResult use for the same values
+type Quote struct {
+	Time time.Time `json:"time" yaml:"time"` // when the quotes came back
+	Rate float64   `json:"rate_aud" yaml:"rate_aud"`
+
+	// Bid and Ask are the mean of the sources' best bid and ask in AUD, left out when no source has them
+	Bid float64 `json:"bid_aud,omitempty" yaml:"bid_aud,omitempty"`
+	Ask float64 `json:"ask_aud,omitempty" yaml:"ask_aud,omitempty"`
+
+	USD         float64 `json:"usd,omitempty" yaml:"usd,omitempty"`         // the ETH/USD price the rate was worked out from
+	FXRate      float64 `json:"fx_rate,omitempty" yaml:"fx_rate,omitempty"` // AUD per USD; unknown for samples recorded before it was kept
+	Aggregation string  `json:"aggregation" yaml:"aggregation"`
+
+	// Confidence is from 0 to 1, see confidence.go; a Sample keeps it as it was when fetched, At lowers it with age
+	Confidence float64       `json:"confidence" yaml:"confidence"`
+	Sources    []SourceQuote `json:"sources" yaml:"sources"`
+}
+
+// newQuote makes the quote for a fetch that began at started, from the rate, the FX rate it used,
+// how it was aggregated and the results behind it
+func newQuote(at, started time.Time, rateAUD, fxRate float64, aggregation string, results []PriceResult) Quote {
+	q := Quote{Rate: rateAUD, FXRate: fxRate, Aggregation: aggregation, Confidence: fetchConfidence(results, started)}
+	return newSample(at, q, results).Quote()
+}
+
+// Quote rebuilds the quote s was recorded from
+// The bid and ask need the FX rate, so a sample recorded before it was kept has neither;
+// one recorded before its aggregation was kept was a mean, the only kind there was
+func (s Sample) Quote() Quote {
+	q := Quote{
+		Time:        s.Time.UTC(),
+		Rate:        s.RateAUD,
+		FXRate:      s.FXRate,
+		Aggregation: cmp.Or(s.Aggregation, "mean"),
+		Confidence:  s.Confidence,
+		Sources:     []SourceQuote{},
+	}
+	q.Sources = append(q.Sources, sourceQuotes(s)...)
+	if s.FXRate <= 0 {
+		q.USD = sampleMeanUSD(s)
+		return q
+	}
+	q.USD = s.RateAUD / s.FXRate
+
+	var bids, asks float64
+	count := 0
+	for _, src := range s.Sources {
+		if src.Error == "" && src.Bid > 0 && src.Ask > 0 {
+			bids += src.Bid
+			asks += src.Ask
+			count++
+		}
+	}
+	if count > 0 {
+		q.Bid = bids / float64(count) * s.FXRate
+		q.Ask = asks / float64(count) * s.FXRate
+	}
+	return q
+}
+
+// SourceQuote is one source's part of a Quote
+type SourceQuote struct {
+	Name  string    `json:"name" yaml:"name"`
+	USD   float64   `json:"usd,omitempty" yaml:"usd,omitempty"`
+	AUD   float64   `json:"aud,omitempty" yaml:"aud,omitempty"` // the quote at the rate's USD/AUD rate
+	Time  time.Time `json:"time" yaml:"time"`
+	Error string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Bid   float64   `json:"bid,omitempty" yaml:"bid,omitempty"` // best bid in USD, from the sources that have one
+	Ask   float64   `json:"ask,omitempty" yaml:"ask,omitempty"`
+}
+
+// sourceQuotes is each source's part of sample; a source without its own time, e.g. read back from
+// the SQLite store, gets the sample's
+func sourceQuotes(sample Sample) []SourceQuote {
+	var quotes []SourceQuote
+	for _, src := range sample.Sources {
+		at := src.Time
+		if at.IsZero() {
+			at = sample.Time
+		}
+		quotes = append(quotes, SourceQuote{Name: src.Name, USD: src.USD, AUD: src.AUD, Time: at.UTC(), Error: src.Error, Bid: src.Bid, Ask: src.Ask})
+	}
+	return quotes
+}
+
+// sampleMeanUSD is the mean of the sample's USD quotes, the ETH/USD price its rate was worked out from
+func sampleMeanUSD(s Sample) float64 {
+	var sum float64
+	count := 0
+	for _, src := range s.Sources {
+		if src.Error == "" && src.USD > 0 {
+			sum += src.USD
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
@@ -0,0 +1,98 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// Sample is one recorded aggregation: the AUD rate plus what each source returned
+// Compacted daily samples also carry how many raw samples they replace and the day's range
+type Sample struct {
+	Time    time.Time      `json:"time"`
+	RateAUD float64        `json:"rate_aud"`
+	Sources []SourceSample `json:"sources"`
+
+	Aggregated int     `json:"aggregated,omitempty"`
+	High       float64 `json:"high,omitempty"`
+	Low        float64 `json:"low,omitempty"`
+
+	// FXRate is the USD/AUD rate the quotes were converted at, Confidence the quote's and Aggregation how
+	// the rate was made from them, see Quote; samples recorded before they were kept leave them zero
+	FXRate      float64 `json:"fx_rate,omitempty"`
+	Confidence  float64 `json:"confidence,omitempty"`
+	Aggregation string  `json:"aggregation,omitempty"`
+
+	// Attestation is set on samples served by a server with a signing key
+	Attestation *Attestation `json:"attestation,omitempty"`
+}
+
+// SourceSample records a single source's USD quote or the error it produced
+// Time is when the source answered; samples recorded before it was kept, and the SQLite store, leave it zero
+// AUD is the quote at the sample's USD/AUD rate; samples recorded before it was kept leave it zero
+type SourceSample struct {
+	Name  string    `json:"name"`
+	USD   float64   `json:"usd,omitempty"`
+	AUD   float64   `json:"aud,omitempty"`
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time,omitzero"`
+
+	// Bid and Ask are the best bid and ask in USD, from the sources whose ticker has them
+	Bid float64 `json:"bid,omitempty"`
+	Ask float64 `json:"ask,omitempty"`
+}
+
+// Attestation is an ed25519 signature over a Sample, so a consumer can check the rate and its input quotes
+// weren't changed after the server produced them
+// The signed message is the compact JSON of the sample with its attestation left out, exactly as
+// encoding/json writes it; "attest verify" rebuilds that message from the sample it is given
+// PublicKey says which key signed, a verifier should still compare it with the key it expects
+type Attestation struct {
+	Algorithm string `json:"algorithm"` // always "ed25519"
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+// newSample builds a Sample from the fetch results of one run and the quote made from them
+// The errors are kept as they are, the Converter has already redacted them
+func newSample(at time.Time, q Quote, results []PriceResult) Sample {
+	s := Sample{Time: at.UTC(), RateAUD: q.Rate, FXRate: q.FXRate, Confidence: q.Confidence, Aggregation: q.Aggregation}
+	for _, r := range results {
+		src := SourceSample{Name: r.Name, Time: r.At.UTC()}
+		if r.Err != nil {
+			src.Error = r.Err.Error()
+		} else {
+			src.USD, src.AUD, src.Bid, src.Ask = r.Price, r.AUD, r.Bid, r.Ask
+		}
+		s.Sources = append(s.Sources, src)
+	}
+	return s
+}
+
+// Clone is a copy of s sharing nothing with it, so a sample handed out can't change the one kept
+func (s Sample) Clone() Sample {
+	s.Sources = slices.Clone(s.Sources)
+	if s.Attestation != nil {
+		a := *s.Attestation
+		s.Attestation = &a
+	}
+	return s
+}
+
+// sampleResults turns a stored sample back into fetch results
+func sampleResults(s Sample) []PriceResult {
+	results := make([]PriceResult, 0, len(s.Sources))
+	for _, src := range s.Sources {
+		r := PriceResult{Price: src.USD, AUD: src.AUD, Name: src.Name, At: src.Time, Bid: src.Bid, Ask: src.Ask}
+		if src.Error != "" {
+			r.Err = errors.New(src.Error)
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,96 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audeth
+
+import (
+	"context"
+	"time"
+)
+
+// Subscribe returns a channel that gets a Quote every time the converter's rate is refreshed,
+// starting with the current one when there is one, until ctx is done and the channel is closed
+// While anyone is subscribed the converter refreshes itself once every TTL (a minute without one), so the
+// quotes keep coming without anything calling Rate; refreshes by callers and other processes are sent too
+// A subscriber that falls behind only misses quotes that are already out of date: the channel holds the
+// latest one, never a backlog
+func (c *Converter) Subscribe(ctx context.Context) (<-chan Quote, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ch := make(chan Quote, 1)
+	c.subMu.Lock()
+	if c.subs == nil {
+		c.subs = make(map[chan Quote]struct{})
+	}
+	c.subs[ch] = struct{}{}
+	if len(c.subs) == 1 {
+		pollCtx, stop := context.WithCancel(context.Background())
+		c.stopPoll = stop
+		go c.poll(pollCtx)
+	}
+	// The current rate is read only once ch is registered and under subMu, so a refresh either lands
+	// before it and is read here, or is published to ch after; one storing in between is never missed
+	if last := c.last.Load(); last != nil {
+		ch <- last.Quote().At(c.clock.Now())
+	}
+	c.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.subMu.Lock()
+		defer c.subMu.Unlock()
+		delete(c.subs, ch)
+		close(ch)
+		if len(c.subs) == 0 {
+			c.stopPoll()
+		}
+	}()
+	return ch, nil
+}
+
+// poll refreshes the rate once a TTL until ctx is cancelled by the last subscriber leaving
+func (c *Converter) poll(ctx context.Context) {
+	every := c.ttl
+	if every <= 0 {
+		every = DefaultTTL
+	}
+	var wait time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.clock.After(wait):
+		}
+		// A rate still fresh in the cache is reused, as for any other refresh
+		if _, err := c.refresh(); err != nil {
+			c.say("warning.subscription_refresh", err)
+		}
+		wait = every
+	}
+}
+
+// storeLast makes a copy of s the current rate, and sends it to the subscribers when it is newer than the last one
+func (c *Converter) storeLast(s Sample) {
+	s = s.Clone()
+	prev := c.last.Swap(&s)
+	if prev != nil && prev.Time.Equal(s.Time) {
+		return
+	}
+	c.publish(s.Quote().At(c.clock.Now()))
+}
+
+// publish sends q to every subscriber, replacing a quote one hasn't read yet instead of waiting for it
+func (c *Converter) publish(q Quote) {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	for ch := range c.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- q
+	}
+}
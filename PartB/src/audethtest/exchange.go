@@ -0,0 +1,40 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package audethtest
+
+import (
+	"net/http/httptest"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/internal/mockexchange"
+)
+
+// Exchange answers in the response format of every built-in source
+// Each source can be given its own price, failure status and latency with SetSourcePrice, Fail and SetLatency
+// It is a plain http.Handler, so it can be mounted on any server; MockExchange wraps it in httptest
+type Exchange = mockexchange.Exchange
+
+// NewExchange creates a handler quoting ETH at usd and one USD at audPerUSD
+func NewExchange(usd, audPerUSD float64) *Exchange {
+	return mockexchange.NewExchange(usd, audPerUSD)
+}
+
+// MockExchange is an Exchange running on an httptest server
+type MockExchange struct {
+	*httptest.Server
+	*Exchange
+}
+
+// NewMockExchange starts a server quoting ETH at usd and one USD at audPerUSD
+// Call Close when done, as with any httptest server
+func NewMockExchange(usd, audPerUSD float64) *MockExchange {
+	e := NewExchange(usd, audPerUSD)
+	return &MockExchange{Server: httptest.NewServer(e), Exchange: e}
+}
+
+// URL returns the full URL the named source should fetch from
+func (m *MockExchange) URL(name string) string {
+	return m.Server.URL + mockexchange.Path(name)
+}
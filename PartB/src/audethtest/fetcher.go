@@ -0,0 +1,152 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+// Package audethtest provides fakes for testing code built on the audeth converter without network calls
+// FakeFetcher, FakeFX and FakeClock satisfy audeth's PriceFetcher, FXProvider and Clock interfaces implicitly,
+// so this package doesn't need to import audeth at all; it is for tests only, nothing else in the module imports it
+package audethtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Step is one scripted response: a price or an error, returned after an optional delay
+type Step struct {
+	Price float64
+	Err   error
+	Delay time.Duration
+}
+
+// FakeFetcher returns scripted prices
+// Queued steps are used in order; once they run out every call returns the default step
+// Delays are waited out on the clock given to SetClock, or in real time without one
+type FakeFetcher struct {
+	name  string
+	clock *FakeClock
+
+	mu    sync.Mutex
+	def   Step
+	queue []Step
+	calls int
+}
+
+// NewFakeFetcher creates a fetcher that always returns price unless told otherwise
+func NewFakeFetcher(name string, price float64) *FakeFetcher {
+	return &FakeFetcher{name: name, def: Step{Price: price}}
+}
+
+func (f *FakeFetcher) Name() string {
+	return f.name
+}
+
+func (f *FakeFetcher) FetchPrice() (float64, error) {
+	return f.FetchPriceContext(context.Background())
+}
+
+// FetchPriceContext is FetchPrice giving up on a delay when ctx ends, as a real request would
+func (f *FakeFetcher) FetchPriceContext(ctx context.Context) (float64, error) {
+	f.mu.Lock()
+	step := f.def
+	if len(f.queue) > 0 {
+		step, f.queue = f.queue[0], f.queue[1:]
+	}
+	f.calls++
+	clock := f.clock
+	f.mu.Unlock()
+
+	// Wait outside the lock so concurrent callers see the latency in parallel, like real requests
+	if step.Delay > 0 {
+		var done <-chan time.Time
+		if clock != nil {
+			done = clock.After(step.Delay)
+		} else {
+			done = time.After(step.Delay)
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-done:
+		}
+	}
+	if step.Err != nil {
+		return 0, step.Err
+	}
+	return step.Price, nil
+}
+
+// SetPrice changes the default price
+func (f *FakeFetcher) SetPrice(price float64) *FakeFetcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.def.Price, f.def.Err = price, nil
+	return f
+}
+
+// SetClock makes the delays wait on clock, so they end when a test advances it rather than in real time
+func (f *FakeFetcher) SetClock(clock *FakeClock) *FakeFetcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.clock = clock
+	return f
+}
+
+// SetLatency delays every default response by d
+func (f *FakeFetcher) SetLatency(d time.Duration) *FakeFetcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.def.Delay = d
+	return f
+}
+
+// FailAlways makes every default response return err
+func (f *FakeFetcher) FailAlways(err error) *FakeFetcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.def.Err = err
+	return f
+}
+
+// FailNext makes the next n calls return err
+func (f *FakeFetcher) FailNext(n int, err error) *FakeFetcher {
+	for range n {
+		f.Then(Step{Err: err})
+	}
+	return f
+}
+
+// Then queues scripted steps ahead of the default
+func (f *FakeFetcher) Then(steps ...Step) *FakeFetcher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queue = append(f.queue, steps...)
+	return f
+}
+
+// Calls reports how many times FetchPrice has been called
+func (f *FakeFetcher) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// ErrInjected is a convenient error for scripted failures
+var ErrInjected = errors.New("audethtest: injected failure")
+
+// FakeFX is a fixed USD to AUD rate
+type FakeFX struct {
+	Rate float64
+	Err  error
+}
+
+func (f FakeFX) Name() string {
+	return "FakeFX"
+}
+
+func (f FakeFX) FetchRate() (float64, error) {
+	return f.Rate, f.Err
+}
@@ -0,0 +1,233 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PricePoint is an ETH price in AUD at a point in time
+type PricePoint struct {
+	Time time.Time
+	AUD  float64
+}
+
+// BacktestResult compares dollar-cost averaging against a single lump-sum purchase
+type BacktestResult struct {
+	Contributions int
+	Invested      float64
+	ETH           float64
+	AvgEntry      float64
+	FinalPrice    float64
+	FinalValue    float64
+	LumpSumETH    float64
+	LumpSumValue  float64
+}
+
+// parseInterval accepts Go durations plus day and week suffixes, e.g. "7d" or "2w"
+// time.ParseDuration stops at hours, so days and weeks are handled here
+func parseInterval(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(s, suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(s, suffix), 64)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid interval: %s", s)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid interval: %s", s)
+	}
+	return d, nil
+}
+
+// priceAt returns the most recent price at or before t, or the first later price if none exists
+// points must be sorted oldest first
+func priceAt(points []PricePoint, t time.Time) float64 {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Time.After(t) })
+	if i == 0 {
+		return points[0].AUD
+	}
+	return points[i-1].AUD
+}
+
+// backtestDCA buys amount AUD of ETH every interval from start to end
+// and compares the result with investing the same total at the start
+func backtestDCA(points []PricePoint, amount float64, every time.Duration, start, end time.Time) (BacktestResult, error) {
+	var r BacktestResult
+	if len(points) == 0 {
+		return r, fmt.Errorf("no price data to backtest against")
+	}
+	if amount <= 0 {
+		return r, fmt.Errorf("contribution amount must be positive")
+	}
+
+	for t := start; !t.After(end); t = t.Add(every) {
+		price := priceAt(points, t)
+		r.ETH += amount / price
+		r.Invested += amount
+		r.Contributions++
+	}
+	if r.Contributions == 0 {
+		return r, fmt.Errorf("schedule has no contributions between %s and %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+
+	r.AvgEntry = r.Invested / r.ETH
+	r.FinalPrice = priceAt(points, end)
+	r.FinalValue = r.ETH * r.FinalPrice
+	r.LumpSumETH = r.Invested / priceAt(points, start)
+	r.LumpSumValue = r.LumpSumETH * r.FinalPrice
+	return r, nil
+}
+
+// historyPricePoints loads recorded rates from the local history
+func historyPricePoints(store Store, from, to time.Time) ([]PricePoint, error) {
+	samples, err := store.Samples(from, to)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]PricePoint, 0, len(samples))
+	for _, s := range samples {
+		points = append(points, PricePoint{Time: s.Time, AUD: s.RateAUD})
+	}
+	return points, nil
+}
+
+// downloadPricePoints fetches historical ETH/AUD prices from CoinGecko's market chart API
+func downloadPricePoints(from, to time.Time) ([]PricePoint, error) {
+	url := fmt.Sprintf("https://api.coingecko.com/api/v3/coins/ethereum/market_chart/range?vs_currency=aud&from=%d&to=%d",
+		from.Unix(), to.Unix())
+	client := newHTTPClient(30 * time.Second)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+	}
+
+	var data struct {
+		Prices [][2]float64 `json:"prices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("parsing response failed: %v", err)
+	}
+
+	points := make([]PricePoint, 0, len(data.Prices))
+	for _, p := range data.Prices {
+		points = append(points, PricePoint{Time: time.UnixMilli(int64(p[0])).UTC(), AUD: p[1]})
+	}
+	return points, nil
+}
+
+// backtestFlags are the flags of the "backtest" command
+type backtestFlags struct {
+	amount  float64
+	every   string
+	fromStr string
+	toStr   string
+	source  string
+}
+
+func (f *backtestFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("backtest")
+	fs.Float64Var(&f.amount, "amount", 100, "AUD contributed each period")
+	fs.StringVar(&f.every, "every", "7d", "contribution interval, e.g. 1d, 2w, 12h")
+	fs.StringVar(&f.fromStr, "from", "", "start date YYYY-MM-DD (default one year ago)")
+	fs.StringVar(&f.toStr, "to", "", "end date YYYY-MM-DD (default today)")
+	fs.StringVar(&f.source, "source", "history", "price data: history (recorded runs) or coingecko (download)")
+	return fs
+}
+
+// runBacktest implements the "backtest" command
+func runBacktest(args []string) error {
+	var flags backtestFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	interval, err := parseInterval(flags.every)
+	if err != nil {
+		return UsageError{err}
+	}
+	if flags.amount <= 0 {
+		return usageErrorf("-amount must be positive")
+	}
+	if flags.source != "history" && flags.source != "coingecko" {
+		return usageErrorf("unknown source: %s (use history or coingecko)", flags.source)
+	}
+
+	to := defaultClock.Now().UTC()
+	if flags.toStr != "" {
+		if to, err = time.Parse("2006-01-02", flags.toStr); err != nil {
+			return usageErrorf("invalid -to date: %v", err)
+		}
+		to = to.Add(24*time.Hour - time.Second)
+	}
+	from := to.AddDate(-1, 0, 0)
+	if flags.fromStr != "" {
+		if from, err = time.Parse("2006-01-02", flags.fromStr); err != nil {
+			return usageErrorf("invalid -from date: %v", err)
+		}
+	}
+	if !from.Before(to) {
+		return usageErrorf("-from must be before -to")
+	}
+
+	var points []PricePoint
+	switch flags.source {
+	case "history":
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		store, err := openStore(cfg)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if points, err = historyPricePoints(store, from, to.Add(time.Second)); err != nil {
+			return err
+		}
+	case "coingecko":
+		if points, err = downloadPricePoints(from, to); err != nil {
+			return err
+		}
+	}
+
+	r, err := backtestDCA(points, flags.amount, interval, from, to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(tr("backtest.title", flags.amount, flags.every, from.Format("2006-01-02"), to.Format("2006-01-02"), len(points)))
+	fmt.Println(tr("backtest.contributions", r.Contributions))
+	fmt.Println(tr("backtest.invested", r.Invested))
+	fmt.Println(tr("backtest.eth", r.ETH))
+	fmt.Println(tr("backtest.entry", r.AvgEntry))
+	fmt.Println(tr("backtest.value", r.FinalValue, r.FinalPrice))
+	fmt.Println(tr("backtest.lump_sum", r.LumpSumETH, r.LumpSumValue))
+
+	diff := r.ETH - r.LumpSumETH
+	if diff >= 0 {
+		fmt.Println(tr("backtest.more", diff, diff/r.LumpSumETH*100))
+	} else {
+		fmt.Println(tr("backtest.less", -diff, diff/r.LumpSumETH*100))
+	}
+	return nil
+}
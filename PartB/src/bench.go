@@ -0,0 +1,201 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// benchResult is the outcome of one request sent by the bench command
+type benchResult struct {
+	latency time.Duration
+	status  int // 0 when the request failed before a response arrived
+	err     error
+}
+
+// BenchReport summarises a load test
+type BenchReport struct {
+	Sent       int
+	OK         int
+	Dropped    int // requests not sent because -max-inflight were already waiting
+	Elapsed    time.Duration
+	Statuses   map[int]int
+	Errors     map[string]int
+	Latencies  []time.Duration // sorted, successful and failed responses alike
+	TargetRate float64
+}
+
+// percentile reads the p-th percentile from sorted latencies using the nearest-rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// runLoad sends requests to url at a steady rate for duration
+// The rate is open-loop: a slow server doesn't slow the sender down, it builds up requests in flight,
+// which is what real clients do and what makes queueing show up in the percentiles
+func runLoad(client *http.Client, url string, rps float64, duration time.Duration, maxInflight int) BenchReport {
+	report := BenchReport{Statuses: make(map[int]int), Errors: make(map[string]int), TargetRate: rps}
+	results := make(chan benchResult, maxInflight)
+	slots := make(chan struct{}, maxInflight) // a counting semaphore built from a buffered channel
+
+	var wg sync.WaitGroup
+	var collected sync.WaitGroup
+	collected.Add(1)
+	go func() {
+		defer collected.Done()
+		for r := range results {
+			report.Latencies = append(report.Latencies, r.latency)
+			switch {
+			case r.err != nil:
+				report.Errors[r.err.Error()]++
+			default:
+				report.Statuses[r.status]++
+				if r.status >= 200 && r.status < 300 {
+					report.OK++
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rps))
+	defer ticker.Stop()
+	start := time.Now()
+	deadline := start.Add(duration)
+	for now := range ticker.C {
+		if now.After(deadline) {
+			break
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			report.Dropped++
+			continue
+		}
+		report.Sent++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			sent := time.Now()
+			resp, err := client.Get(url)
+			if err != nil {
+				results <- benchResult{latency: time.Since(sent), err: err}
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			results <- benchResult{latency: time.Since(sent), status: resp.StatusCode}
+		}()
+	}
+	wg.Wait()
+	close(results)
+	collected.Wait()
+
+	report.Elapsed = time.Since(start)
+	sort.Slice(report.Latencies, func(i, j int) bool { return report.Latencies[i] < report.Latencies[j] })
+	return report
+}
+
+// renderBench prints the report as text
+func renderBench(url string, r BenchReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bench %s\n", url)
+	fmt.Fprintf(&b, "  Requests:   %d sent in %s (%.1f/s achieved, %.1f/s target)\n",
+		r.Sent, r.Elapsed.Round(time.Millisecond), float64(r.Sent)/r.Elapsed.Seconds(), r.TargetRate)
+	if r.Dropped > 0 {
+		fmt.Fprintf(&b, "  Dropped:    %d (too many requests in flight)\n", r.Dropped)
+	}
+	if r.Sent > 0 {
+		fmt.Fprintf(&b, "  Success:    %d (%.2f%% errors)\n", r.OK, float64(r.Sent-r.OK)/float64(r.Sent)*100)
+	}
+	fmt.Fprintln(&b, "  Latency:")
+	for _, p := range []float64{50, 90, 95, 99} {
+		fmt.Fprintf(&b, "    p%-3.0f %s\n", p, percentile(r.Latencies, p).Round(time.Microsecond))
+	}
+	if n := len(r.Latencies); n > 0 {
+		fmt.Fprintf(&b, "    max  %s\n", r.Latencies[n-1].Round(time.Microsecond))
+	}
+
+	codes := make([]int, 0, len(r.Statuses))
+	for code := range r.Statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	if len(codes) > 0 {
+		fmt.Fprintln(&b, "  Status codes:")
+		for _, code := range codes {
+			fmt.Fprintf(&b, "    %d  %d\n", code, r.Statuses[code])
+		}
+	}
+	if len(r.Errors) > 0 {
+		msgs := make([]string, 0, len(r.Errors))
+		for msg := range r.Errors {
+			msgs = append(msgs, msg)
+		}
+		sort.Strings(msgs)
+		fmt.Fprintln(&b, "  Errors:")
+		for _, msg := range msgs {
+			fmt.Fprintf(&b, "    %dx %s\n", r.Errors[msg], msg)
+		}
+	}
+	return b.String()
+}
+
+// benchFlags are the flags of the "bench" command
+type benchFlags struct {
+	url         string
+	rps         float64
+	duration    time.Duration
+	timeout     time.Duration
+	maxInflight int
+}
+
+func (f *benchFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("bench")
+	fs.StringVar(&f.url, "url", "http://localhost:8080/rate", "endpoint to load, e.g. a serve instance or the mock exchange")
+	fs.Float64Var(&f.rps, "rps", 50, "requests per second")
+	fs.DurationVar(&f.duration, "duration", 10*time.Second, "how long to send for")
+	fs.DurationVar(&f.timeout, "timeout", 10*time.Second, "per-request timeout")
+	fs.IntVar(&f.maxInflight, "max-inflight", 1000, "requests allowed to wait at once before new ones are dropped")
+	return fs
+}
+
+// runBench implements the "bench" command
+func runBench(args []string) error {
+	var flags benchFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if flags.rps <= 0 || flags.duration <= 0 || flags.maxInflight <= 0 {
+		return usageErrorf("-rps, -duration and -max-inflight must be positive")
+	}
+
+	// A dedicated transport keeps connections to the target open instead of redialling for every request
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = flags.maxInflight
+	client := &http.Client{Timeout: flags.timeout, Transport: transport}
+
+	fmt.Println(tr("bench.sending", flags.rps, flags.url, flags.duration))
+	report := runLoad(client, flags.url, flags.rps, flags.duration, flags.maxInflight)
+	fmt.Print(renderBench(flags.url, report))
+	if report.Sent > 0 && report.OK == 0 {
+		return fmt.Errorf("no request succeeded")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// handlerTransport answers requests in-process from a handler, so a refresh can be measured without sockets
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	resp := rec.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// BenchmarkRefresh measures one whole uncached refresh of every source and the FX rate against the mock exchange
+// The refresh prints every quote it fetches, so stdout is silenced while it runs
+func BenchmarkRefresh(b *testing.B) {
+	saved := httpTransport
+	httpTransport = handlerTransport{handler: audethtest.NewExchange(3300, 1.52)}
+	defer func() { httpTransport = saved }()
+	devnull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer devnull.Close()
+	stdout := os.Stdout
+	os.Stdout = devnull
+	defer func() { os.Stdout = stdout }()
+
+	batch := NewCoinGeckoBatch()
+	// No TTLs, so every iteration fetches and parses all sources and the FX rate again
+	c := NewConverter(batchedFetchers(batch), batch.FX(), 0, nil)
+	b.ReportAllocs()
+	for b.Loop() {
+		batch.mu.Lock()
+		batch.prices = nil
+		batch.mu.Unlock()
+		if _, _, err := c.Rate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
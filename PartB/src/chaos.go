@@ -0,0 +1,101 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// chaosTransport sabotages a share of requests so failure handling can be exercised locally
+// Each affected request gets one of: extra latency, a 5xx response, or a mangled body
+// It wraps the real transport like the recorder does, so nothing else knows it is there
+type chaosTransport struct {
+	probability float64
+	maxLatency  time.Duration
+	next        http.RoundTripper
+
+	mu  sync.Mutex // rand.Rand isn't safe for concurrent use and fetchers run in parallel
+	rng *rand.Rand
+}
+
+func newChaosTransport(probability float64, maxLatency time.Duration, seed uint64, next http.RoundTripper) *chaosTransport {
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	return &chaosTransport{
+		probability: probability,
+		maxLatency:  maxLatency,
+		next:        next,
+		rng:         rand.New(rand.NewPCG(seed, seed>>1|1)),
+	}
+}
+
+// roll decides what happens to one request: "" leaves it alone
+func (t *chaosTransport) roll() (fault string, delay time.Duration, status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rng.Float64() >= t.probability {
+		return "", 0, 0
+	}
+	switch t.rng.IntN(3) {
+	case 0:
+		return "latency", time.Duration(t.rng.Int64N(int64(t.maxLatency) + 1)), 0
+	case 1:
+		return "status", 0, []int{500, 502, 503, 504}[t.rng.IntN(4)]
+	default:
+		return "malformed", 0, 0
+	}
+}
+
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault, delay, status := t.roll()
+	switch fault {
+	case "latency":
+		fmt.Fprintln(progressOut(), tr("chaos.delay", req.URL.Host, delay.Round(time.Millisecond)))
+		// Respect the client's timeout: a context that ends first fails the request like a slow server would
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	case "status":
+		fmt.Fprintln(progressOut(), tr("chaos.status", req.URL.Host, status))
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode: status,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(http.StatusText(status))),
+			Request:    req,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || fault != "malformed" {
+		return resp, err
+	}
+	fmt.Fprintln(progressOut(), tr("chaos.malformed", req.URL.Host))
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// Cutting the body in half leaves JSON that starts out right but never closes
+	body = body[:len(body)/2]
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
@@ -0,0 +1,132 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// copyUnit is set by --copy: "eth" or "wei" puts each conversion's amount on the clipboard, "" leaves it alone
+var copyUnit string
+
+// copyFlag is --copy as a flag.Value: a bare --copy means ETH, --copy=wei the amount in wei
+// IsBoolFlag is what lets the flag package accept it without a value
+type copyFlag struct{}
+
+func (copyFlag) String() string   { return copyUnit }
+func (copyFlag) IsBoolFlag() bool { return true }
+
+func (copyFlag) Set(v string) error {
+	switch v {
+	case "true", "eth":
+		copyUnit = "eth"
+	case "false":
+		copyUnit = ""
+	case "wei":
+		copyUnit = "wei"
+	default:
+		return fmt.Errorf("use --copy for ETH or --copy=wei")
+	}
+	return nil
+}
+
+// weiPerETH is 10^18, the smallest units in one ether
+var weiPerETH = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// weiAmount is the conversion in wei, rounded down to a whole wei
+// It is worked out from the AUD amount and the rate in big.Float, since float64 runs out of digits at about 10^16 wei
+func weiAmount(r ConversionResult) *big.Int {
+	if r.Rate <= 0 {
+		return new(big.Int)
+	}
+	prec := uint(256)
+	wei := new(big.Float).SetPrec(prec).SetFloat64(r.AUD)
+	wei.Quo(wei, new(big.Float).SetPrec(prec).SetFloat64(r.Rate))
+	wei.Mul(wei, weiPerETH)
+	n, _ := wei.Int(nil)
+	return n
+}
+
+// copyText is what --copy and "c" put on the clipboard: the ETH amount to 18 places without trailing zeros,
+// which is what wallets accept, or the whole number of wei
+func copyText(r ConversionResult, unit string) string {
+	wei := weiAmount(r)
+	if unit == "wei" {
+		return wei.String()
+	}
+	digits := fmt.Sprintf("%019s", wei.String())
+	whole, frac := digits[:len(digits)-18], strings.TrimRight(digits[len(digits)-18:], "0")
+	if frac == "" {
+		return whole
+	}
+	return whole + "." + frac
+}
+
+// clipboardCommands are the programs tried in turn to set the clipboard on this platform
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		cmds = append(cmds, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	return append(cmds, []string{"termux-clipboard-set"})
+}
+
+// errNoClipboard means no clipboard program was found and stdout isn't a terminal to send OSC 52 to
+var errNoClipboard = errors.New("no clipboard found: install xclip, xsel or wl-copy, or run in a terminal that supports OSC 52")
+
+// copyToClipboard puts text on the system clipboard
+// Without a clipboard program, e.g. over SSH, it falls back to the OSC 52 escape sequence,
+// which terminals such as iTerm2, kitty, WezTerm and tmux turn into a copy on the local machine
+func copyToClipboard(text string) error {
+	for _, args := range clipboardCommands() {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %v", args[0], err)
+		}
+		return nil
+	}
+	if !isTerminal(os.Stdout) {
+		return errNoClipboard
+	}
+	_, err := fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+// copyResult copies a conversion in unit and says so; failures are only reported, the conversion still stands
+// The message goes to progressOut, so --output json stays parseable
+func copyResult(r ConversionResult, unit string) {
+	text := copyText(r, unit)
+	if err := copyToClipboard(text); err != nil {
+		fmt.Fprintln(progressOut(), tr("copy.failed", err))
+		return
+	}
+	name := "ETH"
+	if unit == "wei" {
+		name = "wei"
+	}
+	fmt.Fprintln(progressOut(), tr("copy.done", text, name))
+}
@@ -0,0 +1,38 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+)
+
+// Clock is where time-dependent code gets the time from, see audeth.Clock
+type Clock = audeth.Clock
+
+// offsetClock runs at normal speed but starts from a chosen time, used by --now
+// so a replayed session sees the same dates as the recording
+type offsetClock struct {
+	offset time.Duration
+}
+
+func newOffsetClock(start time.Time) offsetClock {
+	return offsetClock{offset: start.Sub(time.Now())}
+}
+
+func (c offsetClock) Now() time.Time        { return time.Now().Add(c.offset) }
+func (c offsetClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// After sends the offset time, as Now would give it then
+func (c offsetClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	time.AfterFunc(d, func() { ch <- c.Now() })
+	return ch
+}
+
+// defaultClock is picked up by constructors; --now replaces it before anything is built
+var defaultClock Clock = audeth.RealClock{}
@@ -0,0 +1,154 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+// mockexchange serves every built-in exchange's response format from one local port
+// Point the converter at it with --api-base http://localhost:9090 for demos, load tests and CI
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/internal/mockexchange"
+)
+
+// sources lists the exchanges, then FX, which is last so the exchanges are sources[:len(sources)-1]
+var sources = []string{"CoinGecko", "Coinbase", "Bitstamp", "Kraken", "Bitfinex", "Binance", "OKX", "Bybit", "KuCoin", "Gemini", "Crypto.com", "Gate.io", "HTX", "Bitget", "MEXC", "BTC Markets", "Independent Reserve", "CoinSpot", "Swyftx", "CoinJar", "Luno", "CoinMarketCap", "CryptoCompare", "CoinAPI", "FX"}
+
+// parsePairs reads "Name=value,Name=value" flags such as -fail Bitfinex=503
+func parsePairs(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	if s == "" {
+		return out, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected Name=value, got %q", part)
+		}
+		out[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return out, nil
+}
+
+func main() {
+	addr := flag.String("addr", ":9090", "address to listen on")
+	usd := flag.Float64("usd", 3300, "ETH/USD price quoted by every source")
+	audPerUSD := flag.Float64("aud-per-usd", 1.52, "AUD bought by one USD")
+	fail := flag.String("fail", "", "sources that answer with an HTTP status, e.g. Bitfinex=503,Kraken=500")
+	latency := flag.String("latency", "", "extra delay per source, e.g. Kraken=2s")
+	spread := flag.Float64("spread", 0, "quote each source up to this many percent away from -usd")
+	drift := flag.Float64("drift", 0, "random walk the price by up to this many percent every -tick")
+	tick := flag.Duration("tick", time.Second, "how often -drift moves the price")
+	flag.Parse()
+
+	ex := mockexchange.NewExchange(*usd, *audPerUSD)
+
+	fails, err := parsePairs(*fail)
+	if err != nil {
+		log.Fatalf("-fail: %v", err)
+	}
+	for name, value := range fails {
+		status, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("-fail %s: %v", name, err)
+		}
+		ex.Fail(name, status)
+	}
+	delays, err := parsePairs(*latency)
+	if err != nil {
+		log.Fatalf("-latency: %v", err)
+	}
+	for name, value := range delays {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("-latency %s: %v", name, err)
+		}
+		ex.SetLatency(name, d)
+	}
+
+	// Each source keeps a fixed offset from the shared price, so the spread survives drifting
+	offsets := make(map[string]float64)
+	for _, name := range sources[:len(sources)-1] {
+		offsets[name] = (rand.Float64()*2 - 1) * *spread / 100
+	}
+	var mu sync.Mutex // guards price, moved by both the drift ticker and the control endpoint
+	price := *usd
+	setPrice := func(p float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		price = p
+		ex.SetPrice(p)
+		if *spread > 0 {
+			for name, off := range offsets {
+				ex.SetSourcePrice(name, p*(1+off))
+			}
+		}
+	}
+	setPrice(price)
+
+	if *drift > 0 {
+		go func() {
+			for range time.Tick(*tick) {
+				mu.Lock()
+				next := price * (1 + (rand.Float64()*2-1)**drift/100)
+				mu.Unlock()
+				setPrice(next)
+			}
+		}()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", ex)
+	// The control endpoints change behaviour while running, e.g. to fail a source mid load test
+	mux.HandleFunc("POST /_mock/price", func(w http.ResponseWriter, r *http.Request) {
+		p, err := strconv.ParseFloat(r.URL.Query().Get("usd"), 64)
+		if err != nil || p <= 0 {
+			http.Error(w, "usd must be a positive number", http.StatusBadRequest)
+			return
+		}
+		setPrice(p)
+	})
+	mux.HandleFunc("POST /_mock/fail", func(w http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(r.URL.Query().Get("status"))
+		if err != nil {
+			http.Error(w, "status must be an HTTP status code, 0 to recover", http.StatusBadRequest)
+			return
+		}
+		ex.Fail(r.URL.Query().Get("source"), status)
+	})
+	mux.HandleFunc("POST /_mock/latency", func(w http.ResponseWriter, r *http.Request) {
+		d, err := time.ParseDuration(r.URL.Query().Get("delay"))
+		if err != nil {
+			http.Error(w, "delay must be a duration such as 2s", http.StatusBadRequest)
+			return
+		}
+		ex.SetLatency(r.URL.Query().Get("source"), d)
+	})
+	mux.HandleFunc("GET /_mock/state", func(w http.ResponseWriter, r *http.Request) {
+		state := make(map[string]any)
+		for _, name := range sources {
+			state[name] = map[string]any{"usd": ex.Price(name), "requests": ex.Requests(name)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+
+	log.Printf("Mock exchanges listening on %s (ETH/USD %g, USD/AUD %g)", *addr, *usd, *audPerUSD)
+	// Any origin may read the fake APIs, so the WebAssembly build can be pointed at them from a local page
+	cors := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		mux.ServeHTTP(w, r)
+	})
+	log.Fatal(http.ListenAndServe(*addr, cors))
+}
@@ -0,0 +1,210 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+//go:build js && wasm
+
+// wasm is the converter for web pages: GOOS=js GOARCH=wasm go build -o audeth.wasm ./cmd/wasm
+// It puts an audeth object on the page, whose functions return promises, see "Web version" in the README
+// Everything runs in the browser; there is no server besides the exchanges themselves
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"syscall/js"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/core"
+)
+
+func main() {
+	js.Global().Set("audeth", js.ValueOf(map[string]any{
+		"sources": js.FuncOf(func(this js.Value, args []js.Value) any {
+			return toJS(allSources())
+		}),
+		// rate(options) resolves to {time, rate_aud, usd_aud, sources}
+		"rate": js.FuncOf(func(this js.Value, args []js.Value) any {
+			return promise(func() (any, error) {
+				c, err := converterFor(arg(args, 0))
+				if err != nil {
+					return nil, err
+				}
+				return c.Rate(context.Background())
+			})
+		}),
+		// convert(aud, options) is rate with the ETH that aud buys
+		"convert": js.FuncOf(func(this js.Value, args []js.Value) any {
+			return promise(func() (any, error) {
+				aud := arg(args, 0)
+				if aud.Type() != js.TypeNumber || aud.Float() <= 0 {
+					return nil, fmt.Errorf("the amount must be a positive number of AUD")
+				}
+				c, err := converterFor(arg(args, 1))
+				if err != nil {
+					return nil, err
+				}
+				rate, err := c.Rate(context.Background())
+				if err != nil {
+					return nil, err
+				}
+				return struct {
+					AUD float64 `json:"aud"`
+					ETH float64 `json:"eth"`
+					core.Rate
+				}{aud.Float(), rate.Convert(aud.Float()), rate}, nil
+			})
+		}),
+	}))
+	// The functions above are called from JavaScript for as long as the page is open
+	select {}
+}
+
+// allSources is every endpoint a page can ask for, with whether it is queried by default
+func allSources() []any {
+	var out []any
+	for _, s := range core.DefaultSources() {
+		out = append(out, map[string]any{"name": s.Name, "url": s.URL, "quote": s.Quote, "default": true})
+	}
+	for _, s := range core.OptionalSources() {
+		out = append(out, map[string]any{"name": s.Name, "url": s.URL, "quote": s.Quote, "default": false})
+	}
+	return out
+}
+
+// converterFor reads the options object:
+//   - sources: names to query instead of the default five, e.g. ["Kraken", "BTC Markets"]
+//   - apiBase: send every request to this server instead, like --api-base
+//   - fetch: a function called as fetch(url) that returns a promise of a Response, e.g. to go through a CORS proxy;
+//     without it the browser's own fetch is used
+func converterFor(opts js.Value) (*core.Converter, error) {
+	c := core.NewConverter()
+	if opts.Type() != js.TypeObject {
+		return c, nil
+	}
+	if names := opts.Get("sources"); names.Type() == js.TypeObject {
+		all := append(core.DefaultSources(), core.OptionalSources()...)
+		c.Sources = nil
+		for i := range names.Length() {
+			name := names.Index(i).String()
+			j := slices.IndexFunc(all, func(s core.Source) bool { return strings.EqualFold(s.Name, name) })
+			if j < 0 {
+				return nil, fmt.Errorf("there is no source called %s, see audeth.sources()", name)
+			}
+			c.Sources = append(c.Sources, all[j])
+		}
+		if len(c.Sources) == 0 {
+			return nil, fmt.Errorf("sources is empty")
+		}
+	}
+	if fetch := opts.Get("fetch"); fetch.Type() == js.TypeFunction {
+		c.Transport = fetchTransport{fetch: fetch}
+	}
+	if base := opts.Get("apiBase"); base.Type() == js.TypeString {
+		u, err := url.Parse(base.String())
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid apiBase: %s", base.String())
+		}
+		c.Transport = core.RebaseTransport{Base: u, Next: c.Transport}
+	}
+	return c, nil
+}
+
+// fetchTransport sends requests through a JavaScript fetch function
+// Go's own net/http already uses the browser's fetch, but not under Node and not through a proxy of the page's choosing
+type fetchTransport struct {
+	fetch js.Value
+}
+
+func (t fetchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := await(req.Context(), t.fetch.Invoke(req.URL.String()))
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %v", err)
+	}
+	text, err := await(req.Context(), resp.Call("text"))
+	if err != nil {
+		return nil, fmt.Errorf("reading the response failed: %v", err)
+	}
+	return &http.Response{
+		Status:     resp.Get("statusText").String(),
+		StatusCode: resp.Get("status").Int(),
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(text.String())),
+		Request:    req,
+	}, nil
+}
+
+// await waits for a JavaScript promise; it must not be called on the goroutine running a js.FuncOf callback
+func await(ctx context.Context, p js.Value) (js.Value, error) {
+	done := make(chan js.Value, 1)
+	failed := make(chan error, 1)
+	then := js.FuncOf(func(this js.Value, args []js.Value) any {
+		done <- arg(args, 0)
+		return nil
+	})
+	defer then.Release()
+	catch := js.FuncOf(func(this js.Value, args []js.Value) any {
+		failed <- fmt.Errorf("%s", jsString(arg(args, 0)))
+		return nil
+	})
+	defer catch.Release()
+	p.Call("then", then, catch)
+	select {
+	case v := <-done:
+		return v, nil
+	case err := <-failed:
+		return js.Undefined(), err
+	case <-ctx.Done():
+		return js.Undefined(), ctx.Err()
+	}
+}
+
+// promise runs fn on its own goroutine, since a callback from JavaScript can't block, and settles with its result
+func promise(fn func() (any, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(this js.Value, args []js.Value) any {
+		resolve, reject := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			v, err := fn()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(toJS(v))
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}
+
+// toJS hands v to JavaScript as plain objects, through its JSON form so struct tags give the field names
+func toJS(v any) js.Value {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+	return js.Global().Get("JSON").Call("parse", string(b))
+}
+
+// arg is args[i], or undefined when there are fewer arguments
+func arg(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
+
+// jsString describes a rejection, which is usually an Error but can be anything
+func jsString(v js.Value) string {
+	if v.Type() == js.TypeObject && v.Get("message").Type() == js.TypeString {
+		return v.Get("message").String()
+	}
+	return v.String()
+}
@@ -0,0 +1,204 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// coinGeckoBatchReuse is how long one /simple/price response answers every pair in it
+// The quote and FX lookups of a refresh start together but don't always overlap exactly
+const coinGeckoBatchReuse = 2 * time.Second
+
+// CoinGeckoBatch asks /simple/price for every registered coin and currency in a single request
+// The endpoint takes comma-separated ids= and vs_currencies= lists, so the ETH/USD quote and the
+// USD to AUD rate, which used to be two calls, now share one, as would any pairs added later
+type CoinGeckoBatch struct {
+	base    string
+	timeout time.Duration
+	clock   Clock
+
+	mu         sync.Mutex
+	ids        []string
+	currencies []string
+	prices     map[string]map[string]float64
+	fetchedAt  time.Time
+
+	group singleflight.Group // callers arriving during a request wait for it instead of sending another
+}
+
+// NewCoinGeckoBatch creates an empty batch; Quote and FX register the pairs they need
+func NewCoinGeckoBatch() *CoinGeckoBatch {
+	return &CoinGeckoBatch{
+		base:    "https://api.coingecko.com/api/v3/simple/price",
+		timeout: 10 * time.Second,
+		clock:   defaultClock,
+	}
+}
+
+// add registers a pair, keeping the order they were added in
+func (b *CoinGeckoBatch) add(id, currency string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !slices.Contains(b.ids, id) {
+		b.ids = append(b.ids, id)
+	}
+	if !slices.Contains(b.currencies, currency) {
+		b.currencies = append(b.currencies, currency)
+	}
+}
+
+// url builds the request for every registered pair
+func (b *CoinGeckoBatch) url() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.base + "?ids=" + strings.Join(b.ids, ",") + "&vs_currencies=" + strings.Join(b.currencies, ",")
+}
+
+// Price returns the id's price in currency, from the latest batch or a new one
+func (b *CoinGeckoBatch) Price(ctx context.Context, id, currency string) (float64, error) {
+	prices, err := b.latest(ctx)
+	if err != nil {
+		return 0, err
+	}
+	price, ok := prices[id][currency]
+	if !ok {
+		return 0, fmt.Errorf("parsing response failed: missing %s.%s", id, currency)
+	}
+	return price, nil
+}
+
+// latest returns the reusable response or fetches a new one
+// The request itself ignores the caller's cancellation, since other callers may be waiting on it,
+// but each caller stops waiting when its own ctx ends
+func (b *CoinGeckoBatch) latest(ctx context.Context) (map[string]map[string]float64, error) {
+	b.mu.Lock()
+	if b.prices != nil && b.clock.Now().Sub(b.fetchedAt) < coinGeckoBatchReuse {
+		prices := b.prices
+		b.mu.Unlock()
+		return prices, nil
+	}
+	b.mu.Unlock()
+
+	ch := b.group.DoChan("simple/price", func() (any, error) {
+		prices, err := b.fetch(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		b.mu.Lock()
+		b.prices, b.fetchedAt = prices, b.clock.Now()
+		b.mu.Unlock()
+		return prices, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(map[string]map[string]float64), nil
+	}
+}
+
+func (b *CoinGeckoBatch) fetch(ctx context.Context) (map[string]map[string]float64, error) {
+	client := newHTTPClient(b.timeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+	}
+
+	prices, err := parsers.DecodeCoinGeckoPrices(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing response failed: %v", err)
+	}
+	return prices, nil
+}
+
+// Quote registers id/currency and returns a PriceFetcher for it, e.g. Quote("ethereum", "usd")
+func (b *CoinGeckoBatch) Quote(id, currency string) PriceFetcher {
+	b.add(id, currency)
+	return coinGeckoQuote{batch: b, id: id, currency: currency}
+}
+
+// FX registers ETH in USD and AUD and returns an FXProvider implying the rate from them,
+// the same way CoinGeckoFX does with its own request
+func (b *CoinGeckoBatch) FX() FXProvider {
+	b.add("ethereum", "usd")
+	b.add("ethereum", "aud")
+	return coinGeckoBatchFX{batch: b}
+}
+
+// coinGeckoQuote is one pair's price read from the batch
+type coinGeckoQuote struct {
+	batch        *CoinGeckoBatch
+	id, currency string
+}
+
+func (q coinGeckoQuote) Name() string {
+	return "CoinGecko"
+}
+
+func (q coinGeckoQuote) FetchPrice() (float64, error) {
+	return q.FetchPriceContext(context.Background())
+}
+
+func (q coinGeckoQuote) FetchPriceContext(ctx context.Context) (float64, error) {
+	price, err := q.batch.Price(ctx, q.id, q.currency)
+	if err != nil {
+		return 0, err
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("invalid price: %f", price)
+	}
+	return price, nil
+}
+
+// coinGeckoBatchFX is the USD to AUD rate read from the batch
+type coinGeckoBatchFX struct {
+	batch *CoinGeckoBatch
+}
+
+func (f coinGeckoBatchFX) Name() string {
+	return "CoinGecko"
+}
+
+func (f coinGeckoBatchFX) FetchRate() (float64, error) {
+	return f.FetchRateContext(context.Background())
+}
+
+func (f coinGeckoBatchFX) FetchRateContext(ctx context.Context) (float64, error) {
+	usd, err := f.batch.Price(ctx, "ethereum", "usd")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	aud, err := f.batch.Price(ctx, "ethereum", "aud")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	if usd == 0 || aud == 0 {
+		return 0, fmt.Errorf("failed to decode exchange rates: invalid exchange rates")
+	}
+	return aud / usd, nil
+}
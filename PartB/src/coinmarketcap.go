@@ -0,0 +1,67 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// coinMarketCapKeyHeader carries the API key on every CoinMarketCap request
+const coinMarketCapKeyHeader = "X-CMC_PRO_API_KEY"
+
+// coinMarketCapURL is the quotes endpoint; symbol=ETH and the convert= currencies are added per request
+const coinMarketCapURL = "https://pro-api.coinmarketcap.com/v2/cryptocurrency/quotes/latest"
+
+// NewCoinMarketCapBatch creates a batch asking quotes/latest for ETH, authenticating with key
+// Batching matters more here than for CoinGecko, since every call counts against the key's monthly credits
+func NewCoinMarketCapBatch(key string) *ETHBatch {
+	timeout := 10 * time.Second
+	return newETHBatch("CoinMarketCap", func(ctx context.Context, currencies []string) (map[string]float64, error) {
+		if key == "" {
+			return nil, fmt.Errorf("CoinMarketCap needs an API key, set api_keys.coinmarketcap in config.json")
+		}
+		u := coinMarketCapURL + "?symbol=ETH&convert=" + strings.Join(currencies, ",")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %v", err)
+		}
+		req.Header.Set(coinMarketCapKeyHeader, key)
+		req.Header.Set("Accept", "application/json")
+		resp, err := newHTTPClient(timeout).Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		// CoinMarketCap explains a bad key or an exhausted plan in the body, so it is read whatever the status
+		prices, err := parsers.DecodeCoinMarketCapQuotes(resp.Body, "ETH")
+		if err != nil {
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("non-OK status code: %d: %w", resp.StatusCode, err)
+			}
+			return nil, fmt.Errorf("parsing response failed: %w", err)
+		}
+		return prices, nil
+	})
+}
+
+// newCoinMarketCapFromConfig creates the batch, resolving api_keys.coinmarketcap only when the source or the FX rate uses it,
+// so a config without the key still loads as long as CoinMarketCap isn't asked for
+func newCoinMarketCapFromConfig(cfg Config) (*ETHBatch, error) {
+	if !cfg.usesBatch("coinmarketcap") {
+		return NewCoinMarketCapBatch(""), nil
+	}
+	key, err := cfg.apiKey("coinmarketcap")
+	if err != nil {
+		return nil, err
+	}
+	return NewCoinMarketCapBatch(key), nil
+}
@@ -0,0 +1,263 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/core"
+)
+
+// prefetch is set by --prefetch; without it nothing is fetched until a rate is actually needed
+var prefetch bool
+
+// globalFlags are the flags of the program itself, given before any command
+type globalFlags struct {
+	record        string
+	replay        string
+	apiBase       string
+	now           string
+	demo          bool
+	demoSeed      uint64
+	chaos         float64
+	chaosLatency  time.Duration
+	chaosSeed     uint64
+	prefetch      bool
+	output        string
+	format        string
+	delimiter     string
+	private       bool
+	maxOutbound   int
+	plain         bool
+	detailed      bool
+	lock          time.Duration
+	breakdown     bool
+	minConfidence float64
+	ticker        bool
+	refresh       time.Duration
+	lang          string
+}
+
+func (f *globalFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("audeth")
+	fs.StringVar(&f.record, "record", "", "save every HTTP response into this directory")
+	fs.StringVar(&f.replay, "replay", "", "answer HTTP requests from responses saved with --record")
+	fs.StringVar(&f.apiBase, "api-base", "", "send every API request to this server instead, e.g. http://localhost:9090")
+	fs.StringVar(&f.now, "now", "", "pretend the program started at this time (RFC 3339 or YYYY-MM-DD)")
+	fs.BoolVar(&f.demo, "demo", false, "use seeded fake prices and a separate demo history instead of real APIs")
+	fs.Uint64Var(&f.demoSeed, "demo-seed", 1, "seed for --demo prices")
+	fs.Float64Var(&f.chaos, "chaos", 0, "inject a fault into this share of HTTP requests, e.g. 0.3")
+	fs.DurationVar(&f.chaosLatency, "chaos-latency", 3*time.Second, "longest delay injected by --chaos")
+	fs.Uint64Var(&f.chaosSeed, "chaos-seed", 0, "seed for --chaos so a run can be repeated (default random)")
+	fs.BoolVar(&f.prefetch, "prefetch", false, "fetch rates at startup instead of on the first conversion or request")
+	fs.StringVar(&f.output, "output", "text", "how conversions are written: text, json, yaml, csv, tsv, influx or xlsx")
+	fs.StringVar(&f.format, "format", "", "render each conversion with a Go template, e.g. '{{.ETH}} ETH @ {{.Rate}}'")
+	fs.StringVar(&f.delimiter, "delimiter", ",", "field separator for --output csv, e.g. \";\"")
+	fs.BoolVar(&f.private, "private", false, "fetch through privacy.proxy (default Tor) without identifying headers, and save nothing about the fetches")
+	fs.IntVar(&f.maxOutbound, "max-outbound", defaultMaxOutbound, "API requests allowed in flight at once across the program")
+	fs.BoolVar(&f.plain, "plain", false, "ASCII-only, one line per message, for screen readers and logs (default when stdout isn't a terminal)")
+	fs.BoolVar(&f.detailed, "detailed", false, "also show each conversion in gwei and US dollars")
+	fs.DurationVar(&f.lock, "lock", 0, "keep the first rate fetched for this long, e.g. 60s, so every amount in the window converts at the same rate")
+	fs.BoolVar(&f.breakdown, "breakdown", false, "also show each source's quote in AUD and the rate's confidence under a conversion")
+	fs.Float64Var(&f.minConfidence, "min-confidence", 0, "fail instead of converting when the rate's confidence is below this, from 0 to 1 (default fetch.min_confidence)")
+	fs.BoolVar(&f.ticker, "ticker", false, "print one line with the rate, updated every --refresh, for status bars such as polybar, i3blocks or tmux")
+	fs.DurationVar(&f.refresh, "refresh", 30*time.Second, "how often --ticker updates its line; 0 prints it once and exits")
+	fs.Var(copyFlag{}, "copy", "put each conversion's ETH amount on the clipboard; --copy=wei copies it in wei")
+	fs.StringVar(&f.lang, "lang", "", "language of messages and prompts: "+strings.Join(languages(), ", ")+" (default from LANG)")
+	return fs
+}
+
+// parseGlobalFlags handles the flags that come before any command, e.g. "--replay fixtures/ report"
+// It returns the remaining arguments, starting with the command name if there is one
+func parseGlobalFlags(args []string) ([]string, error) {
+	var flags globalFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	// First, so the errors below are already in the chosen language and form
+	plainSet := false
+	fs.Visit(func(f *flag.Flag) { plainSet = plainSet || f.Name == "plain" })
+	setPlain(flags.plain, plainSet)
+	if err := setLanguage(flags.lang); err != nil {
+		return nil, UsageError{err}
+	}
+
+	if flags.now != "" {
+		start, err := parseDateFlag(flags.now)
+		if err != nil {
+			return nil, usageErrorf("invalid --now: %v", err)
+		}
+		defaultClock = newOffsetClock(start)
+	}
+
+	prefetch = flags.prefetch
+	detailed = flags.detailed
+	breakdown = flags.breakdown
+	if err := setOutputFormat(flags.output); err != nil {
+		return nil, UsageError{err}
+	}
+	if flags.lock < 0 {
+		return nil, usageErrorf("--lock can't be negative")
+	}
+	rateLock = flags.lock
+	if flags.minConfidence < 0 || flags.minConfidence > 1 {
+		return nil, usageErrorf("--min-confidence is from 0 to 1, e.g. 0.8")
+	}
+	minConfidence = flags.minConfidence
+	switch {
+	case flags.ticker:
+		if flags.lock > 0 {
+			return nil, usageErrorf("--ticker can't be combined with --lock, which would keep the ticker's rate still")
+		}
+		if fs.NArg() > 0 {
+			return nil, usageErrorf("--ticker can't be combined with a command")
+		}
+		if err := setTicker(flags.refresh, flags.format); err != nil {
+			return nil, UsageError{err}
+		}
+	case flags.format != "":
+		if err := setOutputTemplate(flags.format); err != nil {
+			return nil, UsageError{err}
+		}
+	}
+	comma, err := parseDelimiter(flags.delimiter)
+	if err != nil {
+		return nil, usageErrorf("invalid --delimiter: %v", err)
+	}
+	outputComma = comma
+	if flags.maxOutbound <= 0 {
+		return nil, usageErrorf("--max-outbound must be positive")
+	}
+	if err := configureTLS(); err != nil {
+		return nil, err
+	}
+	if flags.private {
+		if flags.record != "" {
+			return nil, usageErrorf("--private can't be combined with --record, which saves every request")
+		}
+		if err := enablePrivacy(); err != nil {
+			return nil, err
+		}
+		private = true
+	}
+	// Innermost, so the limit counts real connections; replay and demo replace it as they open none
+	httpTransport = newLimitTransport(flags.maxOutbound, httpTransport)
+
+	switch {
+	case flags.demo && (flags.record != "" || flags.replay != ""):
+		return nil, usageErrorf("--demo can't be combined with --record or --replay")
+	case flags.record != "" && flags.replay != "":
+		return nil, usageErrorf("--record and --replay can't be used together")
+	case flags.record != "":
+		httpTransport = recordingTransport{dir: flags.record, next: httpTransport}
+	case flags.replay != "":
+		httpTransport = replayingTransport{dir: flags.replay}
+		streamDialer = nil
+	case flags.demo:
+		if err := enableDemo(flags.demoSeed); err != nil {
+			return nil, fmt.Errorf("starting demo mode failed: %v", err)
+		}
+		streamDialer = nil
+	}
+	if flags.apiBase != "" {
+		base, err := url.Parse(flags.apiBase)
+		if err != nil || base.Host == "" {
+			return nil, usageErrorf("invalid --api-base: %s", flags.apiBase)
+		}
+		httpTransport = core.RebaseTransport{Base: base, Next: httpTransport}
+		streamBase = base
+	}
+	if flags.chaos < 0 || flags.chaos > 1 {
+		return nil, usageErrorf("--chaos must be between 0 and 1")
+	}
+	if flags.chaos > 0 {
+		// Applied last so --record never saves an injected fault
+		httpTransport = newChaosTransport(flags.chaos, flags.chaosLatency, flags.chaosSeed, httpTransport)
+	}
+	return fs.Args(), nil
+}
+
+// command is one subcommand such as "report"
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+	// subcommands are the words it takes first, e.g. history's prune, export and import
+	subcommands []string
+	// flags declares the command's own flags, nil when it takes none, and subcommandFlags those of each
+	// subcommand that takes some; run parses the same FlagSets, so completion lists them without running anything
+	flags           flagSetFunc
+	subcommandFlags map[string]flagSetFunc
+}
+
+// flagSetFunc makes a command's FlagSet, declaring its flags and nothing else
+type flagSetFunc func() *flag.FlagSet
+
+// flagsOf is the flagSetFunc of a flag struct's flagSet method, e.g. flagsOf((*reportFlags).flagSet)
+func flagsOf[T any](flagSet func(*T) *flag.FlagSet) flagSetFunc {
+	return func() *flag.FlagSet { return flagSet(new(T)) }
+}
+
+// commandTable lists the commands in the order completion offers them
+// It is a function rather than a variable because completion, which is in it, reads it
+func commandTable() []command {
+	return []command{
+		{name: "convert", summary: "convert amounts given as arguments or one per line on stdin", run: runConvert, flags: flagsOf((*convertFlags).flagSet)},
+		{name: "report", summary: "summarise the rates and conversions in the history", run: runReport, flags: flagsOf((*reportFlags).flagSet)},
+		{name: "backtest", summary: "replay a regular purchase over past prices", run: runBacktest, flags: flagsOf((*backtestFlags).flagSet)},
+		{name: "routes", summary: "compare buying through each exchange, with fees", run: runRoutes, flags: flagsOf((*routesFlags).flagSet)},
+		{name: "history", summary: "prune, export or import the history", run: runHistory,
+			subcommands: []string{"prune", "export", "import"}, subcommandFlags: map[string]flagSetFunc{
+				"prune":  flagsOf((*historyPruneFlags).flagSet),
+				"export": flagsOf((*historyExportFlags).flagSet),
+				"import": flagsOf((*historyImportFlags).flagSet),
+			}},
+		{name: "serve", summary: "serve the rate over HTTP", run: runServe, flags: flagsOf((*serveFlags).flagSet)},
+		{name: "snapshot", summary: "save the rates and caches to a file, or restore them", run: runSnapshot,
+			subcommands: []string{"restore"}, flags: flagsOf((*snapshotFlags).flagSet)},
+		{name: "selftest", summary: "check every exchange still answers the way its parser expects", run: runSelftest, flags: flagsOf((*selftestFlags).flagSet)},
+		{name: "bench", summary: "load test a rate endpoint", run: runBench, flags: flagsOf((*benchFlags).flagSet)},
+		{name: "pins", summary: "print the certificate pins of hosts", run: runPins},
+		{name: "secrets", summary: "store, delete or check API keys in the keychain", run: runSecrets, subcommands: []string{"set", "delete", "check"}},
+		{name: "attest", summary: "create a signing key, or sign and verify samples", run: runAttest,
+			subcommands: []string{"keygen", "verify", "sign"}, subcommandFlags: map[string]flagSetFunc{"verify": flagsOf((*attestVerifyFlags).flagSet)}},
+		{name: "sheets", summary: "append rates or conversions to a Google Sheet", run: runSheets, subcommands: []string{"append"},
+			subcommandFlags: map[string]flagSetFunc{"append": flagsOf((*sheetsAppendFlags).flagSet)}},
+		{name: "sources", summary: "list the price and FX sources and their health", run: runSources, flags: flagsOf((*sourcesFlags).flagSet)},
+		{name: "self-update", summary: "install the latest release", run: runSelfUpdate, flags: flagsOf((*selfUpdateFlags).flagSet)},
+		{name: "version", summary: "print the version and build details, or check the exchanges still match it", run: runVersion, flags: flagsOf((*versionFlags).flagSet)},
+		{name: "completion", summary: "print a shell completion script", run: runCompletion, subcommands: completionShells},
+	}
+}
+
+// runCommand dispatches a subcommand such as "report"
+// With no subcommand, main falls through to the interactive converter
+func runCommand(name string, args []string) error {
+	for _, c := range commandTable() {
+		if c.name == name {
+			return c.run(args)
+		}
+	}
+	return usageErrorf("unknown command: %s", name)
+}
+
+// newFlagSet is flag.NewFlagSet as the commands use it, returning errors for parseFlags to wrap
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+// parseFlags parses a command's flags; a bad one is a UsageError, and -h is one wrapping flag.ErrHelp
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return UsageError{err}
+	}
+	return nil
+}
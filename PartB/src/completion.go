@@ -0,0 +1,501 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/core"
+)
+
+// completionShells are the shells "completion" writes a script for
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionContext is what can be typed after some words, e.g. after "history export" or after nothing at all
+// The scripts work out the context the same way in every shell: starting from the top, each word that names
+// a deeper context moves into it, a flag is skipped along with its value, and any other word is an argument
+type completionContext struct {
+	path  string // the words leading here, "" at the top
+	words []completionWord
+	flags []completionFlag
+}
+
+type completionWord struct {
+	name, summary string
+}
+
+type completionFlag struct {
+	name, usage string
+	dashes      string   // "--" for the flags before a command, which the README writes that way, "-" after
+	takesValue  bool     // false for a boolean flag
+	values      []string // what the value can be, when it is one of a few
+}
+
+// completionValues are the flags whose values completion can offer, keyed by context and flag name
+var completionValues = map[string]func() []string{
+	"|output":               func() []string { return outputFormats },
+	"|lang":                 languages,
+	"report|format":         fixedValues("text", "markdown", "html", "json", "xlsx"),
+	"report|period":         fixedValues("daily", "weekly"),
+	"backtest|source":       fixedValues("history", "coingecko"),
+	"history export|format": fixedValues("jsonl", "csv", "tsv", "influx", "xlsx"),
+	"history export|kind":   fixedValues("rates", "conversions"),
+	"history import|format": fixedValues("jsonl", "csv", "tsv"),
+	"history import|kind":   fixedValues("rates", "conversions"),
+	"sheets append|kind":    fixedValues("rates", "conversions"),
+	"sources|format":        fixedValues("text", "json"),
+	"version|format":        fixedValues("text", "json"),
+}
+
+func fixedValues(values ...string) func() []string {
+	return func() []string { return values }
+}
+
+// runCompletion implements the "completion" command
+// The configured sources are written into the script, so it should be generated again after they change
+func runCompletion(args []string) error {
+	if len(args) != 1 || !slices.Contains(completionShells, args[0]) {
+		return usageErrorf("usage: completion %s", strings.Join(completionShells, "|"))
+	}
+	contexts, err := completionContexts()
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(os.Stdout, contexts)
+	case "zsh":
+		writeZshCompletion(os.Stdout, contexts)
+	case "fish":
+		writeFishCompletion(os.Stdout, contexts)
+	case "powershell":
+		writePowerShellCompletion(os.Stdout, contexts)
+	}
+	return nil
+}
+
+// completionContexts reads the commands, their flags and the source names into the contexts the scripts use
+func completionContexts() ([]completionContext, error) {
+	sourceWords, err := completionSourceWords()
+	if err != nil {
+		return nil, err
+	}
+	top := completionContext{flags: completionFlags("", "--", flagsOf((*globalFlags).flagSet))}
+	contexts := []completionContext{{}}
+	for _, c := range commandTable() {
+		top.words = append(top.words, completionWord{c.name, c.summary})
+		ctx := completionContext{path: c.name}
+		for _, sub := range c.subcommands {
+			ctx.words = append(ctx.words, completionWord{name: sub})
+		}
+		if c.name == "sources" {
+			ctx.words = sourceWords
+		}
+		if c.flags != nil {
+			ctx.flags = completionFlags(c.name, "-", c.flags)
+		}
+		contexts = append(contexts, ctx)
+		for _, sub := range c.subcommands {
+			subCtx := completionContext{path: c.name + " " + sub}
+			if flags, ok := c.subcommandFlags[sub]; ok {
+				subCtx.flags = completionFlags(subCtx.path, "-", flags)
+			}
+			contexts = append(contexts, subCtx)
+		}
+	}
+	contexts[0] = top
+	return contexts, nil
+}
+
+func completionFlags(path, dashes string, flagSet flagSetFunc) []completionFlag {
+	var out []completionFlag
+	flagSet().VisitAll(func(f *flag.Flag) {
+		cf := completionFlag{name: f.Name, usage: f.Usage, dashes: dashes, takesValue: true}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			cf.takesValue = false
+		}
+		if values, ok := completionValues[path+"|"+f.Name]; ok {
+			cf.values = values()
+		}
+		out = append(out, cf)
+	})
+	return out
+}
+
+// completionSourceWords are what "sources" can be narrowed to: every source name the config can turn on,
+// and the currencies they quote in
+func completionSourceWords() ([]completionWord, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var words []completionWord
+	quotes := []string{"AUD", "USD"}
+	add := func(name, quote string) {
+		if !slices.ContainsFunc(words, func(w completionWord) bool { return strings.EqualFold(w.name, name) }) {
+			words = append(words, completionWord{name, "source quoting in " + quote})
+		}
+		if !slices.Contains(quotes, quote) {
+			quotes = append(quotes, quote)
+		}
+	}
+	for _, s := range append(core.DefaultSources(), core.OptionalSources()...) {
+		add(s.Name, s.Quote)
+	}
+	for _, f := range []PriceFetcher{NewCoinMarketCapBatch("").Quote(), NewCryptoCompareBatch("").Quote()} {
+		add(f.Name(), audeth.QuoteCurrency(f))
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Sources)) {
+		quote := strings.ToUpper(cfg.Sources[name].Quote)
+		if quote == "" {
+			quote = "USD"
+		}
+		add(name, quote)
+	}
+	slices.Sort(quotes)
+	for _, q := range quotes {
+		words = append(words, completionWord{q, "sources quoting in " + q})
+	}
+	return words, nil
+}
+
+func (f completionFlag) String() string {
+	return f.dashes + f.name
+}
+
+// firstLine keeps a description to one short line, which is all a completion menu has room for
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(s, "\n")
+	if len(s) > 80 {
+		s = strings.ToValidUTF8(s[:77], "") + "..."
+	}
+	return s
+}
+
+// singleQuoted quotes s for sh, zsh, fish and PowerShell alike, with quote doubling written out per shell
+func singleQuoted(s, quote string) string {
+	return "'" + strings.ReplaceAll(s, "'", quote) + "'"
+}
+
+// sh is s in single quotes for bash and zsh
+func sh(s string) string {
+	return singleQuoted(s, `'\''`)
+}
+
+// valued lists the flags that take a value as "context|name", which is all a script needs to skip their values
+func valued(contexts []completionContext) []string {
+	var out []string
+	for _, ctx := range contexts {
+		for _, f := range ctx.flags {
+			if f.takesValue {
+				out = append(out, ctx.path+"|"+f.name)
+			}
+		}
+	}
+	return out
+}
+
+// writeShCaseTables writes the lookups the bash and zsh scripts share: which words are contexts, which
+// flags take a value, and the candidates of each context; entry formats a word or flag with its description
+func writeShCaseTables(w io.Writer, contexts []completionContext, entry func(name, summary string) string) {
+	fmt.Fprintln(w, "_audeth_is_context() {\n    case $1 in")
+	for _, ctx := range contexts {
+		fmt.Fprintf(w, "        %s) return 0 ;;\n", sh(ctx.path))
+	}
+	fmt.Fprint(w, "    esac\n    return 1\n}\n\n")
+
+	fmt.Fprintln(w, "_audeth_valued() {\n    case \"$1|$2\" in")
+	for _, v := range valued(contexts) {
+		fmt.Fprintf(w, "        %s) return 0 ;;\n", sh(v))
+	}
+	fmt.Fprint(w, "    esac\n    return 1\n}\n\n")
+
+	fmt.Fprintln(w, "_audeth_words() {\n    case $1 in")
+	for _, ctx := range contexts {
+		if len(ctx.words) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "        %s) printf '%%s\\n'", sh(ctx.path))
+		for _, word := range ctx.words {
+			fmt.Fprintf(w, " %s", sh(entry(word.name, word.summary)))
+		}
+		fmt.Fprintln(w, " ;;")
+	}
+	fmt.Fprint(w, "    esac\n}\n\n")
+
+	fmt.Fprintln(w, "_audeth_flags() {\n    case $1 in")
+	for _, ctx := range contexts {
+		if len(ctx.flags) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "        %s) printf '%%s\\n'", sh(ctx.path))
+		for _, f := range ctx.flags {
+			fmt.Fprintf(w, " %s", sh(entry(f.String(), f.usage)))
+		}
+		fmt.Fprintln(w, " ;;")
+	}
+	fmt.Fprint(w, "    esac\n}\n\n")
+
+	fmt.Fprintln(w, "_audeth_values() {\n    case \"$1|$2\" in")
+	for _, ctx := range contexts {
+		for _, f := range ctx.flags {
+			if len(f.values) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "        %s) printf '%%s\\n'", sh(ctx.path+"|"+f.name))
+			for _, v := range f.values {
+				fmt.Fprintf(w, " %s", sh(v))
+			}
+			fmt.Fprintln(w, " ;;")
+		}
+	}
+	fmt.Fprint(w, "    esac\n}\n\n")
+}
+
+// shContext is the loop both the bash and the zsh script use to find the context, see completionContext
+// It reads the words before the cursor from an array named words, from index first to just before last
+const shContext = `    local ctx="" name w i skip=0
+    for ((i = first; i < last; i++)); do
+        w=${words[i]}
+        if ((skip)); then
+            skip=0
+            continue
+        fi
+        if [[ $w == -* ]]; then
+            name=${w#-}
+            name=${name#-}
+            [[ $name != *=* ]] && _audeth_valued "$ctx" "$name" && skip=1
+            continue
+        fi
+        _audeth_is_context "${ctx:+$ctx }$w" && ctx="${ctx:+$ctx }$w"
+    done
+`
+
+func writeBashCompletion(w io.Writer, contexts []completionContext) {
+	fmt.Fprintln(w, "# bash completion for audeth, from \"audeth completion bash\"")
+	fmt.Fprint(w, "# Load it with: source <(audeth completion bash)\n\n")
+	writeShCaseTables(w, contexts, func(name, _ string) string { return name })
+	io.WriteString(w, `_audeth() {
+    local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+    local -a words=("${COMP_WORDS[@]}")
+    local first=1 last=$COMP_CWORD
+`+shContext+`    local IFS=$'\n' candidates
+    if ((skip)); then
+        name=${prev#-}
+        candidates=$(_audeth_values "$ctx" "${name#-}")
+    elif [[ $cur == -* ]]; then
+        candidates=$(_audeth_flags "$ctx")
+    else
+        candidates=$(_audeth_words "$ctx")
+    fi
+    COMPREPLY=($(compgen -W "$candidates" -- "$cur"))
+    # Names such as "BTC Markets" have spaces, which have to be escaped on the command line
+    if ((${#COMPREPLY[@]})); then
+        COMPREPLY=($(printf '%q\n' "${COMPREPLY[@]}"))
+    fi
+}
+
+# -o default falls back to file names for the arguments that are files, e.g. snapshot restore FILE
+complete -o default -F _audeth audeth
+`)
+}
+
+func writeZshCompletion(w io.Writer, contexts []completionContext) {
+	fmt.Fprintln(w, "#compdef audeth")
+	fmt.Fprintln(w, "# zsh completion for audeth, from \"audeth completion zsh\"")
+	fmt.Fprint(w, "# Save it as _audeth in a directory on $fpath, or load it with: source <(audeth completion zsh)\n\n")
+	// _describe takes name:description, so a colon in a name is escaped
+	writeShCaseTables(w, contexts, func(name, summary string) string {
+		name = strings.ReplaceAll(name, ":", `\:`)
+		if summary == "" {
+			return name
+		}
+		return name + ":" + firstLine(summary)
+	})
+	io.WriteString(w, `_audeth() {
+    local first=2 last=$CURRENT
+`+shContext+`    local -a candidates
+    if ((skip)); then
+        name=${words[CURRENT-1]#-}
+        candidates=(${(f)"$(_audeth_values "$ctx" "${name#-}")"})
+        compadd -a candidates
+    elif [[ ${words[CURRENT]} == -* ]]; then
+        candidates=(${(f)"$(_audeth_flags "$ctx")"})
+        _describe -t flags flag candidates
+    else
+        candidates=(${(f)"$(_audeth_words "$ctx")"})
+        if ((${#candidates})); then
+            _describe -t words argument candidates
+        else
+            _files
+        fi
+    fi
+}
+
+if [[ $zsh_eval_context[-1] == loadautofunc ]]; then
+    _audeth "$@"
+else
+    compdef _audeth audeth
+fi
+`)
+}
+
+// fish quotes s for fish, where a backslash escapes a quote inside single quotes
+func fish(s string) string {
+	return singleQuoted(strings.ReplaceAll(s, `\`, `\\`), `\'`)
+}
+
+func writeFishCompletion(w io.Writer, contexts []completionContext) {
+	fmt.Fprintln(w, "# fish completion for audeth, from \"audeth completion fish\"")
+	fmt.Fprint(w, "# Save it as ~/.config/fish/completions/audeth.fish, or load it with: audeth completion fish | source\n\n")
+	var paths []string
+	for _, ctx := range contexts {
+		paths = append(paths, fish(ctx.path))
+	}
+	var skips []string
+	for _, v := range valued(contexts) {
+		skips = append(skips, fish(v))
+	}
+	fmt.Fprintf(w, `function __audeth_context
+    set -l contexts %s
+    set -l valued %s
+    set -l ctx ''
+    set -l skip 0
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    for w in $tokens
+        if test $skip = 1
+            set skip 0
+            continue
+        end
+        if string match -q -- '-*' $w
+            set -l name (string replace -r -- '^--?' '' $w)
+            if not string match -q -- '*=*' $name; and contains -- "$ctx|$name" $valued
+                set skip 1
+            end
+            continue
+        end
+        set -l next (string trim -- "$ctx $w")
+        if contains -- $next $contexts
+            set ctx $next
+        end
+    end
+    echo $ctx
+end
+
+function __audeth_in
+    set -l ctx (__audeth_context)
+    test "$ctx" = "$argv[1]"
+end
+`, strings.Join(paths, " "), strings.Join(skips, " "))
+	for _, ctx := range contexts {
+		cond := fish("__audeth_in " + fish(ctx.path))
+		for _, word := range ctx.words {
+			// -f leaves out file names where there are words to choose from instead
+			fmt.Fprintf(w, "complete -c audeth -n %s -f -a %s", cond, fish(fish(word.name)))
+			if word.summary != "" {
+				fmt.Fprintf(w, " -d %s", fish(firstLine(word.summary)))
+			}
+			fmt.Fprintln(w)
+		}
+		for _, f := range ctx.flags {
+			option := "-o"
+			if f.dashes == "--" {
+				option = "-l"
+			}
+			fmt.Fprintf(w, "complete -c audeth -n %s %s %s", cond, option, fish(f.name))
+			if f.takesValue {
+				fmt.Fprint(w, " -r")
+			}
+			if len(f.values) > 0 {
+				fmt.Fprintf(w, " -a %s", fish(strings.Join(f.values, " ")))
+			}
+			fmt.Fprintf(w, " -d %s\n", fish(firstLine(f.usage)))
+		}
+	}
+}
+
+// ps quotes s for PowerShell, where ” is a quote inside single quotes
+func ps(s string) string {
+	return singleQuoted(s, "''")
+}
+
+func writePowerShellCompletion(w io.Writer, contexts []completionContext) {
+	fmt.Fprintln(w, "# PowerShell completion for audeth, from \"audeth completion powershell\"")
+	fmt.Fprint(w, "# Load it with: audeth completion powershell | Out-String | Invoke-Expression, or add that line to $PROFILE\n\n")
+	fmt.Fprintln(w, "$audethContexts = @{")
+	for _, ctx := range contexts {
+		fmt.Fprintf(w, "    %s = @{\n        Words = @(", ps(ctx.path))
+		for i, word := range ctx.words {
+			if i > 0 {
+				fmt.Fprint(w, ", ")
+			}
+			summary := word.summary
+			if summary == "" {
+				summary = word.name
+			}
+			fmt.Fprintf(w, "@{Text = %s; Usage = %s}", ps(word.name), ps(firstLine(summary)))
+		}
+		fmt.Fprint(w, ")\n        Flags = @(")
+		for i, f := range ctx.flags {
+			if i > 0 {
+				fmt.Fprint(w, ", ")
+			}
+			var values []string
+			for _, v := range f.values {
+				values = append(values, ps(v))
+			}
+			fmt.Fprintf(w, "@{Name = %s; Text = %s; Usage = %s; Value = $%t; Values = @(%s)}",
+				ps(f.name), ps(f.String()), ps(firstLine(f.usage)), f.takesValue, strings.Join(values, ", "))
+		}
+		fmt.Fprintln(w, ")\n    }")
+	}
+	io.WriteString(w, `}
+
+Register-ArgumentCompleter -Native -CommandName audeth, audeth.exe -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    # The word being completed ends at the cursor, so only the ones before it are read
+    $elements = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.Extent.Text })
+    $ctx = ''
+    $skip = $false
+    $pending = $null
+    foreach ($w in $elements | Select-Object -Skip 1) {
+        if ($skip) { $skip = $false; $pending = $null; continue }
+        if ($w.StartsWith('-')) {
+            $name = $w.TrimStart('-')
+            if (-not $name.Contains('=')) {
+                $flag = $audethContexts[$ctx].Flags | Where-Object { $_.Name -eq $name -and $_.Value }
+                if ($flag) { $skip = $true; $pending = $flag }
+            }
+            continue
+        }
+        $next = "$ctx $w".Trim()
+        if ($audethContexts.ContainsKey($next)) { $ctx = $next }
+    }
+    if ($skip) {
+        $candidates = $pending.Values | ForEach-Object { @{Text = $_; Usage = $_} }
+    } elseif ($wordToComplete.StartsWith('-')) {
+        $candidates = $audethContexts[$ctx].Flags
+    } else {
+        $candidates = $audethContexts[$ctx].Words
+    }
+    foreach ($c in $candidates) {
+        if ($c.Text -like "$wordToComplete*") {
+            $insert = $c.Text
+            if ($insert.Contains(' ')) { $insert = "'" + $insert + "'" }
+            [System.Management.Automation.CompletionResult]::new($insert, $c.Text, 'ParameterValue', $c.Usage)
+        }
+    }
+}
+`)
+}
@@ -0,0 +1,51 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompletionContexts(t *testing.T) {
+	t.Setenv("AUDETH_HOME", t.TempDir())
+	contexts, err := completionContexts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := make(map[string]completionContext)
+	for _, ctx := range contexts {
+		byPath[ctx.path] = ctx
+	}
+	tests := []struct {
+		path, flag string
+		takesValue bool
+	}{
+		{"", "output", true},
+		{"", "prefetch", false},
+		{"report", "period", true},
+		{"report", "notify", false},
+		{"history export", "format", true},
+		{"attest verify", "key", true},
+		{"sheets append", "kind", true},
+	}
+	for _, tt := range tests {
+		i := slices.IndexFunc(byPath[tt.path].flags, func(f completionFlag) bool { return f.name == tt.flag })
+		if i < 0 {
+			t.Errorf("%q: no -%s", tt.path, tt.flag)
+			continue
+		}
+		if f := byPath[tt.path].flags[i]; f.takesValue != tt.takesValue {
+			t.Errorf("%q -%s: takesValue is %v", tt.path, tt.flag, f.takesValue)
+		}
+	}
+	// Subcommands that take no flags list none, and neither does their command
+	for _, path := range []string{"history", "attest keygen", "pins"} {
+		if flags := byPath[path].flags; len(flags) != 0 {
+			t.Errorf("%q lists %d flags", path, len(flags))
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Config holds the user's settings, loaded from config.json in the data directory
+// Struct tags map the JSON keys onto fields, so encoding/json does the parsing for us
+type Config struct {
+	Notifiers  []NotifierConfig   `json:"notifiers"`
+	RouteFees  map[string]float64 `json:"route_fees"`   // percentage fee per route leg, e.g. "aud_eth": 0.26
+	CacheTTL   string             `json:"cache_ttl"`    // how long the crypto quotes are reused, e.g. "30s"
+	FX         string             `json:"fx"`           // USD to AUD rate provider, "coingecko" by default, see fxFromConfig
+	FXCacheTTL string             `json:"fx_cache_ttl"` // how long the USD to AUD rate is reused, e.g. "10m"
+	History    string             `json:"history"`      // history backend: "jsonl" (default), "sqlite" or "memory"
+	Retention  RetentionConfig    `json:"retention"`
+	Fetch      FetchConfig        `json:"fetch"`
+	TLS        TLSConfig          `json:"tls"`
+	Privacy    PrivacyConfig      `json:"privacy"`
+
+	// FXSources averages several FX providers instead of using fx, leaving out any further than
+	// FXMaxDivergence percent from CoinGecko's implied rate when that is set
+	FXSources       []string `json:"fx_sources"`
+	FXMaxDivergence float64  `json:"fx_max_divergence"`
+
+	// FXEndpoints are more FX providers for fx and fx_sources to name, each read with a parse expression
+	FXEndpoints map[string]FXEndpoint `json:"fx_endpoints"`
+
+	// StaleWhileRevalidate serves an expired rate for this much longer while it is refetched in the background
+	StaleWhileRevalidate string `json:"stale_while_revalidate"`
+
+	// Pairs are the currency pairs serve refreshes in the background, each on its own schedule, see pairs.go
+	Pairs map[string]PairConfig `json:"pairs"`
+
+	CacheBackend string      `json:"cache_backend"` // rate cache: "memory" (default) or "redis"
+	Redis        RedisConfig `json:"redis"`
+
+	// Sources overrides the endpoint of a built-in source by name, limited by SourcePolicy
+	Sources      map[string]SourceConfig `json:"sources"`
+	SourcePolicy URLPolicy               `json:"source_policy"`
+	Remote       RemoteConfig            `json:"remote"` // more sources from a signed document, see remote.go
+
+	Signing SigningConfig `json:"signing"` // attest the server's /rate responses, see attest.go
+	Influx  InfluxConfig  `json:"influx"`  // push every fresh rate to InfluxDB, see influx.go
+	MQTT    MQTTConfig    `json:"mqtt"`    // publish rates and conversions to an MQTT broker, see mqtt.go
+	NATS    NATSConfig    `json:"nats"`    // publish every fresh rate to a NATS subject, see events.go
+	Kafka   KafkaConfig   `json:"kafka"`   // produce every fresh rate to a Kafka topic, see events.go
+	StatsD  StatsDConfig  `json:"statsd"`  // report every fresh rate to a StatsD or DogStatsD agent, see statsd.go
+	Sheets  SheetsConfig  `json:"sheets"`  // the Google Sheet "sheets append" writes to, see sheets.go
+	Update  UpdateConfig  `json:"update"`  // the key self-update checks releases with, see selfupdate.go
+
+	// APIKeys holds keys for sources that need one, each a secret reference such as "keychain:coinmarketcap"
+	APIKeys map[string]string `json:"api_keys"`
+}
+
+// FetchConfig bounds how the exchanges are queried on each refresh
+type FetchConfig struct {
+	Limit    int    `json:"limit"`    // sources queried at once, default 8
+	Quorum   int    `json:"quorum"`   // stop once this many have answered, default all of them
+	Deadline string `json:"deadline"` // give up on slower sources after this long, e.g. "3s"
+
+	MinSources    int     `json:"min_sources"`    // fail unless at least this many sources answered
+	MaxDivergence float64 `json:"max_divergence"` // fail if the quotes are further apart than this percentage, e.g. 2.5
+	MinConfidence float64 `json:"min_confidence"` // fail if the rate's confidence is below this, from 0 to 1, e.g. 0.8
+
+	USDTPeg float64 `json:"usdt_usd"` // US dollars one USDT is taken to be worth for ETH/USDT quotes, default 1
+
+	// Aggregate is an expression over the USD quotes used instead of their mean, e.g. "median(quotes)", see expr.go
+	Aggregate string `json:"aggregate"`
+}
+
+// apiKey resolves the configured key for a source, see resolveSecret for the reference forms
+func (c Config) apiKey(source string) (string, error) {
+	ref, ok := c.APIKeys[source]
+	if !ok || ref == "" {
+		return "", fmt.Errorf("%s needs an API key, set api_keys.%s in config.json", source, source)
+	}
+	return resolveSecret(ref)
+}
+
+// usesBatch reports whether a batched source, e.g. "coinmarketcap", is enabled in sources or is the FX provider
+func (c Config) usesBatch(name string) bool {
+	if c.FX == name || slices.Contains(c.FXSources, name) {
+		return true
+	}
+	for source, cfg := range c.Sources {
+		if strings.EqualFold(source, name) && (cfg.Enabled == nil || *cfg.Enabled) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifierConfig describes one notification target such as a webhook
+type NotifierConfig struct {
+	Type    string `json:"type"`    // "webhook" or "exec"
+	URL     string `json:"url"`     // may be a secret reference, webhook URLs often embed a token
+	Command string `json:"command"` // for exec, a shell command given the message as JSON on stdin
+}
+
+// dataDir returns the directory used for config and recorded data
+// AUDETH_HOME overrides the default of ~/.audeth
+func dataDir() string {
+	if dir := os.Getenv("AUDETH_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".audeth"
+	}
+	return filepath.Join(home, ".audeth")
+}
+
+// loadConfig reads config.json from the data directory
+// A missing file is not an error, the zero Config is returned instead
+func loadConfig() (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(filepath.Join(dataDir(), "config.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("reading config failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing config failed: %v", err)
+	}
+	return cfg, nil
+}
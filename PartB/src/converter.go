@@ -0,0 +1,244 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/core"
+)
+
+// The Converter is in package audeth so other programs can embed it; this package keeps using its names unqualified
+type (
+	Converter       = audeth.Converter
+	ConverterOption = audeth.ConverterOption
+	FetchOptions    = audeth.FetchOptions
+	PriceFetcher    = audeth.PriceFetcher
+	PriceResult     = audeth.PriceResult
+	FXProvider      = audeth.FXProvider
+	ContextFetcher  = audeth.ContextFetcher
+	RateCache       = audeth.RateCache
+	Sample          = audeth.Sample
+	SourceSample    = audeth.SourceSample
+	Attestation     = audeth.Attestation
+	Quote           = audeth.Quote
+	SourceQuote     = audeth.SourceQuote
+)
+
+// defaultCacheTTL is how long an aggregated rate is reused before refetching
+const defaultCacheTTL = audeth.DefaultTTL
+
+// NewConverter is audeth.NewConverter on defaultClock, so --now reaches it, printing its messages through tr
+// and redacting the secrets resolveSecret handed out from its errors; options given here are applied after those
+func NewConverter(fetchers []PriceFetcher, fx FXProvider, ttl time.Duration, cache RateCache, options ...ConverterOption) *Converter {
+	own := []ConverterOption{audeth.WithClock(defaultClock), audeth.WithMessages(sayConverter), audeth.WithRedaction(redactError)}
+	return audeth.NewConverter(fetchers, fx, ttl, cache, append(own, options...)...)
+}
+
+// sayConverter prints one of the converter's messages, with its times in the display timezone
+func sayConverter(key string, args ...any) {
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = t.In(displayLocation).Format("15:04:05")
+		}
+	}
+	fmt.Fprintln(progressOut(), tr(key, args...))
+}
+
+// defaultFetchers returns the built-in set of exchange APIs
+func defaultFetchers() []PriceFetcher {
+	return apisFor(core.DefaultSources())
+}
+
+// optionalFetchers are the sources that are only queried once named in the sources section of the config
+func optionalFetchers() []PriceFetcher {
+	return apisFor(core.OptionalSources())
+}
+
+// apisFor makes an API for each of core's endpoints
+func apisFor(sources []core.Source) []PriceFetcher {
+	var fetchers []PriceFetcher
+	for _, s := range sources {
+		api := NewAPI(s.Name, s.URL)
+		if s.Quote != "USD" {
+			api = api.quotedIn(s.Quote)
+		}
+		fetchers = append(fetchers, api)
+	}
+	return fetchers
+}
+
+// batchedFetchers returns the default fetchers with CoinGecko's quote read from batch
+func batchedFetchers(batch *CoinGeckoBatch) []PriceFetcher {
+	fetchers := defaultFetchers()
+	for i, f := range fetchers {
+		if f.Name() == "CoinGecko" {
+			fetchers[i] = batch.Quote("ethereum", "usd")
+		}
+	}
+	return fetchers
+}
+
+// newConverterFromConfig builds a Converter with the default fetchers and the configured cache
+// Extra options are applied after the ones from the config
+func newConverterFromConfig(cfg Config, extra ...ConverterOption) (*Converter, error) {
+	cfg, err := withRemoteSources(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := parseTTL(cfg.CacheTTL, defaultCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("cache_ttl: %v", err)
+	}
+	fxTTL, err := parseTTL(cfg.FXCacheTTL, defaultFXCacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("fx_cache_ttl: %v", err)
+	}
+	stale, err := parseTTL(cfg.StaleWhileRevalidate, 0)
+	if err != nil {
+		return nil, fmt.Errorf("stale_while_revalidate: %v", err)
+	}
+
+	var cache RateCache
+	switch cfg.CacheBackend {
+	case "", "memory":
+	case "redis":
+		redisCfg := cfg.Redis
+		if redisCfg.Password, err = resolveSecret(redisCfg.Password); err != nil {
+			return nil, fmt.Errorf("redis.password: %v", err)
+		}
+		cache = NewRedisRateCache(redisCfg)
+	default:
+		return nil, fmt.Errorf("unknown cache backend: %s (use memory or redis)", cfg.CacheBackend)
+	}
+	opts, err := fetchOptions(cfg.Fetch)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Weights, err = sourceWeights(cfg.Sources); err != nil {
+		return nil, err
+	}
+	// CoinGecko's quote and the FX rate come from the same endpoint, so they share one batched request
+	batch := NewCoinGeckoBatch()
+	cmc, err := newCoinMarketCapFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := newCryptoCompareFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	fetchers, err := applySources(batchedFetchers(batch), append(optionalFetchers(), cmc.Quote(), cc.Quote()), cfg)
+	if err != nil {
+		return nil, err
+	}
+	fx, err := fxFromConfig(cfg, batch, cmc, cc)
+	if err != nil {
+		return nil, err
+	}
+	options := append([]ConverterOption{audeth.WithFetchOptions(opts), audeth.WithStaleWhileRevalidate(stale)}, extra...)
+	return NewConverter(fetchers, newCachedFX(fx, fxTTL), ttl, cache, options...), nil
+}
+
+// fxFromConfig picks the FX provider named by fx, or averages the ones in fx_sources
+// The batched providers reuse the quote requests they share a batch with
+func fxFromConfig(cfg Config, gecko *CoinGeckoBatch, cmc, cc *ETHBatch) (FXProvider, error) {
+	provider := func(name string) (FXProvider, error) {
+		switch name {
+		case "", "coingecko":
+			return gecko.FX(), nil
+		case "coinmarketcap":
+			return cmc.FX(), nil
+		case "cryptocompare":
+			return cc.FX(), nil
+		case "rba":
+			return NewRBAFX(), nil
+		case "frankfurter":
+			return NewFrankfurterFX(), nil
+		case "exchangerate.host":
+			key, err := cfg.apiKey("exchangerate.host")
+			if err != nil {
+				return nil, err
+			}
+			return NewExchangerateHostFX(key), nil
+		}
+		if e, ok := cfg.FXEndpoints[name]; ok {
+			return newExprFX(name, e, cfg.SourcePolicy)
+		}
+		return nil, fmt.Errorf("unknown fx provider: %s (use coingecko, coinmarketcap, cryptocompare, rba, frankfurter, exchangerate.host or a name from fx_endpoints)", name)
+	}
+	if cfg.FXMaxDivergence < 0 {
+		return nil, fmt.Errorf("fx_max_divergence can't be negative")
+	}
+	if len(cfg.FXSources) == 0 {
+		if cfg.FXMaxDivergence > 0 {
+			return nil, fmt.Errorf("fx_max_divergence needs fx_sources")
+		}
+		return provider(cfg.FX)
+	}
+	if cfg.FX != "" {
+		return nil, fmt.Errorf("set either fx or fx_sources, not both")
+	}
+	avg := averageFX{maxDivergence: cfg.FXMaxDivergence}
+	for _, name := range cfg.FXSources {
+		p, err := provider(name)
+		if err != nil {
+			return nil, fmt.Errorf("fx_sources: %v", err)
+		}
+		avg.providers = append(avg.providers, p)
+	}
+	if cfg.FXMaxDivergence > 0 {
+		avg.check = gecko.FX()
+	}
+	return avg, nil
+}
+
+// fetchOptions reads the fetch section of the config, applying the default concurrency limit
+func fetchOptions(cfg FetchConfig) (FetchOptions, error) {
+	opts := FetchOptions{Limit: audeth.DefaultFetchLimit, Quorum: cfg.Quorum, MinSources: cfg.MinSources, MaxDivergence: cfg.MaxDivergence,
+		MinConfidence: cfg.MinConfidence, USDTPeg: cfg.USDTPeg}
+	if cfg.Limit > 0 {
+		opts.Limit = cfg.Limit
+	}
+	if cfg.Quorum < 0 || cfg.Limit < 0 || cfg.MinSources < 0 || cfg.MaxDivergence < 0 || cfg.MinConfidence < 0 || cfg.USDTPeg < 0 {
+		return opts, fmt.Errorf("fetch.limit, quorum, min_sources, max_divergence, min_confidence and usdt_usd can't be negative")
+	}
+	if cfg.MinConfidence > 1 {
+		return opts, fmt.Errorf("fetch.min_confidence is from 0 to 1, e.g. 0.8")
+	}
+	if cfg.Quorum > 0 && cfg.MinSources > cfg.Quorum {
+		return opts, fmt.Errorf("fetch.min_sources can't be more than fetch.quorum, the fetch stops at the quorum")
+	}
+	if cfg.Deadline != "" {
+		d, err := parseInterval(cfg.Deadline)
+		if err != nil {
+			return opts, fmt.Errorf("fetch.deadline: %v", err)
+		}
+		opts.Deadline = d
+	}
+	if cfg.Aggregate != "" {
+		e, err := compileExpr(cfg.Aggregate)
+		if err != nil {
+			return opts, fmt.Errorf("fetch.aggregate: %v", err)
+		}
+		opts.Aggregate = e
+	}
+	return opts, nil
+}
+
+// parseTTL reads a cache lifetime from the config, where "" means the default and "0s" disables caching
+func parseTTL(s string, def time.Duration) (time.Duration, error) {
+	switch s {
+	case "":
+		return def, nil
+	case "0", "0s":
+		return 0, nil
+	default:
+		return parseInterval(s)
+	}
+}
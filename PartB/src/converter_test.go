@@ -0,0 +1,60 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// newFakeConverter builds a converter over two fake sources quoting 3000 and 3100 USD, at 1.5 AUD per USD
+func newFakeConverter(t *testing.T, options ...ConverterOption) *Converter {
+	t.Helper()
+	fetchers := []PriceFetcher{audethtest.NewFakeFetcher("A", 3000), audethtest.NewFakeFetcher("B", 3100)}
+	return NewConverter(fetchers, audethtest.FakeFX{Rate: 1.5}, time.Minute, nil, options...)
+}
+
+func TestQuoteAggregation(t *testing.T) {
+	maxQuote, err := compileExpr("max(quotes)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		opts FetchOptions
+		rate float64
+		want string
+	}{
+		{"mean", FetchOptions{Limit: 2}, 3050 * 1.5, "mean"},
+		{"custom", FetchOptions{Limit: 2, Aggregate: maxQuote}, 3100 * 1.5, "custom"},
+	}
+	// Built and used at once, as serve does with its pairs, so each has to keep its own label
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := newFakeConverter(t, audeth.WithFetchOptions(tt.opts))
+			q, err := c.Quote()
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+				return
+			}
+			if q.Rate != tt.rate || q.Aggregation != tt.want {
+				t.Errorf("%s: got rate %v aggregated by %q, want %v by %q", tt.name, q.Rate, q.Aggregation, tt.rate, tt.want)
+			}
+			s, _ := c.Sample()
+			if r := newConversionResult(defaultClock.Now(), 100, s, true, nil); r.Aggregation != tt.want {
+				t.Errorf("%s: the result says %q, want %q", tt.name, r.Aggregation, tt.want)
+			}
+		}()
+	}
+	wg.Wait()
+}
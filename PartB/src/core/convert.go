@@ -0,0 +1,182 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// Converter fetches the sources and averages them into an AUD rate
+// It is the command-line converter without its cache, config and history, for callers such as the WebAssembly build
+type Converter struct {
+	Sources   []Source
+	Transport http.RoundTripper // carries every request; nil is http.DefaultTransport, the browser's fetch under GOOS=js
+	Timeout   time.Duration     // per request
+}
+
+// NewConverter creates a converter for the default sources with the usual 10 second timeout
+func NewConverter() *Converter {
+	return &Converter{Sources: DefaultSources(), Timeout: 10 * time.Second}
+}
+
+// SourceResult is one source's part of a Rate
+type SourceResult struct {
+	Name  string  `json:"name"`
+	USD   float64 `json:"usd,omitempty"`
+	Error string  `json:"error,omitempty"`
+}
+
+// Rate is one round of fetching: AUD per ETH, the USD/AUD rate behind it and each source's quote in USD
+type Rate struct {
+	Time     time.Time      `json:"time"`
+	RateAUD  float64        `json:"rate_aud"`
+	USDToAUD float64        `json:"usd_aud"`
+	Sources  []SourceResult `json:"sources"`
+}
+
+// Convert is how much ETH aud buys at the rate
+func (r Rate) Convert(aud float64) float64 {
+	return aud / r.RateAUD
+}
+
+func (c *Converter) client() *http.Client {
+	return &http.Client{Timeout: c.Timeout, Transport: c.Transport}
+}
+
+// Quote fetches one source's price, in whatever currency the source quotes
+func (c *Converter) Quote(ctx context.Context, s Source) (parsers.Quote, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return parsers.Quote{}, fmt.Errorf("request failed: %v", err)
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return parsers.Quote{}, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return parsers.Quote{}, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+	}
+	q, err := Decode(s.Name, resp.Body)
+	if err != nil {
+		return parsers.Quote{}, fmt.Errorf("parsing response failed: %w", err)
+	}
+	if q.Last <= 0 {
+		return parsers.Quote{}, fmt.Errorf("invalid price: %f", q.Last)
+	}
+	return q, nil
+}
+
+// FX fetches how many AUD one USD buys, implied from CoinGecko's ETH price in both
+func (c *Converter) FX(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, FXURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	defer resp.Body.Close()
+	rate, err := parsers.Checked(resp.Body, FXSchema, parsers.DecodeCoinGeckoFX)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rates: %v", err)
+	}
+	return rate, nil
+}
+
+// Rate queries every source and the FX rate at once and averages the quotes that came back
+func (c *Converter) Rate(ctx context.Context) (Rate, error) {
+	r := Rate{Time: time.Now(), Sources: make([]SourceResult, len(c.Sources))}
+	quotes := make([]parsers.Quote, len(c.Sources))
+	errs := make([]error, len(c.Sources))
+	var fxErr error
+	var wg sync.WaitGroup
+	for i, s := range c.Sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quotes[i], errs[i] = c.Quote(ctx, s)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.USDToAUD, fxErr = c.FX(ctx)
+	}()
+	wg.Wait()
+	if fxErr != nil {
+		return r, fxErr
+	}
+
+	var sum float64
+	var count int
+	for i, s := range c.Sources {
+		r.Sources[i].Name = s.Name
+		usd, err := quotes[i].Last, errs[i]
+		if err == nil {
+			usd, err = ToUSD(usd, s.Quote, r.USDToAUD, 1)
+		}
+		if err != nil {
+			r.Sources[i].Error = err.Error()
+			continue
+		}
+		r.Sources[i].USD = usd
+		sum += usd
+		count++
+	}
+	if count == 0 {
+		return r, fmt.Errorf("no valid prices found")
+	}
+	r.RateAUD = sum / float64(count) * r.USDToAUD
+	return r, nil
+}
+
+// ToUSD turns a quote into ETH/USD so every source can be averaged together
+// USDT is taken at usdtUSD US dollars, or 1 when that is 0; an AUD quote goes back through
+// the FX rate, which the average then undoes, so it counts at exactly its AUD price
+func ToUSD(price float64, currency string, usdToAUD, usdtUSD float64) (float64, error) {
+	switch currency {
+	case "USD", "":
+		return price, nil
+	case "USDT":
+		if usdtUSD > 0 {
+			return price * usdtUSD, nil
+		}
+		return price, nil
+	case "AUD":
+		if usdToAUD <= 0 {
+			return 0, fmt.Errorf("no USD/AUD rate to compare the AUD quote with")
+		}
+		return price / usdToAUD, nil
+	}
+	return 0, fmt.Errorf("unsupported quote currency: %s", currency)
+}
+
+// RebaseTransport sends every request to Base instead of its real host, keeping path and query
+// With the bundled mock exchange this runs the converter against local fake APIs
+type RebaseTransport struct {
+	Base *url.URL
+	Next http.RoundTripper // nil is http.DefaultTransport
+}
+
+func (t RebaseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme, out.URL.Host = t.Base.Scheme, t.Base.Host
+	out.Host = t.Base.Host
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(out)
+}
@@ -0,0 +1,162 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+// Package core is the part of the converter that runs anywhere, including GOOS=js in a browser:
+// the public exchange endpoints, reading their responses, the USD/AUD leg and the average
+// It has no flags, files, terminal or stdin; everything it fetches goes through an http.RoundTripper
+// the caller can replace, so a web page can hand it the browser's fetch or a CORS proxy
+package core
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// Source is one exchange endpoint and the currency its ETH price is in
+type Source struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Quote string `json:"quote"` // "USD", "USDT" or "AUD"
+}
+
+// DefaultSources are the exchanges queried when nothing else is configured
+func DefaultSources() []Source {
+	return []Source{
+		{"CoinGecko", "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd", "USD"},
+		{"Coinbase", "https://api.coinbase.com/v2/prices/ETH-USD/spot", "USD"},
+		{"Bitstamp", "https://www.bitstamp.net/api/v2/ticker/ethusd/", "USD"},
+		{"Kraken", "https://api.kraken.com/0/public/Ticker?pair=ETHUSD", "USD"},
+		{"Bitfinex", "https://api-pub.bitfinex.com/v2/ticker/tETHUSD", "USD"},
+	}
+}
+
+// OptionalSources are the other public endpoints, only queried once asked for
+// Each one is another request on every refresh, so the default set stays at the five above
+func OptionalSources() []Source {
+	return []Source{
+		{"Binance", "https://api.binance.com/api/v3/ticker/price?symbol=ETHUSDT", "USDT"},
+		{"OKX", "https://www.okx.com/api/v5/market/ticker?instId=ETH-USDT", "USDT"},
+		{"Bybit", "https://api.bybit.com/v5/market/tickers?category=spot&symbol=ETHUSDT", "USDT"},
+		{"KuCoin", "https://api.kucoin.com/api/v1/market/orderbook/level1?symbol=ETH-USDT", "USDT"},
+		{"Gemini", "https://api.gemini.com/v1/pubticker/ethusd", "USD"},
+		{"Crypto.com", "https://api.crypto.com/exchange/v1/public/get-tickers?instrument_name=ETH_USDT", "USDT"},
+		{"Gate.io", "https://api.gateio.ws/api/v4/spot/tickers?currency_pair=ETH_USDT", "USDT"},
+		{"HTX", "https://api.huobi.pro/market/detail/merged?symbol=ethusdt", "USDT"},
+		{"Bitget", "https://api.bitget.com/api/v2/spot/market/tickers?symbol=ETHUSDT", "USDT"},
+		{"MEXC", "https://api.mexc.com/api/v3/ticker/24hr?symbol=ETHUSDT", "USDT"},
+		{"BTC Markets", "https://api.btcmarkets.net/v3/markets/ETH-AUD/ticker", "AUD"},
+		{"Independent Reserve", "https://api.independentreserve.com/Public/GetMarketSummary?primaryCurrencyCode=eth&secondaryCurrencyCode=aud", "AUD"},
+		{"CoinSpot", "https://www.coinspot.com.au/pubapi/v2/latest/ETH", "AUD"},
+		{"Swyftx", "https://api.swyftx.com.au/markets/info/basic/ETH/", "AUD"},
+		{"CoinJar", "https://data.exchange.coinjar.com/products/ETHAUD/ticker", "AUD"},
+		{"Luno", "https://api.luno.com/api/1/ticker?pair=ETHAUD", "AUD"},
+		{"CoinAPI", "https://rest.coinapi.io/v1/exchangerate/ETH/USD", "USD"},
+	}
+}
+
+// FXURL is CoinGecko's ETH price in USD and AUD, which gives the USD/AUD rate without another provider
+const FXURL = "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd,aud"
+
+// schemas are the fields each source's parser reads, so a response it can't read says which one was wrong
+var schemas = map[string]parsers.Schema{
+	"CoinGecko":           {{Path: "ethereum.usd", Kind: parsers.Number}},
+	"Coinbase":            {{Path: "data.amount", Kind: parsers.NumericString}},
+	"Bitstamp":            {{Path: "last", Kind: parsers.NumericString}},
+	"Kraken":              {{Path: "result.*.c[0]", Kind: parsers.NumericString}},
+	"Bitfinex":            {{Path: "[6]", Kind: parsers.Number}},
+	"Binance":             {{Path: "price", Kind: parsers.NumericString}},
+	"OKX":                 {{Path: "data[0].last", Kind: parsers.NumericString}},
+	"Bybit":               {{Path: "result.list[0].lastPrice", Kind: parsers.NumericString}},
+	"KuCoin":              {{Path: "data.price", Kind: parsers.NumericString}},
+	"Gemini":              {{Path: "last", Kind: parsers.NumericString}},
+	"Crypto.com":          {{Path: "result.data[0].a", Kind: parsers.NumericString}},
+	"Gate.io":             {{Path: "[0].last", Kind: parsers.NumericString}},
+	"HTX":                 {{Path: "tick.close", Kind: parsers.Number}},
+	"Bitget":              {{Path: "data[0].lastPr", Kind: parsers.NumericString}},
+	"MEXC":                {{Path: "lastPrice", Kind: parsers.NumericString}},
+	"BTC Markets":         {{Path: "lastPrice", Kind: parsers.NumericString}},
+	"Independent Reserve": {{Path: "LastPrice", Kind: parsers.Number}},
+	"CoinSpot":            {{Path: "prices.last", Kind: parsers.NumericString}},
+	"Swyftx":              {{Path: "[0].buy", Kind: parsers.NumericString}},
+	"CoinJar":             {{Path: "last", Kind: parsers.NumericString}},
+	"Luno":                {{Path: "last_trade", Kind: parsers.NumericString}},
+	"CoinAPI":             {{Path: "rate", Kind: parsers.Number}},
+}
+
+// FXSchema is the fields of the FXURL response
+var FXSchema = parsers.Schema{{Path: "ethereum.usd", Kind: parsers.Number}, {Path: "ethereum.aud", Kind: parsers.Number}}
+
+// Decode reads the named source's response
+// When the parser fails on a response without the fields it needs, the error is a *parsers.SchemaError
+// naming the field and quoting the body around it
+func Decode(name string, body io.Reader) (parsers.Quote, error) {
+	schema, ok := schemas[name]
+	if !ok {
+		return parsers.Quote{}, fmt.Errorf("unknown API: %s", name)
+	}
+	return parsers.Checked(body, schema, func(r io.Reader) (parsers.Quote, error) {
+		return decode(name, r)
+	})
+}
+
+// decode picks the source's parser
+// Limitation: Go's lack of inheritance, can't create a base API class with common functionality
+// Instead, use composition and switch statements, which can be verbose
+// The exchanges whose ticker has a bid and ask keep them; every other source's Quote has only the last price
+func decode(name string, body io.Reader) (parsers.Quote, error) {
+	var q parsers.Quote
+	var err error
+	switch name {
+	case "CoinGecko":
+		q.Last, err = parsers.DecodeCoinGeckoSimple(body, "usd")
+	case "Coinbase":
+		q.Last, err = parsers.DecodeCoinbaseSpot(body)
+	case "Bitstamp":
+		q.Last, err = parsers.DecodeBitstampTicker(body)
+	case "Kraken":
+		q.Last, err = parsers.DecodeKrakenTicker(body)
+	case "Bitfinex":
+		q.Last, err = parsers.DecodeBitfinexTicker(body)
+	case "Binance":
+		q.Last, err = parsers.DecodeBinanceTicker(body)
+	case "OKX":
+		q.Last, err = parsers.DecodeOKXTicker(body)
+	case "Bybit":
+		q.Last, err = parsers.DecodeBybitTickers(body)
+	case "KuCoin":
+		q.Last, err = parsers.DecodeKuCoinLevel1(body)
+	case "Gemini":
+		q.Last, err = parsers.DecodeGeminiPubticker(body)
+	case "Crypto.com":
+		q.Last, err = parsers.DecodeCryptoComTickers(body)
+	case "Gate.io":
+		q.Last, err = parsers.DecodeGateTickers(body)
+	case "HTX":
+		q.Last, err = parsers.DecodeHTXMerged(body)
+	case "Bitget":
+		q.Last, err = parsers.DecodeBitgetTickers(body)
+	case "MEXC":
+		q.Last, err = parsers.DecodeMEXCTicker24hr(body)
+	case "BTC Markets":
+		q.Last, err = parsers.DecodeBTCMarketsTicker(body)
+	case "Independent Reserve":
+		q, err = parsers.DecodeIndependentReserveSummary(body)
+	case "CoinSpot":
+		q, err = parsers.DecodeCoinSpotLatest(body)
+	case "Swyftx":
+		q, err = parsers.DecodeSwyftxBasicInfo(body)
+	case "CoinJar":
+		q, err = parsers.DecodeCoinJarTicker(body)
+	case "Luno":
+		q, err = parsers.DecodeLunoTicker(body)
+	case "CoinAPI":
+		q.Last, err = parsers.DecodeCoinAPIExchangeRate(body)
+	default:
+		return q, fmt.Errorf("unknown API: %s", name)
+	}
+	return q, err
+}
@@ -0,0 +1,60 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// cryptoCompareURL is the single-symbol price endpoint; fsym=ETH and the tsyms= currencies are added per request
+const cryptoCompareURL = "https://min-api.cryptocompare.com/data/price"
+
+// NewCryptoCompareBatch creates a batch asking /data/price for ETH
+// No key is needed for the free tier's limits; one in api_keys.cryptocompare is sent as "Authorization: Apikey ..."
+func NewCryptoCompareBatch(key string) *ETHBatch {
+	timeout := 10 * time.Second
+	return newETHBatch("CryptoCompare", func(ctx context.Context, currencies []string) (map[string]float64, error) {
+		u := cryptoCompareURL + "?fsym=ETH&tsyms=" + strings.Join(currencies, ",")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %v", err)
+		}
+		if key != "" {
+			req.Header.Set("Authorization", "Apikey "+key)
+		}
+		resp, err := newHTTPClient(timeout).Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+		}
+		prices, err := parsers.DecodeCryptoComparePrices(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("parsing response failed: %w", err)
+		}
+		return prices, nil
+	})
+}
+
+// newCryptoCompareFromConfig creates the batch with api_keys.cryptocompare when it is set
+func newCryptoCompareFromConfig(cfg Config) (*ETHBatch, error) {
+	if _, ok := cfg.APIKeys["cryptocompare"]; !ok || !cfg.usesBatch("cryptocompare") {
+		return NewCryptoCompareBatch(""), nil
+	}
+	key, err := cfg.apiKey("cryptocompare")
+	if err != nil {
+		return nil, err
+	}
+	return NewCryptoCompareBatch(key), nil
+}
@@ -0,0 +1,161 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Decoder turns a response into the value a parse expression sees as body
+// It returns the same kinds of values as encoding/json does into an any: maps, lists, strings, numbers and bools
+type Decoder func(body io.Reader) (any, error)
+
+// decoders are the formats a configured source can give in its format setting, see decoderFor
+var decoders = struct {
+	mu     sync.RWMutex
+	byName map[string]Decoder
+}{byName: map[string]Decoder{
+	"json": decodeJSONBody,
+	"xml":  decodeXMLBody,
+	"csv":  decodeCSVBody,
+	"text": decodeTextBody,
+}}
+
+// RegisterDecoder adds a format for configured sources, or replaces one, for programs embedding the converter
+func RegisterDecoder(format string, decode Decoder) {
+	decoders.mu.Lock()
+	defer decoders.mu.Unlock()
+	decoders.byName[format] = decode
+}
+
+// decoderFor looks up a format; "" is JSON
+func decoderFor(format string) (Decoder, error) {
+	if format == "" {
+		format = "json"
+	}
+	decoders.mu.RLock()
+	defer decoders.mu.RUnlock()
+	decode, ok := decoders.byName[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (use %s)", format, strings.Join(slices.Sorted(maps.Keys(decoders.byName)), ", "))
+	}
+	return decode, nil
+}
+
+func decodeJSONBody(body io.Reader) (any, error) {
+	var v any
+	if err := json.NewDecoder(body).Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// decodeXMLBody reads a document as nested maps keyed by element name without its namespace, e.g.
+// <rate currency="USD"><value>0.65</value></rate> is {"rate": {"@currency": "USD", "value": "0.65"}}
+// An element with only text is that text, a repeated element is a list, and the text of an element
+// that also has attributes or children is under "#text"
+func decodeXMLBody(body io.Reader) (any, error) {
+	dec := xml.NewDecoder(body)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("no XML element in the response")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			root, err := decodeXMLElement(dec, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{start.Name.Local: root}, nil
+		}
+	}
+}
+
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement) (any, error) {
+	node := make(map[string]any)
+	for _, a := range start.Attr {
+		// Namespace declarations say nothing about the data
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		node["@"+a.Name.Local] = a.Value
+	}
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			// A child can only be a string or a map, so a list means the element was repeated already
+			switch prev := node[t.Name.Local].(type) {
+			case nil:
+				node[t.Name.Local] = child
+			case []any:
+				node[t.Name.Local] = append(prev, child)
+			default:
+				node[t.Name.Local] = []any{prev, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(node) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				node["#text"] = s
+			}
+			return node, nil
+		}
+	}
+}
+
+// decodeCSVBody reads the rows as lists of strings, the header too if there is one, so body[1][2]
+// is the third field of the second line; rows may have different lengths
+func decodeCSVBody(body io.Reader) (any, error) {
+	r := csv.NewReader(body)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]any, len(records))
+	for i, record := range records {
+		row := make([]any, len(record))
+		for j, field := range record {
+			row[j] = field
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// decodeTextBody is the whole response as a string without the surrounding whitespace
+func decodeTextBody(body io.Reader) (any, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,140 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/internal/mockexchange"
+)
+
+// Demo mode settings: the walk starts from demoBaseUSD and moves once a minute
+const (
+	demoBaseUSD   = 3300.0
+	demoAUDPerUSD = 1.52
+	demoHistory   = 30 * 24 * time.Hour
+)
+
+// demoWalk is a seeded random walk of the ETH/USD price, one step per minute
+// The same seed always gives the same prices at the same times, so demos and screenshots are repeatable
+type demoWalk struct {
+	seed  uint64
+	epoch time.Time // the walk starts here; earlier times return the base price
+
+	mu    sync.Mutex
+	steps []float64 // log price after each minute, extended on demand
+	rng   *rand.Rand
+}
+
+func newDemoWalk(seed uint64, epoch time.Time) *demoWalk {
+	return &demoWalk{seed: seed, epoch: epoch.Truncate(time.Minute), rng: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+// At returns the price at t
+func (w *demoWalk) At(t time.Time) float64 {
+	n := int(t.Sub(w.epoch) / time.Minute)
+	if n < 0 {
+		return demoBaseUSD
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.steps) <= n {
+		last := 0.0
+		if len(w.steps) > 0 {
+			last = w.steps[len(w.steps)-1]
+		}
+		// About 0.1% per minute, pulled gently back towards the base so a long demo doesn't wander off
+		w.steps = append(w.steps, last*0.9995+w.rng.NormFloat64()*0.001)
+	}
+	return demoBaseUSD * math.Exp(w.steps[n])
+}
+
+// demoTransport answers every API request in-process from the mock exchange handler,
+// with the price set from the walk at the current time
+type demoTransport struct {
+	walk     *demoWalk
+	exchange *mockexchange.Exchange
+	offsets  map[string]float64
+	mu       sync.Mutex
+}
+
+// demoOffsets places each exchange a fixed, seeded distance from the walk so there is a spread to look at
+func demoOffsets(seed uint64) map[string]float64 {
+	offsets := make(map[string]float64)
+	rng := rand.New(rand.NewPCG(seed, 1))
+	for _, name := range []string{"CoinGecko", "Coinbase", "Bitstamp", "Kraken", "Bitfinex"} {
+		offsets[name] = (rng.Float64()*2 - 1) * 0.002
+	}
+	return offsets
+}
+
+func newDemoTransport(walk *demoWalk) *demoTransport {
+	return &demoTransport{walk: walk, exchange: mockexchange.NewExchange(demoBaseUSD, demoAUDPerUSD), offsets: demoOffsets(walk.seed)}
+}
+
+func (t *demoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	price := t.walk.At(defaultClock.Now())
+
+	// The mutex keeps the price set and the response written together when fetchers run in parallel
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.exchange.SetPrice(price)
+	for name, off := range t.offsets {
+		t.exchange.SetSourcePrice(name, price*(1+off))
+	}
+	return t.exchange.RoundTrip(req)
+}
+
+// demoSamples builds hourly history from the walk for the period before now
+func demoSamples(walk *demoWalk, from, to time.Time) []Sample {
+	offsets := demoOffsets(walk.seed)
+	var samples []Sample
+	for at := from.Truncate(time.Hour); at.Before(to); at = at.Add(time.Hour) {
+		usd := walk.At(at)
+		s := Sample{Time: at.UTC()}
+		var sum float64
+		for _, name := range []string{"CoinGecko", "Coinbase", "Bitstamp", "Kraken", "Bitfinex"} {
+			quote := usd * (1 + offsets[name])
+			s.Sources = append(s.Sources, SourceSample{Name: name, USD: quote})
+			sum += quote
+		}
+		s.RateAUD = sum / float64(len(s.Sources)) * demoAUDPerUSD
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// enableDemo switches to a separate data directory, serves all prices from a seeded random walk
+// and fills the demo history on first use, so reports and backtests have something to show
+func enableDemo(seed uint64) error {
+	dir := filepath.Join(dataDir(), "demo")
+	if err := os.Setenv("AUDETH_HOME", dir); err != nil {
+		return err
+	}
+
+	// The walk starts at a midnight, so the same seed shows the same prices all day
+	now := defaultClock.Now()
+	walk := newDemoWalk(seed, now.UTC().Truncate(24*time.Hour).Add(-demoHistory))
+	httpTransport = newDemoTransport(walk)
+
+	store := NewFileStore(dir)
+	existing, err := store.Samples(now.Add(-demoHistory), now)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+	fmt.Println(tr("demo.history", int(demoHistory.Hours()/24), dir))
+	return store.ReplaceSamples(now.Add(-demoHistory), now, demoSamples(walk, now.Add(-demoHistory), now))
+}
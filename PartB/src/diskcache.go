@@ -0,0 +1,53 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// rateCachePath is the file holding the last successful aggregate and its per-source quotes
+func rateCachePath() string {
+	return filepath.Join(dataDir(), "rate_cache.json")
+}
+
+// loadRateCache reads the last known rate, returning ok=false when nothing has been cached yet
+func loadRateCache(path string) (Sample, bool, error) {
+	var s Sample
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, false, nil
+	}
+	if err != nil {
+		return s, false, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, false, fmt.Errorf("parsing %s failed: %v", path, err)
+	}
+	return s, s.RateAUD > 0, nil
+}
+
+// saveRateCache writes the sample to a temporary file and renames it into place
+// The rename is atomic, so a crash mid-write never leaves a truncated cache behind
+func saveRateCache(path string, s Sample) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,148 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// ETHBatch prices ETH in every registered currency with one request, for sources whose endpoint
+// takes a list of currencies, like CoinGeckoBatch does for /simple/price
+// The ETH/USD quote and the AUD price behind the FX rate then share a request when both come from the source
+type ETHBatch struct {
+	name    string
+	request func(ctx context.Context, currencies []string) (map[string]float64, error)
+	clock   Clock
+
+	mu         sync.Mutex
+	currencies []string
+	prices     map[string]float64
+	fetchedAt  time.Time
+
+	group singleflight.Group
+}
+
+// newETHBatch creates an empty batch sending its requests through request; Quote and FX register the currencies they need
+func newETHBatch(name string, request func(ctx context.Context, currencies []string) (map[string]float64, error)) *ETHBatch {
+	return &ETHBatch{name: name, request: request, clock: defaultClock}
+}
+
+// add registers a currency, keeping the order they were added in
+func (b *ETHBatch) add(currency string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !slices.Contains(b.currencies, currency) {
+		b.currencies = append(b.currencies, currency)
+	}
+}
+
+// Price returns ETH's price in currency, from the latest batch or a new one
+// The response is reused for as long as CoinGecko's, see coinGeckoBatchReuse
+func (b *ETHBatch) Price(ctx context.Context, currency string) (float64, error) {
+	b.mu.Lock()
+	prices := b.prices
+	fresh := prices != nil && b.clock.Now().Sub(b.fetchedAt) < coinGeckoBatchReuse
+	currencies := slices.Clone(b.currencies)
+	b.mu.Unlock()
+
+	if !fresh {
+		ch := b.group.DoChan("prices", func() (any, error) {
+			prices, err := b.request(context.WithoutCancel(ctx), currencies)
+			if err != nil {
+				return nil, err
+			}
+			b.mu.Lock()
+			b.prices, b.fetchedAt = prices, b.clock.Now()
+			b.mu.Unlock()
+			return prices, nil
+		})
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case r := <-ch:
+			if r.Err != nil {
+				return 0, r.Err
+			}
+			prices = r.Val.(map[string]float64)
+		}
+	}
+	price, ok := prices[currency]
+	if !ok {
+		return 0, fmt.Errorf("parsing response failed: missing ETH.%s", currency)
+	}
+	return price, nil
+}
+
+// Quote registers USD and returns a PriceFetcher for ETH/USD
+func (b *ETHBatch) Quote() PriceFetcher {
+	b.add("USD")
+	return ethBatchQuote{batch: b}
+}
+
+// FX registers USD and AUD and returns an FXProvider implying the rate from ETH's price in both
+func (b *ETHBatch) FX() FXProvider {
+	b.add("USD")
+	b.add("AUD")
+	return ethBatchFX{batch: b}
+}
+
+// ethBatchQuote is ETH/USD read from the batch
+type ethBatchQuote struct {
+	batch *ETHBatch
+}
+
+func (q ethBatchQuote) Name() string {
+	return q.batch.name
+}
+
+func (q ethBatchQuote) FetchPrice() (float64, error) {
+	return q.FetchPriceContext(context.Background())
+}
+
+func (q ethBatchQuote) FetchPriceContext(ctx context.Context) (float64, error) {
+	price, err := q.batch.Price(ctx, "USD")
+	if err != nil {
+		return 0, err
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("invalid price: %f", price)
+	}
+	return price, nil
+}
+
+// ethBatchFX is the USD to AUD rate read from the batch
+type ethBatchFX struct {
+	batch *ETHBatch
+}
+
+func (f ethBatchFX) Name() string {
+	return f.batch.name
+}
+
+func (f ethBatchFX) FetchRate() (float64, error) {
+	return f.FetchRateContext(context.Background())
+}
+
+func (f ethBatchFX) FetchRateContext(ctx context.Context) (float64, error) {
+	usd, err := f.batch.Price(ctx, "USD")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	aud, err := f.batch.Price(ctx, "AUD")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	if usd == 0 || aud == 0 {
+		return 0, fmt.Errorf("failed to decode exchange rates: invalid exchange rates")
+	}
+	return aud / usd, nil
+}
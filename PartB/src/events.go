@@ -0,0 +1,329 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NATSConfig is a NATS server each rate refresh is published to
+type NATSConfig struct {
+	URL      string `json:"url"`      // "nats://host:4222", or "tls://host:4222" for TLS with the tls section's settings
+	Subject  string `json:"subject"`  // default "audeth.rate"
+	User     string `json:"user"`     // optional, with password
+	Password string `json:"password"` // a secret reference such as "env:NATS_PASSWORD"
+	Token    string `json:"token"`    // optional auth token, also a secret reference
+	Format   string `json:"format"`   // "json" (default) or "protobuf", see events.proto
+}
+
+// KafkaConfig is a Kafka topic each rate refresh is produced to, through a Confluent REST Proxy or
+// Redpanda's HTTP proxy, so no Kafka client library is needed
+type KafkaConfig struct {
+	RestProxy string `json:"rest_proxy"` // e.g. "http://localhost:8082"
+	Topic     string `json:"topic"`      // default "audeth.rate"
+	Format    string `json:"format"`     // "json" (default) or "protobuf", see events.proto
+}
+
+// encodeEvent serialises a sample as format
+func encodeEvent(s Sample, format string) ([]byte, error) {
+	switch format {
+	case "", "json":
+		return json.Marshal(s)
+	case "protobuf":
+		return rateEventProto(s), nil
+	}
+	return nil, fmt.Errorf("unknown format: %s (use json or protobuf)", format)
+}
+
+// Protobuf wire types (https://protobuf.dev/programming-guides/encoding/)
+const (
+	protoVarint = 0
+	protoI64    = 1
+	protoLen    = 2
+)
+
+func appendProtoTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wire))
+}
+
+// Fields at their zero value are left out, as proto3 does
+func appendProtoInt64(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(appendProtoTag(b, field, protoVarint), uint64(v))
+}
+
+func appendProtoDouble(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(appendProtoTag(b, field, protoI64), math.Float64bits(v))
+}
+
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	return append(binary.AppendUvarint(appendProtoTag(b, field, protoLen), uint64(len(v))), v...)
+}
+
+func appendProtoString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return appendProtoBytes(b, field, []byte(v))
+}
+
+// unixNano is t in nanoseconds, 0 for the zero time
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// rateEventProto encodes s as the RateEvent message in events.proto
+func rateEventProto(s Sample) []byte {
+	b := appendProtoInt64(nil, 1, unixNano(s.Time))
+	b = appendProtoDouble(b, 2, s.RateAUD)
+	for _, src := range s.Sources {
+		q := appendProtoString(nil, 1, src.Name)
+		q = appendProtoDouble(q, 2, src.USD)
+		q = appendProtoString(q, 3, src.Error)
+		q = appendProtoInt64(q, 4, unixNano(src.Time))
+		q = appendProtoDouble(q, 5, src.Bid)
+		q = appendProtoDouble(q, 6, src.Ask)
+		b = appendProtoBytes(b, 3, q)
+	}
+	return b
+}
+
+// NATSPublisher publishes to one subject over a short-lived connection, like MQTTPublisher
+// The NATS client protocol is plain text: the server sends INFO, the client answers CONNECT,
+// then PUB subject size, the payload, and a PING whose PONG confirms the server took it
+type NATSPublisher struct {
+	addr    string
+	useTLS  bool
+	tls     TLSConfig
+	connect map[string]any
+	subject string
+	format  string
+	timeout time.Duration
+}
+
+// newNATSPublisherFromConfig returns the configured publisher, or false when nats.url isn't set
+func newNATSPublisherFromConfig(cfg Config) (NATSPublisher, bool, error) {
+	n := cfg.NATS
+	if n.URL == "" {
+		return NATSPublisher{}, false, nil
+	}
+	u, err := url.Parse(n.URL)
+	if err != nil || u.Host == "" {
+		return NATSPublisher{}, false, fmt.Errorf("nats.url: use nats://host:4222 or tls://host:4222")
+	}
+	p := NATSPublisher{addr: u.Host, tls: cfg.TLS, subject: n.Subject, format: n.Format, timeout: 5 * time.Second,
+		connect: map[string]any{"verbose": false, "pedantic": false, "name": "audeth", "lang": "go", "version": "1", "protocol": 1}}
+	switch u.Scheme {
+	case "nats":
+	case "tls":
+		p.useTLS = true
+	default:
+		return NATSPublisher{}, false, fmt.Errorf("nats.url: unknown scheme %q (use nats or tls)", u.Scheme)
+	}
+	if u.Port() == "" {
+		p.addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	if n.User != "" {
+		password, err := resolveSecret(n.Password)
+		if err != nil {
+			return NATSPublisher{}, false, fmt.Errorf("nats.password: %v", err)
+		}
+		p.connect["user"], p.connect["pass"] = n.User, password
+	}
+	if n.Token != "" {
+		token, err := resolveSecret(n.Token)
+		if err != nil {
+			return NATSPublisher{}, false, fmt.Errorf("nats.token: %v", err)
+		}
+		p.connect["auth_token"] = token
+	}
+	if p.subject == "" {
+		p.subject = "audeth.rate"
+	}
+	if _, err := encodeEvent(Sample{}, p.format); err != nil {
+		return NATSPublisher{}, false, fmt.Errorf("nats.format: %v", err)
+	}
+	return p, true, nil
+}
+
+// Publish sends one sample
+func (p NATSPublisher) Publish(s Sample) error {
+	payload, err := encodeEvent(s, p.format)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", p.addr, p.timeout)
+	if err != nil {
+		return fmt.Errorf("connecting to %s failed: %v", p.addr, err)
+	}
+	defer func() { conn.Close() }()
+	conn.SetDeadline(time.Now().Add(p.timeout))
+
+	rd := bufio.NewReader(conn)
+	line, err := rd.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats handshake failed: expected INFO, got %q", strings.TrimSpace(line))
+	}
+	// A TLS connection is upgraded after INFO, as the server expects
+	if p.useTLS {
+		conf, err := newTLSClientConfig(p.tls)
+		if err != nil {
+			return err
+		}
+		conf = conf.Clone()
+		conf.ServerName, _, _ = net.SplitHostPort(p.addr)
+		tlsConn := tls.Client(conn, conf)
+		if err := tlsConn.Handshake(); err != nil {
+			return fmt.Errorf("nats tls handshake failed: %v", err)
+		}
+		conn, rd = tlsConn, bufio.NewReader(tlsConn)
+	}
+
+	options, _ := json.Marshal(p.connect)
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "CONNECT %s\r\nPUB %s %d\r\n", options, p.subject, len(payload))
+	msg.Write(payload)
+	msg.WriteString("\r\nPING\r\n")
+	if _, err := conn.Write(msg.Bytes()); err != nil {
+		return fmt.Errorf("nats publish failed: %v", err)
+	}
+	// The server answers -ERR for a bad login or subject before the PONG
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("nats publish failed: %v", err)
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats refused the publish: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		case line == "PING":
+			conn.Write([]byte("PONG\r\n"))
+		}
+	}
+}
+
+// KafkaPublisher produces to a topic through the REST Proxy's v2 API
+// JSON events are sent embedded as JSON, protobuf ones as base64 through the binary embedded format
+type KafkaPublisher struct {
+	url     string
+	format  string
+	timeout time.Duration
+}
+
+// newKafkaPublisherFromConfig returns the configured publisher, or false when kafka.rest_proxy isn't set
+func newKafkaPublisherFromConfig(cfg Config) (KafkaPublisher, bool, error) {
+	k := cfg.Kafka
+	if k.RestProxy == "" {
+		return KafkaPublisher{}, false, nil
+	}
+	topic := k.Topic
+	if topic == "" {
+		topic = "audeth.rate"
+	}
+	if _, err := encodeEvent(Sample{}, k.Format); err != nil {
+		return KafkaPublisher{}, false, fmt.Errorf("kafka.format: %v", err)
+	}
+	u := strings.TrimSuffix(k.RestProxy, "/") + "/topics/" + url.PathEscape(topic)
+	return KafkaPublisher{url: u, format: k.Format, timeout: 10 * time.Second}, true, nil
+}
+
+// Publish produces one record for the sample
+func (p KafkaPublisher) Publish(s Sample) error {
+	payload, err := encodeEvent(s, p.format)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	contentType := "application/vnd.kafka.json.v2+json"
+	if p.format == "protobuf" {
+		contentType = "application/vnd.kafka.binary.v2+json"
+		body, _ = json.Marshal(map[string]any{"records": []map[string]string{{"value": base64.StdEncoding.EncodeToString(payload)}}})
+	} else {
+		body, _ = json.Marshal(map[string]any{"records": []map[string]json.RawMessage{{"value": payload}}})
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("kafka produce failed: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := newHTTPClient(p.timeout).Do(req)
+	if err != nil {
+		return redactError(fmt.Errorf("kafka produce failed: %v", err))
+	}
+	defer resp.Body.Close()
+	// The proxy answers 200 even when a record failed, with the error in its offsets list
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	json.Unmarshal(data, &result)
+	if resp.StatusCode != http.StatusOK {
+		msg := result.Message
+		if msg == "" {
+			msg = strings.TrimSpace(string(data))
+		}
+		return fmt.Errorf("kafka produce returned status code %d: %s", resp.StatusCode, msg)
+	}
+	for _, o := range result.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("kafka produce failed: %s", o.Error)
+		}
+	}
+	return nil
+}
+
+// publishEvents sends a freshly fetched sample to the configured NATS subject and Kafka topic
+// Failures are only warnings, like failing to push to InfluxDB
+func publishEvents(sample Sample) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return
+	}
+	if p, ok, err := newNATSPublisherFromConfig(cfg); err != nil || ok {
+		if err == nil {
+			err = p.Publish(sample)
+		}
+		if err != nil {
+			fmt.Fprintln(progressOut(), tr("warning.nats", redactError(err)))
+		}
+	}
+	if p, ok, err := newKafkaPublisherFromConfig(cfg); err != nil || ok {
+		if err == nil {
+			err = p.Publish(sample)
+		}
+		if err != nil {
+			fmt.Fprintln(progressOut(), tr("warning.kafka", redactError(err)))
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+)
+
+// Exit codes, so scripts and monitoring can tell failures apart; listed in the README under "Exit codes"
+// These are part of the interface: a code is never reused for a different meaning
+const (
+	exitOK         = 0
+	exitError      = 1 // anything not covered below, e.g. an unreadable config file
+	exitUsage      = 2 // invalid flags, arguments or amounts
+	exitFetch      = 3 // no rate: every source failed, or the USD/AUD rate couldn't be fetched
+	exitQuorum     = 4 // fewer sources answered than fetch.min_sources asks for
+	exitDivergence = 5 // the sources disagreed by more than fetch.max_divergence
+	exitPartial    = 6 // some of the amounts were converted and some weren't
+	exitConfidence = 7 // the rate's confidence was below fetch.min_confidence
+)
+
+// The typed errors carry their exit code; errors.As finds them through redactError and any %w wrapping
+// The converter's own are in package audeth, named here as they were before it moved there
+type (
+	FetchError      = audeth.FetchError
+	QuorumError     = audeth.QuorumError
+	DivergenceError = audeth.DivergenceError
+	ConfidenceError = audeth.ConfidenceError
+)
+
+// UsageError is an invalid command line or input
+type UsageError struct {
+	err error
+}
+
+func (e UsageError) Error() string { return e.err.Error() }
+func (e UsageError) Unwrap() error { return e.err }
+
+// usageErrorf is fmt.Errorf for a UsageError
+func usageErrorf(format string, a ...any) error {
+	return UsageError{fmt.Errorf(format, a...)}
+}
+
+// PartialError is a batch where only some of the work succeeded
+type PartialError struct {
+	Failed, Total int
+}
+
+func (e PartialError) Error() string {
+	return fmt.Sprintf("%d of the %d amounts could not be converted", e.Failed, e.Total)
+}
+
+// exitCode maps an error onto the exit code the program ends with
+// -h isn't a failure: the usage it asked for has been printed, so it ends with exitOK
+func exitCode(err error) int {
+	var (
+		fetch      FetchError
+		quorum     QuorumError
+		divergence DivergenceError
+		usage      UsageError
+		partial    PartialError
+		confidence ConfidenceError
+	)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.As(err, &quorum):
+		return exitQuorum
+	case errors.As(err, &divergence):
+		return exitDivergence
+	case errors.As(err, &fetch):
+		return exitFetch
+	case errors.As(err, &partial):
+		return exitPartial
+	case errors.As(err, &confidence):
+		return exitConfidence
+	default:
+		return exitError
+	}
+}
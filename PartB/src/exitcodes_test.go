@@ -0,0 +1,111 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	fetch := FetchError{Err: errors.New("every source failed")}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"help", UsageError{flag.ErrHelp}, exitOK},
+		{"usage", usageErrorf("unknown period: yearly"), exitUsage},
+		{"fetch", fetch, exitFetch},
+		{"wrapped and redacted", redactError(fmt.Errorf("convert: %w", fetch)), exitFetch},
+		{"quorum", QuorumError{Got: 1, Want: 2}, exitQuorum},
+		{"divergence", DivergenceError{SpreadPct: 3, MaxPct: 1}, exitDivergence},
+		{"partial", PartialError{Failed: 1, Total: 3}, exitPartial},
+		{"confidence", ConfidenceError{Got: 0.5, Min: 0.8}, exitConfidence},
+		{"other", errors.New("config.json: unexpected end of JSON input"), exitError},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"-period", "weekly"}, exitOK},
+		{[]string{"-h"}, exitOK},
+		{[]string{"-bogus"}, exitUsage},
+		{[]string{"-every", "x"}, exitUsage},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String("period", "daily", "")
+		fs.Duration("every", 0, "")
+		if got := exitCode(parseFlags(fs, tt.args)); got != tt.want {
+			t.Errorf("%q: got exit code %d, want %d", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCommandUsageErrors(t *testing.T) {
+	// Each fails on its arguments before loading the config or reaching the network
+	tests := []struct {
+		run  func([]string) error
+		args []string
+	}{
+		{runReport, []string{"-bogus"}},
+		{runReport, []string{"-period", "yearly"}},
+		{runReport, []string{"-format", "pdf"}},
+		{runBacktest, []string{"-every", "x"}},
+		{runBacktest, []string{"-amount", "-5"}},
+		{runBacktest, []string{"-source", "nope"}},
+		{runRoutes, []string{"-amount", "-5"}},
+		{runHistory, []string{"export", "-format", "nope"}},
+		{runHistory, []string{"export", "-kind", "nope"}},
+		{runHistory, []string{"import", "-format", "xlsx", "rates.xlsx"}},
+		{runHistory, []string{"nope"}},
+		{runBench, []string{"-rps", "0"}},
+	}
+	for _, tt := range tests {
+		var usage UsageError
+		if err := tt.run(tt.args); !errors.As(err, &usage) {
+			t.Errorf("%q: got %v, want a UsageError", tt.args, err)
+		}
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	fetch := FetchError{Err: errors.New("every source failed")}
+	tests := []struct {
+		name                        string
+		entered, converted, invalid int
+		rateErr                     error
+		want                        error
+	}{
+		{"all converted", 3, 3, 0, nil, nil},
+		{"one invalid", 3, 2, 1, nil, PartialError{Failed: 1, Total: 3}},
+		// Each amount whose rate failed is a failure of its own
+		{"two rate failures", 4, 2, 0, fetch, PartialError{Failed: 2, Total: 4}},
+		{"invalid and rate failures", 5, 1, 1, fetch, PartialError{Failed: 4, Total: 5}},
+		{"no rate at all", 2, 0, 0, fetch, fetch},
+		{"nothing valid", 2, 0, 2, nil, usageErrorf("no valid amounts given")},
+	}
+	for _, tt := range tests {
+		got := batchError(tt.entered, tt.converted, tt.invalid, tt.rateErr)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) || exitCode(got) != exitCode(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,885 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expressions are a small language for the config file, modelled on CEL (https://cel.dev):
+// sources.<name>.parse picks the price out of a response the built-in parsers don't know,
+// and fetch.aggregate replaces the mean of the quotes; see "Expressions" in the README
+// It is written here rather than pulled in as a library, like the MQTT and NATS clients, and only has
+// what those two uses need: arithmetic, comparisons, field and index access, a few list functions,
+// and the filter and map macros. Expressions can't loop forever, call out or change anything
+
+// exprNode is one node of a parsed expression
+type exprNode interface {
+	eval(env map[string]any) (any, error)
+}
+
+// Expr is a compiled expression; the source is kept for error messages
+type Expr struct {
+	src  string
+	root exprNode
+}
+
+// compileExpr parses src, so a mistake is reported when the config is loaded rather than on the first fetch
+func compileExpr(src string) (*Expr, error) {
+	p := &exprParser{src: src}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	root, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at column %d", t.text, p.column(t.pos))
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// Eval evaluates the expression with the variables in env
+func (e *Expr) Eval(env map[string]any) (any, error) {
+	return e.root.eval(env)
+}
+
+// EvalNumber evaluates the expression and requires a finite number as the result
+func (e *Expr) EvalNumber(env map[string]any) (float64, error) {
+	v, err := e.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("%s gave %s, not a number", e.src, exprType(v))
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("%s gave %v", e.src, n)
+	}
+	return n, nil
+}
+
+// exprType names a value's type the way the error messages use it
+func exprType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case bool:
+		return "a bool"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a map"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Tokens
+
+const (
+	tokEOF = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind int
+	text string
+	num  float64
+	pos  int
+}
+
+type exprParser struct {
+	src    string
+	tokens []exprToken
+	next   int
+	depth  int // how many ternaries and unary operators are being parsed, see maxExprDepth
+}
+
+// maxExprDepth caps how deeply an expression can nest, so a runaway one fails to compile instead of
+// taking the stack with it
+const maxExprDepth = 100
+
+// column is the 1-based column of the byte offset pos, counting characters rather than bytes
+func (p *exprParser) column(pos int) int {
+	return utf8.RuneCountInString(p.src[:pos]) + 1
+}
+
+// nest counts one more level on the way into ternary or unary, the rules everything nested goes through;
+// call the func it returns on the way out
+func (p *exprParser) nest() (func(), error) {
+	if p.depth++; p.depth > maxExprDepth {
+		return nil, fmt.Errorf("expression nests more than %d deep", maxExprDepth)
+	}
+	return func() { p.depth-- }, nil
+}
+
+// exprOps are the operators, longest first so "<=" isn't read as "<" and "="
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "+", "-", "*", "/", "%", "<", ">", "!", "?", ":", ".", ",", "(", ")", "[", "]"}
+
+func (p *exprParser) lex() error {
+	src := p.src
+	for i := 0; i < len(src); {
+		c := src[i]
+		r, _ := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.' || src[j] == 'e' || src[j] == 'E' ||
+				(src[j] == '-' || src[j] == '+') && (src[j-1] == 'e' || src[j-1] == 'E')) {
+				j++
+			}
+			n, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q at column %d", src[i:j], p.column(i))
+			}
+			p.tokens = append(p.tokens, exprToken{kind: tokNumber, text: src[i:j], num: n, pos: i})
+			i = j
+		case c == '"' || c == '\'':
+			text, end, err := p.lexString(i)
+			if err != nil {
+				return err
+			}
+			p.tokens = append(p.tokens, exprToken{kind: tokString, text: text, pos: i})
+			i = end
+		case identRune(r, true):
+			j := i
+			for j < len(src) {
+				r, size := utf8.DecodeRuneInString(src[j:])
+				if !identRune(r, false) {
+					break
+				}
+				j += size
+			}
+			p.tokens = append(p.tokens, exprToken{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range exprOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return fmt.Errorf("unexpected %q at column %d", r, p.column(i))
+			}
+			p.tokens = append(p.tokens, exprToken{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	p.tokens = append(p.tokens, exprToken{kind: tokEOF, text: "end of expression", pos: len(src)})
+	return nil
+}
+
+// identRune reports whether r can be part of a name; a name starts with a letter or _, then digits are allowed too
+func identRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || !first && r >= '0' && r <= '9'
+}
+
+// stringEscapes are what a backslash and the character after it stand for in a string, as in CEL;
+// \xHH, \uHHHH and \UHHHHHHHH give the character with that code
+var stringEscapes = map[byte]string{
+	'n': "\n", 't': "\t", 'r': "\r", 'a': "\a", 'b': "\b", 'f': "\f", 'v': "\v",
+	'\\': "\\", '"': "\"", '\'': "'", '`': "`", '?': "?",
+}
+
+// lexString reads the string starting with the quote at i, returning its value and the offset just past it
+func (p *exprParser) lexString(i int) (string, int, error) {
+	src, quote := p.src, p.src[i]
+	var b strings.Builder
+	for j := i + 1; j < len(src); j++ {
+		if src[j] == quote {
+			return b.String(), j + 1, nil
+		}
+		if src[j] != '\\' {
+			b.WriteByte(src[j])
+			continue
+		}
+		if j+1 == len(src) {
+			break
+		}
+		j++
+		if esc, ok := stringEscapes[src[j]]; ok {
+			b.WriteString(esc)
+			continue
+		}
+		digits := 0
+		switch src[j] {
+		case 'x':
+			digits = 2
+		case 'u':
+			digits = 4
+		case 'U':
+			digits = 8
+		}
+		if digits == 0 {
+			return "", 0, fmt.Errorf("unknown escape \\%c at column %d", src[j], p.column(j-1))
+		}
+		if j+digits >= len(src) {
+			break
+		}
+		code, err := strconv.ParseUint(src[j+1:j+1+digits], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return "", 0, fmt.Errorf("invalid escape \\%s at column %d", src[j:j+1+digits], p.column(j-1))
+		}
+		b.WriteRune(rune(code))
+		j += digits
+	}
+	return "", 0, fmt.Errorf("unterminated string at column %d", p.column(i))
+}
+
+func (p *exprParser) peek() exprToken { return p.tokens[p.next] }
+
+// accept consumes the operator op if it is next
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return fmt.Errorf("expected %q at column %d, found %q", op, p.column(t.pos), t.text)
+	}
+	return nil
+}
+
+// Grammar, loosest first: ternary ?:, ||, &&, comparisons, + -, * / %, unary - !, then . [] and calls
+
+func (p *exprParser) ternary() (exprNode, error) {
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	cond, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	then, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return exprTernary{cond, then, otherwise}, nil
+}
+
+// exprLevels are the binary operators by precedence
+var exprLevels = [][]string{{"||"}, {"&&"}, {"==", "!=", "<", "<=", ">", ">="}, {"+", "-"}, {"*", "/", "%"}}
+
+func (p *exprParser) binary(level int) (exprNode, error) {
+	if level == len(exprLevels) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !slices.Contains(exprLevels[level], t.text) {
+			return left, nil
+		}
+		p.next++
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = exprBinary{t.text, left, right}
+	}
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	leave, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+	for _, op := range []string{"-", "!"} {
+		if p.accept(op) {
+			operand, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			return exprUnary{op, operand}, nil
+		}
+	}
+	return p.postfix()
+}
+
+func (p *exprParser) postfix() (exprNode, error) {
+	node, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			t := p.peek()
+			if t.kind != tokIdent {
+				return nil, fmt.Errorf("expected a field name at column %d", p.column(t.pos))
+			}
+			p.next++
+			node = exprIndex{node, exprLiteral{t.text}}
+		case p.accept("["):
+			index, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			node = exprIndex{node, index}
+		default:
+			return node, nil
+		}
+	}
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.next++
+	switch t.kind {
+	case tokNumber:
+		return exprLiteral{t.num}, nil
+	case tokString:
+		return exprLiteral{t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return exprLiteral{t.text == "true"}, nil
+		case "null":
+			return exprLiteral{nil}, nil
+		}
+		if p.accept("(") {
+			return p.call(t)
+		}
+		return exprVar(t.text), nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			node, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			var items exprList
+			for !p.accept("]") {
+				if len(items) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.ternary()
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at column %d", t.text, p.column(t.pos))
+}
+
+// call parses the arguments of a function call; filter and map are macros, their second argument
+// names the variable the third is evaluated with for each item, e.g. filter(quotes, q, q > 1000)
+func (p *exprParser) call(name exprToken) (exprNode, error) {
+	var args []exprNode
+	for !p.accept(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if name.text == "filter" || name.text == "map" {
+		v, ok := exprNode(nil), false
+		if len(args) == 3 {
+			v, ok = args[1].(exprVar)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s needs a list, a variable name and an expression, e.g. %s(quotes, q, q > 0)", name.text, name.text)
+		}
+		return exprMacro{name.text, args[0], string(v.(exprVar)), args[2]}, nil
+	}
+	fn, ok := exprFuncs[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at column %d", name.text, p.column(name.pos))
+	}
+	if len(args) != fn.args {
+		return nil, fmt.Errorf("%s takes %d argument(s), not %d", name.text, fn.args, len(args))
+	}
+	return exprCall{name.text, fn.call, args}, nil
+}
+
+// Nodes
+
+type exprLiteral struct{ v any }
+
+func (n exprLiteral) eval(map[string]any) (any, error) { return n.v, nil }
+
+type exprVar string
+
+func (n exprVar) eval(env map[string]any) (any, error) {
+	v, ok := env[string(n)]
+	if !ok {
+		names := slices.Sorted(maps.Keys(env))
+		return nil, fmt.Errorf("unknown variable %s (have %s)", n, strings.Join(names, ", "))
+	}
+	return v, nil
+}
+
+type exprList []exprNode
+
+func (n exprList) eval(env map[string]any) (any, error) {
+	out := make([]any, len(n))
+	for i, item := range n {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+type exprIndex struct{ target, index exprNode }
+
+func (n exprIndex) eval(env map[string]any) (any, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case map[string]any:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("a map is indexed by a string, not %s", exprType(index))
+		}
+		v, ok := t[key]
+		if !ok {
+			return nil, fmt.Errorf("no field %q", key)
+		}
+		return v, nil
+	case []any:
+		i, ok := index.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, fmt.Errorf("a list is indexed by a whole number, not %s", exprType(index))
+		}
+		// A negative index counts from the end, -1 is the last item
+		if i < 0 {
+			i += float64(len(t))
+		}
+		if i < 0 || i >= float64(len(t)) {
+			return nil, fmt.Errorf("index %v is out of range for a list of %d", index, len(t))
+		}
+		return t[int(i)], nil
+	}
+	return nil, fmt.Errorf("can't index %s", exprType(target))
+}
+
+type exprUnary struct {
+	op      string
+	operand exprNode
+}
+
+func (n exprUnary) eval(env map[string]any) (any, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("! needs a bool, not %s", exprType(v))
+		}
+		return !b, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("- needs a number, not %s", exprType(v))
+	}
+	return -f, nil
+}
+
+type exprBinary struct {
+	op          string
+	left, right exprNode
+}
+
+func (n exprBinary) eval(env map[string]any) (any, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// && and || only evaluate the right side when it decides the result
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs bools, not %s", n.op, exprType(left))
+		}
+		if l == (n.op == "||") {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s needs bools, not %s", n.op, exprType(right))
+		}
+		return r, nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	}
+	if l, ok := left.(string); ok && n.op == "+" {
+		if r, ok := right.(string); ok {
+			return l + r, nil
+		}
+	}
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		// Strings compare in byte order, which is enough for ISO dates and symbols
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if lok && rok {
+			switch n.op {
+			case "<":
+				return ls < rs, nil
+			case "<=":
+				return ls <= rs, nil
+			case ">":
+				return ls > rs, nil
+			case ">=":
+				return ls >= rs, nil
+			}
+		}
+		return nil, fmt.Errorf("can't use %s on %s and %s", n.op, exprType(left), exprType(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	}
+	return l >= r, nil
+}
+
+// exprEqual compares by value, lists item by item and maps key by key, as CEL does
+// Values of different types are never equal
+func exprEqual(a, b any) bool {
+	switch a := a.(type) {
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, exprEqual)
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		return ok && maps.EqualFunc(a, b, exprEqual)
+	}
+	return a == b
+}
+
+type exprTernary struct{ cond, then, otherwise exprNode }
+
+func (n exprTernary) eval(env map[string]any) (any, error) {
+	c, err := n.cond.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := c.(bool)
+	if !ok {
+		return nil, fmt.Errorf("?: needs a bool condition, not %s", exprType(c))
+	}
+	if b {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+type exprMacro struct {
+	name string
+	list exprNode
+	v    string
+	body exprNode
+}
+
+func (n exprMacro) eval(env map[string]any) (any, error) {
+	v, err := n.list.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	items, err := exprItems(n.name, v)
+	if err != nil {
+		return nil, err
+	}
+	inner := maps.Clone(env)
+	out := []any{}
+	for _, item := range items {
+		inner[n.v] = item
+		result, err := n.body.eval(inner)
+		if err != nil {
+			return nil, err
+		}
+		if n.name == "map" {
+			out = append(out, result)
+			continue
+		}
+		keep, ok := result.(bool)
+		if !ok {
+			return nil, fmt.Errorf("filter needs a bool condition, not %s", exprType(result))
+		}
+		if keep {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+type exprCall struct {
+	name string
+	fn   func(args []any) (any, error)
+	args []exprNode
+}
+
+func (n exprCall) eval(env map[string]any) (any, error) {
+	args := make([]any, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := n.fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.name, err)
+	}
+	return v, nil
+}
+
+// Functions
+
+// exprItems is v as a list; a map gives its values in key order, so filter and map work on both
+func exprItems(name string, v any) ([]any, error) {
+	switch t := v.(type) {
+	case []any:
+		return t, nil
+	case map[string]any:
+		var items []any
+		for _, k := range slices.Sorted(maps.Keys(t)) {
+			items = append(items, t[k])
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("%s needs a list or a map, not %s", name, exprType(v))
+}
+
+// exprNumbers is v as a list of numbers, for the aggregate functions
+func exprNumbers(v any) ([]float64, error) {
+	items, err := exprItems("the argument", v)
+	if err != nil {
+		return nil, err
+	}
+	nums := make([]float64, len(items))
+	for i, item := range items {
+		n, ok := item.(float64)
+		if !ok {
+			return nil, fmt.Errorf("item %d is %s, not a number", i, exprType(item))
+		}
+		nums[i] = n
+	}
+	if len(nums) == 0 {
+		return nil, fmt.Errorf("the list is empty")
+	}
+	return nums, nil
+}
+
+// exprFunc is a function with a fixed number of arguments
+type exprFunc struct {
+	args int
+	call func(args []any) (any, error)
+}
+
+// numbersFunc wraps a function of a non-empty list of numbers
+func numbersFunc(f func([]float64) float64) exprFunc {
+	return exprFunc{1, func(args []any) (any, error) {
+		nums, err := exprNumbers(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(nums), nil
+	}}
+}
+
+// exprFuncs are the functions an expression can call
+var exprFuncs = map[string]exprFunc{
+	// double turns a number sent as a string, as most exchanges do, into a number
+	"double": {1, func(args []any) (any, error) {
+		switch v := args[0].(type) {
+		case float64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", v)
+			}
+			return n, nil
+		}
+		return nil, fmt.Errorf("can't turn %s into a number", exprType(args[0]))
+	}},
+	"string": {1, func(args []any) (any, error) {
+		if n, ok := args[0].(float64); ok {
+			return strconv.FormatFloat(n, 'f', -1, 64), nil
+		}
+		return fmt.Sprint(args[0]), nil
+	}},
+	"size": {1, func(args []any) (any, error) {
+		switch v := args[0].(type) {
+		case []any:
+			return float64(len(v)), nil
+		case map[string]any:
+			return float64(len(v)), nil
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		}
+		return nil, fmt.Errorf("can't take the size of %s", exprType(args[0]))
+	}},
+	"has": {2, func(args []any) (any, error) {
+		m, ok := args[0].(map[string]any)
+		key, kok := args[1].(string)
+		if !ok || !kok {
+			return nil, fmt.Errorf("has needs a map and a key")
+		}
+		_, found := m[key]
+		return found, nil
+	}},
+	"keys": {1, func(args []any) (any, error) {
+		m, ok := args[0].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("keys needs a map, not %s", exprType(args[0]))
+		}
+		var keys []any
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			keys = append(keys, k)
+		}
+		return keys, nil
+	}},
+	"values": {1, func(args []any) (any, error) {
+		return exprItems("values", args[0])
+	}},
+	"abs": {1, func(args []any) (any, error) {
+		n, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("abs needs a number, not %s", exprType(args[0]))
+		}
+		return math.Abs(n), nil
+	}},
+	"sort": {1, func(args []any) (any, error) {
+		nums, err := exprNumbers(args[0])
+		if err != nil {
+			return nil, err
+		}
+		slices.Sort(nums)
+		out := make([]any, len(nums))
+		for i, n := range nums {
+			out[i] = n
+		}
+		return out, nil
+	}},
+	"sum": numbersFunc(func(nums []float64) float64 {
+		var s float64
+		for _, n := range nums {
+			s += n
+		}
+		return s
+	}),
+	"min": numbersFunc(slices.Min[[]float64]),
+	"max": numbersFunc(slices.Max[[]float64]),
+	"mean": numbersFunc(func(nums []float64) float64 {
+		var s float64
+		for _, n := range nums {
+			s += n
+		}
+		return s / float64(len(nums))
+	}),
+	"median": numbersFunc(func(nums []float64) float64 {
+		nums = slices.Sorted(slices.Values(nums))
+		mid := len(nums) / 2
+		if len(nums)%2 == 1 {
+			return nums[mid]
+		}
+		return (nums[mid-1] + nums[mid]) / 2
+	}),
+}
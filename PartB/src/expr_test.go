@@ -0,0 +1,272 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exprEnv is what the tests evaluate against: a decoded response as body, and the quotes aggregate sees
+func exprEnv() map[string]any {
+	return map[string]any{
+		"body": map[string]any{
+			"result": map[string]any{"XETHZUSD": map[string]any{"c": []any{"3291.65", "0.5"}}},
+			"data":   []any{map[string]any{"last": 3291.5, "sym": "ETH"}, map[string]any{"last": 3290.0, "sym": "BTC"}},
+			"name":   "Ärger",
+			"empty":  []any{},
+			"none":   nil,
+		},
+		"quotes":  []any{3291.0, 3292.0, 3300.0, 3000.0},
+		"sources": map[string]any{"Kraken": 3291.0, "Coinbase": 3292.0},
+	}
+}
+
+func TestExprEval(t *testing.T) {
+	tests := []struct {
+		src  string
+		want any
+	}{
+		// Literals and arithmetic
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"10 - 4 - 3", 3.0},
+		{"7 % 4", 3.0},
+		{"-2 * -3", 6.0},
+		{"1.5e3", 1500.0},
+		{"2.5E-1", 0.25},
+		{"null", nil},
+		{"true && !false", true},
+		{"false || 1 < 2", true},
+		{"1 < 2 ? 'yes' : 'no'", "yes"},
+		{"false ? 1 : true ? 2 : 3", 2.0},
+
+		// Strings and escapes
+		{`"a" + 'b'`, "ab"},
+		{`"line\nbreak"`, "line\nbreak"},
+		{`"tab\there"`, "tab\there"},
+		{`"quote \" inside"`, `quote " inside`},
+		{`'it\'s'`, "it's"},
+		{`"back\\slash"`, `back\slash`},
+		{`"é\x41\U0001F600"`, "éA😀"},
+		{`"raw é stays"`, "raw é stays"},
+		{`"2026-03-02" < "2026-03-10"`, true},
+		{`size("héllo")`, 5.0},
+
+		// Fields, indexes and names
+		{"double(body.result.XETHZUSD.c[0])", 3291.65},
+		{`body["result"]["XETHZUSD"].c[-1]`, "0.5"},
+		{"body.data[1].sym", "BTC"},
+		{"body.name", "Ärger"},
+		{"body.none == null", true},
+		{"size(body.empty)", 0.0},
+		{"has(body, 'data') && !has(body, 'missing')", true},
+		{"keys(sources)", []any{"Coinbase", "Kraken"}},
+		{"values(sources)", []any{3292.0, 3291.0}},
+
+		// Equality of lists and maps
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, [2, 'x']] == [1, [2, 'x']]", true},
+		{"[1] != [1, 1]", true},
+		{"body.data[0] == body.data[0]", true},
+		{"body.data[0] == body.data[1]", false},
+		{"[] == body.empty", true},
+		{"1 == '1'", false},
+		{"[1] == 1", false},
+
+		// Functions and macros
+		{"sum([1, 2, 3])", 6.0},
+		{"mean(quotes)", 3220.75},
+		{"median(quotes)", 3291.5},
+		{"min(sources)", 3291.0},
+		{"max(quotes)", 3300.0},
+		{"sort([3, 1, 2])", []any{1.0, 2.0, 3.0}},
+		{"abs(-4)", 4.0},
+		{"string(3291.5) + ' AUD'", "3291.5 AUD"},
+		{"double(' 12.5 ')", 12.5},
+		{"filter(quotes, q, q > 3200)", []any{3291.0, 3292.0, 3300.0}},
+		{"map([1, 2], x, x * 10)", []any{10.0, 20.0}},
+		{"map(filter(body.data, d, d.sym == 'ETH'), d, d.last)[0]", 3291.5},
+		{"mean(filter(quotes, q, abs(q - median(quotes)) / median(quotes) < 0.02))", (3291.0 + 3292.0 + 3300.0) / 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := compileExpr(tt.src)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			got, err := e.Eval(exprEnv())
+			if err != nil {
+				t.Fatalf("eval: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprCompileErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"", "unexpected end of expression"},
+		{"1 +", "unexpected end of expression"},
+		{"1 2", `unexpected "2" at column 3`},
+		{"(1", `expected ")"`},
+		{"[1 2]", `expected ","`},
+		{"1.2.3", `invalid number "1.2.3" at column 1`},
+		{"1e", `invalid number "1e"`},
+		{`"open`, "unterminated string at column 1"},
+		{`"ends in \`, "unterminated string"},
+		{`"\q"`, `unknown escape \q at column 2`},
+		{`"\u12"`, "unterminated string"},
+		{`"\uZZZZ"`, `invalid escape \uZZZZ`},
+		{`"\UFFFFFFFF"`, `invalid escape \UFFFFFFFF`},
+		{"a # b", `unexpected '#' at column 3`},
+		{"é + ü", ""}, // names can be any letters
+		{`"é" + ß`, ""},
+		{"€", `unexpected '€' at column 1`},
+		{`"é" €`, `unexpected '€' at column 5`},
+		{"body.", "expected a field name"},
+		{"nope(1)", "unknown function nope"},
+		{"abs(1, 2)", "abs takes 1 argument(s), not 2"},
+		{"filter(quotes, 1, true)", "filter needs a list, a variable name and an expression"},
+		{strings.Repeat("(", 200) + "1" + strings.Repeat(")", 200), "nests more than 100 deep"},
+		{strings.Repeat("-", 200) + "1", "nests more than 100 deep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := compileExpr(tt.src)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("got %v, want it to compile", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprEvalErrors(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{"missing", "unknown variable missing (have body, quotes, sources)"},
+		{"body.nope", `no field "nope"`},
+		{"body.data[2]", "index 2 is out of range for a list of 2"},
+		{"body.data[-3]", "index -3 is out of range"},
+		{"body.data[1e300]", "index 1e+300 is out of range"},
+		{"body.data[0.5]", "indexed by a whole number"},
+		{"body.data['x']", "indexed by a whole number, not a string"},
+		{"body[0]", "a map is indexed by a string, not a number"},
+		{"quotes[0].x", "can't index a number"},
+		{"1 / 0", "division by zero"},
+		{"1 % 0", "division by zero"},
+		{"1 + 'a'", "can't use + on a number and a string"},
+		{"[1] < [2]", "can't use < on a list and a list"},
+		{"!1", "! needs a bool"},
+		{"-'a'", "- needs a number"},
+		{"1 && true", "&& needs bools"},
+		{"1 ? 2 : 3", "needs a bool condition"},
+		{"filter(quotes, q, q)", "filter needs a bool condition"},
+		{"mean(body.empty)", "mean: the list is empty"},
+		{"sum(['a'])", "sum: item 0 is a string, not a number"},
+		{"double('abc')", `double: "abc" is not a number`},
+		{"size(1)", "can't take the size of a number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			e, err := compileExpr(tt.src)
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+			_, err = e.Eval(exprEnv())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExprShortCircuit(t *testing.T) {
+	// The right side would fail, so getting a result means it was never evaluated
+	for _, src := range []string{"false && missing", "true || missing", "true ? 1 : missing"} {
+		e, err := compileExpr(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Eval(exprEnv()); err != nil {
+			t.Errorf("%s: %v", src, err)
+		}
+	}
+}
+
+func TestExprEvalNumber(t *testing.T) {
+	tests := []struct {
+		src     string
+		want    float64
+		wantErr string
+	}{
+		{"double(body.result.XETHZUSD.c[0])", 3291.65, ""},
+		{"body.result.XETHZUSD.c[0]", 0, "gave a string, not a number"},
+		{"body.none", 0, "gave null, not a number"},
+		{"1e308 * 10", 0, "gave +Inf"},
+	}
+	for _, tt := range tests {
+		e, err := compileExpr(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := e.EvalNumber(exprEnv())
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: got %v, %v, want an error containing %q", tt.src, got, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %v, %v, want %v", tt.src, got, err, tt.want)
+		}
+	}
+}
+
+// FuzzExpr compiles and evaluates arbitrary expressions; any may be rejected, none may panic
+// Whatever compiles must evaluate the same way twice, as nothing an expression does changes its env
+func FuzzExpr(f *testing.F) {
+	for _, seed := range []string{
+		"double(body.result.XETHZUSD.c[0])",
+		"mean(filter(quotes, q, abs(q - median(quotes)) / median(quotes) < 0.02))",
+		`body["data"][-1].sym == "BTC" ? 1 : 2`,
+		`"é\n" + 'x\'y'`,
+		"[1, [2]] == [1, [2]] && keys(sources) != []",
+		"map(values(sources), s, s * 1.5e2)[0] % 7",
+		"size('héllo') + body.data[1e300]",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		e, err := compileExpr(src)
+		if err != nil {
+			return
+		}
+		got, err := e.Eval(exprEnv())
+		again, againErr := e.Eval(exprEnv())
+		if (err == nil) != (againErr == nil) {
+			t.Fatalf("%q: evaluated to %v then %v", src, err, againErr)
+		}
+		// Printed rather than compared, so a NaN equals itself
+		if err == nil && fmt.Sprint(got) != fmt.Sprint(again) {
+			t.Errorf("%q: evaluated to %#v then %#v", src, got, again)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// displayLocation is the time zone used when rendering times for people
+// Golden tests pin it to UTC so the expected output doesn't depend on where they run
+var displayLocation = time.Local
+
+// formatMoney formats a value with thousands separators, e.g. 5132.1 -> "5,132.10"
+func formatMoney(v float64) string {
+	return formatGrouped(v, 2)
+}
+
+// formatGrouped formats a value with thousands separators and that many decimal places, e.g. 48710000 with 0 -> "48,710,000"
+func formatGrouped(v float64, places int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', places, 64)
+	whole, frac, hasFrac := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if hasFrac {
+		b.WriteByte('.')
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// formatAgo renders an elapsed duration in its largest whole unit, e.g. "2h ago"
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return tr("ago.now")
+	case d < time.Hour:
+		return tr("ago.minutes", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return tr("ago.hours", int(d.Hours()))
+	default:
+		return tr("ago.days", int(d.Hours()/24))
+	}
+}
+
+// formatChange describes the move from the previous run, e.g. "ETH/AUD $5,132.10, ▲1.4% since 2h ago"
+func formatChange(rate float64, prev Sample, now time.Time) string {
+	pct := (rate - prev.RateAUD) / prev.RateAUD * 100
+	arrow := "▲"
+	switch {
+	case pct < 0:
+		arrow = "▼"
+	case pct == 0:
+		arrow = "="
+	}
+	return tr("rate.change", formatMoney(rate), arrow, math.Abs(pct), rate-prev.RateAUD, formatAgo(now.Sub(prev.Time)))
+}
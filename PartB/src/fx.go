@@ -0,0 +1,352 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/core"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// defaultFXCacheTTL is how long the USD to AUD rate is reused
+// Fiat exchange rates move far slower than crypto prices, so this is much longer than the quote TTL
+const defaultFXCacheTTL = 10 * time.Minute
+
+// CoinGeckoFX implies the USD to AUD rate from CoinGecko's ETH price in both currencies
+type CoinGeckoFX struct {
+	url     string
+	timeout time.Duration
+}
+
+func NewCoinGeckoFX() CoinGeckoFX {
+	return CoinGeckoFX{
+		url:     core.FXURL,
+		timeout: 10 * time.Second,
+	}
+}
+
+func (c CoinGeckoFX) Name() string {
+	return "CoinGecko"
+}
+
+func (c CoinGeckoFX) FetchRate() (float64, error) {
+	return c.FetchRateContext(context.Background())
+}
+
+func (c CoinGeckoFX) FetchRateContext(ctx context.Context) (float64, error) {
+	// Get exchange rates with timeout
+	client := newHTTPClient(c.timeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+	}
+	defer resp.Body.Close()
+
+	rate, err := c.parse(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rates: %v", err)
+	}
+	return rate, nil
+}
+
+// parse reads the rate out of a response, checking it against core.FXSchema when it doesn't parse
+func (c CoinGeckoFX) parse(body io.Reader) (float64, error) {
+	return parsers.Checked(body, core.FXSchema, parsers.DecodeCoinGeckoFX)
+}
+
+// RBAFX reads the Reserve Bank of Australia's 4pm AUD/USD reference rate, the official rate rather than one implied
+// from crypto prices; it is published once per business day, so between 4pm updates it doesn't move at all
+type RBAFX struct {
+	url     string
+	timeout time.Duration
+}
+
+func NewRBAFX() RBAFX {
+	return RBAFX{
+		url:     "https://www.rba.gov.au/rss/rss-cb-exchange-rates.xml",
+		timeout: 10 * time.Second,
+	}
+}
+
+func (r RBAFX) Name() string {
+	return "RBA"
+}
+
+func (r RBAFX) FetchRate() (float64, error) {
+	return r.FetchRateContext(context.Background())
+}
+
+func (r RBAFX) FetchRateContext(ctx context.Context) (float64, error) {
+	return fetchFXRate(ctx, r.url, r.timeout, parsers.DecodeRBAFX)
+}
+
+// FrankfurterFX reads the European Central Bank's daily reference rate through the free Frankfurter API
+type FrankfurterFX struct {
+	url     string
+	timeout time.Duration
+}
+
+func NewFrankfurterFX() FrankfurterFX {
+	return FrankfurterFX{
+		url:     "https://api.frankfurter.app/latest?from=USD&to=AUD",
+		timeout: 10 * time.Second,
+	}
+}
+
+func (f FrankfurterFX) Name() string {
+	return "Frankfurter"
+}
+
+func (f FrankfurterFX) FetchRate() (float64, error) {
+	return f.FetchRateContext(context.Background())
+}
+
+func (f FrankfurterFX) FetchRateContext(ctx context.Context) (float64, error) {
+	return fetchFXRate(ctx, f.url, f.timeout, parsers.DecodeFrankfurterFX)
+}
+
+// ExchangerateHostFX reads exchangerate.host's live USD/AUD rate, which needs an access key even on the free plan
+type ExchangerateHostFX struct {
+	url     string
+	timeout time.Duration
+}
+
+func NewExchangerateHostFX(key string) ExchangerateHostFX {
+	return ExchangerateHostFX{
+		url:     "https://api.exchangerate.host/live?source=USD&currencies=AUD&access_key=" + url.QueryEscape(key),
+		timeout: 10 * time.Second,
+	}
+}
+
+func (e ExchangerateHostFX) Name() string {
+	return "exchangerate.host"
+}
+
+func (e ExchangerateHostFX) FetchRate() (float64, error) {
+	return e.FetchRateContext(context.Background())
+}
+
+func (e ExchangerateHostFX) FetchRateContext(ctx context.Context) (float64, error) {
+	return fetchFXRate(ctx, e.url, e.timeout, parsers.DecodeExchangerateHostFX)
+}
+
+// fetchFXRate is the request every plain fiat FX provider makes: one GET, decoded by its parser
+func fetchFXRate(ctx context.Context, u string, timeout time.Duration, decode func(io.Reader) (float64, error)) (float64, error) {
+	client := newHTTPClient(timeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, redactError(fmt.Errorf("failed to get exchange rates: %v", err))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, redactError(fmt.Errorf("failed to get exchange rates: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to get exchange rates: non-OK status code: %d", resp.StatusCode)
+	}
+
+	rate, err := decode(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+	return rate, nil
+}
+
+// FXEndpoint is an FX provider from the fx_endpoints section of the config: url is fetched,
+// decoded as format says, and parse gives the AUD one USD buys; see "Response formats" in the README
+type FXEndpoint struct {
+	URL    string `json:"url"`
+	Format string `json:"format"`
+	Parse  string `json:"parse"`
+}
+
+// ExprFX fetches an FXEndpoint
+type ExprFX struct {
+	name    string
+	url     string
+	parse   *Expr
+	decode  Decoder
+	policy  URLPolicy
+	timeout time.Duration
+}
+
+// newExprFX checks the endpoint's URL against policy and compiles its parse expression
+func newExprFX(name string, e FXEndpoint, policy URLPolicy) (ExprFX, error) {
+	u, err := url.Parse(e.URL)
+	if err != nil || e.URL == "" {
+		return ExprFX{}, fmt.Errorf("fx_endpoints.%s: url is required", name)
+	}
+	if err := policy.check(u); err != nil {
+		return ExprFX{}, fmt.Errorf("fx_endpoints.%s: %v", name, err)
+	}
+	expr := SourceConfig{Parse: e.Parse, Format: e.Format}.parseExpr()
+	if expr == "" {
+		return ExprFX{}, fmt.Errorf("fx_endpoints.%s: parse is required, to say where the rate is in the response", name)
+	}
+	parse, err := compileExpr(expr)
+	if err != nil {
+		return ExprFX{}, fmt.Errorf("fx_endpoints.%s.parse: %v", name, err)
+	}
+	decode, err := decoderFor(e.Format)
+	if err != nil {
+		return ExprFX{}, fmt.Errorf("fx_endpoints.%s.format: %v", name, err)
+	}
+	return ExprFX{name: name, url: e.URL, parse: parse, decode: decode, policy: policy, timeout: 10 * time.Second}, nil
+}
+
+func (e ExprFX) Name() string {
+	return e.name
+}
+
+func (e ExprFX) FetchRate() (float64, error) {
+	return e.FetchRateContext(context.Background())
+}
+
+// FetchRateContext is fetchFXRate with the policy also checked on redirects, as for configured price sources
+func (e ExprFX) FetchRateContext(ctx context.Context) (float64, error) {
+	client := newHTTPClient(e.timeout)
+	client.CheckRedirect = e.policy.checkRedirect
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url, nil)
+	if err != nil {
+		return 0, redactError(fmt.Errorf("failed to get exchange rates: %v", err))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, redactError(fmt.Errorf("failed to get exchange rates: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to get exchange rates: non-OK status code: %d", resp.StatusCode)
+	}
+	q, err := parseWithExpr(e.parse, e.decode, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+	if q.Last <= 0 {
+		return 0, fmt.Errorf("invalid exchange rate: %f", q.Last)
+	}
+	return q.Last, nil
+}
+
+// averageFX averages the rates of several FX providers, so one provider's bad day moves the fiat leg less
+// With check set, a rate further than maxDivergence percent from check's is left out as suspect;
+// the check is CoinGecko's implied rate, which comes with the quote request anyway
+type averageFX struct {
+	providers     []FXProvider
+	check         FXProvider
+	maxDivergence float64
+}
+
+func (a averageFX) Name() string {
+	names := make([]string, len(a.providers))
+	for i, p := range a.providers {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+func (a averageFX) FetchRate() (float64, error) {
+	return a.FetchRateContext(context.Background())
+}
+
+// FetchRateContext asks every provider at once; failures and outliers are warned about and left out,
+// and it only fails when none of the rates is left
+func (a averageFX) FetchRateContext(ctx context.Context) (float64, error) {
+	rates := make([]float64, len(a.providers))
+	errs := make([]error, len(a.providers))
+	var wg sync.WaitGroup
+	for i, p := range a.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rates[i], errs[i] = audeth.FetchRate(ctx, p)
+		}()
+	}
+	var reference float64
+	var refErr error
+	if a.check != nil {
+		reference, refErr = audeth.FetchRate(ctx, a.check)
+	}
+	wg.Wait()
+	if refErr != nil {
+		fmt.Fprintln(progressOut(), tr("warning.fx_provider", a.check.Name(), refErr))
+		reference = 0
+	}
+
+	var sum float64
+	var used int
+	var last error
+	for i, p := range a.providers {
+		if errs[i] != nil {
+			fmt.Fprintln(progressOut(), tr("warning.fx_provider", p.Name(), errs[i]))
+			last = errs[i]
+			continue
+		}
+		if reference > 0 && a.maxDivergence > 0 {
+			if off := math.Abs(rates[i]-reference) / reference * 100; off > a.maxDivergence {
+				fmt.Fprintln(progressOut(), tr("warning.fx_divergent", p.Name(), rates[i], off, a.check.Name(), reference))
+				last = fmt.Errorf("%s is %.2f%% from %s, more than fx_max_divergence of %.2f%%", p.Name(), off, a.check.Name(), a.maxDivergence)
+				continue
+			}
+		}
+		sum += rates[i]
+		used++
+	}
+	if used == 0 {
+		return 0, fmt.Errorf("no FX provider answered: %v", last)
+	}
+	return sum / float64(used), nil
+}
+
+// cachedFX wraps another FXProvider and reuses its rate for ttl
+// Wrapping rather than inheriting: the cache is itself an FXProvider, so callers can't tell the difference
+type cachedFX struct {
+	FXProvider
+	ttl   time.Duration
+	clock Clock
+
+	mu        sync.Mutex
+	rate      float64
+	fetchedAt time.Time
+}
+
+func newCachedFX(fx FXProvider, ttl time.Duration) *cachedFX {
+	return &cachedFX{FXProvider: fx, ttl: ttl, clock: defaultClock}
+}
+
+func (c *cachedFX) FetchRate() (float64, error) {
+	return c.FetchRateContext(context.Background())
+}
+
+func (c *cachedFX) FetchRateContext(ctx context.Context) (float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rate > 0 && c.clock.Now().Sub(c.fetchedAt) < c.ttl {
+		return c.rate, nil
+	}
+	rate, err := audeth.FetchRate(ctx, c.FXProvider)
+	if err != nil {
+		return 0, err
+	}
+	c.rate, c.fetchedAt = rate, c.clock.Now()
+	return rate, nil
+}
@@ -0,0 +1,132 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// update rewrites the golden files from the current output, to accept an intended change
+var update = flag.Bool("update", false, "rewrite testdata/golden from the current output")
+
+// goldenCase is one renderer run against the fixed dataset
+type goldenCase struct {
+	file   string
+	render func() (string, error)
+}
+
+// goldenData is a fixed day of history: fixed times, prices and one flaky source,
+// so every renderer gives byte-for-byte the same output on every run
+func goldenData() (samples []Sample, conversions []ConversionRecord, from, to time.Time) {
+	from = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	to = from.Add(24 * time.Hour)
+	rates := []float64{4980.25, 5012.5, 5034.1, 4995.75}
+	usd := []float64{3276.5, 3297.75, 3311.9, 3286.65}
+	for i, rate := range rates {
+		at := from.Add(time.Duration(i*6) * time.Hour)
+		s := Sample{Time: at, RateAUD: rate, Sources: []SourceSample{
+			{Name: "Kraken", USD: usd[i]},
+			{Name: "Coinbase", USD: usd[i] + 1.5},
+			{Name: "Bitfinex", USD: usd[i] - 0.75},
+		}}
+		if i%2 == 1 {
+			s.Sources[2] = SourceSample{Name: "Bitfinex", Error: "non-OK status code: 503"}
+		}
+		samples = append(samples, s)
+		conversions = append(conversions, ConversionRecord{Time: at.Add(time.Minute), AUD: 100 * float64(i+1), ETH: 100 * float64(i+1) / rate, RateAUD: rate})
+	}
+	return samples, conversions, from, to
+}
+
+func goldenCases() []goldenCase {
+	samples, conversions, from, to := goldenData()
+	report := func(format string) func() (string, error) {
+		return func() (string, error) {
+			s, err := summarize("daily", from, to, samples)
+			if err != nil {
+				return "", err
+			}
+			return renderSummary(s, format)
+		}
+	}
+	write := func(fn func(*bytes.Buffer) error) func() (string, error) {
+		return func() (string, error) {
+			var b bytes.Buffer
+			err := fn(&b)
+			return b.String(), err
+		}
+	}
+	return []goldenCase{
+		{"report.txt", report("text")},
+		{"report.md", report("markdown")},
+		{"report.html", report("html")},
+		{"report.json", report("json")},
+		{"rates.jsonl", write(func(b *bytes.Buffer) error { return writeSamples(b, samples, jsonlFormat) })},
+		{"rates.csv", write(func(b *bytes.Buffer) error { return writeSamples(b, samples, csvFormat) })},
+		{"conversions.csv", write(func(b *bytes.Buffer) error { return writeConversions(b, conversions, csvFormat) })},
+		{"report.xlsx", report("xlsx")},
+		{"conversions.xlsx", write(func(b *bytes.Buffer) error { return writeConversions(b, conversions, xlsxFormat) })},
+		{"change.txt", func() (string, error) {
+			return formatChange(samples[3].RateAUD, samples[0], to) + "\n", nil
+		}},
+	}
+}
+
+// TestGolden renders every case and compares it with its file in testdata/golden
+// The output is pinned to UTC, English and plain off, so it doesn't depend on where the test runs
+func TestGolden(t *testing.T) {
+	saved, savedMessages, savedPlain := displayLocation, messages, plain
+	displayLocation, messages, plain = time.UTC, catalogs[defaultLanguage], false
+	t.Cleanup(func() { displayLocation, messages, plain = saved, savedMessages, savedPlain })
+
+	for _, c := range goldenCases() {
+		t.Run(c.file, func(t *testing.T) {
+			got, err := c.render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", "golden", c.file)
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v, run go test -run Golden -update", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s\n%s", path, firstDifference(string(want), got))
+			}
+		})
+	}
+}
+
+// firstDifference shows the first line that differs, which is usually enough to spot the regression
+func firstDifference(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("      line %d\n      want: %q\n      got:  %q\n", i+1, w, g)
+		}
+	}
+	return ""
+}
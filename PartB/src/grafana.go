@@ -0,0 +1,231 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The /grafana routes follow the contract of Grafana's JSON datasource plugins (simpod-json-datasource,
+// the older grafana-simple-json-datasource, and Infinity's JSON backend): point the datasource at
+// http://host:8080/grafana and pick a metric from the list /grafana/search returns
+
+// grafanaMetrics are the series every server has; each source's quote is added as "usd.<name>"
+var grafanaMetrics = []string{"rate_aud", "spread_pct", "sources", "conversions"}
+
+// grafanaQuery is the part of a /query request the server uses
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	MaxDataPoints int `json:"maxDataPoints"`
+	Targets       []struct {
+		Target string `json:"target"`
+		Type   string `json:"type"` // "timeserie" (the default) or "table"
+	} `json:"targets"`
+}
+
+// grafanaSeries is one time series: datapoints are [value, unix milliseconds] pairs
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// grafanaColumn and grafanaTable are a table response, used for type "table" targets
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"` // "time" or "number"
+}
+
+type grafanaTable struct {
+	Type    string          `json:"type"` // always "table"
+	Columns []grafanaColumn `json:"columns"`
+	Rows    [][]any         `json:"rows"`
+}
+
+// grafanaRoutes adds the datasource routes to mux
+func (s *Server) grafanaRoutes(mux *http.ServeMux) {
+	// The datasource's "Save & test" only checks that the root answers
+	mux.HandleFunc("GET /grafana/{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("POST /grafana/search", s.handleGrafanaSearch)
+	mux.HandleFunc("POST /grafana/metrics", s.handleGrafanaMetrics)
+	mux.HandleFunc("POST /grafana/query", s.handleGrafanaQuery)
+	// There are no annotations, but the plugins ask for them on every dashboard refresh
+	mux.HandleFunc("POST /grafana/annotations", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []any{})
+	})
+}
+
+// grafanaMetricNames lists the fixed metrics and a usd.<name> metric per configured source
+func (s *Server) grafanaMetricNames() []string {
+	names := slices.Clone(grafanaMetrics)
+	for _, f := range s.converter.Fetchers() {
+		names = append(names, "usd."+f.Name())
+	}
+	return names
+}
+
+// handleGrafanaSearch answers the metric picker: every metric containing the typed text
+func (s *Server) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Target string `json:"target"`
+	}
+	// An empty body is the same as an empty search
+	json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&req)
+	names := []string{}
+	for _, name := range s.grafanaMetricNames() {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(req.Target)) {
+			names = append(names, name)
+		}
+	}
+	writeJSON(w, names)
+}
+
+// handleGrafanaMetrics is the same list in the {label, value} form newer versions of the JSON datasource ask for
+func (s *Server) handleGrafanaMetrics(w http.ResponseWriter, r *http.Request) {
+	type metric struct {
+		Label string `json:"label"`
+		Value string `json:"value"`
+	}
+	metrics := []metric{}
+	for _, name := range s.grafanaMetricNames() {
+		metrics = append(metrics, metric{name, name})
+	}
+	writeJSON(w, metrics)
+}
+
+// handleGrafanaQuery returns each target over the dashboard's time range, from the history store
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var q grafanaQuery
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&q); err != nil {
+		http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if q.Range.To.IsZero() {
+		q.Range.To = s.clock.Now()
+	}
+	if q.Range.From.IsZero() {
+		q.Range.From = q.Range.To.Add(-24 * time.Hour)
+	}
+	samples, err := s.store.Samples(q.Range.From, q.Range.To)
+	if err != nil {
+		http.Error(w, redact(err.Error()), http.StatusInternalServerError)
+		return
+	}
+	var conversions []ConversionRecord
+	results := []any{}
+	for _, t := range q.Targets {
+		if t.Target == "" {
+			continue
+		}
+		if t.Target == "conversions" && conversions == nil {
+			if conversions, err = s.store.Conversions(q.Range.From, q.Range.To); err != nil {
+				http.Error(w, redact(err.Error()), http.StatusInternalServerError)
+				return
+			}
+		}
+		points, ok := grafanaPoints(t.Target, samples, conversions)
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown metric %q, see /grafana/search", t.Target), http.StatusBadRequest)
+			return
+		}
+		if t.Type == "table" {
+			results = append(results, grafanaAsTable(t.Target, points, conversions))
+			continue
+		}
+		results = append(results, grafanaSeries{Target: t.Target, Datapoints: downsample(points, q.MaxDataPoints)})
+	}
+	writeJSON(w, results)
+}
+
+// grafanaPoints is the series for one metric, oldest first; false means there is no such metric
+func grafanaPoints(target string, samples []Sample, conversions []ConversionRecord) ([][2]float64, bool) {
+	points := [][2]float64{}
+	ms := func(t time.Time) float64 { return float64(t.UnixMilli()) }
+	switch {
+	case target == "conversions":
+		for _, c := range conversions {
+			points = append(points, [2]float64{c.AUD, ms(c.Time)})
+		}
+	case target == "rate_aud":
+		for _, s := range samples {
+			points = append(points, [2]float64{s.RateAUD, ms(s.Time)})
+		}
+	case target == "spread_pct":
+		for _, s := range samples {
+			if spread, ok := sampleSpread(s); ok {
+				points = append(points, [2]float64{spread, ms(s.Time)})
+			}
+		}
+	case target == "sources":
+		for _, s := range samples {
+			ok := 0
+			for _, src := range s.Sources {
+				if src.Error == "" {
+					ok++
+				}
+			}
+			points = append(points, [2]float64{float64(ok), ms(s.Time)})
+		}
+	case strings.HasPrefix(target, "usd."):
+		name := strings.TrimPrefix(target, "usd.")
+		for _, s := range samples {
+			for _, src := range s.Sources {
+				if src.Name == name && src.Error == "" {
+					points = append(points, [2]float64{src.USD, ms(s.Time)})
+				}
+			}
+		}
+	default:
+		return nil, false
+	}
+	return points, true
+}
+
+// grafanaAsTable turns a series into a time and value table; conversions get all of their columns instead
+func grafanaAsTable(target string, points [][2]float64, conversions []ConversionRecord) grafanaTable {
+	if target == "conversions" {
+		t := grafanaTable{Type: "table", Columns: []grafanaColumn{
+			{"Time", "time"}, {"AUD", "number"}, {"ETH", "number"}, {"ETH/AUD", "number"},
+		}, Rows: [][]any{}}
+		for _, c := range conversions {
+			t.Rows = append(t.Rows, []any{c.Time.UnixMilli(), c.AUD, c.ETH, c.RateAUD})
+		}
+		return t
+	}
+	t := grafanaTable{Type: "table", Columns: []grafanaColumn{{"Time", "time"}, {target, "number"}}, Rows: [][]any{}}
+	for _, p := range points {
+		t.Rows = append(t.Rows, []any{int64(p[1]), p[0]})
+	}
+	return t
+}
+
+// downsample averages runs of neighbouring points so at most max are returned, the way Grafana expects
+// when the range holds more samples than the panel is pixels wide; max 0 returns every point
+func downsample(points [][2]float64, max int) [][2]float64 {
+	if max <= 0 || len(points) <= max {
+		return points
+	}
+	size := (len(points) + max - 1) / max
+	out := make([][2]float64, 0, max)
+	for run := range slices.Chunk(points, size) {
+		var value float64
+		for _, p := range run {
+			value += p[0]
+		}
+		// Each run is stamped with its last point's time, so the newest value lines up with the end of the range
+		out = append(out, [2]float64{value / float64(len(run)), run[len(run)-1][1]})
+	}
+	return out
+}
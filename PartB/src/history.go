@@ -0,0 +1,180 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// sampleSpread is the gap between the highest and lowest source quote as a percentage of their mean, as in the reports
+// false means fewer than two sources answered
+func sampleSpread(s Sample) (float64, bool) {
+	var lo, hi, sum float64
+	ok := 0
+	for _, src := range s.Sources {
+		if src.Error != "" {
+			continue
+		}
+		if ok == 0 || src.USD < lo {
+			lo = src.USD
+		}
+		if ok == 0 || src.USD > hi {
+			hi = src.USD
+		}
+		sum += src.USD
+		ok++
+	}
+	if ok < 2 || sum <= 0 {
+		return 0, false
+	}
+	return (hi - lo) / (sum / float64(ok)) * 100, true
+}
+
+// FileStore keeps history as JSON Lines files in a directory
+// history.jsonl holds the samples and conversions.jsonl the user's conversions
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store writing into dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+func (s *FileStore) AddSample(sample Sample) error {
+	return appendJSONLine(filepath.Join(s.dir, "history.jsonl"), sample)
+}
+
+func (s *FileStore) Samples(from, to time.Time) ([]Sample, error) {
+	return readJSONLines(filepath.Join(s.dir, "history.jsonl"), func(sample Sample) bool {
+		return !sample.Time.Before(from) && sample.Time.Before(to)
+	})
+}
+
+func (s *FileStore) AddConversion(c ConversionRecord) error {
+	return appendJSONLine(filepath.Join(s.dir, "conversions.jsonl"), c)
+}
+
+func (s *FileStore) Conversions(from, to time.Time) ([]ConversionRecord, error) {
+	return readJSONLines(filepath.Join(s.dir, "conversions.jsonl"), func(c ConversionRecord) bool {
+		return !c.Time.Before(from) && c.Time.Before(to)
+	})
+}
+
+// ReplaceSamples rewrites history.jsonl with the samples in [from, to) swapped for replacement
+// The new file is written beside the old one and renamed over it, so a crash can't lose history
+func (s *FileStore) ReplaceSamples(from, to time.Time, replacement []Sample) error {
+	path := filepath.Join(s.dir, "history.jsonl")
+	kept, err := readJSONLines(path, func(sample Sample) bool {
+		return sample.Time.Before(from) || !sample.Time.Before(to)
+	})
+	if err != nil {
+		return err
+	}
+	all := append(kept, replacement...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Time.Before(all[j].Time) })
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("creating data dir failed: %v", err)
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("rewriting history failed: %v", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, sample := range all {
+		if err := enc.Encode(sample); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Close is a no-op, files are opened and closed per call
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// appendJSONLine writes one value as a JSON line, creating the file if needed
+// Appending keeps recording cheap, each run only touches the end of the file
+func appendJSONLine(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating data dir failed: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening %s failed: %v", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("writing %s failed: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// readJSONLines decodes every line of a JSON Lines file and keeps those accepted by keep
+// Generics let samples and conversions share one reader; a missing file yields no values
+func readJSONLines[T any](path string, keep func(T) bool) ([]T, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening %s failed: %v", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	out, err := decodeJSONLines(f, keep)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s failed: %v", filepath.Base(path), err)
+	}
+	return out, nil
+}
+
+// decodeJSONLines reads one JSON value per line from r, keeping those accepted by keep
+// A nil keep accepts everything; blank lines are skipped
+func decodeJSONLines[T any](r io.Reader, keep func(T) bool) ([]T, error) {
+	var out []T
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var v T
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if keep == nil || keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out, scanner.Err()
+}
@@ -0,0 +1,78 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// runHistory implements the "history" command and its subcommands
+func runHistory(args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: history prune|export|import [flags]")
+	}
+	switch args[0] {
+	case "prune":
+		return runHistoryPrune(args[1:])
+	case "export":
+		return runHistoryExport(args[1:])
+	case "import":
+		return runHistoryImport(args[1:])
+	default:
+		return usageErrorf("unknown history command: %s", args[0])
+	}
+}
+
+// historyPruneFlags are the flags of "history prune"
+type historyPruneFlags struct {
+	raw   string
+	daily string
+}
+
+func (f *historyPruneFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("history prune")
+	fs.StringVar(&f.raw, "raw", "", "keep raw samples for this long (default from config, else 90d)")
+	fs.StringVar(&f.daily, "daily", "", "keep daily aggregates for this long (default from config, else forever)")
+	return fs
+}
+
+// runHistoryPrune compacts old samples into daily aggregates right away
+func runHistoryPrune(args []string) error {
+	var flags historyPruneFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if flags.raw != "" {
+		cfg.Retention.Raw = flags.raw
+	}
+	if flags.daily != "" {
+		cfg.Retention.Daily = flags.daily
+	}
+	raw, daily, err := retentionPeriods(cfg.Retention)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	before, after, err := compactHistory(store, defaultClock.Now(), raw, daily)
+	if err != nil {
+		return err
+	}
+	fmt.Println(tr("history.compacted", before, raw.Hours()/24, after))
+	return nil
+}
@@ -0,0 +1,493 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CSV headers for exported history
+// Rates are written one row per source, so a sample with five sources spans five rows sharing a time
+var (
+	rateCSVHeader       = []string{"time", "rate_aud", "aggregated", "high", "low", "source", "usd", "error"}
+	conversionCSVHeader = []string{"time", "aud", "eth", "rate_aud"}
+)
+
+// exportFormat is a history file format: "jsonl", "csv" with comma as the field separator, or "influx"
+// TSV is CSV separated by tabs, so it is the same format with a different comma
+// Influx line protocol, see influx.go, and xlsx workbooks, see xlsxexport.go, can only be exported
+type exportFormat struct {
+	name  string
+	comma rune
+}
+
+var (
+	jsonlFormat  = exportFormat{name: "jsonl"}
+	csvFormat    = exportFormat{name: "csv", comma: ','}
+	influxFormat = exportFormat{name: "influx"}
+	xlsxFormat   = exportFormat{name: "xlsx"}
+)
+
+// parseExportFormat reads the -format and -delimiter flags; the delimiter only applies to csv
+func parseExportFormat(name, delimiter string) (exportFormat, error) {
+	var f exportFormat
+	switch name {
+	case "jsonl":
+		f = jsonlFormat
+	case "csv":
+		f = csvFormat
+	case "tsv":
+		f = exportFormat{name: "csv", comma: '\t'}
+	case "influx":
+		f = influxFormat
+	case "xlsx":
+		f = xlsxFormat
+	default:
+		return f, fmt.Errorf("unknown format: %s (use jsonl, csv, tsv, influx or xlsx)", name)
+	}
+	if delimiter != "" {
+		if name != "csv" {
+			return f, fmt.Errorf("-delimiter only applies to csv")
+		}
+		comma, err := parseDelimiter(delimiter)
+		if err != nil {
+			return f, err
+		}
+		f.comma = comma
+	}
+	return f, nil
+}
+
+// parseDelimiter reads a field separator: one character, or "tab"
+// encoding/csv refuses quotes, newlines and the like itself, so those surface as its error
+func parseDelimiter(s string) (rune, error) {
+	if s == "tab" || s == `\t` {
+		return '\t', nil
+	}
+	r := []rune(s)
+	if len(r) != 1 {
+		return 0, fmt.Errorf("the delimiter must be a single character or \"tab\", not %q", s)
+	}
+	if r[0] == '"' || r[0] == '\r' || r[0] == '\n' || r[0] == 0xFFFD {
+		return 0, fmt.Errorf("%q can't be used as a delimiter", s)
+	}
+	return r[0], nil
+}
+
+// parseDateFlag accepts YYYY-MM-DD or a full RFC 3339 timestamp
+func parseDateFlag(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// historyRange reads -from/-to style flag values, defaulting to all of history
+func historyRange(fromStr, toStr string) (time.Time, time.Time, error) {
+	from, to := time.Time{}, defaultClock.Now().Add(time.Minute)
+	var err error
+	if fromStr != "" {
+		if from, err = parseDateFlag(fromStr); err != nil {
+			return from, to, fmt.Errorf("invalid -from: %v", err)
+		}
+	}
+	if toStr != "" {
+		if to, err = parseDateFlag(toStr); err != nil {
+			return from, to, fmt.Errorf("invalid -to: %v", err)
+		}
+	}
+	return from, to, nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// writeSamples encodes samples as jsonl or csv
+func writeSamples(w io.Writer, samples []Sample, format exportFormat) error {
+	switch format.name {
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		for _, s := range samples {
+			if err := enc.Encode(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Comma = format.comma
+		cw.Write(rateCSVHeader)
+		for _, s := range samples {
+			base := []string{s.Time.Format(time.RFC3339Nano), formatFloat(s.RateAUD),
+				strconv.Itoa(s.Aggregated), formatFloat(s.High), formatFloat(s.Low)}
+			if len(s.Sources) == 0 {
+				cw.Write(append(base, "", "", ""))
+			}
+			for _, src := range s.Sources {
+				cw.Write(append(base[:5:5], src.Name, formatFloat(src.USD), src.Error))
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "influx":
+		for _, s := range samples {
+			if err := writeInfluxPoints(w, samplePoints(s)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "xlsx":
+		return sampleWorkbook(samples).Write(w)
+	default:
+		return fmt.Errorf("unknown format: %s (use jsonl, csv, tsv, influx or xlsx)", format.name)
+	}
+}
+
+// writeConversions encodes conversions as jsonl or csv
+func writeConversions(w io.Writer, conversions []ConversionRecord, format exportFormat) error {
+	switch format.name {
+	case "jsonl":
+		enc := json.NewEncoder(w)
+		for _, c := range conversions {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Comma = format.comma
+		cw.Write(conversionCSVHeader)
+		for _, c := range conversions {
+			cw.Write([]string{c.Time.Format(time.RFC3339Nano), formatFloat(c.AUD), formatFloat(c.ETH), formatFloat(c.RateAUD)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case "influx":
+		for _, c := range conversions {
+			if err := writeInfluxPoints(w, []influxPoint{conversionPoint(c)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "xlsx":
+		return conversionWorkbook(conversions).Write(w)
+	default:
+		return fmt.Errorf("unknown format: %s (use jsonl, csv, tsv, influx or xlsx)", format.name)
+	}
+}
+
+// readSamplesFrom decodes samples written by writeSamples
+// CSV rows sharing a time are folded back into one sample
+func readSamplesFrom(r io.Reader, format exportFormat) ([]Sample, error) {
+	switch format.name {
+	case "jsonl":
+		return decodeJSONLines[Sample](r, nil)
+	case "csv":
+		rows, err := readCSV(r, rateCSVHeader, format.comma)
+		if err != nil {
+			return nil, err
+		}
+		var samples []Sample
+		for i, row := range rows {
+			t, err := time.Parse(time.RFC3339Nano, row[0])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", i+2, err)
+			}
+			nums, err := parseFloats(row[1], row[2], row[3], row[4], row[6])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", i+2, err)
+			}
+			if len(samples) == 0 || !samples[len(samples)-1].Time.Equal(t) {
+				samples = append(samples, Sample{Time: t, RateAUD: nums[0], Aggregated: int(nums[1]), High: nums[2], Low: nums[3]})
+			}
+			if row[5] != "" {
+				cur := &samples[len(samples)-1]
+				cur.Sources = append(cur.Sources, SourceSample{Name: row[5], USD: nums[4], Error: row[7]})
+			}
+		}
+		return samples, nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s (use jsonl, csv or tsv)", format.name)
+	}
+}
+
+// readConversionsFrom decodes conversions written by writeConversions
+func readConversionsFrom(r io.Reader, format exportFormat) ([]ConversionRecord, error) {
+	switch format.name {
+	case "jsonl":
+		return decodeJSONLines[ConversionRecord](r, nil)
+	case "csv":
+		rows, err := readCSV(r, conversionCSVHeader, format.comma)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]ConversionRecord, 0, len(rows))
+		for i, row := range rows {
+			t, err := time.Parse(time.RFC3339Nano, row[0])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", i+2, err)
+			}
+			nums, err := parseFloats(row[1], row[2], row[3])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %v", i+2, err)
+			}
+			out = append(out, ConversionRecord{Time: t, AUD: nums[0], ETH: nums[1], RateAUD: nums[2]})
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s (use jsonl, csv or tsv)", format.name)
+	}
+}
+
+// readCSV reads all rows and checks the header matches what export writes
+func readCSV(r io.Reader, header []string, comma rune) ([][]string, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = len(header)
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	for i, name := range header {
+		if rows[0][i] != name {
+			return nil, fmt.Errorf("unexpected CSV header, want %v", header)
+		}
+	}
+	return rows[1:], nil
+}
+
+// parseFloats parses each value, treating blanks as zero
+func parseFloats(values ...string) ([]float64, error) {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// historyExportFlags are the flags of "history export"
+type historyExportFlags struct {
+	kind       string
+	formatName string
+	delimiter  string
+	out        string
+	push       bool
+	fromStr    string
+	toStr      string
+}
+
+func (f *historyExportFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("history export")
+	fs.StringVar(&f.kind, "kind", "rates", "what to export: rates or conversions")
+	fs.StringVar(&f.formatName, "format", "jsonl", "output format: jsonl, csv, tsv, influx or xlsx")
+	fs.StringVar(&f.delimiter, "delimiter", "", "field separator for csv, e.g. \";\" for spreadsheets set to a comma decimal (default \",\")")
+	fs.StringVar(&f.out, "o", "", "output file (default stdout)")
+	fs.BoolVar(&f.push, "push", false, "with -format influx, send to influx.url from config.json instead of writing a file")
+	fs.StringVar(&f.fromStr, "from", "", "start date YYYY-MM-DD (default all history)")
+	fs.StringVar(&f.toStr, "to", "", "end date YYYY-MM-DD (default now)")
+	return fs
+}
+
+// runHistoryExport writes recorded rates or conversions to a file or stdout
+func runHistoryExport(args []string) error {
+	var flags historyExportFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	from, to, err := historyRange(flags.fromStr, flags.toStr)
+	if err != nil {
+		return UsageError{err}
+	}
+	format, err := parseExportFormat(flags.formatName, flags.delimiter)
+	if err != nil {
+		return UsageError{err}
+	}
+	if flags.push && (format.name != "influx" || flags.out != "") {
+		return usageErrorf("-push needs -format influx and no -o")
+	}
+	if flags.kind != "rates" && flags.kind != "conversions" {
+		return usageErrorf("unknown kind: %s (use rates or conversions)", flags.kind)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if flags.push {
+		return pushHistory(store, cfg.Influx, flags.kind, from, to)
+	}
+
+	w := io.Writer(os.Stdout)
+	if flags.out != "" {
+		f, err := os.Create(flags.out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	} else if format == xlsxFormat {
+		if err := checkBinaryOut(os.Stdout); err != nil {
+			return err
+		}
+	}
+	bw := bufio.NewWriter(w)
+
+	switch flags.kind {
+	case "rates":
+		samples, err := store.Samples(from, to)
+		if err != nil {
+			return err
+		}
+		if err := writeSamples(bw, samples, format); err != nil {
+			return err
+		}
+	case "conversions":
+		conversions, err := store.Conversions(from, to)
+		if err != nil {
+			return err
+		}
+		if err := writeConversions(bw, conversions, format); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// historyImportFlags are the flags of "history import"
+type historyImportFlags struct {
+	kind       string
+	formatName string
+	delimiter  string
+}
+
+func (f *historyImportFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("history import")
+	fs.StringVar(&f.kind, "kind", "rates", "what to import: rates or conversions")
+	fs.StringVar(&f.formatName, "format", "jsonl", "input format: jsonl, csv or tsv")
+	fs.StringVar(&f.delimiter, "delimiter", "", "field separator for csv (default \",\")")
+	return fs
+}
+
+// runHistoryImport loads an exported file into the configured store
+// Entries whose timestamp is already in the store are skipped, so importing twice is harmless
+func runHistoryImport(args []string) error {
+	var flags historyImportFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageErrorf("usage: history import [-kind rates|conversions] [-format jsonl|csv|tsv] [-delimiter C] FILE")
+	}
+	format, err := parseExportFormat(flags.formatName, flags.delimiter)
+	if err != nil {
+		return UsageError{err}
+	}
+	if format == influxFormat || format == xlsxFormat {
+		return usageErrorf("%s can only be exported", flags.formatName)
+	}
+	if flags.kind != "rates" && flags.kind != "conversions" {
+		return usageErrorf("unknown kind: %s (use rates or conversions)", flags.kind)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	added, skipped := 0, 0
+	switch flags.kind {
+	case "rates":
+		samples, err := readSamplesFrom(f, format)
+		if err != nil {
+			return err
+		}
+		existing, err := store.Samples(time.Time{}, defaultClock.Now().AddDate(100, 0, 0))
+		if err != nil {
+			return err
+		}
+		seen := make(map[int64]bool, len(existing))
+		for _, s := range existing {
+			seen[s.Time.UnixMilli()] = true
+		}
+		for _, s := range samples {
+			if seen[s.Time.UnixMilli()] {
+				skipped++
+				continue
+			}
+			if err := store.AddSample(s); err != nil {
+				return err
+			}
+			seen[s.Time.UnixMilli()] = true
+			added++
+		}
+	case "conversions":
+		conversions, err := readConversionsFrom(f, format)
+		if err != nil {
+			return err
+		}
+		existing, err := store.Conversions(time.Time{}, defaultClock.Now().AddDate(100, 0, 0))
+		if err != nil {
+			return err
+		}
+		seen := make(map[int64]bool, len(existing))
+		for _, c := range existing {
+			seen[c.Time.UnixMilli()] = true
+		}
+		for _, c := range conversions {
+			if seen[c.Time.UnixMilli()] {
+				skipped++
+				continue
+			}
+			if err := store.AddConversion(c); err != nil {
+				return err
+			}
+			seen[c.Time.UnixMilli()] = true
+			added++
+		}
+	}
+
+	fmt.Println(tr("history.imported", added, flags.kind, skipped))
+	return nil
+}
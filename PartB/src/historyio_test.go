@@ -0,0 +1,118 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// exportedSamples has the fields every format carries: a sample with sources, an aggregate and one without sources
+func exportedSamples() []Sample {
+	at := time.Date(2026, 3, 2, 9, 0, 0, 123456789, time.UTC)
+	return []Sample{
+		{Time: at, RateAUD: 5012.25, Sources: []SourceSample{
+			{Name: "Kraken", USD: 3200.5},
+			{Name: "Binance", Error: "non-OK status code: 429, retry later"},
+		}},
+		{Time: at.Add(time.Hour), RateAUD: 5000, Aggregated: 24, High: 5100, Low: 4900.125,
+			Sources: []SourceSample{{Name: "Kraken", USD: 3190}}},
+		{Time: at.Add(2 * time.Hour), RateAUD: 4999.5},
+	}
+}
+
+func TestSampleExportRoundTrip(t *testing.T) {
+	formats := map[string]exportFormat{
+		"jsonl":   jsonlFormat,
+		"csv":     csvFormat,
+		"tsv":     {name: "csv", comma: '\t'},
+		"csv ';'": {name: "csv", comma: ';'},
+	}
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeSamples(&buf, exportedSamples(), format); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readSamplesFrom(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, exportedSamples()) {
+				t.Fatalf("read back\n%+v\nwant\n%+v", got, exportedSamples())
+			}
+		})
+	}
+
+	// JSON Lines is the lossless format, it keeps what CSV has no columns for
+	full := exportedSamples()[:1]
+	full[0].FXRate, full[0].Confidence, full[0].Sources[0].AUD = 1.566, 0.93, 5012.25
+	var buf bytes.Buffer
+	if err := writeSamples(&buf, full, jsonlFormat); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readSamplesFrom(&buf, jsonlFormat); err != nil || !reflect.DeepEqual(got, full) {
+		t.Fatalf("jsonl read back %+v, %v", got, err)
+	}
+}
+
+func TestConversionExportRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+	conversions := []ConversionRecord{
+		{Time: at, AUD: 100, ETH: 0.019951, RateAUD: 5012.25},
+		{Time: at.Add(time.Minute), AUD: 0.01, ETH: 0.000002, RateAUD: 5000},
+	}
+	for _, format := range []exportFormat{jsonlFormat, csvFormat, {name: "csv", comma: '\t'}} {
+		var buf bytes.Buffer
+		if err := writeConversions(&buf, conversions, format); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readConversionsFrom(&buf, format)
+		if err != nil || !reflect.DeepEqual(got, conversions) {
+			t.Fatalf("%s read back %+v, %v", format.name, got, err)
+		}
+	}
+}
+
+func TestHistoryImportSkipsWhatIsRecorded(t *testing.T) {
+	from := t.TempDir()
+	t.Setenv("AUDETH_HOME", from)
+	for _, s := range exportedSamples() {
+		if err := NewFileStore(from).AddSample(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(t.TempDir(), "rates.csv")
+	if err := runHistoryExport([]string{"-format", "csv", "-o", file}); err != nil {
+		t.Fatal(err)
+	}
+
+	to := t.TempDir()
+	t.Setenv("AUDETH_HOME", to)
+	// The second import finds every sample already recorded and adds nothing
+	for range 2 {
+		if err := runHistoryImport([]string{"-format", "csv", file}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := NewFileStore(to).Samples(time.Time{}, time.Now().AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, exportedSamples()) {
+		t.Fatalf("imported\n%+v\nwant\n%+v", got, exportedSamples())
+	}
+}
+
+func TestReadCSVChecksTheHeader(t *testing.T) {
+	data := "when,aud,eth,rate\n2026-03-02T09:30:00Z,100,0.02,5000\n"
+	if _, err := readConversionsFrom(bytes.NewBufferString(data), csvFormat); err == nil {
+		t.Fatal("a CSV file with another header was read")
+	}
+}
@@ -0,0 +1,231 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HMACConfig signs a source's requests, for endpoints that only answer authenticated clients
+// Key and Secret are secret references; Scheme picks a preset, or "custom" to describe the signature with the other fields
+type HMACConfig struct {
+	Scheme string `json:"scheme"` // "coinbase", "binance" or "custom"
+	Key    string `json:"key"`
+	Secret string `json:"secret"`
+
+	// Only used by the custom scheme
+	KeyHeader       string `json:"key_header"`       // header carrying the key, e.g. "X-API-Key"
+	TimestampHeader string `json:"timestamp_header"` // header carrying the timestamp, e.g. "X-Timestamp"
+	SignatureHeader string `json:"signature_header"` // header carrying the signature, e.g. "X-Signature"
+	Message         string `json:"message"`          // what is signed, default "{timestamp}{method}{path}{body}"
+	Hash            string `json:"hash"`             // "sha256" (default) or "sha512"
+	Encoding        string `json:"encoding"`         // "hex" (default) or "base64"
+	Timestamp       string `json:"timestamp"`        // "s" (default) or "ms" since the Unix epoch
+	SecretEncoding  string `json:"secret_encoding"`  // "raw" (default) or "base64", for secrets handed out base64 encoded
+}
+
+// hmacScheme is one way of signing a request
+// Most exchanges differ only in the header names, what goes into the message and how the result is encoded,
+// so every preset is a value of the same struct instead of its own code
+type hmacScheme struct {
+	keyHeader, timestampHeader, signatureHeader string
+	// queryParams puts the timestamp and signature into the query string instead of headers, as Binance does
+	queryParams bool
+	message     string
+	hash        func() hash.Hash
+	encode      func([]byte) string
+	millis      bool
+}
+
+var hmacSchemes = map[string]hmacScheme{
+	"coinbase": {
+		keyHeader: "CB-ACCESS-KEY", timestampHeader: "CB-ACCESS-TIMESTAMP", signatureHeader: "CB-ACCESS-SIGN",
+		message: "{timestamp}{method}{path}{body}", hash: sha256.New, encode: hex.EncodeToString,
+	},
+	"binance": {
+		keyHeader: "X-MBX-APIKEY", queryParams: true,
+		message: "{query}", hash: sha256.New, encode: hex.EncodeToString, millis: true,
+	},
+}
+
+// HMACSigner adds a timestamp and an HMAC signature to requests
+type HMACSigner struct {
+	scheme hmacScheme
+	key    string
+	secret []byte
+	now    func() time.Time
+}
+
+// NewHMACSigner resolves the key and secret of cfg and builds its scheme
+// The timestamp comes from the real clock even under --now, the exchange would reject a skewed one
+func NewHMACSigner(cfg HMACConfig) (*HMACSigner, error) {
+	scheme, err := cfg.scheme()
+	if err != nil {
+		return nil, err
+	}
+	key, err := resolveSecret(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("hmac.key: %v", err)
+	}
+	secret, err := resolveSecret(cfg.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("hmac.secret: %v", err)
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("hmac.secret is empty")
+	}
+	raw := []byte(secret)
+	switch cfg.SecretEncoding {
+	case "", "raw":
+	case "base64":
+		if raw, err = base64.StdEncoding.DecodeString(secret); err != nil {
+			return nil, fmt.Errorf("hmac.secret is not valid base64")
+		}
+	default:
+		return nil, fmt.Errorf("hmac.secret_encoding: unknown encoding %q (use raw or base64)", cfg.SecretEncoding)
+	}
+	return &HMACSigner{scheme: scheme, key: key, secret: raw, now: time.Now}, nil
+}
+
+// scheme returns the preset named by cfg.Scheme, or builds the custom one from the other fields
+func (cfg HMACConfig) scheme() (hmacScheme, error) {
+	if cfg.Scheme != "custom" {
+		s, ok := hmacSchemes[cfg.Scheme]
+		if !ok {
+			return s, fmt.Errorf("hmac.scheme: unknown scheme %q (use coinbase, binance or custom)", cfg.Scheme)
+		}
+		return s, nil
+	}
+	s := hmacScheme{
+		keyHeader: cfg.KeyHeader, timestampHeader: cfg.TimestampHeader, signatureHeader: cfg.SignatureHeader,
+		message: cfg.Message, hash: sha256.New, encode: hex.EncodeToString,
+	}
+	if s.signatureHeader == "" {
+		return s, fmt.Errorf("hmac.signature_header is required for the custom scheme")
+	}
+	if s.message == "" {
+		s.message = "{timestamp}{method}{path}{body}"
+	}
+	switch cfg.Hash {
+	case "", "sha256":
+	case "sha512":
+		s.hash = sha512.New
+	default:
+		return s, fmt.Errorf("hmac.hash: unknown hash %q (use sha256 or sha512)", cfg.Hash)
+	}
+	switch cfg.Encoding {
+	case "", "hex":
+	case "base64":
+		s.encode = base64.StdEncoding.EncodeToString
+	default:
+		return s, fmt.Errorf("hmac.encoding: unknown encoding %q (use hex or base64)", cfg.Encoding)
+	}
+	switch cfg.Timestamp {
+	case "", "s":
+	case "ms":
+		s.millis = true
+	default:
+		return s, fmt.Errorf("hmac.timestamp: unknown unit %q (use s or ms)", cfg.Timestamp)
+	}
+	return s, nil
+}
+
+// Sign adds the key, timestamp and signature to req
+// The body, if any, is read to be signed and then put back so it can still be sent
+func (s *HMACSigner) Sign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return fmt.Errorf("reading the request body to sign failed: %v", err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	now := s.now()
+	ts := strconv.FormatInt(now.Unix(), 10)
+	if s.scheme.millis {
+		ts = strconv.FormatInt(now.UnixMilli(), 10)
+	}
+	if s.scheme.queryParams {
+		q := req.URL.Query()
+		q.Set(timestampParam, ts)
+		q.Del(signatureParam)
+		req.URL.RawQuery = q.Encode()
+	}
+
+	path := req.URL.EscapedPath()
+	if req.URL.RawQuery != "" && !s.scheme.queryParams {
+		path += "?" + req.URL.RawQuery
+	}
+	msg := strings.NewReplacer(
+		"{timestamp}", ts,
+		"{method}", req.Method,
+		"{path}", path,
+		"{query}", req.URL.RawQuery,
+		"{body}", string(body),
+	).Replace(s.scheme.message)
+
+	mac := hmac.New(s.scheme.hash, s.secret)
+	mac.Write([]byte(msg))
+	sig := s.scheme.encode(mac.Sum(nil))
+
+	if s.scheme.keyHeader != "" {
+		req.Header.Set(s.scheme.keyHeader, s.key)
+	}
+	if s.scheme.queryParams {
+		req.URL.RawQuery += "&" + signatureParam + "=" + sig
+		return nil
+	}
+	if s.scheme.timestampHeader != "" {
+		req.Header.Set(s.scheme.timestampHeader, ts)
+	}
+	req.Header.Set(s.scheme.signatureHeader, sig)
+	return nil
+}
+
+// signingTransport signs each request before handing it on
+// It sits outside the shared transport, so --record, --api-base and the outbound limit all see the signed request
+// timestampParam and signatureParam are the query parameters Sign adds for a scheme with queryParams
+const (
+	timestampParam = "timestamp"
+	signatureParam = "signature"
+)
+
+// signedQueryKey is set in the context of a request the signer added timestampParam and signatureParam to,
+// so a recording can leave out what changes on every request without touching a query's own parameters
+type signedQueryKey struct{}
+
+type signingTransport struct {
+	signer *HMACSigner
+	next   http.RoundTripper
+}
+
+func (t signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not change the caller's request, so the signature goes on a copy
+	out := req.Clone(req.Context())
+	if err := t.signer.Sign(out); err != nil {
+		return nil, err
+	}
+	if t.signer.scheme.queryParams {
+		out = out.WithContext(context.WithValue(out.Context(), signedQueryKey{}, true))
+	}
+	return t.next.RoundTrip(out)
+}
@@ -0,0 +1,104 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHMACSignatures(t *testing.T) {
+	// 1772442000 seconds since the epoch, the signatures below are HMACs of each message under it
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		cfg     HMACConfig
+		method  string
+		url     string
+		body    string
+		headers map[string]string
+		query   string
+	}{
+		{
+			name: "coinbase", cfg: HMACConfig{Scheme: "coinbase"},
+			method: "GET", url: "https://api.coinbase.com/api/v3/brokerage/products?limit=1",
+			headers: map[string]string{
+				"CB-ACCESS-KEY":       "test-hmac-key",
+				"CB-ACCESS-TIMESTAMP": "1772442000",
+				"CB-ACCESS-SIGN":      "7598e377d8ab4beec72340433a783c4563f142ac752a3dc99fa2114406431285",
+			},
+			query: "limit=1",
+		},
+		{
+			name: "binance", cfg: HMACConfig{Scheme: "binance"},
+			method: "GET", url: "https://api.binance.com/api/v3/account?symbol=ETHUSDT",
+			headers: map[string]string{"X-MBX-APIKEY": "test-hmac-key"},
+			query:   "symbol=ETHUSDT&timestamp=1772442000000&signature=efcb640742b12e804f1968708cdef7513ff3a34853ee2501b122aa40501f1025",
+		},
+		{
+			name: "custom",
+			cfg: HMACConfig{
+				Scheme: "custom", KeyHeader: "X-API-Key", TimestampHeader: "X-Timestamp", SignatureHeader: "X-Signature",
+				Hash: "sha512", Encoding: "base64", Timestamp: "ms",
+			},
+			method: "POST", url: "https://quotes.example.com/v1/quote", body: `{"pair":"ETH/AUD"}`,
+			headers: map[string]string{
+				"X-API-Key":   "test-hmac-key",
+				"X-Timestamp": "1772442000000",
+				"X-Signature": "yTNjHXzGvRxfvGbjLj/3ea6zUe1NuACqWX8sqDcs9ZtLFb1T1FYVUV0m3Lk2HNwkTjkKcEgwkPor27oZJY0FCw==",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Key, tt.cfg.Secret = "test-hmac-key", "test-hmac-secret"
+			signer, err := NewHMACSigner(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer.now = func() time.Time { return now }
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := signer.Sign(req); err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.headers {
+				if got := req.Header.Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if req.URL.RawQuery != tt.query {
+				t.Errorf("query = %q, want %q", req.URL.RawQuery, tt.query)
+			}
+			// The body was read to be signed, it still has to be sent
+			if body, _ := io.ReadAll(req.Body); string(body) != tt.body {
+				t.Errorf("body after signing = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestHMACConfigErrors(t *testing.T) {
+	tests := []struct {
+		cfg  HMACConfig
+		want string
+	}{
+		{HMACConfig{Scheme: "kraken", Secret: "s"}, "unknown scheme"},
+		{HMACConfig{Scheme: "custom", Secret: "s"}, "signature_header is required"},
+		{HMACConfig{Scheme: "coinbase"}, "hmac.secret is empty"},
+		{HMACConfig{Scheme: "coinbase", Secret: "not base64!", SecretEncoding: "base64"}, "not valid base64"},
+	}
+	for _, tt := range tests {
+		if _, err := NewHMACSigner(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewHMACSigner(%+v) = %v, want an error with %q", tt.cfg, err, tt.want)
+		}
+	}
+}
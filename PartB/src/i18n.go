@@ -0,0 +1,104 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// The message catalogs, one JSON file per language mapping a message key to its fmt format
+// Adding a language is adding a file; the tests check it has every key with the same verbs as English
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// defaultLanguage is used when neither --lang nor the locale picks a known one, and fills any gaps
+const defaultLanguage = "en"
+
+// catalogs holds every embedded language, messages the one in use
+// Golden tests pin messages to English and plain off, like displayLocation to UTC
+var (
+	catalogs = loadCatalogs()
+	messages = catalogs[defaultLanguage]
+)
+
+// loadCatalogs reads the embedded catalogs; a broken file is a build mistake, so it panics like template.Must
+func loadCatalogs() map[string]map[string]string {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	all := make(map[string]map[string]string)
+	for _, f := range files {
+		data, err := localeFiles.ReadFile("locales/" + f.Name())
+		if err != nil {
+			panic(err)
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("locales/%s: %v", f.Name(), err))
+		}
+		all[strings.TrimSuffix(f.Name(), path.Ext(f.Name()))] = catalog
+	}
+	return all
+}
+
+// languages lists the available languages, sorted
+func languages() []string {
+	return slices.Sorted(maps.Keys(catalogs))
+}
+
+// setLanguage picks the catalog: --lang if given, otherwise the locale from LC_ALL, LC_MESSAGES or LANG
+// An unknown --lang is an error, an unknown locale quietly falls back to English
+func setLanguage(flagValue string) error {
+	if flagValue != "" {
+		catalog, ok := catalogs[flagValue]
+		if !ok {
+			return fmt.Errorf("unknown --lang %q (use %s)", flagValue, strings.Join(languages(), ", "))
+		}
+		messages = catalog
+		return nil
+	}
+	if catalog, ok := catalogs[localeLanguage()]; ok {
+		messages = catalog
+	}
+	return nil
+}
+
+// localeLanguage turns the first POSIX locale variable that is set, e.g. "vi_VN.UTF-8", into a language such as "vi"
+func localeLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			lang, _, _ := strings.Cut(v, ".")
+			lang, _, _ = strings.Cut(lang, "_")
+			return strings.ToLower(lang)
+		}
+	}
+	return ""
+}
+
+// tr formats the message for key in the current language, made ASCII in plain mode
+// A key missing from a translation falls back to English, and a key missing everywhere is returned as is,
+// so a slip shows up on screen rather than crashing
+func tr(key string, args ...any) string {
+	format, ok := messages[key]
+	if !ok {
+		if format, ok = catalogs[defaultLanguage][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return display(format)
+	}
+	return display(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,65 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// formatVerb matches the fmt verbs in a message, so translations can be checked against English
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// TestCatalogs compares every translation with the English catalog
+// Each needs every key, with the same verbs in the same order, since the code passes the arguments that way
+func TestCatalogs(t *testing.T) {
+	english := catalogs[defaultLanguage]
+	for _, lang := range languages() {
+		if lang == defaultLanguage {
+			continue
+		}
+		catalog := catalogs[lang]
+		for _, key := range slices.Sorted(maps.Keys(english)) {
+			translated, ok := catalog[key]
+			switch {
+			case !ok:
+				t.Errorf("%s is missing %q", lang, key)
+			case !slices.Equal(formatVerb.FindAllString(translated, -1), formatVerb.FindAllString(english[key], -1)):
+				t.Errorf("%s %q has different verbs from English", lang, key)
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(catalog)) {
+			if _, ok := english[key]; !ok {
+				t.Errorf("%s has unknown key %q", lang, key)
+			}
+		}
+	}
+}
+
+// messageKey finds the keys passed to tr, and to the converter's messages hook, which prints through tr
+var messageKey = regexp.MustCompile(`\b(?:tr|say)\("([a-z_.]+)"`)
+
+// TestMessageKeysExist checks every key the code uses literally is in the English catalog,
+// as tr would otherwise print the bare key
+func TestMessageKeysExist(t *testing.T) {
+	files, _ := filepath.Glob("*.go")
+	more, _ := filepath.Glob("audeth/*.go")
+	for _, f := range append(files, more...) {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range messageKey.FindAllSubmatch(data, -1) {
+			if _, ok := catalogs[defaultLanguage][string(m[1])]; !ok {
+				t.Errorf("%s uses %q, which isn't in locales/en.json", f, m[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Measurements written in InfluxDB line protocol, see "InfluxDB" in the README
+const (
+	influxRateMeasurement       = "eth_aud"        // the aggregated rate, one point per sample
+	influxSourceMeasurement     = "eth_usd"        // each source's quote, tagged with source
+	influxConversionMeasurement = "eth_conversion" // each conversion made
+)
+
+// InfluxConfig is where fresh rates are pushed as they are fetched
+type InfluxConfig struct {
+	URL   string `json:"url"`   // write endpoint, e.g. "http://localhost:8086/api/v2/write?org=home&bucket=eth"
+	Token string `json:"token"` // API token, a secret reference such as "env:INFLUX_TOKEN"; sent as "Authorization: Token ..."
+}
+
+// Line protocol escaping: tag keys and values escape commas, equals signs and spaces,
+// measurements commas and spaces, and string field values quotes and backslashes
+var (
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxStringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// influxPoint is one line of line protocol; fields keep their order so the output is stable
+type influxPoint struct {
+	measurement string
+	tags        [][2]string
+	fields      [][2]string // values already formatted, see influxFloat and friends
+	time        time.Time
+}
+
+func influxFloat(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+func influxInt(v int) string       { return strconv.Itoa(v) + "i" }
+func influxBool(v bool) string     { return strconv.FormatBool(v) }
+func influxString(v string) string { return `"` + influxStringEscaper.Replace(v) + `"` }
+
+// String renders the point, e.g. `eth_usd,source=Kraken usd=3300 1772409600000000000`
+func (p influxPoint) String() string {
+	var b strings.Builder
+	b.WriteString(influxMeasurementEscaper.Replace(p.measurement))
+	for _, tag := range p.tags {
+		fmt.Fprintf(&b, ",%s=%s", influxTagEscaper.Replace(tag[0]), influxTagEscaper.Replace(tag[1]))
+	}
+	for i, field := range p.fields {
+		sep := ","
+		if i == 0 {
+			sep = " "
+		}
+		fmt.Fprintf(&b, "%s%s=%s", sep, influxTagEscaper.Replace(field[0]), field[1])
+	}
+	fmt.Fprintf(&b, " %d", p.time.UnixNano())
+	return b.String()
+}
+
+// samplePoints is one eth_aud point for the sample and one eth_usd point per source
+// A failed source still gets a point, with its error as a string field, so gaps can be told from outages
+func samplePoints(s Sample) []influxPoint {
+	ok := 0
+	for _, src := range s.Sources {
+		if src.Error == "" {
+			ok++
+		}
+	}
+	rate := influxPoint{measurement: influxRateMeasurement, time: s.Time, fields: [][2]string{
+		{"rate_aud", influxFloat(s.RateAUD)},
+		{"sources", influxInt(ok)},
+		{"errors", influxInt(len(s.Sources) - ok)},
+	}}
+	if s.Aggregated > 0 {
+		rate.tags = [][2]string{{"aggregated", "daily"}}
+		rate.fields = append(rate.fields, [2]string{"samples", influxInt(s.Aggregated)},
+			[2]string{"high", influxFloat(s.High)}, [2]string{"low", influxFloat(s.Low)})
+	}
+	points := []influxPoint{rate}
+	for _, src := range s.Sources {
+		at := src.Time
+		if at.IsZero() {
+			at = s.Time
+		}
+		p := influxPoint{measurement: influxSourceMeasurement, tags: [][2]string{{"source", src.Name}}, time: at}
+		if src.Error != "" {
+			p.fields = [][2]string{{"error", influxString(src.Error)}}
+		} else {
+			p.fields = [][2]string{{"usd", influxFloat(src.USD)}}
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// conversionPoint is one eth_conversion point
+func conversionPoint(c ConversionRecord) influxPoint {
+	return influxPoint{measurement: influxConversionMeasurement, time: c.Time, fields: [][2]string{
+		{"aud", influxFloat(c.AUD)},
+		{"eth", influxFloat(c.ETH)},
+		{"rate_aud", influxFloat(c.RateAUD)},
+	}}
+}
+
+// resultPoints is what --output influx writes for a conversion: the conversion, plus the rate it used
+// A failed conversion has no rate, so it is only an eth_conversion point with the error
+func resultPoints(r ConversionResult) []influxPoint {
+	conv := influxPoint{measurement: influxConversionMeasurement, time: r.Time, fields: [][2]string{
+		{"aud", influxFloat(r.AUD)},
+	}}
+	if r.Error != "" {
+		conv.fields = append(conv.fields, [2]string{"error", influxString(r.Error)})
+		return []influxPoint{conv}
+	}
+	conv.fields = append(conv.fields, [2]string{"eth", influxFloat(r.ETH)}, [2]string{"rate_aud", influxFloat(r.Rate)},
+		[2]string{"cached", influxBool(r.Cached)}, [2]string{"confidence", influxFloat(r.Confidence)})
+	rate := influxPoint{measurement: influxRateMeasurement, time: r.RateTime, fields: [][2]string{{"rate_aud", influxFloat(r.Rate)}}}
+	if r.FXRate > 0 {
+		rate.fields = append(rate.fields, [2]string{"fx_rate", influxFloat(r.FXRate)})
+	}
+	return []influxPoint{conv, rate}
+}
+
+// writeInfluxPoints writes one line per point
+func writeInfluxPoints(w io.Writer, points []influxPoint) error {
+	for _, p := range points {
+		if _, err := fmt.Fprintln(w, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InfluxWriter pushes points to an InfluxDB write endpoint
+// Both the v2 API (/api/v2/write with org and bucket) and v1 (/write?db=) take the same body
+type InfluxWriter struct {
+	url, token string
+	timeout    time.Duration
+}
+
+// NewInfluxWriter creates a writer with a default timeout
+func NewInfluxWriter(url, token string) InfluxWriter {
+	return InfluxWriter{url: url, token: token, timeout: 10 * time.Second}
+}
+
+// newInfluxWriterFromConfig returns the configured writer, or false when influx.url isn't set
+func newInfluxWriterFromConfig(cfg InfluxConfig) (InfluxWriter, bool, error) {
+	if cfg.URL == "" {
+		return InfluxWriter{}, false, nil
+	}
+	var token string
+	if cfg.Token != "" {
+		var err error
+		if token, err = resolveSecret(cfg.Token); err != nil {
+			return InfluxWriter{}, false, fmt.Errorf("influx.token: %v", err)
+		}
+	}
+	return NewInfluxWriter(cfg.URL, token), true, nil
+}
+
+// Write sends the points in one request; InfluxDB answers 204 when it has stored them
+func (iw InfluxWriter) Write(points []influxPoint) error {
+	var body bytes.Buffer
+	if err := writeInfluxPoints(&body, points); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, iw.url, &body)
+	if err != nil {
+		return fmt.Errorf("influx write failed: %v", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if iw.token != "" {
+		req.Header.Set("Authorization", "Token "+iw.token)
+	}
+	resp, err := newHTTPClient(iw.timeout).Do(req)
+	if err != nil {
+		return redactError(fmt.Errorf("influx write failed: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// InfluxDB explains a rejected write in the body, e.g. a field type conflict
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("influx write returned status code %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// influxBatchSize is how many points go in one write, InfluxDB's recommended batch
+const influxBatchSize = 5000
+
+// pushHistory backfills influx.url from the store, for "history export -format influx -push"
+func pushHistory(store Store, cfg InfluxConfig, kind string, from, to time.Time) error {
+	iw, ok, err := newInfluxWriterFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("-push needs influx.url in config.json")
+	}
+	var points []influxPoint
+	switch kind {
+	case "rates":
+		samples, err := store.Samples(from, to)
+		if err != nil {
+			return err
+		}
+		for _, s := range samples {
+			points = append(points, samplePoints(s)...)
+		}
+	case "conversions":
+		conversions, err := store.Conversions(from, to)
+		if err != nil {
+			return err
+		}
+		for _, c := range conversions {
+			points = append(points, conversionPoint(c))
+		}
+	default:
+		return fmt.Errorf("unknown kind: %s (use rates or conversions)", kind)
+	}
+	for batch := range slices.Chunk(points, influxBatchSize) {
+		if err := iw.Write(batch); err != nil {
+			return err
+		}
+	}
+	fmt.Println(tr("influx.pushed", len(points)))
+	return nil
+}
+
+// pushSample sends a freshly fetched sample to influx.url, if one is configured
+// Failures are only warnings, like failing to record the history
+func pushSample(sample Sample) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return
+	}
+	iw, ok, err := newInfluxWriterFromConfig(cfg.Influx)
+	if err == nil && ok {
+		err = iw.Write(samplePoints(sample))
+	}
+	if err != nil {
+		fmt.Fprintln(progressOut(), tr("warning.influx", redactError(err)))
+	}
+}
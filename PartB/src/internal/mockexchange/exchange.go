@@ -0,0 +1,360 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+// Package mockexchange answers requests the way every built-in source does, from prices set in the handler
+// It is the exchange behind the demo mode and cmd/mockexchange, and what audethtest.MockExchange serves
+package mockexchange
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// exchangePaths maps each built-in source to the path and query its real API uses
+// Keeping the real paths means a fetcher only needs its host swapped to talk to the mock
+var exchangePaths = map[string]string{
+	"CoinGecko":           "/api/v3/simple/price?ids=ethereum&vs_currencies=usd",
+	"Coinbase":            "/v2/prices/ETH-USD/spot",
+	"Bitstamp":            "/api/v2/ticker/ethusd/",
+	"Kraken":              "/0/public/Ticker?pair=ETHUSD",
+	"Bitfinex":            "/v2/ticker/tETHUSD",
+	"Binance":             "/api/v3/ticker/price?symbol=ETHUSDT",
+	"OKX":                 "/api/v5/market/ticker?instId=ETH-USDT",
+	"Bybit":               "/v5/market/tickers?category=spot&symbol=ETHUSDT",
+	"KuCoin":              "/api/v1/market/orderbook/level1?symbol=ETH-USDT",
+	"Gemini":              "/v1/pubticker/ethusd",
+	"Crypto.com":          "/exchange/v1/public/get-tickers?instrument_name=ETH_USDT",
+	"Gate.io":             "/api/v4/spot/tickers?currency_pair=ETH_USDT",
+	"HTX":                 "/market/detail/merged?symbol=ethusdt",
+	"Bitget":              "/api/v2/spot/market/tickers?symbol=ETHUSDT",
+	"MEXC":                "/api/v3/ticker/24hr?symbol=ETHUSDT",
+	"BTC Markets":         "/v3/markets/ETH-AUD/ticker",
+	"Independent Reserve": "/Public/GetMarketSummary?primaryCurrencyCode=eth&secondaryCurrencyCode=aud",
+	"CoinSpot":            "/pubapi/v2/latest/ETH",
+	"Swyftx":              "/markets/info/basic/ETH/",
+	"CoinJar":             "/products/ETHAUD/ticker",
+	"Luno":                "/api/1/ticker?pair=ETHAUD",
+	"CoinMarketCap":       "/v2/cryptocurrency/quotes/latest?symbol=ETH&convert=USD",
+	"CryptoCompare":       "/data/price?fsym=ETH&tsyms=USD",
+	"CoinAPI":             "/v1/exchangerate/ETH/USD",
+	"FX":                  "/api/v3/simple/price?ids=ethereum&vs_currencies=usd,aud",
+	"RBA":                 "/rss/rss-cb-exchange-rates.xml",
+	"Frankfurter":         "/latest?from=USD&to=AUD",
+	"exchangerate.host":   "/live?source=USD&currencies=AUD",
+}
+
+// Exchange answers in the response format of every built-in source
+// Each source can be given its own price, failure status and latency
+// It is a plain http.Handler, so it can be mounted on any server or called in-process
+// WebSocket requests get the Kraken, Coinbase and Bitstamp ticker streams instead
+type Exchange struct {
+	mu        sync.Mutex
+	usd       float64
+	audPerUSD float64
+	prices    map[string]float64
+	status    map[string]int
+	latency   map[string]time.Duration
+	requests  map[string]int
+}
+
+// NewExchange creates a handler quoting ETH at usd and one USD at audPerUSD
+func NewExchange(usd, audPerUSD float64) *Exchange {
+	return &Exchange{
+		usd:       usd,
+		audPerUSD: audPerUSD,
+		prices:    make(map[string]float64),
+		status:    make(map[string]int),
+		latency:   make(map[string]time.Duration),
+		requests:  make(map[string]int),
+	}
+}
+
+// Path is the path and query the named source's real API is asked at, "" for a source the exchange doesn't know
+func Path(name string) string {
+	return exchangePaths[name]
+}
+
+// SetPrice sets the ETH/USD price quoted by every source without its own override
+func (m *Exchange) SetPrice(usd float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usd = usd
+}
+
+// SetSourcePrice overrides the price quoted by one source, e.g. to test outliers
+func (m *Exchange) SetSourcePrice(name string, usd float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices[name] = usd
+}
+
+// Fail makes a source answer with the given HTTP status; 0 restores it
+func (m *Exchange) Fail(name string, status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status[name] = status
+}
+
+// SetLatency delays a source's responses by d
+func (m *Exchange) SetLatency(name string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency[name] = d
+}
+
+// Requests reports how many requests a source has received
+func (m *Exchange) Requests(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests[name]
+}
+
+// Price returns the ETH/USD price a source is currently quoting
+func (m *Exchange) Price(name string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if price, ok := m.prices[name]; ok {
+		return price
+	}
+	return m.usd
+}
+
+// RoundTrip answers req in-process, so the exchange can stand in for the network as a client's Transport
+// WebSocket requests need a real connection, so they fail here; serve them from a listener instead
+func (m *Exchange) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := &response{header: make(http.Header)}
+	m.ServeHTTP(w, req)
+	status := cmp.Or(w.status, http.StatusOK)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(&w.body),
+		ContentLength: int64(w.body.Len()),
+		Request:       req,
+	}, nil
+}
+
+// response keeps what ServeHTTP writes for RoundTrip
+type response struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *response) Header() http.Header { return r.header }
+
+func (r *response) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *response) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
+
+// sourceFor works out which source a request is for from its path and query
+// Kraken, CoinMarketCap, CryptoCompare and exchangerate.host are matched on their path alone, since they are asked for several pairs or currencies
+func sourceFor(r *http.Request) string {
+	switch r.URL.Path {
+	case "/0/public/Ticker":
+		return "Kraken"
+	case "/v2/cryptocurrency/quotes/latest":
+		return "CoinMarketCap"
+	case "/data/price":
+		return "CryptoCompare"
+	case "/live":
+		return "exchangerate.host"
+	}
+	for name, path := range exchangePaths {
+		p, q, _ := strings.Cut(path, "?")
+		if r.URL.Path == p && r.URL.RawQuery == q {
+			return name
+		}
+	}
+	return ""
+}
+
+// krakenPair prices the pairs the converter and route comparison ask Kraken for
+func krakenPair(pair string, usd, audPerUSD float64) (float64, bool) {
+	switch pair {
+	case "ETHUSD", "ETHUSDT":
+		return usd, true
+	case "ETHAUD":
+		return usd * audPerUSD, true
+	case "USDTAUD":
+		return audPerUSD, true
+	}
+	return 0, false
+}
+
+func (m *Exchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		m.serveStream(w, r)
+		return
+	}
+	name := sourceFor(r)
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	m.mu.Lock()
+	m.requests[name]++
+	price, ok := m.prices[name]
+	if !ok {
+		price = m.usd
+	}
+	usd, audPerUSD := m.usd, m.audPerUSD
+	gecko, geckoSet := m.prices["CoinGecko"]
+	status := m.status[name]
+	delay := m.latency[name]
+	m.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch name {
+	case "CoinGecko":
+		fmt.Fprintf(w, `{"ethereum":{"usd":%g}}`, price)
+	case "Coinbase":
+		fmt.Fprintf(w, `{"data":{"base":"ETH","currency":"USD","amount":"%g"}}`, price)
+	case "Bitstamp":
+		fmt.Fprintf(w, `{"last":"%g","bid":"%g","ask":"%g"}`, price, price, price)
+	case "Kraken":
+		pair := r.URL.Query().Get("pair")
+		quote, ok := krakenPair(pair, price, audPerUSD)
+		if !ok {
+			fmt.Fprint(w, `{"error":["EQuery:Unknown asset pair"]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"error":[],"result":{"X%s":{"c":["%g","1.0"]}}}`, pair, quote)
+	case "Bitfinex":
+		fmt.Fprintf(w, `[%g,1,%g,1,0,0,%g,1,%g,%g]`, price, price, price, price, price)
+	case "Binance":
+		// USDT is quoted at par with USD
+		fmt.Fprintf(w, `{"symbol":"ETHUSDT","price":"%.8f"}`, price)
+	case "OKX":
+		fmt.Fprintf(w, `{"code":"0","msg":"","data":[{"instId":"ETH-USDT","last":"%g"}]}`, price)
+	case "Bybit":
+		fmt.Fprintf(w, `{"retCode":0,"retMsg":"OK","result":{"category":"spot","list":[{"symbol":"ETHUSDT","lastPrice":"%g"}]}}`, price)
+	case "KuCoin":
+		fmt.Fprintf(w, `{"code":"200000","data":{"price":"%g","bestBid":"%g","bestAsk":"%g"}}`, price, price, price)
+	case "Gemini":
+		fmt.Fprintf(w, `{"bid":"%g","ask":"%g","last":"%g"}`, price, price, price)
+	case "Crypto.com":
+		fmt.Fprintf(w, `{"id":-1,"method":"public/get-tickers","code":0,"result":{"data":[{"i":"ETH_USDT","a":"%g","b":"%g","k":"%g"}]}}`, price, price, price)
+	case "Gate.io":
+		fmt.Fprintf(w, `[{"currency_pair":"ETH_USDT","last":"%g","lowest_ask":"%g","highest_bid":"%g"}]`, price, price, price)
+	case "HTX":
+		fmt.Fprintf(w, `{"ch":"market.ethusdt.detail.merged","status":"ok","tick":{"close":%g,"bid":[%g,1],"ask":[%g,1]}}`, price, price, price)
+	case "Bitget":
+		fmt.Fprintf(w, `{"code":"00000","msg":"success","data":[{"symbol":"ETHUSDT","lastPr":"%g","bidPr":"%g","askPr":"%g"}]}`, price, price, price)
+	case "MEXC":
+		fmt.Fprintf(w, `{"symbol":"ETHUSDT","lastPrice":"%g","bidPrice":"%g","askPrice":"%g"}`, price, price, price)
+	case "BTC Markets":
+		aud := price * audPerUSD
+		fmt.Fprintf(w, `{"marketId":"ETH-AUD","bestBid":"%.2f","bestAsk":"%.2f","lastPrice":"%.2f"}`, aud, aud, aud)
+	case "Independent Reserve":
+		aud := price * audPerUSD
+		fmt.Fprintf(w, `{"LastPrice":%.2f,"CurrentHighestBidPrice":%.2f,"CurrentLowestOfferPrice":%.2f,"PrimaryCurrencyCode":"Eth","SecondaryCurrencyCode":"Aud"}`, aud, aud, aud)
+	case "CoinSpot":
+		aud := price * audPerUSD
+		fmt.Fprintf(w, `{"status":"ok","message":"ok","prices":{"bid":"%.2f","ask":"%.2f","last":"%.2f"}}`, aud, aud, aud)
+	case "Swyftx":
+		aud := price * audPerUSD
+		fmt.Fprintf(w, `[{"name":"Ethereum","code":"ETH","buy":"%.2f","sell":"%.2f"}]`, aud, aud)
+	case "CoinJar":
+		// A small spread either side, so the bid and ask can be told from the last price
+		aud := price * audPerUSD
+		fmt.Fprintf(w, `{"status":"continuous","last":"%.2f","bid":"%.2f","ask":"%.2f"}`, aud, aud*0.9995, aud*1.0005)
+	case "Luno":
+		aud := price * audPerUSD
+		fmt.Fprintf(w, `{"pair":"ETHAUD","bid":"%.2f","ask":"%.2f","last_trade":"%.2f","status":"ACTIVE"}`, aud*0.9995, aud*1.0005, aud)
+	case "CoinMarketCap":
+		// Any key is accepted, but like the real API a request without one is refused
+		if r.Header.Get("X-CMC_PRO_API_KEY") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"status":{"error_code":1002,"error_message":"API key missing."}}`)
+			return
+		}
+		var quotes []string
+		for _, currency := range strings.Split(r.URL.Query().Get("convert"), ",") {
+			switch currency {
+			case "", "USD":
+				quotes = append(quotes, fmt.Sprintf(`"USD":{"price":%g}`, price))
+			case "AUD":
+				quotes = append(quotes, fmt.Sprintf(`"AUD":{"price":%g}`, price*audPerUSD))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"status":{"error_code":400,"error_message":"Invalid value for \"convert\": \"%s\""}}`, currency)
+				return
+			}
+		}
+		fmt.Fprintf(w, `{"status":{"error_code":0,"error_message":null},"data":{"ETH":[{"id":1027,"symbol":"ETH","quote":{%s}}]}}`, strings.Join(quotes, ","))
+	case "CryptoCompare":
+		var quotes []string
+		for _, currency := range strings.Split(r.URL.Query().Get("tsyms"), ",") {
+			switch currency {
+			case "USD":
+				quotes = append(quotes, fmt.Sprintf(`"USD":%g`, price))
+			case "AUD":
+				quotes = append(quotes, fmt.Sprintf(`"AUD":%g`, price*audPerUSD))
+			default:
+				fmt.Fprintf(w, `{"Response":"Error","Message":"There is no data for any of the toSymbols %s .","Type":1}`, currency)
+				return
+			}
+		}
+		fmt.Fprintf(w, `{%s}`, strings.Join(quotes, ","))
+	case "CoinAPI":
+		// Any key is accepted, but like the real API a request without one is refused
+		if r.Header.Get("X-CoinAPI-Key") == "" {
+			http.Error(w, `{"error":"You forgot to specify an API key."}`, http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"time":"%s","asset_id_base":"ETH","asset_id_quote":"USD","rate":%g}`, time.Now().UTC().Format(time.RFC3339Nano), price)
+	case "RBA":
+		// Only the USD item of the feed, which quotes USD per AUD
+		w.Header().Set("Content-Type", "application/rdf+xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:cb="http://www.cbwiki.net/wiki/index.php/Specification_1.2/">`+
+			`<item><cb:statistics><cb:exchangeRate><cb:observation><cb:value>%.4f</cb:value><cb:unit>AUD</cb:unit></cb:observation>`+
+			`<cb:baseCurrency>AUD</cb:baseCurrency><cb:targetCurrency>USD</cb:targetCurrency>`+
+			`<cb:observationPeriod><cb:period>%s</cb:period></cb:observationPeriod></cb:exchangeRate></cb:statistics></item></rdf:RDF>`,
+			1/audPerUSD, time.Now().Format(time.DateOnly))
+	case "Frankfurter":
+		fmt.Fprintf(w, `{"amount":1.0,"base":"USD","date":"%s","rates":{"AUD":%.4f}}`, time.Now().Format(time.DateOnly), audPerUSD)
+	case "exchangerate.host":
+		if r.URL.Query().Get("access_key") == "" {
+			fmt.Fprint(w, `{"success":false,"error":{"code":101,"type":"missing_access_key","info":"You have not supplied an API Access Key."}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success":true,"timestamp":%d,"source":"USD","quotes":{"USDAUD":%g}}`, time.Now().Unix(), audPerUSD)
+	case "FX":
+		// The converter batches its CoinGecko quote into this request, so quote CoinGecko's price
+		if geckoSet {
+			usd = gecko
+		}
+		fmt.Fprintf(w, `{"ethereum":{"usd":%g,"aud":%g}}`, usd, usd*audPerUSD)
+	}
+}
@@ -0,0 +1,97 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package mockexchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// StreamInterval is how often a mock stream pushes a ticker
+var StreamInterval = time.Second
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// streamSourceFor works out which exchange a subscribe message is meant for
+// Coinbase and Bitstamp both stream from "/", so the message shape is what tells them apart
+func streamSourceFor(msg []byte) string {
+	var sub struct {
+		Method string `json:"method"`
+		Type   string `json:"type"`
+		Event  string `json:"event"`
+	}
+	json.Unmarshal(msg, &sub)
+	switch {
+	case sub.Method == "subscribe":
+		return "Kraken"
+	case sub.Type == "subscribe":
+		return "Coinbase"
+	case sub.Event == "bts:subscribe":
+		return "Bitstamp"
+	}
+	return ""
+}
+
+// streamTicker formats the message an exchange pushes for a new price
+func streamTicker(name string, price float64) string {
+	switch name {
+	case "Kraken":
+		return fmt.Sprintf(`{"channel":"ticker","type":"update","data":[{"symbol":"ETH/USD","last":%g}]}`, price)
+	case "Coinbase":
+		return fmt.Sprintf(`{"type":"ticker","product_id":"ETH-USD","price":"%g"}`, price)
+	default:
+		return fmt.Sprintf(`{"event":"trade","channel":"live_trades_ethusd","data":{"price":%g}}`, price)
+	}
+}
+
+// serveStream upgrades the request to a WebSocket and pushes the source's price every StreamInterval
+// A source set to fail with Fail has its connection dropped, as a real outage would
+func (m *Exchange) serveStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		return
+	}
+	name := streamSourceFor(msg)
+	if name == "" {
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"error":"unknown subscription"}`))
+		return
+	}
+	// Replies to pings and notices the client closing, while this goroutine does the writing
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	for {
+		if m.streamStatus(name) != 0 {
+			return
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(streamTicker(name, m.Price(name)))); err != nil {
+			return
+		}
+		time.Sleep(StreamInterval)
+	}
+}
+
+func (m *Exchange) streamStatus(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status[name]
+}
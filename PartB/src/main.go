@@ -7,30 +7,21 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strconv"
-	"sync"
+	"strings"
 	"time"
-)
-
-// PriceResult holds the price and any error from a price fetch
-// Struct bundles related data (price, error, and source) for organized error handling and result tracking
-type PriceResult struct {
-	price float64
-	err   error
-	name  string // Add name to track which API provided the result
-}
 
-// Good feature: Interfaces in Go are satisfied implicitly, encouraging decoupling and flexible architecture
-// This promotes modular code without needing explicit declarations
-type PriceFetcher interface {
-	FetchPrice() (float64, error)
-	Name() string
-}
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/core"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
 
 // API is a concrete implementation of the PriceFetcher interface
 // It holds the API's name and URL, demonstrating Go's preference for composition over inheritance
@@ -38,6 +29,13 @@ type PriceFetcher interface {
 type API struct {
 	name, url string
 	timeout   time.Duration // Add timeout for API calls
+	policy    *URLPolicy    // set for configured sources, checked again on every redirect
+	signer    *HMACSigner   // set for sources whose endpoint needs signed requests
+	quote     string        // currency the price is quoted in, "" for USD
+	keyHeader string        // header the API key is sent in, for sources that need one
+	key       string
+	parse     *Expr   // set for configured sources whose response is read with an expression, see expr.go
+	decode    Decoder // how the response is decoded for parse, nil for JSON, see decoders.go
 }
 
 // NewAPI creates a new API instance with default timeout
@@ -54,251 +52,389 @@ func (a API) Name() string {
 	return a.name
 }
 
+// QuoteCurrency is what the API's price is in, see audeth.QuotedFetcher
+func (a API) QuoteCurrency() string {
+	if a.quote == "" {
+		return "USD"
+	}
+	return a.quote
+}
+
+// quotedIn returns a copy of the API quoting in currency, e.g. "USDT" for an exchange that only lists ETH/USDT
+func (a API) quotedIn(currency string) API {
+	a.quote = currency
+	return a
+}
+
+// withKey returns a copy of the API sending key in header on every request
+func (a API) withKey(header, key string) API {
+	a.keyHeader, a.key = header, key
+	return a
+}
+
 // FetchPrice performs a HTTP GET request to retrieve ETH/USD price data from the specified API
 // Go's error handling model avoids exceptions, errors are returned explicitly and checked after each step
 // HTTP client timeout prevents hanging on slow API responses
 func (a API) FetchPrice() (float64, error) {
-	client := &http.Client{
-		Timeout: a.timeout,
+	return a.FetchPriceContext(context.Background())
+}
+
+// FetchPriceContext is FetchPrice with a context, so the request is abandoned when ctx ends
+func (a API) FetchPriceContext(ctx context.Context) (float64, error) {
+	q, err := a.FetchQuoteContext(ctx)
+	return q.Last, err
+}
+
+// FetchQuoteContext is FetchPriceContext keeping the best bid and ask, for the exchanges whose ticker has them
+func (a API) FetchQuoteContext(ctx context.Context) (parsers.Quote, error) {
+	client := newHTTPClient(a.timeout)
+	if a.policy != nil {
+		client.CheckRedirect = a.policy.checkRedirect
+	}
+	if a.signer != nil {
+		client.Transport = signingTransport{signer: a.signer, next: client.Transport}
 	}
 
-	resp, err := client.Get(a.url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url, nil)
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %v", err)
+		return parsers.Quote{}, fmt.Errorf("request failed: %v", err)
+	}
+	if a.key != "" {
+		req.Header.Set(a.keyHeader, a.key)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return parsers.Quote{}, fmt.Errorf("request failed: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+		return parsers.Quote{}, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	// The body is decoded as it arrives rather than read into memory first, and capped at parsers.MaxBodySize
+	q, err := a.parseQuote(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("reading body failed: %v", err)
+		// %w keeps the parsers' typed errors, such as parsers.ErrRateLimited, visible to errors.Is
+		return parsers.Quote{}, fmt.Errorf("parsing response failed: %w", err)
 	}
 
-	var price float64
-	if err := a.parseResponse(body, &price); err != nil {
-		return 0, fmt.Errorf("parsing response failed: %v", err)
+	if q.Last <= 0 {
+		return parsers.Quote{}, fmt.Errorf("invalid price: %f", q.Last)
 	}
+	return q, nil
+}
 
-	if price <= 0 {
-		return 0, fmt.Errorf("invalid price: %f", price)
+// parseQuote reads a response into a parsers.Quote, which has the bid and ask of the sources whose parser returns them
+// and only the last price for every other source
+// The parsing itself lives in the parsers package, which can also be fuzzed on plain bytes without HTTP,
+// and which parser goes with which source is in core, shared with the WebAssembly build
+func (a API) parseQuote(body io.Reader) (parsers.Quote, error) {
+	if a.parse != nil {
+		return parseWithExpr(a.parse, a.decode, body)
 	}
-	return price, nil
+	return core.Decode(a.name, body)
 }
 
-// parseResponse handles the JSON parsing for each API
-// Limitation: Go's lack of inheritance, can't create a base API class with common functionality
-// Instead, use composition and switch statements, which can be verbose
-// Switch statement handles different API response formats
-func (a API) parseResponse(body []byte, price *float64) error {
-	switch a.name {
-	case "CoinGecko":
-		var data map[string]map[string]float64
-		if err := json.Unmarshal(body, &data); err != nil {
-			return err
-		}
-		*price = data["ethereum"]["usd"]
-	case "Coinbase":
-		var data struct {
-			Data struct {
-				Amount string `json:"amount"`
-			} `json:"data"`
-		}
-		if err := json.Unmarshal(body, &data); err != nil {
-			return err
-		}
-		var err error
-		*price, err = strconv.ParseFloat(data.Data.Amount, 64)
-		if err != nil {
-			return err
-		}
-	case "Bitstamp":
-		var data map[string]string
-		if err := json.Unmarshal(body, &data); err != nil {
-			return err
-		}
-		var err error
-		*price, err = strconv.ParseFloat(data["last"], 64)
-		if err != nil {
-			return err
-		}
-	case "Kraken":
-		var data struct {
-			Result map[string]struct {
-				C []string `json:"c"`
-			} `json:"result"`
-		}
-		if err := json.Unmarshal(body, &data); err != nil {
-			return err
-		}
-		for _, v := range data.Result {
-			var err error
-			*price, err = strconv.ParseFloat(v.C[0], 64)
-			if err != nil {
-				return err
-			}
-			break
-		}
-	case "Bitfinex":
-		var data []float64
-		if err := json.Unmarshal(body, &data); err != nil {
-			return err
-		}
-		if len(data) < 7 {
-			return fmt.Errorf("invalid data length from Bitfinex")
-		}
-		*price = data[6]
-	default:
-		return fmt.Errorf("unknown API: %s", a.name)
+// parseWithExpr decodes a response, as JSON when decode is nil, and evaluates parse with it as body
+func parseWithExpr(parse *Expr, decode Decoder, body io.Reader) (parsers.Quote, error) {
+	if decode == nil {
+		decode = decodeJSONBody
+	}
+	v, err := decode(io.LimitReader(body, parsers.MaxBodySize))
+	if err != nil {
+		return parsers.Quote{}, err
 	}
-	return nil
+	price, err := parse.EvalNumber(map[string]any{"body": v})
+	return parsers.Quote{Last: price}, err
 }
 
-// calculateAverageAndConvertToAUD takes a slice of price results and returns the average in AUD
-func calculateAverageAndConvertToAUD(results []PriceResult) (float64, error) {
-	var sum float64
-	var count int
+// exit writes err to out and ends the program with its exit code
+// -h has already printed the usage it asked for, so it gets no error line
+func exit(out io.Writer, err error) {
+	if !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(out, tr("error", redactError(err)))
+	}
+	os.Exit(exitCode(err))
+}
 
-	// Calculate simple average of valid prices
-	for _, result := range results {
-		if result.err == nil {
-			sum += result.price
-			count++
+// main function demonstrates the program's workflow
+// bufio.Scanner for input handling
+func main() {
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		exit(os.Stdout, err)
+	}
+	if len(args) > 0 {
+		if err := runCommand(args[0], args[1:]); err != nil {
+			exit(progressOut(), err)
 		}
+		return
 	}
-
-	if count == 0 {
-		return 0, fmt.Errorf("no valid prices found")
+	if tickerMode {
+		if err := runTicker(); err != nil {
+			exit(os.Stderr, err)
+		}
+		return
+	}
+	// Scripts get the results without the prompts
+	if outputFormat != "text" {
+		if err := runConvert(nil); err != nil {
+			exit(progressOut(), err)
+		}
+		return
 	}
 
-	averageUSD := sum / float64(count)
-
-	// Get exchange rates with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Println(tr("error", redactError(err)))
+		return
 	}
-	resp, err := client.Get("https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd,aud")
+	converter, err := newConverterFromConfig(cfg, audeth.WithRateLock(rateLock), audeth.WithMinConfidence(minConfidence))
 	if err != nil {
-		return 0, fmt.Errorf("failed to get exchange rates: %v", err)
+		fmt.Println(tr("error", redactError(err)))
+		return
 	}
-	defer resp.Body.Close()
-
-	var data map[string]map[string]float64
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return 0, fmt.Errorf("failed to decode exchange rates: %v", err)
+	store, err := openStore(cfg)
+	if err != nil {
+		fmt.Println(tr("error", redactError(err)))
+		return
 	}
-
-	ethData := data["ethereum"]
-	usdRate := ethData["usd"]
-	audRate := ethData["aud"]
-
-	if usdRate == 0 || audRate == 0 {
-		return 0, fmt.Errorf("invalid exchange rates")
+	defer store.Close()
+	if err := maybeCompactHistory(store, cfg); err != nil {
+		fmt.Println(tr("warning.compact", err))
 	}
 
-	conversionRate := audRate / usdRate
-	audPrice := averageUSD * conversionRate
-
-	return audPrice, nil
-}
-
-// fetchAndCalculatePrice handles all the price fetching and calculation logic using channels and WaitGroup
-// Good feature: Go's concurrency model with goroutines and channels makes parallel API calls simple and efficient
-// The combination of WaitGroup and channels demonstrates Go's powerful synchronization primitives
-// Buffered channel prevents goroutine blocking, ensuring all results can be sent
-func fetchAndCalculatePrice() (float64, error) {
-	fetchers := []PriceFetcher{
-		NewAPI("CoinGecko", "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd"),
-		NewAPI("Coinbase", "https://api.coinbase.com/v2/prices/ETH-USD/spot"),
-		NewAPI("Bitstamp", "https://www.bitstamp.net/api/v2/ticker/ethusd/"),
-		NewAPI("Kraken", "https://api.kraken.com/0/public/Ticker?pair=ETHUSD"),
-		NewAPI("Bitfinex", "https://api-pub.bitfinex.com/v2/ticker/tETHUSD"),
-	}
-
-	resultsChan := make(chan PriceResult, len(fetchers))
-	var wg sync.WaitGroup
-
-	for _, fetcher := range fetchers {
-		wg.Add(1)
-		go func(f PriceFetcher) {
-			defer wg.Done()
-			price, err := f.FetchPrice()
-			resultsChan <- PriceResult{
-				price: price,
-				err:   err,
-				name:  f.Name(),
-			}
-			if err != nil {
-				fmt.Printf("[%s] Error: %v\n", f.Name(), err)
-			} else {
-				fmt.Printf("[%s] ETH/USD = $%.2f\n", f.Name(), price)
-			}
-		}(fetcher)
+	cached, usingCache, err := loadRateCache(rateCachePath())
+	if err != nil {
+		fmt.Println(tr("warning.read_cache", err))
 	}
-
-	// Wait for all goroutines to finish
-	wg.Wait()
-	close(resultsChan)
-
-	// Collect results from the channel
-	var results []PriceResult
-	for result := range resultsChan {
-		results = append(results, result)
+	// A locked session converts at the rate it locked, not at a saved one that is swapped out once the fetch is done
+	if rateLock > 0 {
+		usingCache = false
 	}
 
-	return calculateAverageAndConvertToAUD(results)
-}
+	// With --prefetch, start fetching fresh prices straight away, the cached rate covers the wait
+	// Otherwise nothing is fetched until the first conversion, so starting up is instant
+	var fresh chan freshRate
+	if prefetch {
+		fresh = refreshInBackground(converter, store)
+	}
 
-// main function demonstrates the program's workflow
-// bufio.Scanner for input handling
-func main() {
-	// Fetch and calculate current ETH price in AUD
-	avgAUD, err := fetchAndCalculatePrice()
-	if err != nil {
-		fmt.Printf("Error calculating average: %v\n", err)
-		return
+	var current Sample    // the rate conversions use
+	pending := !prefetch  // the first conversion still has to fetch
+	var retryAt time.Time // when a failed background refresh is tried again
+	failures := 0         // background refreshes failed in a row
+	switch {
+	case usingCache:
+		current = cached
+		when := tr("rate.cached.background")
+		if pending {
+			when = tr("rate.cached.pending")
+		}
+		printSection(tr("rate.cached", current.RateAUD, formatAgo(defaultClock.Now().Sub(cached.Time)), when))
+	case prefetch:
+		update := <-fresh
+		if update.err != nil {
+			fmt.Println(tr("error.average", update.err))
+			return
+		}
+		current = update.sample
+		showFreshRate(current, cached, defaultClock.Now())
+	default:
+		printSection(tr("rate.lazy"))
 	}
-	fmt.Printf("\nCurrent ETH price in AUD: $%.2f\n", avgAUD)
 
 	// CLI Interface for AUD to ETH conversion
-	fmt.Println("\n=== ETH Price Converter ===")
-	fmt.Println("Enter the amount in AUD (or 'q' to quit):")
+	// A screen reader would read out the "===" around the title
+	title := tr("title")
+	if plain {
+		title = strings.Trim(title, "= ")
+	}
+	printSection(title)
+	fmt.Println(tr("prompt.start"))
+	fmt.Println(tr("prompt.copy"))
 
+	out := newResultWriter(os.Stdout)
+	var last *ConversionResult // what "c" copies
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
-		fmt.Print("AUD amount: ")
+		fmt.Print(tr("prompt.amount"))
 		if !scanner.Scan() {
 			break
 		}
 		input := scanner.Text()
 
+		if input == "c" || input == "C" {
+			unit := copyUnit
+			if unit == "" {
+				unit = "eth"
+			}
+			if last == nil {
+				fmt.Println(tr("copy.none"))
+			} else {
+				copyResult(*last, unit)
+			}
+			continue
+		}
+
 		if input == "q" || input == "Q" {
-			fmt.Println("\nGoodbye!\n")
+			printSection(tr("goodbye"))
+			if !plain {
+				fmt.Println()
+			}
 			break
 		}
 
 		audAmount, err := strconv.ParseFloat(input, 64)
 		if err != nil {
-			fmt.Println("Invalid input. Please enter a valid number or 'q' to quit.")
+			fmt.Println(tr("input.invalid"))
 			continue
 		}
 
 		if audAmount <= 0 {
-			fmt.Println("Please enter a positive amount.")
+			fmt.Println(tr("input.not_positive"))
 			continue
 		}
 
-		// Calculate ETH amount
-		ethAmount := audAmount / avgAUD
-		fmt.Printf("You can get %.8f ETH for $%.2f AUD\n", ethAmount, audAmount)
-		fmt.Println("\nEnter another amount or 'q' to quit:")
+		// Without --prefetch, the first conversion is what starts fetching the rate
+		// It converts at the cached rate while the fetch runs in the background, and only waits when there is none
+		if pending && usingCache {
+			fresh = refreshInBackground(converter, store)
+			pending = false
+		}
+		if pending {
+			sample, err := converter.Sample()
+			if err != nil {
+				// Nothing to fall back on, so the next conversion tries again
+				fmt.Println(tr("error.average", err))
+				continue
+			}
+			current = saveFreshRate(store, sample, defaultClock.Now())
+			showFreshRate(current, cached, defaultClock.Now())
+			pending = false
+		}
+
+		// A failed refresh is tried again once its backoff is over, the cached rate covering for it until then
+		if usingCache && fresh == nil && !defaultClock.Now().Before(retryAt) {
+			fresh = refreshInBackground(converter, store)
+		}
+
+		// Swap to the fresh rate as soon as the background fetch has finished
+		// A nil channel never receives, so while a failed refresh waits to be retried the select takes the default
+		if usingCache {
+			select {
+			case update := <-fresh:
+				if update.err != nil {
+					failures++
+					delay := min(refreshRetryDelay<<min(failures-1, 6), maxRestartDelay)
+					retryAt = defaultClock.Now().Add(delay)
+					fmt.Println(tr("warning.refresh_failed", delay, update.err))
+					fresh = nil
+				} else {
+					current = update.sample
+					showFreshRate(current, cached, defaultClock.Now())
+					usingCache = false
+				}
+			default:
+			}
+		}
+
+		// Calculate ETH amount, the converter only refetches once its cached rate expires
+		if !usingCache {
+			current, err = converter.Sample()
+			if err != nil {
+				fmt.Println(tr("error.refresh", err))
+				continue
+			}
+		}
+		result := newConversionResult(defaultClock.Now(), audAmount, current, usingCache, nil)
+		result.LockedUntil = converter.LockedUntil().UTC()
+		if err := out.Write(result); err != nil {
+			fmt.Println(tr("error", err))
+		}
+		last = &result
+		if copyUnit != "" {
+			copyResult(result, copyUnit)
+		}
+		recordConversion(store, ConversionRecord{Time: result.Time, AUD: audAmount, ETH: result.ETH, RateAUD: result.Rate}, os.Stdout)
+		printSection(tr("prompt.again"))
 	}
 
 	if err := scanner.Err(); err != nil {
-		fmt.Printf("Error reading input: %v\n", err)
+		fmt.Println(tr("error.input", err))
+	}
+}
+
+// refreshRetryDelay is how long a failed background refresh waits before the first retry, doubling after
+// each failure in a row up to maxRestartDelay
+const refreshRetryDelay = 5 * time.Second
+
+// freshRate carries the result of the background fetch back to main over a channel
+type freshRate struct {
+	sample Sample
+	err    error
+}
+
+// refreshInBackground fetches a rate for the cached one to cover for, and sends it once it is in
+// It is saved as soon as it arrives, so quitting before the next conversion doesn't lose it
+func refreshInBackground(converter *Converter, store Store) chan freshRate {
+	fresh := make(chan freshRate, 1)
+	go func() {
+		sample, err := converter.Sample()
+		if err == nil {
+			sample = saveFreshRate(store, sample, defaultClock.Now())
+		}
+		fresh <- freshRate{sample: sample, err: err}
+	}()
+	return fresh
+}
+
+// saveFreshRate saves a newly fetched rate to the rate cache and the history file unless --private is set
+// It is recorded as of now, as the rate may have been the converter's cached one
+// Failures to persist are only warnings, the converter still works without them
+func saveFreshRate(store Store, sample Sample, now time.Time) Sample {
+	sample.Time = now.UTC()
+	persistSample(store, sample)
+	return sample
+}
+
+// showFreshRate prints a newly fetched rate and the change since the cached one
+func showFreshRate(sample, prev Sample, now time.Time) {
+	printSection(tr("rate.current", sample.RateAUD))
+	if prev.RateAUD > 0 {
+		fmt.Println(formatChange(sample.RateAUD, prev, now))
+	}
+}
+
+// persistSample saves a freshly fetched sample to the rate cache and the history
+// In private mode the fetch leaves no trace on disk, not even when it happened
+func persistSample(store Store, sample Sample) {
+	if private {
+		return
+	}
+	if err := saveRateCache(rateCachePath(), sample); err != nil {
+		fmt.Fprintln(progressOut(), tr("warning.save_cache", err))
+	}
+	if err := store.AddSample(sample); err != nil {
+		fmt.Fprintln(progressOut(), tr("warning.record_history", err))
+	}
+	pushSample(sample)
+	publishSample(sample)
+	publishEvents(sample)
+	emitStatsD(sample)
+}
+
+// recordConversion adds a conversion to the history and sends it to MQTT, warning on w if saving fails
+// In private mode, like persistSample, the conversion isn't kept or sent anywhere
+func recordConversion(store Store, record ConversionRecord, w io.Writer) {
+	if private {
+		return
+	}
+	if err := store.AddConversion(record); err != nil {
+		fmt.Fprintln(w, tr("warning.record_conversion", err))
 	}
+	publishConversion(record)
 }
 
 // Program summary:
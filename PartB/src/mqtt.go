@@ -0,0 +1,340 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// MQTTConfig is the broker fresh rates and conversions are published to, see "MQTT" in the README
+type MQTTConfig struct {
+	Broker   string `json:"broker"`    // "tcp://host:1883", or "tls://host:8883" for TLS with the tls section's settings
+	Username string `json:"username"`  // optional
+	Password string `json:"password"`  // a secret reference such as "env:MQTT_PASSWORD"
+	ClientID string `json:"client_id"` // default "audeth"
+	Topic    string `json:"topic"`     // topic prefix, default "audeth"
+
+	HomeAssistant HomeAssistantConfig `json:"home_assistant"`
+}
+
+// HomeAssistantConfig turns on Home Assistant's MQTT discovery, so the sensors show up without any YAML
+type HomeAssistantConfig struct {
+	Discovery bool   `json:"discovery"`
+	Prefix    string `json:"prefix"` // discovery prefix, default "homeassistant" as in HA
+}
+
+// MQTT control packet types, the high nibble of the first byte (MQTT 3.1.1, section 2.2.1)
+const (
+	mqttConnect    = 0x10
+	mqttConnack    = 0x20
+	mqttPublish    = 0x30
+	mqttDisconnect = 0xe0
+)
+
+// mqttConnackErrors are the CONNACK return codes other than 0, accepted
+var mqttConnackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "client identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// mqttMessage is one PUBLISH at QoS 0
+type mqttMessage struct {
+	topic   string
+	payload []byte
+	retain  bool
+}
+
+// MQTTPublisher connects, sends its messages and disconnects on every publish
+// A minimal MQTT 3.1.1 client: QoS 0 only, which is all a retained sensor value needs,
+// and short-lived connections, so there is no keepalive to run between refreshes
+type MQTTPublisher struct {
+	addr     string
+	useTLS   bool
+	tls      TLSConfig
+	username string
+	password string
+	clientID string
+	topic    string
+	ha       HomeAssistantConfig
+	timeout  time.Duration
+}
+
+// newMQTTPublisherFromConfig returns the configured publisher, or false when mqtt.broker isn't set
+func newMQTTPublisherFromConfig(cfg Config) (MQTTPublisher, bool, error) {
+	m := cfg.MQTT
+	if m.Broker == "" {
+		return MQTTPublisher{}, false, nil
+	}
+	u, err := url.Parse(m.Broker)
+	if err != nil || u.Host == "" {
+		return MQTTPublisher{}, false, fmt.Errorf("mqtt.broker: use tcp://host:1883 or tls://host:8883")
+	}
+	p := MQTTPublisher{addr: u.Host, tls: cfg.TLS, username: m.Username, clientID: m.ClientID, topic: m.Topic,
+		ha: m.HomeAssistant, timeout: 5 * time.Second}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		if u.Port() == "" {
+			p.addr = net.JoinHostPort(u.Hostname(), "1883")
+		}
+	case "tls", "ssl", "mqtts":
+		p.useTLS = true
+		if u.Port() == "" {
+			p.addr = net.JoinHostPort(u.Hostname(), "8883")
+		}
+	default:
+		return MQTTPublisher{}, false, fmt.Errorf("mqtt.broker: unknown scheme %q (use tcp or tls)", u.Scheme)
+	}
+	if m.Password != "" {
+		if p.password, err = resolveSecret(m.Password); err != nil {
+			return MQTTPublisher{}, false, fmt.Errorf("mqtt.password: %v", err)
+		}
+	}
+	if p.clientID == "" {
+		p.clientID = "audeth"
+	}
+	if p.topic == "" {
+		p.topic = "audeth"
+	}
+	if p.ha.Prefix == "" {
+		p.ha.Prefix = "homeassistant"
+	}
+	return p, true, nil
+}
+
+// Publish sends the messages over one connection
+func (p MQTTPublisher) Publish(messages []mqttMessage) error {
+	conn, err := p.dial()
+	if err != nil {
+		return fmt.Errorf("connecting to %s failed: %v", p.addr, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(p.timeout))
+
+	w := bufio.NewWriter(conn)
+	w.Write(p.connectPacket())
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("mqtt connect failed: %v", err)
+	}
+	var ack [4]byte
+	if _, err := io.ReadFull(conn, ack[:]); err != nil {
+		return fmt.Errorf("mqtt connect failed: %v", err)
+	}
+	if ack[0] != mqttConnack || ack[1] != 2 {
+		return fmt.Errorf("mqtt connect failed: unexpected reply %x", ack)
+	}
+	if ack[3] != 0 {
+		return fmt.Errorf("mqtt connect refused: %s", mqttConnackErrors[ack[3]])
+	}
+	for _, m := range messages {
+		w.Write(publishPacket(m))
+	}
+	w.Write([]byte{mqttDisconnect, 0})
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("mqtt publish failed: %v", err)
+	}
+	return nil
+}
+
+func (p MQTTPublisher) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: p.timeout}
+	if !p.useTLS {
+		return dialer.Dial("tcp", p.addr)
+	}
+	conf, err := newTLSClientConfig(p.tls)
+	if err != nil {
+		return nil, err
+	}
+	return tls.DialWithDialer(dialer, "tcp", p.addr, conf)
+}
+
+// connectPacket is CONNECT with a clean session and the optional user name and password
+func (p MQTTPublisher) connectPacket() []byte {
+	flags := byte(0x02) // clean session
+	body := appendMQTTString(nil, "MQTT")
+	body = append(body, 4) // protocol level 4 is MQTT 3.1.1
+	if p.username != "" {
+		flags |= 0x80
+	}
+	if p.password != "" {
+		flags |= 0x40
+	}
+	body = append(body, flags)
+	body = binary.BigEndian.AppendUint16(body, 0) // keepalive off, the connection only lives for one publish
+	body = appendMQTTString(body, p.clientID)
+	if p.username != "" {
+		body = appendMQTTString(body, p.username)
+	}
+	if p.password != "" {
+		body = appendMQTTString(body, p.password)
+	}
+	return mqttPacket(mqttConnect, body)
+}
+
+// publishPacket is PUBLISH at QoS 0, which has no packet identifier
+func publishPacket(m mqttMessage) []byte {
+	header := byte(mqttPublish)
+	if m.retain {
+		header |= 0x01
+	}
+	return mqttPacket(header, append(appendMQTTString(nil, m.topic), m.payload...))
+}
+
+// mqttPacket prefixes body with the fixed header: the packet type and the remaining length in 7-bit groups
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	for n := len(body); ; {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// appendMQTTString appends s with its two-byte length
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// mqttRate is the JSON published, retained, on <topic>/rate
+type mqttRate struct {
+	Time    time.Time `json:"time"`
+	RateAUD float64   `json:"rate_aud"`
+	Sources int       `json:"sources"`
+	Errors  int       `json:"errors"`
+}
+
+// rateMessage is the sample as <topic>/rate
+func (p MQTTPublisher) rateMessage(s Sample) mqttMessage {
+	r := mqttRate{Time: s.Time, RateAUD: s.RateAUD}
+	for _, src := range s.Sources {
+		if src.Error == "" {
+			r.Sources++
+		} else {
+			r.Errors++
+		}
+	}
+	payload, _ := json.Marshal(r)
+	return mqttMessage{topic: p.topic + "/rate", payload: payload, retain: true}
+}
+
+// conversionMessage is a conversion as <topic>/conversion, the same fields the history stores
+func (p MQTTPublisher) conversionMessage(c ConversionRecord) mqttMessage {
+	payload, _ := json.Marshal(c)
+	return mqttMessage{topic: p.topic + "/conversion", payload: payload, retain: true}
+}
+
+// haSensor is the part of a Home Assistant MQTT sensor's discovery config this publishes
+type haSensor struct {
+	Name            string   `json:"name"`
+	UniqueID        string   `json:"unique_id"`
+	StateTopic      string   `json:"state_topic"`
+	ValueTemplate   string   `json:"value_template"`
+	Unit            string   `json:"unit_of_measurement"`
+	DeviceClass     string   `json:"device_class,omitempty"`
+	StateClass      string   `json:"state_class,omitempty"`
+	Icon            string   `json:"icon"`
+	AttributesTopic string   `json:"json_attributes_topic"`
+	Device          haDevice `json:"device"`
+	Precision       int      `json:"suggested_display_precision"`
+}
+
+// haDevice groups the sensors under one device in HA
+type haDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+}
+
+// discoveryMessages are the retained config messages for the rate and last conversion sensors,
+// on <prefix>/sensor/<client_id>/<object>/config as HA's discovery expects
+func (p MQTTPublisher) discoveryMessages() []mqttMessage {
+	device := haDevice{Identifiers: []string{p.clientID}, Name: "AUD to ETH converter", Manufacturer: "audeth", Model: "go-AudToEthConverter"}
+	sensors := []struct {
+		object string
+		sensor haSensor
+	}{
+		{"eth_aud", haSensor{Name: "ETH/AUD rate", StateTopic: p.topic + "/rate", ValueTemplate: "{{ value_json.rate_aud }}",
+			Unit: "AUD", StateClass: "measurement", Icon: "mdi:ethereum", Precision: 2}},
+		{"conversion_eth", haSensor{Name: "Last conversion ETH", StateTopic: p.topic + "/conversion", ValueTemplate: "{{ value_json.eth }}",
+			Unit: "ETH", Icon: "mdi:ethereum", Precision: 8}},
+		{"conversion_aud", haSensor{Name: "Last conversion AUD", StateTopic: p.topic + "/conversion", ValueTemplate: "{{ value_json.aud }}",
+			Unit: "AUD", DeviceClass: "monetary", Icon: "mdi:cash", Precision: 2}},
+	}
+	var messages []mqttMessage
+	for _, s := range sensors {
+		s.sensor.UniqueID = p.clientID + "_" + s.object
+		s.sensor.AttributesTopic = s.sensor.StateTopic
+		s.sensor.Device = device
+		payload, _ := json.Marshal(s.sensor)
+		topic := fmt.Sprintf("%s/sensor/%s/%s/config", p.ha.Prefix, p.clientID, s.object)
+		messages = append(messages, mqttMessage{topic: topic, payload: payload, retain: true})
+	}
+	return messages
+}
+
+// haAnnounced is set once this process has sent the discovery config; it is retained, so once is enough
+var (
+	haMu        sync.Mutex
+	haAnnounced bool
+)
+
+// publishMQTT sends messages to mqtt.broker, if one is configured, with the discovery config first
+// the first time when home_assistant.discovery is on
+// Failures are only warnings, like failing to push to InfluxDB
+func publishMQTT(build func(MQTTPublisher) mqttMessage) {
+	if private {
+		return
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return
+	}
+	p, ok, err := newMQTTPublisherFromConfig(cfg)
+	if err == nil && ok {
+		haMu.Lock()
+		var messages []mqttMessage
+		if p.ha.Discovery && !haAnnounced {
+			messages = p.discoveryMessages()
+		}
+		if err = p.Publish(append(messages, build(p))); err == nil && len(messages) > 0 {
+			haAnnounced = true
+		}
+		haMu.Unlock()
+	}
+	if err != nil {
+		fmt.Fprintln(progressOut(), tr("warning.mqtt", redactError(err)))
+	}
+}
+
+// publishSample publishes a freshly fetched rate, see publishMQTT
+func publishSample(sample Sample) {
+	publishMQTT(func(p MQTTPublisher) mqttMessage { return p.rateMessage(sample) })
+}
+
+// publishConversion publishes a conversion, see publishMQTT
+func publishConversion(c ConversionRecord) {
+	publishMQTT(func(p MQTTPublisher) mqttMessage { return p.conversionMessage(c) })
+}
@@ -0,0 +1,133 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Notifier delivers a message to some external channel
+// Like PriceFetcher, any type with a matching Notify method satisfies it implicitly
+type Notifier interface {
+	Notify(subject, message string) error
+}
+
+// WebhookNotifier POSTs the message as JSON to a URL
+// The "text" key works with Slack and Discord style incoming webhooks
+type WebhookNotifier struct {
+	url     string
+	timeout time.Duration
+}
+
+// NewWebhookNotifier creates a webhook notifier with a default timeout
+func NewWebhookNotifier(url string) WebhookNotifier {
+	return WebhookNotifier{url: url, timeout: 10 * time.Second}
+}
+
+func (w WebhookNotifier) Notify(subject, message string) error {
+	payload, err := json.Marshal(map[string]string{
+		"subject": subject,
+		"text":    message,
+	})
+	if err != nil {
+		return err
+	}
+
+	client := newHTTPClient(w.timeout)
+	resp, err := client.Post(w.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return redactError(fmt.Errorf("webhook request failed: %v", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ExecNotifier runs a command for each message, so any notification tool can be used without new Go code
+// The command gets the same JSON as a webhook on stdin, plus the subject and message in
+// AUDETH_SUBJECT and AUDETH_MESSAGE for tools that take them as arguments, e.g. notify-send
+type ExecNotifier struct {
+	command string
+	timeout time.Duration
+}
+
+// NewExecNotifier creates an exec notifier; command runs through the shell, like a cmd: secret reference
+func NewExecNotifier(command string) ExecNotifier {
+	return ExecNotifier{command: command, timeout: 30 * time.Second}
+}
+
+func (e ExecNotifier) Notify(subject, message string) error {
+	payload, err := json.Marshal(map[string]string{
+		"subject": subject,
+		"text":    message,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	cmd := shellCommandContext(ctx, e.command)
+	cmd.Env = append(os.Environ(), "AUDETH_SUBJECT="+subject, "AUDETH_MESSAGE="+message)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// stderr usually says why, e.g. a missing tool or a refused login
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return redactError(fmt.Errorf("notifier command failed: %v: %s", err, msg))
+		}
+		return fmt.Errorf("notifier command failed: %v", err)
+	}
+	return nil
+}
+
+// buildNotifiers turns the notifier section of the config into Notifier values
+func buildNotifiers(cfgs []NotifierConfig) ([]Notifier, error) {
+	var notifiers []Notifier
+	for _, c := range cfgs {
+		switch c.Type {
+		case "webhook":
+			if c.URL == "" {
+				return nil, fmt.Errorf("webhook notifier needs a url")
+			}
+			url, err := resolveSecret(c.URL)
+			if err != nil {
+				return nil, fmt.Errorf("webhook url: %v", err)
+			}
+			notifiers = append(notifiers, NewWebhookNotifier(url))
+		case "exec":
+			if c.Command == "" {
+				return nil, fmt.Errorf("exec notifier needs a command")
+			}
+			notifiers = append(notifiers, NewExecNotifier(c.Command))
+		default:
+			return nil, fmt.Errorf("unknown notifier type: %s", c.Type)
+		}
+	}
+	return notifiers, nil
+}
+
+// notifyAll sends the message through every notifier, returning the first error
+// All notifiers are tried even if an earlier one fails
+func notifyAll(notifiers []Notifier, subject, message string) error {
+	var firstErr error
+	for _, n := range notifiers {
+		if err := n.Notify(subject, message); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
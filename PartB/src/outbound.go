@@ -0,0 +1,56 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// defaultMaxOutbound is how many API requests may be open at once across the whole process
+const defaultMaxOutbound = 32
+
+// limitTransport caps the number of requests in flight, whichever goroutine sends them
+// fetch.limit bounds one refresh, this bounds everything together, e.g. many server refreshes plus backtests
+// A slot is held until the response body is closed, because that is when the connection is free again
+type limitTransport struct {
+	slots chan struct{} // a counting semaphore built from a buffered channel
+	next  http.RoundTripper
+}
+
+func newLimitTransport(n int, next http.RoundTripper) *limitTransport {
+	return &limitTransport{slots: make(chan struct{}, n), next: next}
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Waiting for a slot gives up with the request, so a fetch deadline still applies while queued
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		<-t.slots
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-t.slots }}
+	return resp, nil
+}
+
+// releasingBody gives the slot back the first time the body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,425 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormat is set by --output and decides how conversions are written
+// outputComma is the field separator of --output csv, set by --delimiter
+var (
+	outputFormat = "text"
+	outputComma  = ','
+)
+
+// outputFormats lists the values --output accepts
+var outputFormats = []string{"text", "json", "yaml", "csv", "tsv", "influx", "xlsx"}
+
+// detailed is set by --detailed, which adds the gwei and US dollar amounts to the text output
+var detailed bool
+
+// rateLock is set by --lock: conversions keep the rate they first got for that long, see WithRateLock
+var rateLock time.Duration
+
+// breakdown is set by --breakdown, which adds each source's quote in AUD and the rate's confidence to the text output
+// The other formats always carry the sources
+var breakdown bool
+
+// minConfidence is set by --min-confidence and replaces fetch.min_confidence, see WithMinConfidence
+var minConfidence float64
+
+// gweiPerETH is the number of gwei in one ether, the unit gas prices are quoted in
+const gweiPerETH = 1e9
+
+// outputTemplate is set by --format, which renders each result with text/template instead
+var outputTemplate *template.Template
+
+// templateFuncs are the helpers --format templates can call besides text/template's own, e.g. printf
+var templateFuncs = template.FuncMap{
+	// {{money .Rate}} gives 5,016.00
+	"money": formatMoney,
+	// {{fixed 4 .ETH}} rounds to that many decimal places
+	"fixed": func(places int, v float64) string { return strconv.FormatFloat(v, 'f', places, 64) },
+	// {{ago .RateTime}} gives e.g. "2m ago"
+	"ago": func(t time.Time) string { return formatAgo(defaultClock.Now().Sub(t)) },
+	// {{local .Time}} gives the local time of day, e.g. 14:05
+	"local": func(t time.Time) string { return t.In(displayLocation).Format("15:04") },
+}
+
+// setOutputTemplate parses --format; it can't be combined with a non-text --output
+func setOutputTemplate(text string) error {
+	if outputFormat != "text" {
+		return fmt.Errorf("--format can't be combined with --output %s", outputFormat)
+	}
+	t, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %v", err)
+	}
+	// Rendering an empty result catches a misspelt field now, rather than after the exchanges have been queried
+	if err := t.Execute(io.Discard, ConversionResult{}); err != nil {
+		return fmt.Errorf("invalid --format: %v", err)
+	}
+	outputTemplate = t
+	outputFormat = "template"
+	return nil
+}
+
+// resultCSVHeader names the columns of --output csv and tsv, one row per conversion
+// The per-source quotes don't fit a row, "history export -format csv" has them
+// Columns are only added at the end, so scripts reading them by position keep working
+var resultCSVHeader = []string{"time", "aud", "eth", "rate_aud", "aggregation", "rate_time", "cached", "error", "gwei", "usd",
+	"fx_rate", "bid_aud", "ask_aud", "confidence"}
+
+// resultSchema is the version of ConversionResult; fields are only ever added,
+// a change to an existing one would bump it
+const resultSchema = 1
+
+// progressOut is where the per-source lines and warnings go
+// In text mode that's stdout as always; otherwise stderr, so stdout holds nothing but the results or the ticker line
+func progressOut() io.Writer {
+	if outputFormat == "text" && !tickerMode {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// ConversionResult is one conversion as written by --output json, yaml and csv, see "JSON output" in the README
+// The YAML keys are the JSON ones
+// Rate is AUD per ETH, the mean of the USD quotes in Sources times the USD/AUD rate
+// Gwei is ETH in gwei, and USD the US dollar value of the ETH at the ETH/USD price behind the rate
+type ConversionResult struct {
+	Schema      int           `json:"schema" yaml:"schema"`
+	Time        time.Time     `json:"time" yaml:"time"`
+	AUD         float64       `json:"aud" yaml:"aud"`
+	ETH         float64       `json:"eth" yaml:"eth"`
+	Gwei        float64       `json:"gwei" yaml:"gwei"`
+	USD         float64       `json:"usd,omitempty" yaml:"usd,omitempty"` // left out when the sources aren't known, e.g. a compacted sample
+	Rate        float64       `json:"rate_aud" yaml:"rate_aud"`
+	Aggregation string        `json:"aggregation" yaml:"aggregation"`
+	FXRate      float64       `json:"fx_rate,omitempty" yaml:"fx_rate,omitempty"` // this and the next three are from the rate's Quote
+	Bid         float64       `json:"bid_aud,omitempty" yaml:"bid_aud,omitempty"`
+	Ask         float64       `json:"ask_aud,omitempty" yaml:"ask_aud,omitempty"`
+	Confidence  float64       `json:"confidence" yaml:"confidence"`
+	RateTime    time.Time     `json:"rate_time" yaml:"rate_time"`                          // when the rate was fetched
+	Cached      bool          `json:"cached" yaml:"cached"`                                // the rate came from a cache rather than this call
+	LockedUntil time.Time     `json:"locked_until,omitzero" yaml:"locked_until,omitempty"` // when --lock lets the rate go
+	Sources     []SourceQuote `json:"sources" yaml:"sources"`
+	Error       string        `json:"error,omitempty" yaml:"error,omitempty"` // set when no rate could be had; ETH and Rate are then 0
+}
+
+// newConversionResult describes converting aud at sample's rate
+func newConversionResult(now time.Time, aud float64, sample Sample, cached bool, err error) ConversionResult {
+	r := ConversionResult{
+		Schema:      resultSchema,
+		Time:        now.UTC(),
+		AUD:         aud,
+		Rate:        sample.RateAUD,
+		Aggregation: sample.Quote().Aggregation,
+		RateTime:    sample.Time.UTC(),
+		Cached:      cached,
+		Sources:     []SourceQuote{},
+	}
+	if err != nil {
+		r.Rate = 0
+		r.Error = redact(err.Error())
+		// A rate turned down for its confidence still says what the confidence was
+		var low ConfidenceError
+		if errors.As(err, &low) {
+			r.Confidence = low.Got
+		}
+	} else if sample.RateAUD > 0 {
+		q := sample.Quote().At(now)
+		r.ETH = aud / sample.RateAUD
+		r.Gwei = r.ETH * gweiPerETH
+		r.USD = r.ETH * q.USD
+		r.FXRate, r.Bid, r.Ask, r.Confidence = q.FXRate, q.Bid, q.Ask, q.Confidence
+	}
+	r.Sources = append(r.Sources, sample.Quote().Sources...)
+	return r
+}
+
+// resultWriter writes results in the current output format
+// The CSV header goes out before the first row, and every row is flushed so a stream of them can be read as it comes
+// An xlsx workbook can only be written whole, so its results are kept until Close
+type resultWriter struct {
+	w           io.Writer
+	csv         *csv.Writer
+	wroteHeader bool
+	xlsx        []ConversionResult
+}
+
+func newResultWriter(w io.Writer) *resultWriter {
+	rw := &resultWriter{w: w}
+	switch outputFormat {
+	case "csv", "tsv":
+		rw.csv = csv.NewWriter(w)
+		rw.csv.Comma = outputComma
+		if outputFormat == "tsv" {
+			rw.csv.Comma = '\t'
+		}
+	}
+	return rw
+}
+
+// Write writes one result; JSON results are one object per line, so they can be piped straight into jq
+func (rw *resultWriter) Write(r ConversionResult) error {
+	switch outputFormat {
+	case "json":
+		return json.NewEncoder(rw.w).Encode(r)
+	case "yaml":
+		// Each result is its own YAML document, so a stream of them splits on "---"
+		data, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(rw.w, "---\n%s", data)
+		return err
+	case "csv", "tsv":
+		if !rw.wroteHeader {
+			rw.csv.Write(resultCSVHeader)
+			rw.wroteHeader = true
+		}
+		rw.csv.Write([]string{r.Time.Format(time.RFC3339Nano), formatFloat(r.AUD), formatFloat(r.ETH),
+			formatFloat(r.Rate), r.Aggregation, r.RateTime.Format(time.RFC3339Nano), strconv.FormatBool(r.Cached), r.Error,
+			formatFloat(r.Gwei), formatFloat(r.USD), formatFloat(r.FXRate), formatFloat(r.Bid), formatFloat(r.Ask), formatFloat(r.Confidence)})
+		rw.csv.Flush()
+		return rw.csv.Error()
+	case "influx":
+		return writeInfluxPoints(rw.w, resultPoints(r))
+	case "xlsx":
+		rw.xlsx = append(rw.xlsx, r)
+		return nil
+	case "template":
+		// One line per result, which is what status bars read
+		var b strings.Builder
+		if err := outputTemplate.Execute(&b, r); err != nil {
+			return fmt.Errorf("rendering --format failed: %v", err)
+		}
+		line := b.String()
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		_, err := io.WriteString(rw.w, line)
+		return err
+	default:
+		if r.Error != "" {
+			_, err := fmt.Fprintln(rw.w, tr("error.average", r.Error))
+			return err
+		}
+		label := ""
+		switch {
+		case !r.LockedUntil.IsZero():
+			label = tr("result.locked", r.LockedUntil.In(displayLocation).Format("15:04:05"))
+		case r.Cached:
+			label = tr("result.cached")
+		}
+		if _, err := fmt.Fprintln(rw.w, tr("result", r.ETH, r.AUD, label)); err != nil {
+			return err
+		}
+		if detailed {
+			if _, err := fmt.Fprintln(rw.w, formatDetailed(r)); err != nil {
+				return err
+			}
+		}
+		if breakdown {
+			for _, src := range r.Sources {
+				if _, err := fmt.Fprintln(rw.w, formatBreakdown(src)); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintln(rw.w, tr("result.confidence", r.Confidence*100)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Close finishes the output; only xlsx has anything left to write
+func (rw *resultWriter) Close() error {
+	if outputFormat != "xlsx" {
+		return nil
+	}
+	return resultWorkbook(rw.xlsx).Write(rw.w)
+}
+
+// formatDetailed is the --detailed line, e.g. "0.01993620 ETH ≈ 19,936,204 gwei ≈ US$65.79"
+// The US dollar part is left out when the sample had no quotes to work it out from
+func formatDetailed(r ConversionResult) string {
+	eth := strconv.FormatFloat(r.ETH, 'f', 8, 64)
+	gwei := formatGrouped(r.Gwei, 0)
+	if r.USD == 0 {
+		return tr("result.detailed.gwei", eth, gwei)
+	}
+	return tr("result.detailed", eth, gwei, formatMoney(r.USD))
+}
+
+// formatBreakdown is one --breakdown line, e.g. "Kraken: A$5,016.00 (US$3,300.00)"
+// A source recorded before AUD quotes were kept only has its US dollar quote
+func formatBreakdown(src SourceQuote) string {
+	switch {
+	case src.Error != "":
+		return tr("result.breakdown.error", src.Name, src.Error)
+	case src.AUD == 0:
+		return tr("result.breakdown.usd", src.Name, formatMoney(src.USD))
+	}
+	return tr("result.breakdown", src.Name, formatMoney(src.AUD), formatMoney(src.USD))
+}
+
+// setOutputFormat validates and applies --output
+func setOutputFormat(name string) error {
+	if !slices.Contains(outputFormats, name) {
+		return fmt.Errorf("unknown --output %q (use %s)", name, strings.Join(outputFormats, ", "))
+	}
+	if name == "xlsx" {
+		if err := checkBinaryOut(os.Stdout); err != nil {
+			return err
+		}
+	}
+	outputFormat = name
+	return nil
+}
+
+// convertFlags are the flags of the "convert" command
+type convertFlags struct {
+	rangeText string
+}
+
+func (f *convertFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("convert")
+	fs.StringVar(&f.rangeText, "range", "", "convert every amount FROM:TO:STEP at the current rate, e.g. 100:1000:100")
+	return fs
+}
+
+// runConvert implements the "convert" command, e.g. "--output json convert 100 250"
+// With no amounts it reads one per line from stdin until EOF or "q", which is also how
+// the interactive mode runs when --output isn't text
+// With -range it converts every amount in the range instead, see sweep.go
+func runConvert(args []string) error {
+	var flags convertFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	var sweep amountRange
+	if flags.rangeText != "" {
+		if len(args) > 0 {
+			return usageErrorf("--range can't be combined with amounts")
+		}
+		r, err := parseRange(flags.rangeText)
+		if err != nil {
+			return usageErrorf("invalid --range: %v", err)
+		}
+		sweep = r
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	converter, err := newConverterFromConfig(cfg, audeth.WithRateLock(rateLock), audeth.WithMinConfidence(minConfidence))
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if flags.rangeText != "" {
+		return runSweep(converter, store, sweep)
+	}
+
+	amounts := slices.Values(args)
+	if len(args) == 0 {
+		amounts = func(yield func(string) bool) {
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if line == "q" || line == "Q" {
+					return
+				}
+				if line != "" && !yield(line) {
+					return
+				}
+			}
+		}
+	}
+
+	out := newResultWriter(os.Stdout)
+	var entered, converted, invalid int
+	var rateErr error
+	var last *ConversionResult // the last successful conversion, for --copy
+	for input := range amounts {
+		entered++
+		aud, err := strconv.ParseFloat(input, 64)
+		if err != nil || aud <= 0 {
+			fmt.Fprintln(os.Stderr, tr("input.skipping", input))
+			invalid++
+			continue
+		}
+		start := defaultClock.Now()
+		sample, err := converter.Sample()
+		// A rate older than this call was served from a cache; a fresh one is saved like the interactive mode does
+		cached := sample.Time.Before(start)
+		if err == nil && !cached {
+			persistSample(store, sample)
+		}
+		result := newConversionResult(defaultClock.Now(), aud, sample, cached, err)
+		if err != nil {
+			rateErr = err
+		} else {
+			result.LockedUntil = converter.LockedUntil().UTC()
+			converted++
+			last = &result
+			recordConversion(store, ConversionRecord{Time: result.Time, AUD: aud, ETH: result.ETH, RateAUD: result.Rate}, os.Stderr)
+		}
+		if err := out.Write(result); err != nil {
+			return err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// Only the last amount is copied, a clipboard holds one value
+	if copyUnit != "" && last != nil {
+		copyResult(*last, copyUnit)
+	}
+
+	return batchError(entered, converted, invalid, rateErr)
+}
+
+// batchError is what a batch of amounts ends with, its exit code saying what went wrong, see exitcodes.go:
+// nil when all converted, a PartialError when some did, or when none did, why the last attempt failed
+// Every amount entered counts towards the partial batch, whether it was invalid or its rate couldn't be fetched
+func batchError(entered, converted, invalid int, rateErr error) error {
+	switch {
+	case converted > 0 && converted < entered:
+		return PartialError{Failed: entered - converted, Total: entered}
+	case rateErr != nil:
+		return rateErr
+	case invalid > 0:
+		return usageErrorf("no valid amounts given")
+	}
+	return nil
+}
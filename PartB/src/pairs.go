@@ -0,0 +1,285 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+)
+
+// PairConfig is one entry of "pairs": a currency pair serve keeps refreshed in the background
+type PairConfig struct {
+	Interval string `json:"interval"`  // how often the pair is refetched, default its cache_ttl
+	Jitter   string `json:"jitter"`    // up to this much is added to each wait at random, so pairs don't fetch in step
+	CacheTTL string `json:"cache_ttl"` // how long the pair's rate is reused, default the top-level cache_ttl
+}
+
+// pairQuotes are the currencies a pair can be quoted in; every source prices ETH, so ETH is the only base
+// AUD is the converter's own rate, the others are the same quotes without the USD/AUD leg
+var pairQuotes = []string{"AUD", "USD", "USDT"}
+
+// pairSpec is a checked entry of "pairs"
+type pairSpec struct {
+	name             string // e.g. "ETH/AUD"
+	quote            string
+	cfg              Config // the config its converter is built from, with the pair's own cache
+	interval, jitter time.Duration
+}
+
+// parsePairs reads the pairs section, sorted by name; each pair gets its own copy of the config
+// The pairs other than ETH/AUD keep their rates under their own Redis key, so they never read each other's
+func parsePairs(cfg Config) ([]pairSpec, error) {
+	var specs []pairSpec
+	for name, pc := range cfg.Pairs {
+		base, quote, ok := strings.Cut(strings.ToUpper(name), "/")
+		if !ok || base != "ETH" || !slices.Contains(pairQuotes, quote) {
+			return nil, fmt.Errorf("pairs: unknown pair %s (use ETH/AUD, ETH/USD or ETH/USDT)", name)
+		}
+		spec := pairSpec{name: base + "/" + quote, quote: quote, cfg: cfg}
+		if slices.ContainsFunc(specs, func(s pairSpec) bool { return s.name == spec.name }) {
+			return nil, fmt.Errorf("pairs: %s is listed twice", spec.name)
+		}
+		if pc.CacheTTL != "" {
+			spec.cfg.CacheTTL = pc.CacheTTL
+		}
+		if quote != "AUD" {
+			spec.cfg.Redis.Prefix = redisPrefix(cfg.Redis) + strings.ToLower(base+"-"+quote) + ":"
+		}
+		ttl, err := parseTTL(spec.cfg.CacheTTL, defaultCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("pairs.%s.cache_ttl: %v", name, err)
+		}
+		if ttl <= 0 {
+			ttl = defaultCacheTTL
+		}
+		if spec.interval, err = parseTTL(pc.Interval, ttl); err != nil || spec.interval <= 0 {
+			return nil, fmt.Errorf("pairs.%s.interval must be a positive duration, e.g. \"30s\"", name)
+		}
+		if spec.jitter, err = parseTTL(pc.Jitter, 0); err != nil {
+			return nil, fmt.Errorf("pairs.%s.jitter: %v", name, err)
+		}
+		specs = append(specs, spec)
+	}
+	slices.SortFunc(specs, func(a, b pairSpec) int { return strings.Compare(a.name, b.name) })
+	return specs, nil
+}
+
+// redisPrefix is the key prefix a RedisConfig ends up with
+func redisPrefix(cfg RedisConfig) string {
+	if cfg.Prefix == "" {
+		return "audeth:"
+	}
+	return cfg.Prefix
+}
+
+// fixedFX stands in for the USD/AUD rate of a pair not quoted in AUD: 1 for USD, USDT per USD for USDT
+type fixedFX struct {
+	name string
+	rate float64
+}
+
+func (f fixedFX) FetchRate() (float64, error) { return f.rate, nil }
+func (f fixedFX) Name() string                { return f.name }
+
+// withPairQuote makes a converter price ETH in quote instead of AUD, usdtPeg being fetch.usdt_usd
+// The sources quoting AUD are left out, as turning them into USD needs the real USD/AUD rate
+func withPairQuote(quote string, usdtPeg float64) ConverterOption {
+	rate := 1.0
+	if quote == "USDT" && usdtPeg > 0 {
+		rate = 1 / usdtPeg
+	}
+	fx := audeth.WithFX(fixedFX{name: "USD/" + quote, rate: rate})
+	fetchers := audeth.WithFetcherWrapper(func(fetchers []PriceFetcher) []PriceFetcher {
+		return slices.DeleteFunc(fetchers, func(f PriceFetcher) bool { return audeth.QuoteCurrency(f) == "AUD" })
+	})
+	return func(c *Converter) {
+		fx(c)
+		fetchers(c)
+	}
+}
+
+// PairState is how one pair's refresher is doing, as /pairs shows it
+type PairState struct {
+	Pair     string `json:"pair"`
+	State    string `json:"state"` // "starting", "ok", "failing", "restarting" or "stopped"
+	Interval string `json:"interval"`
+	Jitter   string `json:"jitter,omitempty"`
+
+	Rate        float64   `json:"rate,omitempty"` // ETH in the pair's quote currency
+	Confidence  float64   `json:"confidence,omitempty"`
+	LastRefresh time.Time `json:"last_refresh,omitzero"` // when the last refresh that worked finished
+	NextRefresh time.Time `json:"next_refresh,omitzero"`
+	Failures    int       `json:"failures,omitempty"` // refreshes failed since the last one that worked
+	LastError   string    `json:"last_error,omitempty"`
+
+	Restarts  int    `json:"restarts,omitempty"` // times the supervisor has restarted it after a panic
+	LastPanic string `json:"last_panic,omitempty"`
+}
+
+// refresher refetches one pair's rate every interval plus up to jitter, until its context ends
+type refresher struct {
+	converter        *Converter
+	interval, jitter time.Duration
+	clock            Clock
+
+	mu    sync.Mutex
+	state PairState
+}
+
+func newRefresher(name string, converter *Converter, interval, jitter time.Duration) *refresher {
+	r := &refresher{converter: converter, interval: interval, jitter: jitter, clock: defaultClock}
+	r.state = PairState{Pair: name, State: "starting", Interval: interval.String()}
+	if jitter > 0 {
+		r.state.Jitter = jitter.String()
+	}
+	return r
+}
+
+func (r *refresher) update(fn func(s *PairState)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.state)
+}
+
+func (r *refresher) State() PairState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state
+}
+
+// run refreshes straight away and then once a wait, returning when ctx is done
+func (r *refresher) run(ctx context.Context) {
+	for {
+		sample, err := r.converter.Refresh()
+		now := r.clock.Now()
+		wait := r.interval
+		if r.jitter > 0 {
+			wait += rand.N(r.jitter)
+		}
+		r.update(func(s *PairState) {
+			if err != nil {
+				s.State, s.LastError = "failing", redact(err.Error())
+				s.Failures++
+			} else {
+				q := sample.Quote().At(now)
+				s.State, s.LastError, s.Failures = "ok", "", 0
+				s.Rate, s.Confidence, s.LastRefresh = q.Rate, q.Confidence, now.UTC()
+			}
+			s.NextRefresh = now.Add(wait).UTC()
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.clock.After(wait):
+		}
+	}
+}
+
+// Supervisor runs a refresher per pair and starts a crashed one again, waiting longer after each crash in a row
+type Supervisor struct {
+	refreshers []*refresher
+	byPair     map[string]*refresher
+}
+
+// maxRestartDelay caps how long the supervisor waits before restarting a refresher that keeps crashing
+const maxRestartDelay = time.Minute
+
+// newPairSupervisor builds a refresher for each pair; ETH/AUD refreshes converter, the server's own,
+// and the other pairs get a converter of their own
+func newPairSupervisor(specs []pairSpec, converter *Converter) (*Supervisor, error) {
+	sv := &Supervisor{byPair: make(map[string]*refresher)}
+	for _, spec := range specs {
+		c := converter
+		if spec.quote != "AUD" {
+			var err error
+			if c, err = newConverterFromConfig(spec.cfg, withPairQuote(spec.quote, spec.cfg.Fetch.USDTPeg)); err != nil {
+				return nil, fmt.Errorf("pairs.%s: %v", spec.name, err)
+			}
+		}
+		r := newRefresher(spec.name, c, spec.interval, spec.jitter)
+		sv.refreshers = append(sv.refreshers, r)
+		sv.byPair[spec.name] = r
+	}
+	return sv, nil
+}
+
+// Run starts every refresher and returns at once; they stop when ctx is done
+func (sv *Supervisor) Run(ctx context.Context) {
+	for _, r := range sv.refreshers {
+		go sv.supervise(ctx, r)
+	}
+}
+
+// supervise keeps r running until ctx is done
+// A refresh that fails isn't a crash, the refresher records it and tries again next time; a panic is
+func (sv *Supervisor) supervise(ctx context.Context, r *refresher) {
+	crashes := 0
+	for {
+		lastRefresh := r.State().LastRefresh
+		// run only returns by itself once ctx is done
+		if panicked := runRecovered(ctx, r); !panicked || ctx.Err() != nil {
+			r.update(func(s *PairState) { s.State, s.NextRefresh = "stopped", time.Time{} })
+			return
+		}
+		// A refresher that got a rate through since its last restart starts the backoff again
+		if !r.State().LastRefresh.Equal(lastRefresh) {
+			crashes = 0
+		}
+		crashes++
+		delay := min(time.Second<<min(crashes-1, 6), maxRestartDelay)
+		r.update(func(s *PairState) {
+			s.State, s.NextRefresh = "restarting", r.clock.Now().Add(delay).UTC()
+			s.Restarts++
+		})
+		state := r.State()
+		fmt.Fprintln(progressOut(), tr("warning.refresher_crashed", state.Pair, state.LastPanic, delay))
+
+		select {
+		case <-ctx.Done():
+			r.update(func(s *PairState) { s.State, s.NextRefresh = "stopped", time.Time{} })
+			return
+		case <-r.clock.After(delay):
+		}
+	}
+}
+
+// runRecovered runs r, turning a panic into its LastPanic and true
+func runRecovered(ctx context.Context, r *refresher) (panicked bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			r.update(func(s *PairState) { s.LastPanic = redact(fmt.Sprint(v)) })
+			panicked = true
+		}
+	}()
+	r.run(ctx)
+	return false
+}
+
+// States lists every pair's state, sorted by pair
+func (sv *Supervisor) States() []PairState {
+	states := []PairState{}
+	for _, r := range sv.refreshers {
+		states = append(states, r.State())
+	}
+	return states
+}
+
+// State is one pair's state, false for a pair that isn't configured
+func (sv *Supervisor) State(pair string) (PairState, bool) {
+	r, ok := sv.byPair[strings.ToUpper(pair)]
+	if !ok {
+		return PairState{}, false
+	}
+	return r.State(), true
+}
@@ -0,0 +1,69 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// eventually waits up to a few seconds of real time for cond, which another goroutine makes true
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func TestRefresherWaitsOnItsClock(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	clock := audethtest.NewFakeClock(start)
+	r := newRefresher("ETH/AUD", newFakeConverter(t), time.Minute, 0)
+	r.clock = clock
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx)
+
+	eventually(t, "the first refresh", func() bool { return clock.Waiters() == 1 })
+	if s := r.State(); s.State != "ok" || !s.LastRefresh.Equal(start) || !s.NextRefresh.Equal(start.Add(time.Minute)) {
+		t.Fatalf("after the first refresh: %+v", s)
+	}
+	clock.Advance(59 * time.Second)
+	if s := r.State(); !s.LastRefresh.Equal(start) {
+		t.Fatalf("refreshed before its interval was up: %+v", s)
+	}
+	clock.Advance(time.Second)
+	eventually(t, "the second refresh", func() bool { return r.State().LastRefresh.Equal(start.Add(time.Minute)) })
+}
+
+func TestSupervisorBacksOffOnItsClock(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	clock := audethtest.NewFakeClock(start)
+	// With no converter every refresh panics, so the refresher crashes as soon as it starts
+	r := newRefresher("ETH/USD", nil, time.Minute, 0)
+	r.clock = clock
+	sv := &Supervisor{refreshers: []*refresher{r}, byPair: map[string]*refresher{"ETH/USD": r}}
+	ctx, cancel := context.WithCancel(context.Background())
+	sv.Run(ctx)
+
+	next := start
+	for restart, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		eventually(t, "the supervisor's wait", func() bool { return clock.Waiters() == 1 && r.State().Restarts == restart+1 })
+		next = next.Add(delay)
+		if s := r.State(); s.State != "restarting" || !s.NextRefresh.Equal(next) || s.LastPanic == "" {
+			t.Fatalf("crash %d: %+v, want a restart at %s", restart+1, s, next)
+		}
+		clock.Advance(delay)
+	}
+	eventually(t, "the fourth crash", func() bool { return r.State().Restarts == 4 })
+	cancel()
+	eventually(t, "the refresher to stop", func() bool { return r.State().State == "stopped" })
+}
@@ -0,0 +1,93 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package parsers
+
+import (
+	"embed"
+	"fmt"
+)
+
+// The fixtures are real responses captured from each API, compiled into the binary with go:embed
+//
+//go:embed fixtures/*.json fixtures/*.xml
+var fixtures embed.FS
+
+// Fixture is a captured payload and the price its parser should read from it
+type Fixture struct {
+	Name  string
+	File  string
+	Parse func([]byte) (float64, error)
+	Want  float64
+}
+
+// Fixtures lists every parser with its captured payload
+func Fixtures() []Fixture {
+	return []Fixture{
+		{"CoinGecko", "coingecko.json", func(b []byte) (float64, error) { return ParseCoinGeckoSimple(b, "usd") }, 3291.42},
+		{"CoinGecko FX", "coingecko_fx.json", ParseCoinGeckoFX, 5030.18 / 3291.42},
+		{"Coinbase", "coinbase.json", ParseCoinbaseSpot, 3290.955},
+		{"Bitstamp", "bitstamp.json", ParseBitstampTicker, 3291.30},
+		{"Kraken", "kraken.json", ParseKrakenTicker, 3291.65},
+		{"Bitfinex", "bitfinex.json", ParseBitfinexTicker, 3291.6},
+		{"Binance", "binance.json", ParseBinanceTicker, 3292.18},
+		{"OKX", "okx.json", ParseOKXTicker, 3291.87},
+		{"Bybit", "bybit.json", ParseBybitTickers, 3291.47},
+		{"KuCoin", "kucoin.json", ParseKuCoinLevel1, 3291.52},
+		{"Gemini", "gemini.json", ParseGeminiPubticker, 3291.25},
+		{"Crypto.com", "cryptocom.json", ParseCryptoComTickers, 3291.63},
+		{"Gate.io", "gateio.json", ParseGateTickers, 3291.39},
+		{"HTX", "htx.json", ParseHTXMerged, 3291.44},
+		{"Bitget", "bitget.json", ParseBitgetTickers, 3291.58},
+		{"MEXC", "mexc.json", ParseMEXCTicker24hr, 3291.66},
+		{"BTC Markets", "btcmarkets.json", ParseBTCMarketsTicker, 5030.12},
+		{"Independent Reserve", "independentreserve.json", lastPrice(ParseIndependentReserveSummary), 5030.36},
+		{"CoinSpot", "coinspot.json", lastPrice(ParseCoinSpotLatest), 5031.07},
+		{"Swyftx", "swyftx.json", lastPrice(ParseSwyftxBasicInfo), 5030.5},
+		{"CoinJar", "coinjar.json", lastPrice(ParseCoinJarTicker), 5030.8},
+		{"Luno", "luno.json", lastPrice(ParseLunoTicker), 5030},
+		{"CoinMarketCap", "coinmarketcap.json", func(b []byte) (float64, error) { return ParseCoinMarketCapQuote(b, "USD") }, 3291.2874},
+		{"CoinMarketCap FX", "coinmarketcap.json", ParseCoinMarketCapFX, 5029.9812 / 3291.2874},
+		{"CryptoCompare", "cryptocompare.json", func(b []byte) (float64, error) { return ParseCryptoCompareQuote(b, "USD") }, 3291.5},
+		{"CryptoCompare FX", "cryptocompare.json", ParseCryptoCompareFX, 5030.25 / 3291.5},
+		{"RBA FX", "rba.xml", ParseRBAFX, 1 / 0.6544},
+		{"Frankfurter FX", "frankfurter.json", ParseFrankfurterFX, 1.5281},
+		{"exchangerate.host FX", "exchangeratehost.json", ParseExchangerateHostFX, 1.528345},
+		{"CoinAPI", "coinapi.json", ParseCoinAPIExchangeRate, 3291.1962418594},
+	}
+}
+
+// lastPrice adapts a parser returning a whole Quote to the last price the fixtures check
+func lastPrice(parse func([]byte) (Quote, error)) func([]byte) (float64, error) {
+	return func(b []byte) (float64, error) {
+		q, err := parse(b)
+		return q.Last, err
+	}
+}
+
+// Payload returns the embedded bytes of a fixture
+func (f Fixture) Payload() ([]byte, error) {
+	b, err := fixtures.ReadFile("fixtures/" + f.File)
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: %v", f.File, err)
+	}
+	return b, nil
+}
+
+// Check parses the fixture and reports an error if the result isn't what was captured
+func (f Fixture) Check() error {
+	b, err := f.Payload()
+	if err != nil {
+		return err
+	}
+	got, err := f.Parse(b)
+	if err != nil {
+		return fmt.Errorf("%s: %v", f.Name, err)
+	}
+	if got != f.Want {
+		return fmt.Errorf("%s: got %v, want %v", f.Name, got, f.Want)
+	}
+	return nil
+}
@@ -0,0 +1,1111 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+// Package parsers turns each exchange's raw response body into a price
+// Every Decode function streams from a reader and never panics on bad input; the Parse wrappers
+// take plain bytes, so they can be fuzzed and checked against captured payloads without any HTTP involved
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxBodySize is the largest response a Decode function reads before giving up
+// Real tickers are a few KB, so anything near this is a broken or hostile server
+const MaxBodySize = 1 << 20
+
+// ErrBodyTooLarge is returned when a response goes past MaxBodySize
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// Quote is a ticker with the best bid and ask as well as the last trade, for the exchanges that have them in one response
+// Bid and Ask are 0 when the response doesn't carry them
+type Quote struct {
+	Last float64
+	Bid  float64
+	Ask  float64
+}
+
+// The kinds of error an exchange can report in its response envelope, for errors.Is on an APIError
+var (
+	ErrRateLimited   = errors.New("rate limited")   // too many requests, try again later
+	ErrUnknownSymbol = errors.New("unknown symbol") // the pair asked for isn't listed
+	ErrUnavailable   = errors.New("unavailable")    // the exchange is down or under maintenance
+)
+
+// APIError is an error an exchange reported in the body of its response, with the exchange's own code
+// Kind is one of the errors above when the code is known, so callers needn't learn every exchange's codes
+type APIError struct {
+	Exchange string
+	Code     string
+	Message  string
+	Kind     error
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("%s error: %s", e.Exchange, e.Message)
+	}
+	return fmt.Sprintf("%s error %s: %s", e.Exchange, e.Code, e.Message)
+}
+
+// Unwrap lets errors.Is(err, ErrRateLimited) and the like see the kind
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// cappedReader fails once more than n bytes have been read, unlike io.LimitReader which just stops
+// Stopping quietly would hand the decoder a truncated document and a confusing syntax error
+type cappedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.n <= 0 {
+		// A body of exactly the limit is fine, only one with more to come is too large
+		var probe [1]byte
+		if n, err := c.r.Read(probe[:]); n == 0 && err == io.EOF {
+			return 0, io.EOF
+		}
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > c.n {
+		p = p[:c.n]
+	}
+	n, err := c.r.Read(p)
+	c.n -= int64(n)
+	return n, err
+}
+
+// bufferPool holds the buffers responses are read into, shared by every parser and refresh
+// A json.Decoder allocates a fresh buffer on every call, while a pooled buffer keeps its capacity,
+// so a long-running server stops allocating per response once the pool is warm
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// maxPooledBuffer stops an unusually large response from pinning its buffer in the pool
+const maxPooledBuffer = 64 * 1024
+
+// decode reads exactly one JSON value from r into v, through a pooled buffer capped at MaxBodySize
+// Only the fields in v are kept, the rest of the payload is skipped over
+func decode(r io.Reader, v any) error {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			bufferPool.Put(buf)
+		}
+	}()
+	if _, err := buf.ReadFrom(&cappedReader{r: r, n: MaxBodySize}); err != nil {
+		return err
+	}
+	return json.Unmarshal(buf.Bytes(), v)
+}
+
+// targetPool reuses one parser's decode struct between calls
+// reset wipes what the last response left behind while keeping the allocated maps and slices,
+// which encoding/json fills in place instead of allocating new ones
+type targetPool[T any] struct {
+	pool  sync.Pool
+	reset func(*T)
+}
+
+func (t *targetPool[T]) get() *T {
+	if v, ok := t.pool.Get().(*T); ok {
+		return v
+	}
+	return new(T)
+}
+
+func (t *targetPool[T]) put(v *T) {
+	t.reset(v)
+	t.pool.Put(v)
+}
+
+// ParseCoinGeckoSimple reads /simple/price for ethereum, returning the price in currency, e.g. "usd"
+func ParseCoinGeckoSimple(b []byte, currency string) (float64, error) {
+	return DecodeCoinGeckoSimple(bytes.NewReader(b), currency)
+}
+
+// DecodeCoinGeckoSimple is ParseCoinGeckoSimple reading from a stream
+func DecodeCoinGeckoSimple(r io.Reader, currency string) (float64, error) {
+	var data map[string]map[string]float64
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	price, ok := data["ethereum"][currency]
+	if !ok {
+		return 0, fmt.Errorf("missing ethereum.%s", currency)
+	}
+	return price, nil
+}
+
+// DecodeCoinGeckoPrices reads a batched /simple/price response, every coin id to every currency
+func DecodeCoinGeckoPrices(r io.Reader) (map[string]map[string]float64, error) {
+	var data map[string]map[string]float64
+	if err := decode(r, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ParseCoinGeckoFX implies how many AUD one USD buys from ethereum's price in both currencies
+func ParseCoinGeckoFX(b []byte) (float64, error) {
+	return DecodeCoinGeckoFX(bytes.NewReader(b))
+}
+
+// DecodeCoinGeckoFX is ParseCoinGeckoFX reading from a stream; both prices come from one decode
+func DecodeCoinGeckoFX(r io.Reader) (float64, error) {
+	var data map[string]map[string]float64
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	for _, currency := range []string{"usd", "aud"} {
+		if _, ok := data["ethereum"][currency]; !ok {
+			return 0, fmt.Errorf("missing ethereum.%s", currency)
+		}
+	}
+	usd, aud := data["ethereum"]["usd"], data["ethereum"]["aud"]
+	if usd == 0 || aud == 0 {
+		return 0, fmt.Errorf("invalid exchange rates")
+	}
+	return aud / usd, nil
+}
+
+// ParseCoinbaseSpot reads /v2/prices/ETH-USD/spot, where the amount is a string
+func ParseCoinbaseSpot(b []byte) (float64, error) {
+	return DecodeCoinbaseSpot(bytes.NewReader(b))
+}
+
+// DecodeCoinbaseSpot is ParseCoinbaseSpot reading from a stream
+func DecodeCoinbaseSpot(r io.Reader) (float64, error) {
+	var data struct {
+		Data struct {
+			Amount string `json:"amount"`
+		} `json:"data"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Data.Amount == "" {
+		return 0, fmt.Errorf("missing data.amount")
+	}
+	return strconv.ParseFloat(data.Data.Amount, 64)
+}
+
+// ParseBitstampTicker reads /api/v2/ticker/, using the last trade price
+func ParseBitstampTicker(b []byte) (float64, error) {
+	return DecodeBitstampTicker(bytes.NewReader(b))
+}
+
+// DecodeBitstampTicker is ParseBitstampTicker reading from a stream
+// A struct with only the field we use replaces the old map[string]any, so the other fields aren't allocated
+func DecodeBitstampTicker(r io.Reader) (float64, error) {
+	var data struct {
+		Last any `json:"last"` // any, so a number instead of a string is "missing" as before, not a decode error
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	last, ok := data.Last.(string)
+	if !ok {
+		return 0, fmt.Errorf("missing last")
+	}
+	return strconv.ParseFloat(last, 64)
+}
+
+// ParseKrakenTicker reads /0/public/Ticker, using the last trade price of the first pair
+// Kraken reports problems in an "error" array alongside a normal 200 response
+func ParseKrakenTicker(b []byte) (float64, error) {
+	return DecodeKrakenTicker(bytes.NewReader(b))
+}
+
+// krakenTicker is the part of Kraken's ticker that is decoded
+// Only the last trade array is kept, the ask, bid, volume and VWAP arrays are skipped
+type krakenTicker struct {
+	Error  []string `json:"error"`
+	Result map[string]struct {
+		C []string `json:"c"`
+	} `json:"result"`
+}
+
+var krakenTargets = targetPool[krakenTicker]{reset: func(t *krakenTicker) {
+	t.Error = t.Error[:0]
+	clear(t.Result)
+}}
+
+// DecodeKrakenTicker is ParseKrakenTicker reading from a stream
+func DecodeKrakenTicker(r io.Reader) (float64, error) {
+	data := krakenTargets.get()
+	defer krakenTargets.put(data)
+	if err := decode(r, data); err != nil {
+		return 0, err
+	}
+	if len(data.Error) > 0 {
+		return 0, &APIError{Exchange: "kraken", Message: data.Error[0]}
+	}
+	for _, v := range data.Result {
+		if len(v.C) == 0 {
+			return 0, fmt.Errorf("missing last trade")
+		}
+		return strconv.ParseFloat(v.C[0], 64)
+	}
+	return 0, fmt.Errorf("empty result")
+}
+
+// ParseBitfinexTicker reads /v2/ticker/, a bare array where index 6 is the last price
+func ParseBitfinexTicker(b []byte) (float64, error) {
+	return DecodeBitfinexTicker(bytes.NewReader(b))
+}
+
+// DecodeBitfinexTicker is ParseBitfinexTicker reading from a stream
+func DecodeBitfinexTicker(r io.Reader) (float64, error) {
+	data := bitfinexTargets.get()
+	defer bitfinexTargets.put(data)
+	if err := decode(r, data); err != nil {
+		return 0, err
+	}
+	if len(*data) < 7 {
+		return 0, fmt.Errorf("invalid data length from Bitfinex")
+	}
+	return (*data)[6], nil
+}
+
+var bitfinexTargets = targetPool[[]float64]{reset: func(t *[]float64) { *t = (*t)[:0] }}
+
+// ParseBinanceTicker reads /api/v3/ticker/price for one symbol, where the price is a string in the quote asset
+// Binance answers errors with {"code": -1121, "msg": "Invalid symbol."}, usually alongside a 400
+func ParseBinanceTicker(b []byte) (float64, error) {
+	return DecodeBinanceTicker(bytes.NewReader(b))
+}
+
+// DecodeBinanceTicker is ParseBinanceTicker reading from a stream
+func DecodeBinanceTicker(r io.Reader) (float64, error) {
+	var data struct {
+		Price string `json:"price"`
+		Code  int    `json:"code"`
+		Msg   string `json:"msg"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Code != 0 {
+		return 0, &APIError{Exchange: "binance", Code: strconv.Itoa(data.Code), Message: data.Msg}
+	}
+	if data.Price == "" {
+		return 0, fmt.Errorf("missing price")
+	}
+	return strconv.ParseFloat(data.Price, 64)
+}
+
+// ParseOKXTicker reads /api/v5/market/ticker?instId=, a data array with one entry per instrument
+// OKX puts its own code in every response, "0" on success, and an error there still comes with a 200
+func ParseOKXTicker(b []byte) (float64, error) {
+	return DecodeOKXTicker(bytes.NewReader(b))
+}
+
+// DecodeOKXTicker is ParseOKXTicker reading from a stream
+func DecodeOKXTicker(r io.Reader) (float64, error) {
+	var data struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			InstID string `json:"instId"`
+			Last   string `json:"last"`
+		} `json:"data"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Code != "0" {
+		return 0, &APIError{Exchange: "okx", Code: data.Code, Message: data.Msg}
+	}
+	if len(data.Data) == 0 {
+		return 0, fmt.Errorf("empty data")
+	}
+	if data.Data[0].Last == "" {
+		return 0, fmt.Errorf("missing last for %s", data.Data[0].InstID)
+	}
+	return strconv.ParseFloat(data.Data[0].Last, 64)
+}
+
+// ParseBybitTickers reads /v5/market/tickers for one spot symbol, the ticker being the first of result.list
+// Bybit reports errors in retCode and retMsg, with a 200 status
+func ParseBybitTickers(b []byte) (float64, error) {
+	return DecodeBybitTickers(bytes.NewReader(b))
+}
+
+// DecodeBybitTickers is ParseBybitTickers reading from a stream
+func DecodeBybitTickers(r io.Reader) (float64, error) {
+	var data struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				Symbol    string `json:"symbol"`
+				LastPrice string `json:"lastPrice"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.RetCode != 0 {
+		return 0, &APIError{Exchange: "bybit", Code: strconv.Itoa(data.RetCode), Message: data.RetMsg}
+	}
+	if len(data.Result.List) == 0 {
+		return 0, fmt.Errorf("empty result.list")
+	}
+	if data.Result.List[0].LastPrice == "" {
+		return 0, fmt.Errorf("missing lastPrice for %s", data.Result.List[0].Symbol)
+	}
+	return strconv.ParseFloat(data.Result.List[0].LastPrice, 64)
+}
+
+// kucoinErrors maps KuCoin's envelope codes to error kinds; "200000" is success
+var kucoinErrors = map[string]error{
+	"429000": ErrRateLimited,
+	"400100": ErrUnknownSymbol, // KuCoin's parameter error, which for this request means the symbol
+	"900001": ErrUnknownSymbol,
+	"503000": ErrUnavailable,
+}
+
+// ParseKuCoinLevel1 reads /api/v1/market/orderbook/level1, where data.price is the last trade as a string
+// An unlisted symbol comes back as success with "data": null
+func ParseKuCoinLevel1(b []byte) (float64, error) {
+	return DecodeKuCoinLevel1(bytes.NewReader(b))
+}
+
+// DecodeKuCoinLevel1 is ParseKuCoinLevel1 reading from a stream
+func DecodeKuCoinLevel1(r io.Reader) (float64, error) {
+	var data struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data *struct {
+			Price string `json:"price"`
+		} `json:"data"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Code != "200000" {
+		return 0, &APIError{Exchange: "kucoin", Code: data.Code, Message: data.Msg, Kind: kucoinErrors[data.Code]}
+	}
+	if data.Data == nil {
+		return 0, &APIError{Exchange: "kucoin", Code: data.Code, Message: "no ticker for this symbol", Kind: ErrUnknownSymbol}
+	}
+	if data.Data.Price == "" {
+		return 0, fmt.Errorf("missing data.price")
+	}
+	return strconv.ParseFloat(data.Data.Price, 64)
+}
+
+// geminiErrors maps Gemini's error reasons to error kinds
+var geminiErrors = map[string]error{
+	"InvalidSymbol": ErrUnknownSymbol,
+	"RateLimited":   ErrRateLimited,
+	"Maintenance":   ErrUnavailable,
+	"System":        ErrUnavailable,
+}
+
+// ParseGeminiPubticker reads /v1/pubticker/, using the last trade price
+// Gemini's errors are {"result": "error", "reason": "InvalidSymbol", ...}
+func ParseGeminiPubticker(b []byte) (float64, error) {
+	return DecodeGeminiPubticker(bytes.NewReader(b))
+}
+
+// DecodeGeminiPubticker is ParseGeminiPubticker reading from a stream
+func DecodeGeminiPubticker(r io.Reader) (float64, error) {
+	var data struct {
+		Last    string `json:"last"`
+		Result  string `json:"result"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Result == "error" {
+		return 0, &APIError{Exchange: "gemini", Code: data.Reason, Message: data.Message, Kind: geminiErrors[data.Reason]}
+	}
+	if data.Last == "" {
+		return 0, fmt.Errorf("missing last")
+	}
+	return strconv.ParseFloat(data.Last, 64)
+}
+
+// cryptoComErrors maps Crypto.com's response codes to error kinds; 0 is success
+var cryptoComErrors = map[int]error{
+	10001: ErrUnavailable, // SYS_ERROR
+	42901: ErrRateLimited, // TOO_MANY_REQUESTS
+}
+
+// ParseCryptoComTickers reads /exchange/v1/public/get-tickers, a result.data array with single-letter fields
+// "a" is the last trade price, "b" and "k" the best bid and ask
+func ParseCryptoComTickers(b []byte) (float64, error) {
+	return DecodeCryptoComTickers(bytes.NewReader(b))
+}
+
+// DecodeCryptoComTickers is ParseCryptoComTickers reading from a stream
+func DecodeCryptoComTickers(r io.Reader) (float64, error) {
+	var data struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Result  struct {
+			Data []struct {
+				Instrument string `json:"i"`
+				Last       string `json:"a"`
+			} `json:"data"`
+		} `json:"result"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Code != 0 {
+		return 0, &APIError{Exchange: "crypto.com", Code: strconv.Itoa(data.Code), Message: data.Message, Kind: cryptoComErrors[data.Code]}
+	}
+	if len(data.Result.Data) == 0 {
+		return 0, fmt.Errorf("empty result.data")
+	}
+	if data.Result.Data[0].Last == "" {
+		return 0, fmt.Errorf("missing last trade for %s", data.Result.Data[0].Instrument)
+	}
+	return strconv.ParseFloat(data.Result.Data[0].Last, 64)
+}
+
+// gateErrors maps Gate.io's error labels to error kinds
+var gateErrors = map[string]error{
+	"INVALID_CURRENCY_PAIR": ErrUnknownSymbol,
+	"TOO_MANY_REQUESTS":     ErrRateLimited,
+	"SERVER_ERROR":          ErrUnavailable,
+}
+
+// ParseGateTickers reads /api/v4/spot/tickers?currency_pair=, an array of ticker objects with the last price as a string
+// An error is a single object instead, {"label": "INVALID_CURRENCY_PAIR", "message": ...}
+func ParseGateTickers(b []byte) (float64, error) {
+	return DecodeGateTickers(bytes.NewReader(b))
+}
+
+// DecodeGateTickers is ParseGateTickers reading from a stream
+// The body is kept raw first, since whether it is an array or an object decides what it holds
+func DecodeGateTickers(r io.Reader) (float64, error) {
+	var raw json.RawMessage
+	if err := decode(r, &raw); err != nil {
+		return 0, err
+	}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+		var e struct {
+			Label   string `json:"label"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return 0, err
+		}
+		return 0, &APIError{Exchange: "gate.io", Code: e.Label, Message: e.Message, Kind: gateErrors[e.Label]}
+	}
+	var data []struct {
+		Pair string `json:"currency_pair"`
+		Last string `json:"last"`
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("empty tickers array")
+	}
+	if data[0].Last == "" {
+		return 0, fmt.Errorf("missing last for %s", data[0].Pair)
+	}
+	return strconv.ParseFloat(data[0].Last, 64)
+}
+
+// htxErrors maps HTX's err-code values to error kinds
+var htxErrors = map[string]error{
+	"invalid-parameter":  ErrUnknownSymbol,
+	"base-symbol-error":  ErrUnknownSymbol,
+	"system-busy":        ErrUnavailable,
+	"system-maintenance": ErrUnavailable,
+}
+
+// ParseHTXMerged reads HTX's (formerly Huobi) /market/detail/merged, where tick.close is the latest price
+// Every response has a status, "ok" or "error" with err-code and err-msg, and errors still come with a 200
+func ParseHTXMerged(b []byte) (float64, error) {
+	return DecodeHTXMerged(bytes.NewReader(b))
+}
+
+// DecodeHTXMerged is ParseHTXMerged reading from a stream
+func DecodeHTXMerged(r io.Reader) (float64, error) {
+	var data struct {
+		Status  string `json:"status"`
+		ErrCode string `json:"err-code"`
+		ErrMsg  string `json:"err-msg"`
+		Tick    *struct {
+			Close float64 `json:"close"`
+		} `json:"tick"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Status != "ok" {
+		return 0, &APIError{Exchange: "htx", Code: data.ErrCode, Message: data.ErrMsg, Kind: htxErrors[data.ErrCode]}
+	}
+	if data.Tick == nil {
+		return 0, fmt.Errorf("missing tick")
+	}
+	return data.Tick.Close, nil
+}
+
+// bitgetErrors maps Bitget's business codes to error kinds; "00000" is success
+var bitgetErrors = map[string]error{
+	"40034": ErrUnknownSymbol, // parameter does not exist
+	"40309": ErrUnknownSymbol, // symbol has been removed
+	"429":   ErrRateLimited,
+	"40725": ErrUnavailable, // service returned an error
+	"45001": ErrUnavailable, // under maintenance
+}
+
+// ParseBitgetTickers reads /api/v2/spot/market/tickers?symbol=, where data[0].lastPr is the last price
+func ParseBitgetTickers(b []byte) (float64, error) {
+	return DecodeBitgetTickers(bytes.NewReader(b))
+}
+
+// DecodeBitgetTickers is ParseBitgetTickers reading from a stream
+func DecodeBitgetTickers(r io.Reader) (float64, error) {
+	var data struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Symbol string `json:"symbol"`
+			LastPr string `json:"lastPr"`
+		} `json:"data"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Code != "00000" {
+		return 0, &APIError{Exchange: "bitget", Code: data.Code, Message: data.Msg, Kind: bitgetErrors[data.Code]}
+	}
+	if len(data.Data) == 0 {
+		return 0, &APIError{Exchange: "bitget", Code: data.Code, Message: "no ticker for this symbol", Kind: ErrUnknownSymbol}
+	}
+	if data.Data[0].LastPr == "" {
+		return 0, fmt.Errorf("missing lastPr for %s", data.Data[0].Symbol)
+	}
+	return strconv.ParseFloat(data.Data[0].LastPr, 64)
+}
+
+// ParseMEXCTicker24hr reads MEXC's /api/v3/ticker/24hr for one symbol, using lastPrice
+// MEXC's API is modelled on Binance's but it is a separate service, so it gets its own parser:
+// the 24hr ticker is used because the bare price ticker's path is the same as Binance's
+func ParseMEXCTicker24hr(b []byte) (float64, error) {
+	return DecodeMEXCTicker24hr(bytes.NewReader(b))
+}
+
+// DecodeMEXCTicker24hr is ParseMEXCTicker24hr reading from a stream
+func DecodeMEXCTicker24hr(r io.Reader) (float64, error) {
+	var data struct {
+		LastPrice string `json:"lastPrice"`
+		Code      int    `json:"code"`
+		Msg       string `json:"msg"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Code != 0 {
+		var kind error
+		if data.Code == -1121 {
+			kind = ErrUnknownSymbol
+		}
+		return 0, &APIError{Exchange: "mexc", Code: strconv.Itoa(data.Code), Message: data.Msg, Kind: kind}
+	}
+	if data.LastPrice == "" {
+		return 0, fmt.Errorf("missing lastPrice")
+	}
+	return strconv.ParseFloat(data.LastPrice, 64)
+}
+
+// btcMarketsErrors maps BTC Markets' error codes to error kinds
+var btcMarketsErrors = map[string]error{
+	"MarketNotFound":  ErrUnknownSymbol,
+	"TooManyRequests": ErrRateLimited,
+}
+
+// ParseBTCMarketsTicker reads BTC Markets' /v3/markets/{id}/ticker, where lastPrice is a string in AUD
+// Errors are {"code": "MarketNotFound", "message": ...}
+func ParseBTCMarketsTicker(b []byte) (float64, error) {
+	return DecodeBTCMarketsTicker(bytes.NewReader(b))
+}
+
+// DecodeBTCMarketsTicker is ParseBTCMarketsTicker reading from a stream
+func DecodeBTCMarketsTicker(r io.Reader) (float64, error) {
+	var data struct {
+		LastPrice string `json:"lastPrice"`
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Code != "" {
+		return 0, &APIError{Exchange: "btcmarkets", Code: data.Code, Message: data.Message, Kind: btcMarketsErrors[data.Code]}
+	}
+	if data.LastPrice == "" {
+		return 0, fmt.Errorf("missing lastPrice")
+	}
+	return strconv.ParseFloat(data.LastPrice, 64)
+}
+
+// ParseIndependentReserveSummary reads Independent Reserve's /Public/GetMarketSummary, with prices as numbers
+// An error is {"Message": ...} alone, e.g. for an unknown currency code
+func ParseIndependentReserveSummary(b []byte) (Quote, error) {
+	return DecodeIndependentReserveSummary(bytes.NewReader(b))
+}
+
+// DecodeIndependentReserveSummary is ParseIndependentReserveSummary reading from a stream
+func DecodeIndependentReserveSummary(r io.Reader) (Quote, error) {
+	var data struct {
+		LastPrice               float64 `json:"LastPrice"`
+		CurrentHighestBidPrice  float64 `json:"CurrentHighestBidPrice"`
+		CurrentLowestOfferPrice float64 `json:"CurrentLowestOfferPrice"`
+		Message                 string  `json:"Message"`
+	}
+	if err := decode(r, &data); err != nil {
+		return Quote{}, err
+	}
+	if data.Message != "" {
+		return Quote{}, &APIError{Exchange: "independentreserve", Message: data.Message}
+	}
+	if data.LastPrice == 0 {
+		return Quote{}, fmt.Errorf("missing LastPrice")
+	}
+	return Quote{Last: data.LastPrice, Bid: data.CurrentHighestBidPrice, Ask: data.CurrentLowestOfferPrice}, nil
+}
+
+// ParseCoinSpotLatest reads CoinSpot's public /pubapi/v2/latest/{coin}, prices in AUD as strings
+// CoinSpot is a retail broker, so its bid and ask sit further apart than on the order-book exchanges
+func ParseCoinSpotLatest(b []byte) (Quote, error) {
+	return DecodeCoinSpotLatest(bytes.NewReader(b))
+}
+
+// DecodeCoinSpotLatest is ParseCoinSpotLatest reading from a stream
+func DecodeCoinSpotLatest(r io.Reader) (Quote, error) {
+	var data struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Prices  struct {
+			Bid  string `json:"bid"`
+			Ask  string `json:"ask"`
+			Last string `json:"last"`
+		} `json:"prices"`
+	}
+	if err := decode(r, &data); err != nil {
+		return Quote{}, err
+	}
+	if data.Status != "ok" {
+		return Quote{}, &APIError{Exchange: "coinspot", Code: data.Status, Message: data.Message}
+	}
+	return parseQuote(data.Prices.Last, data.Prices.Bid, data.Prices.Ask)
+}
+
+// parseQuote builds a Quote from string prices; the last price is required, bid and ask may be empty
+func parseQuote(last, bid, ask string) (Quote, error) {
+	if last == "" {
+		return Quote{}, fmt.Errorf("missing last price")
+	}
+	var q Quote
+	var err error
+	if q.Last, err = strconv.ParseFloat(last, 64); err != nil {
+		return Quote{}, err
+	}
+	for _, side := range []struct {
+		s string
+		v *float64
+	}{{bid, &q.Bid}, {ask, &q.Ask}} {
+		if side.s == "" {
+			continue
+		}
+		if *side.v, err = strconv.ParseFloat(side.s, 64); err != nil {
+			return Quote{}, err
+		}
+	}
+	return q, nil
+}
+
+// ParseSwyftxBasicInfo reads Swyftx's public /markets/info/basic/{asset}/, an array with one entry per asset
+// Swyftx quotes the price it buys and sells at rather than a last trade, so the last price is their midpoint
+func ParseSwyftxBasicInfo(b []byte) (Quote, error) {
+	return DecodeSwyftxBasicInfo(bytes.NewReader(b))
+}
+
+// DecodeSwyftxBasicInfo is ParseSwyftxBasicInfo reading from a stream
+func DecodeSwyftxBasicInfo(r io.Reader) (Quote, error) {
+	var data []struct {
+		Code string `json:"code"`
+		Buy  string `json:"buy"`
+		Sell string `json:"sell"`
+	}
+	if err := decode(r, &data); err != nil {
+		return Quote{}, err
+	}
+	if len(data) == 0 {
+		return Quote{}, &APIError{Exchange: "swyftx", Message: "no market for this asset", Kind: ErrUnknownSymbol}
+	}
+	// The user buys at Swyftx's ask and sells at its bid
+	q, err := parseQuote(data[0].Buy, data[0].Sell, data[0].Buy)
+	if err != nil {
+		return Quote{}, fmt.Errorf("%s: %v", data[0].Code, err)
+	}
+	if q.Bid > 0 {
+		q.Last = (q.Bid + q.Ask) / 2
+	}
+	return q, nil
+}
+
+// ParseCoinJarTicker reads CoinJar Exchange's /products/{id}/ticker, last, bid and ask in AUD as strings
+// A product outside trading hours has a status other than "continuous", and its last price may be stale
+func ParseCoinJarTicker(b []byte) (Quote, error) {
+	return DecodeCoinJarTicker(bytes.NewReader(b))
+}
+
+// DecodeCoinJarTicker is ParseCoinJarTicker reading from a stream
+func DecodeCoinJarTicker(r io.Reader) (Quote, error) {
+	var data struct {
+		Status string `json:"status"`
+		Last   string `json:"last"`
+		Bid    string `json:"bid"`
+		Ask    string `json:"ask"`
+		Error  string `json:"error"`
+	}
+	if err := decode(r, &data); err != nil {
+		return Quote{}, err
+	}
+	if data.Error != "" {
+		return Quote{}, &APIError{Exchange: "coinjar", Message: data.Error}
+	}
+	if data.Status != "" && data.Status != "continuous" {
+		return Quote{}, &APIError{Exchange: "coinjar", Code: data.Status, Message: "market is not trading", Kind: ErrUnavailable}
+	}
+	return parseQuote(data.Last, data.Bid, data.Ask)
+}
+
+// lunoErrors maps Luno's error codes to error kinds
+var lunoErrors = map[string]error{
+	"ErrMarketNotFound":   ErrUnknownSymbol,
+	"ErrTooManyRequests":  ErrRateLimited,
+	"ErrUnderMaintenance": ErrUnavailable,
+}
+
+// ParseLunoTicker reads Luno's /api/1/ticker?pair=, with last_trade, bid and ask as strings
+// A market that isn't ACTIVE, e.g. POSTONLY during maintenance, has no trading to price it by
+func ParseLunoTicker(b []byte) (Quote, error) {
+	return DecodeLunoTicker(bytes.NewReader(b))
+}
+
+// DecodeLunoTicker is ParseLunoTicker reading from a stream
+func DecodeLunoTicker(r io.Reader) (Quote, error) {
+	var data struct {
+		Status    string `json:"status"`
+		LastTrade string `json:"last_trade"`
+		Bid       string `json:"bid"`
+		Ask       string `json:"ask"`
+		Error     string `json:"error"`
+		ErrorCode string `json:"error_code"`
+	}
+	if err := decode(r, &data); err != nil {
+		return Quote{}, err
+	}
+	if data.ErrorCode != "" {
+		return Quote{}, &APIError{Exchange: "luno", Code: data.ErrorCode, Message: data.Error, Kind: lunoErrors[data.ErrorCode]}
+	}
+	if data.Status != "" && data.Status != "ACTIVE" {
+		return Quote{}, &APIError{Exchange: "luno", Code: data.Status, Message: "market is not trading", Kind: ErrUnavailable}
+	}
+	return parseQuote(data.LastTrade, data.Bid, data.Ask)
+}
+
+// cmcErrors maps CoinMarketCap's status error codes to error kinds; 0 is success
+var cmcErrors = map[int]error{
+	400:  ErrUnknownSymbol, // a symbol or convert currency it doesn't know
+	1008: ErrRateLimited,   // minute rate limit reached
+	1009: ErrRateLimited,   // daily limit reached
+	1010: ErrRateLimited,   // monthly limit reached
+	1011: ErrRateLimited,   // IP rate limit reached
+}
+
+// DecodeCoinMarketCapQuotes reads /v2/cryptocurrency/quotes/latest?symbol=&convert=, returning the price of symbol
+// in every convert currency, keyed by currency code, e.g. {"USD": 3291.2, "AUD": 5030.1}
+// v2 lists every coin sharing a symbol under it; the first is CoinMarketCap's pick, the one with the highest rank
+func DecodeCoinMarketCapQuotes(r io.Reader, symbol string) (map[string]float64, error) {
+	var data struct {
+		Status struct {
+			ErrorCode    int    `json:"error_code"`
+			ErrorMessage string `json:"error_message"`
+		} `json:"status"`
+		Data map[string][]struct {
+			Quote map[string]struct {
+				Price float64 `json:"price"`
+			} `json:"quote"`
+		} `json:"data"`
+	}
+	if err := decode(r, &data); err != nil {
+		return nil, err
+	}
+	if data.Status.ErrorCode != 0 {
+		return nil, &APIError{Exchange: "coinmarketcap", Code: strconv.Itoa(data.Status.ErrorCode),
+			Message: data.Status.ErrorMessage, Kind: cmcErrors[data.Status.ErrorCode]}
+	}
+	coins := data.Data[symbol]
+	if len(coins) == 0 {
+		return nil, &APIError{Exchange: "coinmarketcap", Message: "no data for " + symbol, Kind: ErrUnknownSymbol}
+	}
+	prices := make(map[string]float64, len(coins[0].Quote))
+	for currency, q := range coins[0].Quote {
+		prices[currency] = q.Price
+	}
+	return prices, nil
+}
+
+// ParseCoinMarketCapQuote reads ETH's price in currency, e.g. "USD", from a quotes/latest response
+func ParseCoinMarketCapQuote(b []byte, currency string) (float64, error) {
+	prices, err := DecodeCoinMarketCapQuotes(bytes.NewReader(b), "ETH")
+	if err != nil {
+		return 0, err
+	}
+	price, ok := prices[currency]
+	if !ok {
+		return 0, fmt.Errorf("missing data.ETH.quote.%s", currency)
+	}
+	return price, nil
+}
+
+// ParseCoinMarketCapFX implies how many AUD one USD buys from ETH's price in both, as ParseCoinGeckoFX does
+func ParseCoinMarketCapFX(b []byte) (float64, error) {
+	prices, err := DecodeCoinMarketCapQuotes(bytes.NewReader(b), "ETH")
+	if err != nil {
+		return 0, err
+	}
+	usd, aud := prices["USD"], prices["AUD"]
+	if usd == 0 || aud == 0 {
+		return 0, fmt.Errorf("invalid exchange rates")
+	}
+	return aud / usd, nil
+}
+
+// DecodeCryptoComparePrices reads /data/price?fsym=&tsyms=, a flat object of the price in each tsym,
+// e.g. {"USD": 3291.2, "AUD": 5030.1}
+// Errors come back with status 200 as {"Response":"Error","Message":...}, so the object is checked for that first
+func DecodeCryptoComparePrices(r io.Reader) (map[string]float64, error) {
+	var raw json.RawMessage
+	if err := decode(r, &raw); err != nil {
+		return nil, err
+	}
+	var e struct {
+		Response string `json:"Response"`
+		Message  string `json:"Message"`
+		Type     int    `json:"Type"`
+	}
+	if err := json.Unmarshal(raw, &e); err == nil && e.Response == "Error" {
+		// Type 99 is CryptoCompare's rate limit, the rest are bad parameters such as an unknown symbol
+		kind := ErrUnknownSymbol
+		if e.Type == 99 {
+			kind = ErrRateLimited
+		}
+		return nil, &APIError{Exchange: "cryptocompare", Code: strconv.Itoa(e.Type), Message: e.Message, Kind: kind}
+	}
+	var prices map[string]float64
+	if err := json.Unmarshal(raw, &prices); err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// ParseCryptoCompareQuote reads ETH's price in currency, e.g. "USD", from a /data/price response
+func ParseCryptoCompareQuote(b []byte, currency string) (float64, error) {
+	prices, err := DecodeCryptoComparePrices(bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	price, ok := prices[currency]
+	if !ok {
+		return 0, fmt.Errorf("missing %s", currency)
+	}
+	return price, nil
+}
+
+// ParseCryptoCompareFX implies how many AUD one USD buys from ETH's price in both, as ParseCoinGeckoFX does
+func ParseCryptoCompareFX(b []byte) (float64, error) {
+	prices, err := DecodeCryptoComparePrices(bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	usd, aud := prices["USD"], prices["AUD"]
+	if usd == 0 || aud == 0 {
+		return 0, fmt.Errorf("invalid exchange rates")
+	}
+	return aud / usd, nil
+}
+
+// ParseCoinAPIExchangeRate reads CoinAPI's /v1/exchangerate/{base}/{quote}, where rate is a number
+// A bad key, an exhausted plan or an unknown asset comes back as {"error": "..."}
+func ParseCoinAPIExchangeRate(b []byte) (float64, error) {
+	return DecodeCoinAPIExchangeRate(bytes.NewReader(b))
+}
+
+// DecodeCoinAPIExchangeRate is ParseCoinAPIExchangeRate reading from a stream
+func DecodeCoinAPIExchangeRate(r io.Reader) (float64, error) {
+	var data struct {
+		Rate  *float64 `json:"rate"`
+		Error string   `json:"error"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Error != "" {
+		return 0, &APIError{Exchange: "coinapi", Message: data.Error}
+	}
+	if data.Rate == nil {
+		return 0, fmt.Errorf("missing rate")
+	}
+	return *data.Rate, nil
+}
+
+// RBARate is one rate from the RBA's exchange rate feed, in units of Target per Australian dollar
+type RBARate struct {
+	Target string
+	Value  float64
+	Period string // the business day it was published for, e.g. "2026-10-14"
+}
+
+// DecodeRBAExchangeRates reads the Reserve Bank of Australia's RSS feed of its 4pm rates, rss-cb-exchange-rates.xml
+// It is RDF with one item per currency in the central bank (cb:) namespace; encoding/xml matches the
+// elements by local name, so the namespaces don't need spelling out
+func DecodeRBAExchangeRates(r io.Reader) ([]RBARate, error) {
+	var feed struct {
+		Items []struct {
+			Statistics struct {
+				ExchangeRate struct {
+					Value  string `xml:"observation>value"`
+					Base   string `xml:"baseCurrency"`
+					Target string `xml:"targetCurrency"`
+					Period string `xml:"observationPeriod>period"`
+				} `xml:"exchangeRate"`
+			} `xml:"statistics"`
+		} `xml:"item"`
+	}
+	if err := xml.NewDecoder(&cappedReader{r: r, n: MaxBodySize}).Decode(&feed); err != nil {
+		return nil, err
+	}
+	var rates []RBARate
+	for _, item := range feed.Items {
+		rate := item.Statistics.ExchangeRate
+		if rate.Base != "AUD" {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(rate.Value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s rate: %v", rate.Target, err)
+		}
+		rates = append(rates, RBARate{Target: rate.Target, Value: value, Period: rate.Period})
+	}
+	return rates, nil
+}
+
+// ParseRBAFX reads how many AUD one USD buys from the RBA feed, which quotes USD per AUD
+func ParseRBAFX(b []byte) (float64, error) {
+	return DecodeRBAFX(bytes.NewReader(b))
+}
+
+// DecodeRBAFX is ParseRBAFX reading from a stream
+func DecodeRBAFX(r io.Reader) (float64, error) {
+	rates, err := DecodeRBAExchangeRates(r)
+	if err != nil {
+		return 0, err
+	}
+	for _, rate := range rates {
+		if rate.Target == "USD" {
+			if rate.Value <= 0 {
+				return 0, fmt.Errorf("invalid USD rate: %f", rate.Value)
+			}
+			return 1 / rate.Value, nil
+		}
+	}
+	return 0, fmt.Errorf("missing USD rate")
+}
+
+// ParseFrankfurterFX reads how many AUD one USD buys from Frankfurter's /latest?from=USD&to=AUD,
+// the European Central Bank's daily reference rates; errors are {"message": "not found"}
+func ParseFrankfurterFX(b []byte) (float64, error) {
+	return DecodeFrankfurterFX(bytes.NewReader(b))
+}
+
+// DecodeFrankfurterFX is ParseFrankfurterFX reading from a stream
+func DecodeFrankfurterFX(r io.Reader) (float64, error) {
+	var data struct {
+		Base    string             `json:"base"`
+		Rates   map[string]float64 `json:"rates"`
+		Message string             `json:"message"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if data.Message != "" {
+		return 0, &APIError{Exchange: "frankfurter", Message: data.Message, Kind: ErrUnknownSymbol}
+	}
+	if data.Base != "USD" {
+		return 0, fmt.Errorf("rates are based on %q, not USD", data.Base)
+	}
+	rate, ok := data.Rates["AUD"]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("missing rates.AUD")
+	}
+	return rate, nil
+}
+
+// exchangerateHostErrors maps exchangerate.host's error codes to error kinds
+var exchangerateHostErrors = map[int]error{
+	104: ErrRateLimited,   // the plan's monthly requests are used up
+	106: ErrUnavailable,   // no results for the query
+	201: ErrUnknownSymbol, // an invalid source currency
+	202: ErrUnknownSymbol, // an invalid currency code
+}
+
+// ParseExchangerateHostFX reads how many AUD one USD buys from exchangerate.host's /live?source=USD&currencies=AUD,
+// where the quotes are keyed by both currencies run together, e.g. "USDAUD"
+func ParseExchangerateHostFX(b []byte) (float64, error) {
+	return DecodeExchangerateHostFX(bytes.NewReader(b))
+}
+
+// DecodeExchangerateHostFX is ParseExchangerateHostFX reading from a stream
+// Errors come back with status 200 and "success": false, like the CurrencyLayer API it shares
+func DecodeExchangerateHostFX(r io.Reader) (float64, error) {
+	var data struct {
+		Success bool               `json:"success"`
+		Quotes  map[string]float64 `json:"quotes"`
+		Error   struct {
+			Code int    `json:"code"`
+			Type string `json:"type"`
+			Info string `json:"info"`
+		} `json:"error"`
+	}
+	if err := decode(r, &data); err != nil {
+		return 0, err
+	}
+	if !data.Success {
+		return 0, &APIError{Exchange: "exchangerate.host", Code: strconv.Itoa(data.Error.Code), Message: data.Error.Info,
+			Kind: exchangerateHostErrors[data.Error.Code]}
+	}
+	rate, ok := data.Quotes["USDAUD"]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("missing quotes.USDAUD")
+	}
+	return rate, nil
+}
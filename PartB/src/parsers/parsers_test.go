@@ -0,0 +1,225 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package parsers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestFixtures parses every captured payload and checks it gives the price it was captured with
+func TestFixtures(t *testing.T) {
+	for _, f := range Fixtures() {
+		t.Run(f.Name, func(t *testing.T) {
+			if err := f.Check(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func([]byte) (float64, error)
+		body  string
+		kind  error // the APIError kind, nil for an error that isn't one or has none
+		api   bool  // whether the error is an *APIError
+	}{
+		{"empty body", ParseCoinbaseSpot, "", nil, false},
+		{"not JSON", ParseBitstampTicker, "<html>bad gateway</html>", nil, false},
+		{"coinbase missing amount", ParseCoinbaseSpot, `{"data":{}}`, nil, false},
+		{"coinbase amount not a number", ParseCoinbaseSpot, `{"data":{"amount":"abc"}}`, nil, false},
+		{"bitstamp last as a number", ParseBitstampTicker, `{"last":3291.3}`, nil, false},
+		{"kraken error array", ParseKrakenTicker, `{"error":["EQuery:Unknown asset pair"],"result":{}}`, nil, true},
+		{"kraken empty result", ParseKrakenTicker, `{"error":[],"result":{}}`, nil, false},
+		{"bitfinex short array", ParseBitfinexTicker, `[1,2,3]`, nil, false},
+		{"binance invalid symbol", ParseBinanceTicker, `{"code":-1121,"msg":"Invalid symbol."}`, nil, true},
+		{"okx error code", ParseOKXTicker, `{"code":"51001","msg":"Instrument ID does not exist","data":[]}`, nil, true},
+		{"okx empty data", ParseOKXTicker, `{"code":"0","data":[]}`, nil, false},
+		{"kucoin null data", ParseKuCoinLevel1, `{"code":"200000","data":null}`, ErrUnknownSymbol, true},
+		{"mexc invalid symbol", ParseMEXCTicker24hr, `{"code":-1121,"msg":"Invalid symbol."}`, ErrUnknownSymbol, true},
+		{"gemini rate limited", ParseGeminiPubticker, `{"result":"error","reason":"RateLimited","message":"slow down"}`, ErrRateLimited, true},
+		{"gemini maintenance", ParseGeminiPubticker, `{"result":"error","reason":"Maintenance","message":"down"}`, ErrUnavailable, true},
+		{"btcmarkets unknown market", ParseBTCMarketsTicker, `{"code":"MarketNotFound","message":"no such market"}`, ErrUnknownSymbol, true},
+		{"swyftx no market", lastPrice(ParseSwyftxBasicInfo), `[]`, ErrUnknownSymbol, true},
+		{"coingecko fx zero", ParseCoinGeckoFX, `{"ethereum":{"usd":0,"aud":5030}}`, nil, false},
+		{"coingecko fx missing aud", ParseCoinGeckoFX, `{"ethereum":{"usd":3291}}`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse([]byte(tt.body))
+			if err == nil {
+				t.Fatalf("got %v, want an error", got)
+			}
+			var apiErr *APIError
+			if isAPI := errors.As(err, &apiErr); isAPI != tt.api {
+				t.Errorf("error %q: APIError is %v, want %v", err, isAPI, tt.api)
+			}
+			if tt.kind != nil && !errors.Is(err, tt.kind) {
+				t.Errorf("error %q isn't %v", err, tt.kind)
+			}
+		})
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func([]byte) (float64, bool, error)
+		body    string
+		want    float64
+		ok      bool
+		wantErr bool
+	}{
+		{"kraken ticker", ParseKrakenStream, `{"channel":"ticker","data":[{"last":3291.5}]}`, 3291.5, true, false},
+		{"kraken heartbeat", ParseKrakenStream, `{"channel":"heartbeat"}`, 0, false, false},
+		{"kraken ticker without data", ParseKrakenStream, `{"channel":"ticker","data":[]}`, 0, false, true},
+		{"coinbase ticker", ParseCoinbaseStream, `{"type":"ticker","price":"3290.95"}`, 3290.95, true, false},
+		{"coinbase subscriptions", ParseCoinbaseStream, `{"type":"subscriptions","channels":[]}`, 0, false, false},
+		{"coinbase bad price", ParseCoinbaseStream, `{"type":"ticker","price":"x"}`, 0, false, true},
+		{"bitstamp trade", ParseBitstampStream, `{"event":"trade","data":{"price":3291.3}}`, 3291.3, true, false},
+		{"bitstamp subscription ack", ParseBitstampStream, `{"event":"bts:subscription_succeeded","data":{}}`, 0, false, false},
+		{"not JSON", ParseBitstampStream, `{"event":`, 0, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := tt.parse([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("got %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	body := io.MultiReader(strings.NewReader(`{"price":"1"}`), strings.NewReader(strings.Repeat(" ", MaxBodySize)))
+	if _, err := DecodeBinanceTicker(body); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("got %v, want %v", err, ErrBodyTooLarge)
+	}
+	// A body of exactly the limit is still read
+	exact := `{"price":"1"}`
+	exact += strings.Repeat(" ", MaxBodySize-len(exact))
+	if got, err := DecodeBinanceTicker(strings.NewReader(exact)); err != nil || got != 1 {
+		t.Errorf("got %v, %v, want 1", got, err)
+	}
+}
+
+func TestSchemaCheck(t *testing.T) {
+	schema := Schema{{Path: "data.list[0].last", Kind: NumericString}}
+	tests := []struct {
+		name    string
+		body    string
+		path    string // the SchemaError's path, "" for a body that matches
+		problem string
+	}{
+		{"matches", `{"data":{"list":[{"last":"3291.5"}]},"extra":true}`, "", ""},
+		{"missing key", `{"data":{}}`, "data.list", "is missing"},
+		{"empty list", `{"data":{"list":[]}}`, "data.list[0]", "is missing: the list has 0 items"},
+		{"wrong kind", `{"data":{"list":[{"last":3291.5}]}}`, "data.list[0].last", "is a number, want a numeric string"},
+		{"not numeric", `{"data":{"list":[{"last":"n/a"}]}}`, "data.list[0].last", `is "n/a", want a numeric string`},
+		{"empty body", ``, "", "is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Check([]byte(tt.body))
+			if tt.problem == "" {
+				if err != nil {
+					t.Fatalf("got %v, want a match", err)
+				}
+				return
+			}
+			var serr *SchemaError
+			if !errors.As(err, &serr) {
+				t.Fatalf("got %v, want a *SchemaError", err)
+			}
+			if serr.Path != tt.path || serr.Problem != tt.problem {
+				t.Errorf("got %q %q, want %q %q", serr.Path, serr.Problem, tt.path, tt.problem)
+			}
+		})
+	}
+}
+
+// FuzzParse runs every fixture's parser over mutations of the captured payloads
+// A parser may reject anything, but must never panic and must read the same body the same way twice
+func FuzzParse(f *testing.F) {
+	fixtures := Fixtures()
+	for i, fx := range fixtures {
+		b, err := fx.Payload()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(uint8(i), b)
+	}
+	f.Fuzz(func(t *testing.T, i uint8, body []byte) {
+		fx := fixtures[int(i)%len(fixtures)]
+		got, err := fx.Parse(body)
+		// The pooled decode targets must not carry one response into the next
+		again, againErr := fx.Parse(bytes.Clone(body))
+		if (err == nil) != (againErr == nil) || (err == nil && got != again && !(math.IsNaN(got) && math.IsNaN(again))) {
+			t.Errorf("%s: parsed %v, %v then %v, %v", fx.Name, got, err, again, againErr)
+		}
+	})
+}
+
+// FuzzParseStream runs the WebSocket message parsers over arbitrary messages
+func FuzzParseStream(f *testing.F) {
+	f.Add(`{"channel":"ticker","data":[{"last":3291.5}]}`)
+	f.Add(`{"type":"ticker","price":"3290.95"}`)
+	f.Add(`{"event":"trade","data":{"price":3291.3}}`)
+	f.Add(`{"channel":"heartbeat"}`)
+	f.Fuzz(func(t *testing.T, msg string) {
+		for _, parse := range []func([]byte) (float64, bool, error){ParseKrakenStream, ParseCoinbaseStream, ParseBitstampStream} {
+			if _, ok, err := parse([]byte(msg)); ok && err != nil {
+				t.Errorf("%q: ok with error %v", msg, err)
+			}
+		}
+	})
+}
+
+// FuzzSchemaCheck checks arbitrary bodies against every source's kind of path
+func FuzzSchemaCheck(f *testing.F) {
+	for _, fx := range Fixtures() {
+		if b, err := fx.Payload(); err == nil {
+			f.Add(b)
+		}
+	}
+	schema := Schema{{Path: "*.c[0]", Kind: NumericString}, {Path: "[6]", Kind: Number}, {Path: "data.amount", Kind: NumericString}}
+	f.Fuzz(func(t *testing.T, body []byte) {
+		for _, field := range schema {
+			err := Schema{field}.Check(body)
+			var serr *SchemaError
+			if err != nil && !errors.As(err, &serr) {
+				t.Errorf("%s: got %T %v, want a *SchemaError", field.Path, err, err)
+			}
+		}
+	})
+}
+
+// BenchmarkParse measures every parser on its captured payload
+func BenchmarkParse(b *testing.B) {
+	for _, f := range Fixtures() {
+		payload, err := f.Payload()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(f.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := f.Parse(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kind is what a schema expects to find at a path
+type Kind int
+
+const (
+	Number        Kind = iota // a JSON number
+	NumericString             // a string holding a number, as most exchanges send prices
+)
+
+func (k Kind) String() string {
+	if k == NumericString {
+		return "a numeric string"
+	}
+	return "a number"
+}
+
+// Field is one value a response must have, e.g. {"data.amount", NumericString}
+// A path is keys separated by dots and list indexes in brackets, like "result.list[0].lastPrice" or "[6]";
+// a * key is whichever key the object has, for responses keyed by the pair's own name such as Kraken's
+type Field struct {
+	Path string
+	Kind Kind
+}
+
+// Schema is the fields a parser reads from a response
+// It only describes what the parser needs, so a response with extra fields still matches
+type Schema []Field
+
+// SchemaError says which field of a response didn't match its schema, with the part of the body around it
+type SchemaError struct {
+	Path    string // the path as found, with a * replaced by the real key
+	Problem string // e.g. "is missing" or "is a string, want a number"
+	Snippet string // the JSON of the deepest object or list on the path that exists, shortened
+}
+
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		if e.Snippet == "" {
+			return "response " + e.Problem
+		}
+		return fmt.Sprintf("response %s: %s", e.Problem, e.Snippet)
+	}
+	return fmt.Sprintf("%s %s in %s", e.Path, e.Problem, e.Snippet)
+}
+
+// maxSnippet is how much of the body a SchemaError quotes
+const maxSnippet = 120
+
+// Check reports the first field of s that body doesn't have, or has as the wrong kind, and nil when all match
+func (s Schema) Check(body []byte) error {
+	var root any
+	if err := json.Unmarshal(body, &root); err != nil {
+		problem := fmt.Sprintf("isn't JSON (%v)", err)
+		if len(bytes.TrimSpace(body)) == 0 {
+			problem = "is empty"
+		}
+		return &SchemaError{Problem: problem, Snippet: snippet(strings.Join(strings.Fields(string(body)), " "))}
+	}
+	for _, f := range s {
+		if err := f.check(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f Field) check(root any) error {
+	steps, err := splitPath(f.Path)
+	if err != nil {
+		return err
+	}
+	// holder is the object or list cur was found in, which is what an error about cur quotes
+	cur, at, holder := root, "", root
+	fail := func(path, problem string, parent any) error {
+		b, _ := json.Marshal(parent)
+		return &SchemaError{Path: path, Problem: problem, Snippet: snippet(string(b))}
+	}
+	for _, step := range steps {
+		parent, parentAt := cur, at
+		switch obj := cur.(type) {
+		case map[string]any:
+			if step.index >= 0 {
+				return fail(parentAt, fmt.Sprintf("is %s, want a list", describe(cur)), parent)
+			}
+			key := step.key
+			if key == "*" {
+				keys := slices.Sorted(maps.Keys(obj))
+				if len(keys) == 0 {
+					return fail(joinPath(parentAt, "*"), "is missing: the object is empty", parent)
+				}
+				key = keys[0]
+			}
+			at = joinPath(parentAt, key)
+			v, ok := obj[key]
+			if !ok {
+				return fail(at, "is missing", parent)
+			}
+			cur, holder = v, obj
+		case []any:
+			if step.index < 0 {
+				return fail(parentAt, fmt.Sprintf("is %s, want an object", describe(cur)), parent)
+			}
+			at = fmt.Sprintf("%s[%d]", parentAt, step.index)
+			if step.index >= len(obj) {
+				return fail(at, fmt.Sprintf("is missing: the list has %d items", len(obj)), parent)
+			}
+			cur, holder = obj[step.index], obj
+		default:
+			want := "an object"
+			if step.index >= 0 {
+				want = "a list"
+			}
+			return fail(displayPath(parentAt), fmt.Sprintf("is %s, want %s", describe(cur), want), holder)
+		}
+		if step.last {
+			return f.checkValue(at, cur, parent, fail)
+		}
+	}
+	return nil
+}
+
+// checkValue checks the value a path ends at against the field's kind
+func (f Field) checkValue(at string, v, parent any, fail func(path, problem string, parent any) error) error {
+	switch v := v.(type) {
+	case float64:
+		if f.Kind == Number {
+			return nil
+		}
+	case string:
+		if f.Kind == NumericString {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return fail(at, fmt.Sprintf("is %q, want %s", snippet(v), f.Kind), parent)
+			}
+			return nil
+		}
+	}
+	return fail(at, fmt.Sprintf("is %s, want %s", describe(v), f.Kind), parent)
+}
+
+type pathStep struct {
+	key   string
+	index int // -1 for a key
+	last  bool
+}
+
+// splitPath reads "result.list[0].lastPrice" as the steps result, list, 0 and lastPrice
+func splitPath(path string) ([]pathStep, error) {
+	var steps []pathStep
+	for part := range strings.SplitSeq(path, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			steps = append(steps, pathStep{key: key, index: -1})
+		}
+		for rest != "" {
+			n, after, ok := strings.Cut(rest, "]")
+			i, err := strconv.Atoi(n)
+			if !ok || err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid schema path %q", path)
+			}
+			steps = append(steps, pathStep{index: i})
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("invalid schema path %q", path)
+	}
+	steps[len(steps)-1].last = true
+	return steps, nil
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// displayPath names the top of the response, which has no path of its own
+func displayPath(path string) string {
+	if path == "" {
+		return "the response"
+	}
+	return path
+}
+
+// describe names a decoded JSON value's kind for an error
+func describe(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case string:
+		if v == "" {
+			return "an empty string"
+		}
+		return "a string"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func snippet(s string) string {
+	if len(s) <= maxSnippet {
+		return s
+	}
+	// A cut through a multi-byte character would leave half of it
+	return strings.ToValidUTF8(s[:maxSnippet], "") + "..."
+}
+
+// Checked reads a response with decode and, when that fails on a response that doesn't match s, returns a
+// *SchemaError naming the field instead of decode's own error
+// The schema is only checked after a failure, so a response that parses costs no more than before; an exchange's
+// own *APIError and ErrBodyTooLarge are returned as they are
+func Checked[T any](r io.Reader, s Schema, decode func(io.Reader) (T, error)) (T, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			bufferPool.Put(buf)
+		}
+	}()
+	if _, err := buf.ReadFrom(&cappedReader{r: r, n: MaxBodySize}); err != nil {
+		var zero T
+		return zero, err
+	}
+	v, err := decode(bytes.NewReader(buf.Bytes()))
+	if err == nil {
+		return v, nil
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return v, err
+	}
+	if serr := s.Check(buf.Bytes()); serr != nil {
+		return v, serr
+	}
+	return v, err
+}
@@ -0,0 +1,75 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// The stream parsers read one WebSocket message each
+// Besides tickers the exchanges send heartbeats and subscription acks, so ok is false for any
+// message that carries no price, and an error is only returned for a ticker that can't be read
+
+// ParseKrakenStream reads a v2 "ticker" channel message from wss://ws.kraken.com/v2
+func ParseKrakenStream(b []byte) (price float64, ok bool, err error) {
+	var msg struct {
+		Channel string `json:"channel"`
+		Data    []struct {
+			Last float64 `json:"last"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return 0, false, err
+	}
+	if msg.Channel != "ticker" {
+		return 0, false, nil
+	}
+	if len(msg.Data) == 0 || msg.Data[0].Last <= 0 {
+		return 0, false, fmt.Errorf("missing data[0].last")
+	}
+	return msg.Data[0].Last, true, nil
+}
+
+// ParseCoinbaseStream reads a "ticker" message from wss://ws-feed.exchange.coinbase.com
+func ParseCoinbaseStream(b []byte) (price float64, ok bool, err error) {
+	var msg struct {
+		Type  string `json:"type"`
+		Price string `json:"price"`
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return 0, false, err
+	}
+	if msg.Type != "ticker" {
+		return 0, false, nil
+	}
+	if msg.Price == "" {
+		return 0, false, fmt.Errorf("missing price")
+	}
+	price, err = strconv.ParseFloat(msg.Price, 64)
+	return price, err == nil, err
+}
+
+// ParseBitstampStream reads a "trade" event from wss://ws.bitstamp.net, using the trade price
+func ParseBitstampStream(b []byte) (price float64, ok bool, err error) {
+	var msg struct {
+		Event string `json:"event"`
+		Data  struct {
+			Price float64 `json:"price"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return 0, false, err
+	}
+	if msg.Event != "trade" {
+		return 0, false, nil
+	}
+	if msg.Data.Price <= 0 {
+		return 0, false, fmt.Errorf("missing data.price")
+	}
+	return msg.Data.Price, true, nil
+}
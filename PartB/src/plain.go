@@ -0,0 +1,83 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// plain is set by --plain, or when stdout isn't a terminal: every message is ASCII, without
+// decorations or blank lines, so screen readers, log files and pipes get one plain line per message
+// Nothing in the program uses colour; any that is added must check plain too
+var plain bool
+
+// setPlain applies --plain; explicit is false when the flag wasn't given, so the terminal check decides
+func setPlain(value, explicit bool) {
+	if explicit {
+		plain = value
+		return
+	}
+	plain = !isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is a character device such as a terminal, rather than a file or a pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// plainSymbols are spelt out in words, which a screen reader reads better than the symbol's name
+var plainSymbols = strings.NewReplacer(
+	"▲", "up ",
+	"▼", "down ",
+	" → ", " to ",
+	"→", " to ",
+	"…", "...",
+	" ≈ ", ", about ",
+	"≈", "about ",
+	"Đ", "D",
+	"đ", "d",
+)
+
+// plainText makes s ASCII: symbols become words, accented letters lose their accents ("Giá" reads "Gia"),
+// and anything else left over becomes "?"
+func plainText(s string) string {
+	s = plainSymbols.Replace(s)
+	var b strings.Builder
+	// NFD splits "á" into "a" and a combining accent, which is then dropped
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case r <= unicode.MaxASCII:
+			b.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// display prepares text for the screen: plainText in plain mode, unchanged otherwise
+func display(s string) string {
+	if plain {
+		return plainText(s)
+	}
+	return s
+}
+
+// printSection prints a message that starts a new section of the interactive mode
+// It is set off by a blank line, except in plain mode where every line counts
+func printSection(s string) {
+	if !plain {
+		fmt.Println()
+	}
+	fmt.Println(s)
+}
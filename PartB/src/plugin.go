@@ -0,0 +1,163 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// A plugin source is any program that prints a quote, so sources can be added in any language
+// Version 1 of the protocol, see "Plugin sources" in the README:
+//   - the program is started without a shell, with the request {"protocol":1,"base":"ETH","quote":"USD"}
+//     on stdin and the same in AUDETH_PLUGIN_PROTOCOL, AUDETH_BASE and AUDETH_QUOTE
+//   - it prints one JSON object to stdout, {"price":3301.25} with optional "bid" and "ask",
+//     or {"error":"why not"}, and exits; a non-zero exit status is a failure, with stderr as the reason
+const pluginProtocol = 1
+
+// pluginOutputLimit caps what is read of a plugin's stdout and stderr, the same as an exchange's body
+const pluginOutputLimit = parsers.MaxBodySize
+
+// PluginFetcher runs a plugin for each quote
+type PluginFetcher struct {
+	name    string
+	command []string
+	quote   string // currency the plugin quotes in, "USD", "USDT" or "AUD"
+	timeout time.Duration
+}
+
+// NewPluginFetcher creates a plugin source running command, argv style, with the default API timeout
+func NewPluginFetcher(name string, command []string, quote string) PluginFetcher {
+	if quote == "" {
+		quote = "USD"
+	}
+	return PluginFetcher{name: name, command: command, quote: quote, timeout: 10 * time.Second}
+}
+
+func (p PluginFetcher) Name() string {
+	return p.name
+}
+
+// QuoteCurrency is what the plugin's price is in, see audeth.QuotedFetcher
+func (p PluginFetcher) QuoteCurrency() string {
+	return p.quote
+}
+
+func (p PluginFetcher) FetchPrice() (float64, error) {
+	return p.FetchPriceContext(context.Background())
+}
+
+func (p PluginFetcher) FetchPriceContext(ctx context.Context) (float64, error) {
+	q, err := p.FetchQuoteContext(ctx)
+	return q.Last, err
+}
+
+// FetchQuoteContext runs the plugin once; it is killed when ctx ends or the timeout passes
+func (p PluginFetcher) FetchQuoteContext(ctx context.Context) (parsers.Quote, error) {
+	// A plugin makes its own connections, which the privacy proxy can't see
+	if private {
+		return parsers.Quote{}, fmt.Errorf("plugin sources are not used with --private")
+	}
+	request, err := json.Marshal(map[string]any{"protocol": pluginProtocol, "base": "ETH", "quote": p.quote})
+	if err != nil {
+		return parsers.Quote{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("AUDETH_PLUGIN_PROTOCOL=%d", pluginProtocol), "AUDETH_BASE=ETH", "AUDETH_QUOTE="+p.quote)
+	cmd.Stdin = bytes.NewReader(request)
+	var stdout, stderr limitedBuffer
+	stdout.limit, stderr.limit = pluginOutputLimit, 4096
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return parsers.Quote{}, fmt.Errorf("plugin timed out after %s", p.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return parsers.Quote{}, fmt.Errorf("plugin failed: %v: %s", err, msg)
+		}
+		return parsers.Quote{}, fmt.Errorf("plugin failed: %v", err)
+	}
+	if stdout.truncated {
+		return parsers.Quote{}, fmt.Errorf("plugin output is over %d bytes", pluginOutputLimit)
+	}
+	q, err := decodePluginQuote(stdout.Bytes())
+	var reported *parsers.APIError
+	if errors.As(err, &reported) {
+		return parsers.Quote{}, err
+	}
+	if err != nil {
+		return parsers.Quote{}, fmt.Errorf("parsing plugin output failed: %w", err)
+	}
+	return q, nil
+}
+
+// decodePluginQuote reads a plugin's answer; an "error" it reports comes back as a parsers.APIError
+func decodePluginQuote(b []byte) (parsers.Quote, error) {
+	var data struct {
+		Price *float64 `json:"price"`
+		Bid   float64  `json:"bid"`
+		Ask   float64  `json:"ask"`
+		Error string   `json:"error"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return parsers.Quote{}, err
+	}
+	if data.Error != "" {
+		return parsers.Quote{}, &parsers.APIError{Exchange: "plugin", Message: data.Error}
+	}
+	if data.Price == nil {
+		return parsers.Quote{}, errors.New(`no "price" in the output`)
+	}
+	if *data.Price <= 0 {
+		return parsers.Quote{}, fmt.Errorf("invalid price: %f", *data.Price)
+	}
+	return parsers.Quote{Last: *data.Price, Bid: data.Bid, Ask: data.Ask}, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and drops the rest,
+// so a plugin printing without end can't use up memory
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - int64(b.Len()); int64(len(p)) > room {
+		b.truncated = true
+		b.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// newPluginFromConfig checks a plugin source's config and creates it
+func newPluginFromConfig(name string, source SourceConfig) (PluginFetcher, error) {
+	if source.URL != "" || source.HMAC != nil {
+		return PluginFetcher{}, fmt.Errorf("sources.%s: a plugin source takes a command, not a url or hmac", name)
+	}
+	if len(source.Command) == 0 || source.Command[0] == "" {
+		return PluginFetcher{}, fmt.Errorf("sources.%s: command is empty", name)
+	}
+	switch source.Quote {
+	case "", "USD", "USDT", "AUD":
+	default:
+		return PluginFetcher{}, fmt.Errorf("sources.%s: unsupported quote currency %q (use USD, USDT or AUD)", name, source.Quote)
+	}
+	return NewPluginFetcher(name, source.Command, source.Quote), nil
+}
@@ -0,0 +1,83 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// defaultPrivacyProxy is Tor's SOCKS port; Go resolves host names through a SOCKS5 proxy, so DNS doesn't leak either
+const defaultPrivacyProxy = "socks5://127.0.0.1:9050"
+
+// PrivacyConfig configures --private
+type PrivacyConfig struct {
+	Proxy string `json:"proxy"` // SOCKS5 or HTTP proxy every fetch goes through, default Tor on 127.0.0.1:9050
+}
+
+// private is set by --private: fetches go through the proxy without identifying headers,
+// and nothing about them is written to disk
+var private bool
+
+// identifyingHeaders are dropped from every outbound request in private mode
+var identifyingHeaders = []string{"Referer", "Cookie", "Accept-Language", "X-Forwarded-For", "Via", "From"}
+
+// anonymousTransport strips identifying headers before a request is sent
+type anonymousTransport struct {
+	next http.RoundTripper
+}
+
+func (t anonymousTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	for _, h := range identifyingHeaders {
+		out.Header.Del(h)
+	}
+	// An empty User-Agent stops net/http sending its default "Go-http-client/1.1", which singles this program out
+	out.Header.Set("User-Agent", "")
+	return t.next.RoundTrip(out)
+}
+
+// enablePrivacy routes the shared transport and the stream dialer through the configured proxy
+// Keep-alives are turned off so separate fetches can't be linked by sharing a connection
+// It expects the *http.Transport set up by configureTLS, before any wrappers are added
+func enablePrivacy() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	raw := cfg.Privacy.Proxy
+	if raw == "" {
+		raw = defaultPrivacyProxy
+	}
+	proxy, err := url.Parse(raw)
+	if err != nil || proxy.Host == "" {
+		return fmt.Errorf("privacy.proxy: invalid proxy URL %q", raw)
+	}
+	switch proxy.Scheme {
+	case "socks5", "socks5h", "http", "https":
+	default:
+		return fmt.Errorf("privacy.proxy: unsupported scheme %q (use socks5 or http)", proxy.Scheme)
+	}
+
+	transport, ok := httpTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("--private must be set up before the other transports")
+	}
+	transport.Proxy = http.ProxyURL(proxy)
+	transport.DisableKeepAlives = true
+	httpTransport = anonymousTransport{next: transport}
+
+	if streamDialer != nil {
+		// The WebSocket library only knows "socks5", which already sends host names to the proxy
+		streamProxy := *proxy
+		if streamProxy.Scheme == "socks5h" {
+			streamProxy.Scheme = "socks5"
+		}
+		streamDialer.Proxy = http.ProxyURL(&streamProxy)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestPrivateLeavesNoTrace records a fetch and a conversion with private set and checks nothing was kept
+func TestPrivateLeavesNoTrace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AUDETH_HOME", home)
+	private = true
+	t.Cleanup(func() { private = false })
+
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	var warnings bytes.Buffer
+	persistSample(store, Sample{Time: at, RateAUD: 5000})
+	recordConversion(store, ConversionRecord{Time: at, AUD: 100, ETH: 0.02, RateAUD: 5000}, &warnings)
+
+	if samples, _ := store.Samples(at.Add(-time.Hour), at.Add(time.Hour)); len(samples) != 0 {
+		t.Errorf("the history kept %d samples", len(samples))
+	}
+	if conversions, _ := store.Conversions(at.Add(-time.Hour), at.Add(time.Hour)); len(conversions) != 0 {
+		t.Errorf("the history kept %d conversions", len(conversions))
+	}
+	if entries, _ := os.ReadDir(home); len(entries) != 0 {
+		t.Errorf("wrote %v to the data directory", entries)
+	}
+
+	// Without --private the same conversion is recorded
+	private = false
+	recordConversion(store, ConversionRecord{Time: at, AUD: 100, ETH: 0.02, RateAUD: 5000}, &warnings)
+	if conversions, _ := store.Conversions(at.Add(-time.Hour), at.Add(time.Hour)); len(conversions) != 1 {
+		t.Errorf("got %d conversions without --private, want 1", len(conversions))
+	}
+}
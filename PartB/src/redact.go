@@ -0,0 +1,77 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// redacted replaces every secret in logs, recordings and error messages
+const redacted = "[REDACTED]"
+
+// knownSecrets holds every value resolveSecret has handed out, so it can be scrubbed wherever it ends up
+// Matching on the values themselves catches secrets in any position: query strings, paths,
+// headers, or a webhook URL quoted whole in a net/http error
+var knownSecrets struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// registerSecret adds a value to be redacted; very short values are skipped, they would mangle ordinary text
+func registerSecret(value string) {
+	if len(value) < 4 {
+		return
+	}
+	knownSecrets.mu.Lock()
+	defer knownSecrets.mu.Unlock()
+	if !slices.Contains(knownSecrets.values, value) {
+		knownSecrets.values = append(knownSecrets.values, value)
+		// Longest first, so a secret containing another is replaced whole
+		slices.SortFunc(knownSecrets.values, func(a, b string) int { return len(b) - len(a) })
+	}
+}
+
+// sensitiveParam matches query parameters that commonly carry API keys or signatures
+var sensitiveParam = regexp.MustCompile(`(?i)([?&](?:api[_-]?key|apikey|key|token|access_token|access_key|secret|signature|sig|x_cg_(?:pro|demo)_api_key)=)[^&#\s"']+`)
+
+// sensitiveHeader matches auth headers written as "Name: value" or JSON "Name":"value"
+var sensitiveHeader = regexp.MustCompile(`(?i)((?:proxy-)?authorization|x-api-key|api-key|x-mbx-apikey|cb-access-key|cb-access-sign|x-cmc_pro_api_key|x-coinapi-key)("?\s*[:=]\s*"?)(?:(?:bearer|basic)\s+)?[^\s",]+`)
+
+// redact scrubs known secret values, sensitive query parameters and auth headers from s
+func redact(s string) string {
+	knownSecrets.mu.RLock()
+	for _, v := range knownSecrets.values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	knownSecrets.mu.RUnlock()
+	s = sensitiveParam.ReplaceAllString(s, "${1}"+redacted)
+	return sensitiveHeader.ReplaceAllString(s, "${1}${2}"+redacted)
+}
+
+// redactedError is an error whose message has been scrubbed
+// Unwrap keeps errors.Is and errors.As working on the original
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e redactedError) Error() string { return e.msg }
+func (e redactedError) Unwrap() error { return e.err }
+
+// redactError returns err with a scrubbed message, or err itself when there was nothing to scrub
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if clean := redact(msg); clean != msg {
+		return redactedError{msg: clean, err: err}
+	}
+	return err
+}
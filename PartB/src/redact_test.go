@@ -0,0 +1,151 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// failingTransport fails every request, the way an unreachable host would
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+// echoHandler answers with the request URL in the body, like an API that echoes its parameters
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, `{"request":%q}`, r.URL.String())
+})
+
+// withFailingTransport makes every outbound request fail until the test ends
+func withFailingTransport(t *testing.T) {
+	saved := httpTransport
+	httpTransport = failingTransport{}
+	t.Cleanup(func() { httpTransport = saved })
+}
+
+// TestRedaction plants a secret everywhere a request or error is serialized and checks none comes out
+// registered is a known secret, as resolveSecret would have handed out; unregistered is only caught by the patterns
+func TestRedaction(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, registered, unregistered string) string
+	}{
+		{"recording", func(t *testing.T, reg, unreg string) string {
+			dir := t.TempDir()
+			client := &http.Client{Transport: recordingTransport{dir: dir, next: handlerTransport{handler: echoHandler}}}
+			resp, err := client.Get("http://example.test/" + reg + "/price?apikey=" + unreg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			var out strings.Builder
+			files, _ := filepath.Glob(filepath.Join(dir, "*"))
+			for _, f := range files {
+				data, err := os.ReadFile(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out.WriteString(filepath.Base(f) + "\n" + string(data))
+			}
+			return out.String()
+		}},
+		{"replay miss", func(t *testing.T, reg, unreg string) string {
+			req, _ := http.NewRequest(http.MethodGet, "http://example.test/"+reg+"?token="+unreg, nil)
+			_, err := replayingTransport{dir: t.TempDir()}.RoundTrip(req)
+			return fmt.Sprint(err)
+		}},
+		{"fetch error", func(t *testing.T, reg, unreg string) string {
+			withFailingTransport(t)
+			api := NewAPI("Probe", "http://example.test/"+reg+"/ticker?api_key="+unreg)
+			_, results, _ := NewConverter([]PriceFetcher{api}, audethtest.FakeFX{Rate: 1.5}, 0, nil).Rate()
+			if len(results) == 0 || results[0].Err == nil {
+				t.Fatal("the probe fetch didn't fail")
+			}
+			return results[0].Err.Error()
+		}},
+		{"history", func(t *testing.T, reg, unreg string) string {
+			err := fmt.Errorf(`Get "http://example.test/%s?key=%s": timeout`, reg, unreg)
+			probe := audethtest.NewFakeFetcher("Probe", 0).FailAlways(err)
+			sample, _ := NewConverter([]PriceFetcher{probe}, audethtest.FakeFX{Rate: 1.5}, 0, nil).Sample()
+			data, jerr := json.Marshal(sample)
+			if jerr != nil {
+				t.Fatal(jerr)
+			}
+			return string(data)
+		}},
+		{"webhook", func(t *testing.T, reg, unreg string) string {
+			withFailingTransport(t)
+			// Webhook URLs come through resolveSecret, so the whole URL is what gets registered
+			url := "http://hooks.example.test/services/" + reg
+			registerSecret(url)
+			return fmt.Sprint(NewWebhookNotifier(url).Notify("probe", "probe"))
+		}},
+		{"auth headers", func(t *testing.T, reg, unreg string) string {
+			return redact("Authorization: Bearer " + unreg + "\n" + `{"X-API-Key":"` + unreg + `"}` + "\nX-MBX-APIKEY: " + unreg)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg, unreg := randomSecret(), randomSecret()
+			registerSecret(reg)
+			out := tt.run(t, reg, unreg)
+			if strings.Contains(out, reg) || strings.Contains(out, unreg) || !strings.Contains(out, redacted) {
+				t.Errorf("leaked a secret: %s", snippet([]byte(out)))
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"https://api.example.test/price?symbol=ETH&api_key=abc123", "https://api.example.test/price?symbol=ETH&api_key=" + redacted},
+		{"https://api.example.test/price?token=abc123#frag", "https://api.example.test/price?token=" + redacted + "#frag"},
+		{"Proxy-Authorization: Basic dXNlcjpwYXNz", "Proxy-Authorization: " + redacted},
+		{`{"X-CMC_PRO_API_KEY":"abc123"}`, `{"X-CMC_PRO_API_KEY":"` + redacted + `"}`},
+		{"https://api.example.test/price?monkey=ok", "https://api.example.test/price?monkey=ok"},
+		{"nothing secret here", "nothing secret here"},
+	}
+	for _, tt := range tests {
+		if got := redact(tt.in); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	base := errors.New("dial failed")
+	err := redactError(fmt.Errorf("Get https://example.test/?key=abc123: %w", base))
+	if strings.Contains(err.Error(), "abc123") {
+		t.Errorf("leaked the key: %v", err)
+	}
+	if !errors.Is(err, base) {
+		t.Error("the redacted error no longer wraps the original")
+	}
+	if plain := errors.New("no secrets"); redactError(plain) != plain {
+		t.Error("an error with nothing to scrub was wrapped")
+	}
+}
+
+func randomSecret() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "sk" + hex.EncodeToString(b)
+}
@@ -0,0 +1,243 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisConfig points the rate cache at a Redis server shared by several replicas
+type RedisConfig struct {
+	Addr     string `json:"addr"`     // host:port, default localhost:6379
+	Password string `json:"password"` // may be a secret reference such as "env:REDIS_PASSWORD"
+	DB       int    `json:"db"`
+	Prefix   string `json:"prefix"` // key prefix, default "audeth:"
+}
+
+// errRedisNil is returned for a nil bulk reply, i.e. a missing key
+var errRedisNil = errors.New("redis: nil")
+
+// redisConn is a minimal RESP client: just enough for GET, SET, DEL and EVAL
+// A single connection guarded by a mutex is plenty for one refresh at a time
+type redisConn struct {
+	cfg  RedisConfig
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// do sends one command and reads its reply, reconnecting once if the connection broke
+func (r *redisConn) do(args ...string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reply, err := r.roundTrip(args)
+	var netErr net.Error
+	if err != nil && (errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed)) {
+		r.close()
+		reply, err = r.roundTrip(args)
+	}
+	return reply, err
+}
+
+func (r *redisConn) roundTrip(args []string) (any, error) {
+	if r.conn == nil {
+		if err := r.connect(); err != nil {
+			return nil, err
+		}
+	}
+	r.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := r.conn.Write(encodeRESP(args)); err != nil {
+		return nil, err
+	}
+	return readRESP(r.rd)
+}
+
+func (r *redisConn) connect() error {
+	conn, err := net.DialTimeout("tcp", r.cfg.Addr, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("connecting to redis failed: %v", err)
+	}
+	r.conn, r.rd = conn, bufio.NewReader(conn)
+
+	if r.cfg.Password != "" {
+		if _, err := r.roundTrip([]string{"AUTH", r.cfg.Password}); err != nil {
+			r.close()
+			return fmt.Errorf("redis auth failed: %v", err)
+		}
+	}
+	if r.cfg.DB != 0 {
+		if _, err := r.roundTrip([]string{"SELECT", strconv.Itoa(r.cfg.DB)}); err != nil {
+			r.close()
+			return fmt.Errorf("redis select failed: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *redisConn) close() {
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	r.conn, r.rd = nil, nil
+}
+
+// encodeRESP encodes a command as a RESP array of bulk strings
+func encodeRESP(args []string) []byte {
+	b := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		b = append(b, "$"+strconv.Itoa(len(a))+"\r\n"+a+"\r\n"...)
+	}
+	return b
+}
+
+// readRESP reads one reply; bulk strings come back as string and integers as int64
+func readRESP(rd *bufio.Reader) (any, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, fmt.Errorf("redis: short reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errRedisNil
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readRESP(rd); err != nil && !errors.Is(err, errRedisNil) {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
+
+// unlockScript deletes the lock only if it still holds our token,
+// so a slow refresher can't release a lock that has since passed to another replica
+const unlockScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// RedisRateCache shares the aggregated rate and the refresh lock between replicas
+type RedisRateCache struct {
+	conn     *redisConn
+	rateKey  string
+	lockKey  string
+	token    string
+	tokenMu  sync.Mutex
+	heldLock bool
+}
+
+// NewRedisRateCache creates a cache using the given server; no connection is made until first use
+func NewRedisRateCache(cfg RedisConfig) *RedisRateCache {
+	if cfg.Addr == "" {
+		cfg.Addr = "localhost:6379"
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "audeth:"
+	}
+	return &RedisRateCache{
+		conn:    &redisConn{cfg: cfg},
+		rateKey: cfg.Prefix + "rate",
+		lockKey: cfg.Prefix + "rate:lock",
+	}
+}
+
+func (c *RedisRateCache) Get() (Sample, bool, error) {
+	var s Sample
+	reply, err := c.conn.do("GET", c.rateKey)
+	if errors.Is(err, errRedisNil) {
+		return s, false, nil
+	}
+	if err != nil {
+		return s, false, err
+	}
+	data, _ := reply.(string)
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return s, false, fmt.Errorf("decoding cached rate failed: %v", err)
+	}
+	return s, s.RateAUD > 0, nil
+}
+
+// Set stores the sample with a Redis expiry, so stale rates disappear on their own
+func (c *RedisRateCache) Set(s Sample, ttl time.Duration) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = c.conn.do("SET", c.rateKey, string(data), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+// Lock uses SET NX PX: only one replica can create the key, and it expires if that replica dies
+func (c *RedisRateCache) Lock(ttl time.Duration) (bool, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return false, err
+	}
+
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.token = hex.EncodeToString(token)
+	_, err := c.conn.do("SET", c.lockKey, c.token, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if errors.Is(err, errRedisNil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	c.heldLock = true
+	return true, nil
+}
+
+func (c *RedisRateCache) Unlock() error {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if !c.heldLock {
+		return nil
+	}
+	c.heldLock = false
+	_, err := c.conn.do("EVAL", unlockScript, "1", c.lockKey, c.token)
+	return err
+}
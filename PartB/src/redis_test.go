@@ -0,0 +1,186 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis answers the commands RedisRateCache sends, keeping every command it was sent
+// Expiry isn't simulated; the PX a key was set with is in the log
+type fakeRedis struct {
+	l net.Listener
+
+	mu       sync.Mutex
+	data     map[string]string
+	commands [][]string
+	conns    []net.Conn
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRedis{l: l, data: make(map[string]string)}
+	t.Cleanup(func() {
+		l.Close()
+		r.dropConnections()
+	})
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			r.mu.Lock()
+			r.conns = append(r.conns, conn)
+			r.mu.Unlock()
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	rd := bufio.NewReader(conn)
+	for {
+		// A command is an array of bulk strings, which readRESP reads as well as any reply
+		req, err := readRESP(rd)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, a := range req.([]any) {
+			args = append(args, a.(string))
+		}
+		conn.Write([]byte(r.reply(args)))
+	}
+}
+
+func (r *fakeRedis) reply(args []string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, args)
+	switch strings.ToUpper(args[0]) {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		v, ok := r.data[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "SET":
+		if _, exists := r.data[args[1]]; exists && len(args) > 3 && args[3] == "NX" {
+			return "$-1\r\n"
+		}
+		r.data[args[1]] = args[2]
+		return "+OK\r\n"
+	case "EVAL":
+		// Only the unlock script is sent: delete KEYS[1] if it still holds ARGV[1]
+		if args[1] != unlockScript {
+			return "-ERR unknown script\r\n"
+		}
+		if r.data[args[3]] != args[4] {
+			return ":0\r\n"
+		}
+		delete(r.data, args[3])
+		return ":1\r\n"
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+}
+
+func (r *fakeRedis) sent() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]string(nil), r.commands...)
+}
+
+// dropConnections closes every connection from the server's side, as a restarted Redis would
+func (r *fakeRedis) dropConnections() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.conns {
+		c.Close()
+	}
+	r.conns = nil
+}
+
+func TestRedisRateCache(t *testing.T) {
+	server := newFakeRedis(t)
+	cache := NewRedisRateCache(RedisConfig{Addr: server.l.Addr().String(), Password: "hunter2", DB: 3, Prefix: "test:"})
+
+	if _, ok, err := cache.Get(); ok || err != nil {
+		t.Fatalf("Get on an empty cache: %v, %v", ok, err)
+	}
+	sample := Sample{Time: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), RateAUD: 5012.25, Sources: []SourceSample{{Name: "Kraken", USD: 3200.5}}}
+	if err := cache.Set(sample, 90*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	got, ok, err := cache.Get()
+	if err != nil || !ok || !reflect.DeepEqual(got, sample) {
+		t.Fatalf("Get after Set: %+v, %v, %v", got, ok, err)
+	}
+
+	sent := server.sent()
+	if want := [][]string{{"AUTH", "hunter2"}, {"SELECT", "3"}, {"GET", "test:rate"}}; !reflect.DeepEqual(sent[:3], want) {
+		t.Fatalf("first commands %q, want %q", sent[:3], want)
+	}
+	if set := sent[3]; set[0] != "SET" || set[1] != "test:rate" || set[3] != "PX" || set[4] != "90000" {
+		t.Fatalf("Set sent %q, want the rate with a 90000 ms expiry", set)
+	}
+
+	// A restarted server drops the connection, the next command reconnects and logs in again
+	server.dropConnections()
+	if _, ok, err := cache.Get(); !ok || err != nil {
+		t.Fatalf("Get after the connection dropped: %v, %v", ok, err)
+	}
+	if sent := server.sent(); sent[len(sent)-3][0] != "AUTH" {
+		t.Fatalf("didn't log in again after reconnecting: %q", sent[len(sent)-3:])
+	}
+}
+
+func TestRedisLock(t *testing.T) {
+	server := newFakeRedis(t)
+	cfg := RedisConfig{Addr: server.l.Addr().String()}
+	a, b := NewRedisRateCache(cfg), NewRedisRateCache(cfg)
+
+	if ok, err := a.Lock(time.Minute); !ok || err != nil {
+		t.Fatalf("first Lock: %v, %v", ok, err)
+	}
+	if ok, err := b.Lock(time.Minute); ok || err != nil {
+		t.Fatalf("second replica took a held lock: %v, %v", ok, err)
+	}
+	// b never held the lock, so its Unlock can't release a's
+	if err := b.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := b.Lock(time.Minute); ok {
+		t.Fatal("the lock was released by a replica that didn't hold it")
+	}
+	if err := a.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := b.Lock(time.Minute); !ok || err != nil {
+		t.Fatalf("Lock after the holder unlocked: %v, %v", ok, err)
+	}
+}
+
+func TestReadRESPError(t *testing.T) {
+	if _, err := readRESP(bufio.NewReader(strings.NewReader("-WRONGPASS invalid password\r\n"))); err == nil || err.Error() != "redis: WRONGPASS invalid password" {
+		t.Fatalf("error reply: %v", err)
+	}
+}
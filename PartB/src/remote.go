@@ -0,0 +1,222 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RemoteConfig points at a sources document shared by many converters, so they can all be moved off
+// an exchange that changed its API by publishing one file; see "Remote sources" in the README
+// The document is {"sources": {...}}, the same as the local sources section, signed with an ed25519
+// key: the base64 signature of its exact bytes is served next to it at the same URL plus ".sig"
+type RemoteConfig struct {
+	URL       string `json:"url"`
+	PublicKey string `json:"public_key"` // base64 ed25519 key the document must be signed with
+	Refresh   string `json:"refresh"`    // how long a fetched copy is used before fetching again, default "1h"
+}
+
+// defaultRemoteRefresh is how often the remote sources are fetched again without refresh set
+const defaultRemoteRefresh = time.Hour
+
+// remoteConfigLimit caps the document's size; a sources list is a few KB
+const remoteConfigLimit = 1 << 20
+
+// remoteCache is the last verified copy of the document, kept in remote_sources.json
+// It is verified again whenever it is read, so a changed file is refused like a changed download
+type remoteCache struct {
+	URL       string    `json:"url"`
+	Fetched   time.Time `json:"fetched"`
+	Body      string    `json:"body"`
+	Signature string    `json:"signature"`
+}
+
+func remoteCachePath() string {
+	return filepath.Join(dataDir(), "remote_sources.json")
+}
+
+// withRemoteSources returns cfg with the remote sources added; a name in the local sources as well keeps the local entry
+// Within refresh the cached copy is used; when fetching fails a cached copy is still used, with a warning
+func withRemoteSources(cfg Config) (Config, error) {
+	remote := cfg.Remote
+	if remote.URL == "" {
+		return cfg, nil
+	}
+	u, err := url.Parse(remote.URL)
+	if err != nil {
+		return cfg, fmt.Errorf("remote.url: %v", redactError(err))
+	}
+	if err := cfg.SourcePolicy.check(u); err != nil {
+		return cfg, fmt.Errorf("remote.url: %v", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(remote.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return cfg, fmt.Errorf("remote.public_key: want a base64 ed25519 public key, see \"attest keygen\"")
+	}
+	refresh := defaultRemoteRefresh
+	if remote.Refresh != "" {
+		if refresh, err = parseInterval(remote.Refresh); err != nil {
+			return cfg, fmt.Errorf("remote.refresh: %v", err)
+		}
+	}
+
+	cached, cacheErr := loadRemoteCache(remote.URL, key)
+	body := []byte(cached.Body)
+	if cacheErr != nil || defaultClock.Now().Sub(cached.Fetched) >= refresh {
+		fetched, sig, err := fetchRemoteSources(remote.URL, key)
+		switch {
+		case err == nil:
+			body = fetched
+			if err := saveRemoteCache(remoteCache{URL: remote.URL, Fetched: defaultClock.Now().UTC(), Body: string(fetched), Signature: sig}); err != nil {
+				fmt.Fprintln(progressOut(), tr("warning.remote_cache", redactError(err)))
+			}
+		case cacheErr != nil:
+			return cfg, fmt.Errorf("fetching remote sources failed: %v", err)
+		default:
+			fmt.Fprintln(progressOut(), tr("warning.remote_sources", cached.Fetched.Local().Format("2006-01-02 15:04"), redactError(err)))
+		}
+	}
+
+	sources, err := parseRemoteSources(body)
+	if err != nil {
+		return cfg, err
+	}
+	maps.Copy(sources, cfg.Sources)
+	cfg.Sources = sources
+	return cfg, nil
+}
+
+// fetchRemoteSources downloads the document and its signature and checks one against the other
+func fetchRemoteSources(rawURL string, key ed25519.PublicKey) ([]byte, string, error) {
+	body, err := fetchRemoteFile(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	sig, err := fetchRemoteFile(rawURL + ".sig")
+	if err != nil {
+		return nil, "", fmt.Errorf("signature: %v", err)
+	}
+	signature := strings.TrimSpace(string(sig))
+	if err := verifyRemoteSources(body, signature, key); err != nil {
+		return nil, "", err
+	}
+	return body, signature, nil
+}
+
+func fetchRemoteFile(rawURL string) ([]byte, error) {
+	resp, err := newHTTPClient(10 * time.Second).Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, remoteConfigLimit+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading the response failed: %v", err)
+	}
+	if len(data) > remoteConfigLimit {
+		return nil, fmt.Errorf("response is over %d bytes", remoteConfigLimit)
+	}
+	return data, nil
+}
+
+// verifyRemoteSources checks that signature, in base64, is key's signature of body
+func verifyRemoteSources(body []byte, signature string, key ed25519.PublicKey) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding")
+	}
+	if !ed25519.Verify(key, body, sig) {
+		return fmt.Errorf("signature doesn't match, the remote sources were changed or signed with another key")
+	}
+	return nil
+}
+
+// parseRemoteSources reads the document's sources
+// Plugins are refused: a command from a remote file would run whatever its publisher wanted on every converter
+// So are hmac settings and secret references, as resolving those runs cmd: commands and reads the local keychain
+func parseRemoteSources(body []byte) (map[string]SourceConfig, error) {
+	var doc struct {
+		Sources map[string]SourceConfig `json:"sources"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing remote sources failed: %v", err)
+	}
+	if doc.Sources == nil {
+		doc.Sources = make(map[string]SourceConfig)
+	}
+	for name, source := range doc.Sources {
+		if source.Command != nil {
+			return nil, fmt.Errorf("remote sources.%s: plugin commands can only be set in the local config", name)
+		}
+		if source.HMAC != nil {
+			return nil, fmt.Errorf("remote sources.%s: hmac can only be set in the local config", name)
+		}
+		for _, value := range []string{source.URL, source.Quote, source.Parse, source.Format} {
+			if isSecretRef(value) {
+				return nil, fmt.Errorf("remote sources.%s: secret references can only be used in the local config", name)
+			}
+		}
+	}
+	return doc.Sources, nil
+}
+
+// loadRemoteCache reads and verifies the cached copy for rawURL
+func loadRemoteCache(rawURL string, key ed25519.PublicKey) (remoteCache, error) {
+	var c remoteCache
+	data, err := os.ReadFile(remoteCachePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, fmt.Errorf("nothing cached yet")
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("parsing %s failed: %v", remoteCachePath(), err)
+	}
+	// A cache from before remote.url was changed is another document
+	if c.URL != rawURL {
+		return c, fmt.Errorf("the cached copy is from %s", c.URL)
+	}
+	if err := verifyRemoteSources([]byte(c.Body), c.Signature, key); err != nil {
+		return c, fmt.Errorf("%s: %v", remoteCachePath(), err)
+	}
+	return c, nil
+}
+
+// saveRemoteCache writes the cache through a temporary file, as saveRateCache does
+func saveRemoteCache(c remoteCache) error {
+	if err := os.MkdirAll(dataDir(), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := remoteCachePath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, remoteCachePath())
+}
@@ -0,0 +1,37 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteSources(t *testing.T) {
+	tests := []struct {
+		doc, want string
+	}{
+		{`{"sources":{"Kraken":{"url":"https://api.kraken.com/0/public/Ticker?pair=ETHUSD","weight":2}}}`, ""},
+		{`{"sources":{"Probe":{"command":["sh","-c","id"]}}}`, "plugin commands can only be set in the local config"},
+		{`{"sources":{"Probe":{"url":"https://example.test/","hmac":{"scheme":"custom","key":"k","secret":"cmd:cat ~/.ssh/id_ed25519"}}}}`, "hmac can only be set in the local config"},
+		{`{"sources":{"Probe":{"url":"https://example.test/","hmac":{"scheme":"coinbase","key":"plain","secret":"plain"}}}}`, "hmac can only be set in the local config"},
+		{`{"sources":{"Probe":{"url":"env:AUDETH_URL"}}}`, "secret references can only be used in the local config"},
+		{`{"sources":{"Probe":{"url":"https://example.test/","parse":"keychain:coinbase"}}}`, "secret references"},
+		{`{"sources":{"Probe":{"shell":"id"}}}`, "unknown field"},
+	}
+	for _, tt := range tests {
+		sources, err := parseRemoteSources([]byte(tt.doc))
+		if tt.want == "" {
+			if err != nil || len(sources) != 1 {
+				t.Errorf("%s: got %v, %v, want one source", tt.doc, sources, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.doc, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary is the daily/weekly report built from recorded samples
+type Summary struct {
+	Period      string              `json:"period"`
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Samples     int                 `json:"samples"`
+	Open        float64             `json:"open"`
+	Close       float64             `json:"close"`
+	High        float64             `json:"high"`
+	Low         float64             `json:"low"`
+	AvgSpreadPc float64             `json:"avg_spread_pct"`
+	Reliability []SourceReliability `json:"reliability"`
+
+	// series is the samples the summary was built from, for the rate tables and the chart
+	series []Sample
+}
+
+// SourceReliability is the share of samples in which a source returned a price
+type SourceReliability struct {
+	Name    string  `json:"name"`
+	OK      int     `json:"ok"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// summarize computes open/close/high/low, average spread and reliability
+// Spread is the gap between the highest and lowest source quote as a percentage of their mean
+func summarize(period string, from, to time.Time, samples []Sample) (Summary, error) {
+	s := Summary{Period: period, From: from, To: to, Samples: len(samples), series: samples}
+	if len(samples) == 0 {
+		return s, fmt.Errorf("no recorded samples between %s and %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	s.Open = samples[0].RateAUD
+	s.Close = samples[len(samples)-1].RateAUD
+	s.High, s.Low = s.Open, s.Open
+	if samples[0].Aggregated > 0 {
+		s.High, s.Low = samples[0].High, samples[0].Low
+	}
+
+	var spreadSum float64
+	var spreadCount int
+	counts := make(map[string]*SourceReliability)
+
+	for _, sample := range samples {
+		// Daily aggregates from compacted history keep their own high and low
+		high, low := sample.RateAUD, sample.RateAUD
+		if sample.Aggregated > 0 {
+			high, low = sample.High, sample.Low
+		}
+		if high > s.High {
+			s.High = high
+		}
+		if low < s.Low {
+			s.Low = low
+		}
+
+		var min, max, sum float64
+		var ok int
+		for _, src := range sample.Sources {
+			r, found := counts[src.Name]
+			if !found {
+				r = &SourceReliability{Name: src.Name}
+				counts[src.Name] = r
+			}
+			r.Total++
+			if src.Error != "" {
+				continue
+			}
+			r.OK++
+			if ok == 0 || src.USD < min {
+				min = src.USD
+			}
+			if ok == 0 || src.USD > max {
+				max = src.USD
+			}
+			sum += src.USD
+			ok++
+		}
+		if ok > 1 {
+			spreadSum += (max - min) / (sum / float64(ok)) * 100
+			spreadCount++
+		}
+	}
+
+	if spreadCount > 0 {
+		s.AvgSpreadPc = spreadSum / float64(spreadCount)
+	}
+	for _, r := range counts {
+		r.Percent = float64(r.OK) / float64(r.Total) * 100
+		s.Reliability = append(s.Reliability, *r)
+	}
+	// Map iteration order is random in Go, so sort for stable output
+	sort.Slice(s.Reliability, func(i, j int) bool {
+		return s.Reliability[i].Name < s.Reliability[j].Name
+	})
+	return s, nil
+}
+
+// renderSummary formats the summary as text, markdown, html, json or xlsx
+func renderSummary(s Summary, format string) (string, error) {
+	var b strings.Builder
+	switch format {
+	case "text":
+		fmt.Fprintln(&b, tr("report.title", s.Period,
+			s.From.In(displayLocation).Format("2006-01-02 15:04"), s.To.In(displayLocation).Format("2006-01-02 15:04"), s.Samples))
+		fmt.Fprintln(&b, tr("report.open", s.Open))
+		fmt.Fprintln(&b, tr("report.close", s.Close))
+		fmt.Fprintln(&b, tr("report.high", s.High))
+		fmt.Fprintln(&b, tr("report.low", s.Low))
+		fmt.Fprintln(&b, tr("report.spread", s.AvgSpreadPc))
+		fmt.Fprintln(&b, tr("report.reliability"))
+		for _, r := range s.Reliability {
+			fmt.Fprintf(&b, "    %-10s %5.1f%% (%d/%d)\n", r.Name, r.Percent, r.OK, r.Total)
+		}
+	case "markdown":
+		fmt.Fprintf(&b, "## ETH/AUD %s summary\n\n", s.Period)
+		fmt.Fprintf(&b, "_%s to %s, %d samples_\n\n", s.From.In(displayLocation).Format("2006-01-02 15:04"),
+			s.To.In(displayLocation).Format("2006-01-02 15:04"), s.Samples)
+		fmt.Fprintln(&b, "| Open | Close | High | Low | Avg spread |")
+		fmt.Fprintln(&b, "|---:|---:|---:|---:|---:|")
+		fmt.Fprintf(&b, "| $%.2f | $%.2f | $%.2f | $%.2f | %.3f%% |\n\n", s.Open, s.Close, s.High, s.Low, s.AvgSpreadPc)
+		fmt.Fprintln(&b, "| Source | Reliability | OK / Total |")
+		fmt.Fprintln(&b, "|---|---:|---:|")
+		for _, r := range s.Reliability {
+			fmt.Fprintf(&b, "| %s | %.1f%% | %d / %d |\n", r.Name, r.Percent, r.OK, r.Total)
+		}
+		fmt.Fprint(&b, "\n### Rates\n\n")
+		fmt.Fprintln(&b, "| Time | ETH/AUD | Sources |")
+		fmt.Fprintln(&b, "|---|---:|---:|")
+		for _, row := range rateRows(s.series) {
+			fmt.Fprintf(&b, "| %s | $%s | %s |\n", row.Time, row.Rate, row.Sources)
+		}
+	case "html":
+		if err := renderSummaryHTML(&b, s); err != nil {
+			return "", err
+		}
+	case "xlsx":
+		if err := summaryWorkbook(s).Write(&b); err != nil {
+			return "", err
+		}
+	case "json":
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	default:
+		return "", fmt.Errorf("unknown format: %s (use text, markdown, html, json or xlsx)", format)
+	}
+	return b.String(), nil
+}
+
+// reportFlags are the flags of the "report" command
+type reportFlags struct {
+	period string
+	format string
+	notify bool
+}
+
+func (f *reportFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("report")
+	fs.StringVar(&f.period, "period", "daily", "report period: daily or weekly")
+	fs.StringVar(&f.format, "format", "text", "output format: text, markdown, html, json or xlsx")
+	fs.BoolVar(&f.notify, "notify", false, "also send the report through the configured notifiers")
+	return fs
+}
+
+// runReport implements the "report" command
+// Each subcommand gets its own flag.FlagSet so flags don't leak between commands
+func runReport(args []string) error {
+	var flags reportFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if !slices.Contains([]string{"text", "markdown", "html", "json", "xlsx"}, flags.format) {
+		return usageErrorf("unknown format: %s (use text, markdown, html, json or xlsx)", flags.format)
+	}
+	if flags.format == "xlsx" {
+		if flags.notify {
+			return usageErrorf("-notify sends the report as a message, use another -format")
+		}
+		if err := checkBinaryOut(os.Stdout); err != nil {
+			return err
+		}
+	}
+
+	var window time.Duration
+	switch flags.period {
+	case "daily":
+		window = 24 * time.Hour
+	case "weekly":
+		window = 7 * 24 * time.Hour
+	default:
+		return usageErrorf("unknown period: %s (use daily or weekly)", flags.period)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	to := defaultClock.Now()
+	from := to.Add(-window)
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	samples, err := store.Samples(from, to)
+	if err != nil {
+		return err
+	}
+	summary, err := summarize(flags.period, from, to, samples)
+	if err != nil {
+		return err
+	}
+	out, err := renderSummary(summary, flags.format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+
+	if flags.notify {
+		notifiers, err := buildNotifiers(cfg.Notifiers)
+		if err != nil {
+			return err
+		}
+		if len(notifiers) == 0 {
+			return fmt.Errorf("no notifiers configured in %s", dataDir())
+		}
+		subject := tr("report.subject", flags.period)
+		if err := notifyAll(notifiers, subject, out); err != nil {
+			return fmt.Errorf("sending report failed: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// rateRow is one sample as shown in the rate tables of the markdown and html reports
+type rateRow struct {
+	Time, Rate, Sources string
+}
+
+// rateRows formats the samples for the rate tables; Sources reads "2/3" for two answers out of three
+// A compacted daily sample shows its range instead, since its sources were dropped
+func rateRows(samples []Sample) []rateRow {
+	rows := make([]rateRow, 0, len(samples))
+	for _, s := range samples {
+		row := rateRow{Time: s.Time.In(displayLocation).Format("2006-01-02 15:04"), Rate: formatMoney(s.RateAUD)}
+		if s.Aggregated > 0 {
+			row.Sources = fmt.Sprintf("daily, %d samples, $%s - $%s", s.Aggregated, formatMoney(s.Low), formatMoney(s.High))
+		} else {
+			ok := 0
+			for _, src := range s.Sources {
+				if src.Error == "" {
+					ok++
+				}
+			}
+			row.Sources = fmt.Sprintf("%d/%d", ok, len(s.Sources))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Chart size in SVG user units; the SVG scales to the page width, so these only set the shape
+const (
+	chartWidth  = 640.0
+	chartHeight = 200.0
+	chartPad    = 8.0
+)
+
+// rateChartPoints returns the SVG polyline points for the rates, scaled to fill the chart
+// Samples are placed by time, so gaps in the history show as longer straight segments
+func rateChartPoints(s Summary) string {
+	span := s.To.Sub(s.From).Seconds()
+	low, high := s.Low, s.High
+	if high == low {
+		// A flat line in the middle instead of a division by zero
+		low, high = low-1, high+1
+	}
+	var b strings.Builder
+	for i, sample := range s.series {
+		x := chartPad
+		if span > 0 {
+			x += (chartWidth - 2*chartPad) * sample.Time.Sub(s.From).Seconds() / span
+		}
+		y := chartPad + (chartHeight-2*chartPad)*(high-sample.RateAUD)/(high-low)
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "%.1f,%.1f", x, y)
+	}
+	return b.String()
+}
+
+// summaryHTML is a standalone page: the styles and the chart are inline, so it can be emailed or attached as is
+// html/template escapes every value, source names included
+var summaryHTML = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ETH/AUD {{.S.Period}} summary</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 44rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+table { border-collapse: collapse; margin: 1rem 0; }
+th, td { padding: .3rem .8rem; border-bottom: 1px solid #ddd; }
+td.n, th.n { text-align: right; }
+svg { width: 100%; height: auto; background: #fafafa; }
+.muted { color: #666; }
+</style>
+</head>
+<body>
+<h1>ETH/AUD {{.S.Period}} summary</h1>
+<p class="muted">{{.From}} to {{.To}}, {{.S.Samples}} samples</p>
+<svg viewBox="0 0 {{.Width}} {{.Height}}" role="img" aria-label="ETH/AUD rate from ${{.Low}} to ${{.High}}">
+<polyline fill="none" stroke="#3b6fd4" stroke-width="2" points="{{.Points}}"/>
+<text x="4" y="14" font-size="11" fill="#666">${{.High}}</text>
+<text x="4" y="{{.Height}}" dy="-4" font-size="11" fill="#666">${{.Low}}</text>
+</svg>
+<table>
+<tr><th class="n">Open</th><th class="n">Close</th><th class="n">High</th><th class="n">Low</th><th class="n">Avg spread</th></tr>
+<tr><td class="n">${{.Open}}</td><td class="n">${{.Close}}</td><td class="n">${{.High}}</td><td class="n">${{.Low}}</td><td class="n">{{printf "%.3f" .S.AvgSpreadPc}}%</td></tr>
+</table>
+<h2>Source reliability</h2>
+<table>
+<tr><th>Source</th><th class="n">Reliability</th><th class="n">OK / Total</th></tr>
+{{range .S.Reliability}}<tr><td>{{.Name}}</td><td class="n">{{printf "%.1f" .Percent}}%</td><td class="n">{{.OK}} / {{.Total}}</td></tr>
+{{end}}</table>
+<h2>Rates</h2>
+<table>
+<tr><th>Time</th><th class="n">ETH/AUD</th><th class="n">Sources</th></tr>
+{{range .Rows}}<tr><td>{{.Time}}</td><td class="n">${{.Rate}}</td><td class="n">{{.Sources}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// renderSummaryHTML writes the summary as a standalone HTML page with an inline SVG chart of the rate
+func renderSummaryHTML(w io.Writer, s Summary) error {
+	return summaryHTML.Execute(w, struct {
+		S                      Summary
+		From, To               string
+		Open, Close, High, Low string
+		Width, Height          float64
+		Points                 string
+		Rows                   []rateRow
+	}{
+		S:     s,
+		From:  s.From.In(displayLocation).Format("2006-01-02 15:04"),
+		To:    s.To.In(displayLocation).Format("2006-01-02 15:04"),
+		Open:  formatMoney(s.Open),
+		Close: formatMoney(s.Close),
+		High:  formatMoney(s.High),
+		Low:   formatMoney(s.Low),
+		Width: chartWidth, Height: chartHeight,
+		Points: rateChartPoints(s),
+		Rows:   rateRows(s.series),
+	})
+}
@@ -0,0 +1,189 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// defaultRawRetention is how long individual samples are kept before being folded into daily aggregates
+const defaultRawRetention = 90 * 24 * time.Hour
+
+// RetentionConfig controls how long recorded history is kept
+// Raw samples older than Raw become one aggregate per day; aggregates older than Daily are deleted
+// An empty Daily keeps the aggregates forever
+type RetentionConfig struct {
+	Raw   string `json:"raw"`   // e.g. "90d"
+	Daily string `json:"daily"` // e.g. "1825d"
+}
+
+// retentionPeriods parses the config, applying the defaults
+func retentionPeriods(cfg RetentionConfig) (raw, daily time.Duration, err error) {
+	raw = defaultRawRetention
+	if cfg.Raw != "" {
+		if raw, err = parseInterval(cfg.Raw); err != nil {
+			return 0, 0, fmt.Errorf("retention.raw: %v", err)
+		}
+	}
+	if cfg.Daily != "" {
+		if daily, err = parseInterval(cfg.Daily); err != nil {
+			return 0, 0, fmt.Errorf("retention.daily: %v", err)
+		}
+	}
+	return raw, daily, nil
+}
+
+// dailyAggregates folds samples into one per UTC day
+// Already-aggregated samples are weighted by how many raw samples they stand for,
+// so compacting the same day twice gives the same result
+func dailyAggregates(samples []Sample) []Sample {
+	type sourceAcc struct {
+		sum     float64
+		ok      int
+		audSum  float64
+		audOK   int
+		lastErr string
+	}
+	type dayAcc struct {
+		sample  Sample
+		weight  int
+		sum     float64
+		sources map[string]*sourceAcc
+		order   []string
+	}
+
+	days := make(map[time.Time]*dayAcc)
+	var keys []time.Time
+	for _, s := range samples {
+		day := s.Time.UTC().Truncate(24 * time.Hour)
+		acc, ok := days[day]
+		if !ok {
+			acc = &dayAcc{sample: Sample{Time: day}, sources: make(map[string]*sourceAcc)}
+			days[day] = acc
+			keys = append(keys, day)
+		}
+
+		weight := max(s.Aggregated, 1)
+		high, low := s.RateAUD, s.RateAUD
+		if s.Aggregated > 0 {
+			high, low = s.High, s.Low
+		}
+		if acc.weight == 0 || high > acc.sample.High {
+			acc.sample.High = high
+		}
+		if acc.weight == 0 || low < acc.sample.Low {
+			acc.sample.Low = low
+		}
+		acc.sum += s.RateAUD * float64(weight)
+		acc.weight += weight
+
+		for _, src := range s.Sources {
+			sa, ok := acc.sources[src.Name]
+			if !ok {
+				sa = &sourceAcc{}
+				acc.sources[src.Name] = sa
+				acc.order = append(acc.order, src.Name)
+			}
+			if src.Error != "" {
+				sa.lastErr = src.Error
+				continue
+			}
+			sa.sum += src.USD
+			sa.ok++
+			if src.AUD > 0 {
+				sa.audSum += src.AUD
+				sa.audOK++
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+	out := make([]Sample, 0, len(keys))
+	for _, day := range keys {
+		acc := days[day]
+		acc.sample.RateAUD = acc.sum / float64(acc.weight)
+		acc.sample.Aggregated = acc.weight
+		// A source counts as up for the day if it answered at least once, with its mean quote
+		for _, name := range acc.order {
+			sa := acc.sources[name]
+			src := SourceSample{Name: name}
+			if sa.ok > 0 {
+				src.USD = sa.sum / float64(sa.ok)
+				if sa.audOK > 0 {
+					src.AUD = sa.audSum / float64(sa.audOK)
+				}
+			} else {
+				src.Error = sa.lastErr
+			}
+			acc.sample.Sources = append(acc.sample.Sources, src)
+		}
+		out = append(out, acc.sample)
+	}
+	return out
+}
+
+// compactHistory replaces raw samples older than the raw retention with daily aggregates
+// and drops aggregates older than the daily retention. It returns how many samples went in and came out
+func compactHistory(store Store, now time.Time, raw, daily time.Duration) (before, after int, err error) {
+	// Only whole UTC days are compacted, so a day is never split between raw and aggregated samples
+	cutoff := now.Add(-raw).UTC().Truncate(24 * time.Hour)
+	old, err := store.Samples(time.Time{}, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	aggregates := dailyAggregates(old)
+	if daily > 0 {
+		keepFrom := now.Add(-daily)
+		kept := aggregates[:0]
+		for _, s := range aggregates {
+			if !s.Time.Before(keepFrom) {
+				kept = append(kept, s)
+			}
+		}
+		aggregates = kept
+	}
+
+	// Skip the rewrite when everything old is already a daily aggregate
+	changed := len(aggregates) != len(old)
+	for _, s := range old {
+		if s.Aggregated == 0 {
+			changed = true
+		}
+	}
+	if !changed {
+		return len(old), len(old), nil
+	}
+	if err := store.ReplaceSamples(time.Time{}, cutoff, aggregates); err != nil {
+		return 0, 0, fmt.Errorf("compacting history failed: %v", err)
+	}
+	return len(old), len(aggregates), nil
+}
+
+// maybeCompactHistory runs compaction at most once a day, tracked by a marker file's modification time
+// This keeps a long-running recorder bounded without paying for a full scan on every start
+func maybeCompactHistory(store Store, cfg Config) error {
+	marker := filepath.Join(dataDir(), "last_compaction")
+	if info, err := os.Stat(marker); err == nil && defaultClock.Now().Sub(info.ModTime()) < 24*time.Hour {
+		return nil
+	}
+
+	raw, daily, err := retentionPeriods(cfg.Retention)
+	if err != nil {
+		return err
+	}
+	if _, _, err := compactHistory(store, defaultClock.Now(), raw, daily); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(marker, []byte(defaultClock.Now().UTC().Format(time.RFC3339)+"\n"), 0o644)
+}
@@ -0,0 +1,85 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompactHistory(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	day := func(daysAgo, hour int) time.Time {
+		return now.Truncate(24*time.Hour).AddDate(0, 0, -daysAgo).Add(time.Duration(hour) * time.Hour)
+	}
+	sample := func(at time.Time, rate, usd float64, sourceErr string) Sample {
+		return Sample{Time: at, RateAUD: rate, Sources: []SourceSample{
+			{Name: "Kraken", USD: usd},
+			{Name: "Binance", Error: sourceErr},
+		}}
+	}
+	store := NewMemoryStore()
+	for _, s := range []Sample{
+		sample(day(100, 1), 1000, 650, "timeout"), // past the daily retention, dropped
+		sample(day(40, 1), 4000, 2600, "timeout"),
+		sample(day(40, 9), 5000, 3300, "timeout"),
+		sample(day(40, 23), 6000, 4000, "rate limited"),
+		sample(day(1, 9), 7000, 4600, ""), // within the raw retention, kept as it is
+	} {
+		store.AddSample(s)
+	}
+
+	before, after, err := compactHistory(store, now, 30*24*time.Hour, 60*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before != 4 || after != 1 {
+		t.Fatalf("compacted %d samples into %d, want 4 into 1", before, after)
+	}
+	samples, _ := store.Samples(time.Time{}, now)
+	if len(samples) != 2 {
+		t.Fatalf("%d samples left, want the aggregate and the recent one: %+v", len(samples), samples)
+	}
+	agg := samples[0]
+	if !agg.Time.Equal(day(40, 0)) || agg.Aggregated != 3 || agg.RateAUD != 5000 || agg.High != 6000 || agg.Low != 4000 {
+		t.Fatalf("aggregate = %+v, want 3 samples averaging 5000 between 4000 and 6000", agg)
+	}
+	if s := agg.Sources; len(s) != 2 || s[0].USD != 3300 || s[1].Error != "rate limited" {
+		t.Fatalf("aggregate sources = %+v", s)
+	}
+	if samples[1].Aggregated != 0 || samples[1].RateAUD != 7000 {
+		t.Fatalf("the recent sample was changed: %+v", samples[1])
+	}
+
+	// Compacting again leaves the aggregate as it is
+	if before, after, err := compactHistory(store, now, 30*24*time.Hour, 60*24*time.Hour); err != nil || before != 1 || after != 1 {
+		t.Fatalf("second compaction: %d into %d, %v", before, after, err)
+	}
+	if again, _ := store.Samples(time.Time{}, now); again[0].RateAUD != 5000 || again[0].Aggregated != 3 {
+		t.Fatalf("second compaction changed the aggregate: %+v", again[0])
+	}
+}
+
+func TestDailyAggregatesWeighByCount(t *testing.T) {
+	// An aggregate of three samples at 5000 and one more sample at 9000 average to 6000, not 7000
+	at := time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)
+	got := dailyAggregates([]Sample{
+		{Time: at, RateAUD: 5000, Aggregated: 3, High: 6000, Low: 4000},
+		{Time: at.Add(time.Hour), RateAUD: 9000},
+	})
+	if len(got) != 1 || got[0].RateAUD != 6000 || got[0].Aggregated != 4 || got[0].High != 9000 || got[0].Low != 4000 {
+		t.Fatalf("dailyAggregates = %+v", got)
+	}
+}
+
+func TestRetentionPeriods(t *testing.T) {
+	if raw, daily, err := retentionPeriods(RetentionConfig{}); err != nil || raw != defaultRawRetention || daily != 0 {
+		t.Fatalf("defaults: %s, %s, %v", raw, daily, err)
+	}
+	if _, _, err := retentionPeriods(RetentionConfig{Raw: "soon"}); err == nil {
+		t.Fatal("retention.raw \"soon\" was accepted")
+	}
+}
@@ -0,0 +1,152 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RouteLeg is one trade in a conversion route: spend From to buy To
+// price returns the cost of one unit of To, quoted in From
+type RouteLeg struct {
+	From, To string
+	FeeKey   string // key into the route_fees section of the config
+	price    func() (float64, error)
+}
+
+// Route is a sequence of legs that turns AUD into ETH
+type Route struct {
+	Name string
+	Legs []RouteLeg
+}
+
+// RouteResult is the outcome of walking an amount through a route
+type RouteResult struct {
+	Route Route
+	ETH   float64
+	Steps []string
+	err   error
+}
+
+// defaultRoutes lists the paths compared by the "routes" command
+// Fetchers are reused as plain price functions: Kraken's parser works for any pair it is pointed at
+func defaultRoutes() []Route {
+	krakenPair := func(pair string) func() (float64, error) {
+		return NewAPI("Kraken", "https://api.kraken.com/0/public/Ticker?pair="+pair).FetchPrice
+	}
+	return []Route{
+		{Name: "AUD→ETH direct", Legs: []RouteLeg{
+			{From: "AUD", To: "ETH", FeeKey: "aud_eth", price: krakenPair("ETHAUD")},
+		}},
+		{Name: "AUD→USD→ETH", Legs: []RouteLeg{
+			{From: "AUD", To: "USD", FeeKey: "aud_usd", price: NewCoinGeckoFX().FetchRate},
+			{From: "USD", To: "ETH", FeeKey: "usd_eth", price: krakenPair("ETHUSD")},
+		}},
+		{Name: "AUD→USDT→ETH", Legs: []RouteLeg{
+			{From: "AUD", To: "USDT", FeeKey: "aud_usdt", price: krakenPair("USDTAUD")},
+			{From: "USDT", To: "ETH", FeeKey: "usdt_eth", price: krakenPair("ETHUSDT")},
+		}},
+	}
+}
+
+// walkRoute converts amount AUD through each leg, deducting the configured percentage fee per leg
+func walkRoute(r Route, amount float64, fees map[string]float64) RouteResult {
+	result := RouteResult{Route: r}
+	for _, leg := range r.Legs {
+		price, err := leg.price()
+		if err != nil {
+			result.err = fmt.Errorf("%s/%s quote failed: %v", leg.To, leg.From, err)
+			return result
+		}
+		fee := fees[leg.FeeKey]
+		bought := amount / price * (1 - fee/100)
+		result.Steps = append(result.Steps, fmt.Sprintf("%.4f %s → %.8f %s @ %.4f (fee %.2f%%)",
+			amount, leg.From, bought, leg.To, price, fee))
+		amount = bought
+	}
+	result.ETH = amount
+	return result
+}
+
+// compareRoutes walks every route concurrently and returns the results in route order
+// Each goroutine writes to its own slice index, so no mutex is needed
+func compareRoutes(routes []Route, amount float64, fees map[string]float64) []RouteResult {
+	results := make([]RouteResult, len(routes))
+	var wg sync.WaitGroup
+	for i, r := range routes {
+		wg.Add(1)
+		go func(i int, r Route) {
+			defer wg.Done()
+			results[i] = walkRoute(r, amount, fees)
+		}(i, r)
+	}
+	wg.Wait()
+	return results
+}
+
+// routesFlags are the flags of the "routes" command
+type routesFlags struct {
+	amount float64
+}
+
+func (f *routesFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("routes")
+	fs.Float64Var(&f.amount, "amount", 1000, "AUD amount to route")
+	return fs
+}
+
+// runRoutes implements the "routes" command
+func runRoutes(args []string) error {
+	var flags routesFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if flags.amount <= 0 {
+		return usageErrorf("-amount must be positive")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	results := compareRoutes(defaultRoutes(), flags.amount, cfg.RouteFees)
+
+	best := -1
+	fmt.Println(tr("routes.title", flags.amount))
+	for i, r := range results {
+		// In plain mode each route is one line, its steps separated by semicolons
+		switch {
+		case r.err != nil && plain:
+			fmt.Println(tr("routes.error.plain", plainText(r.Route.Name), r.err))
+		case r.err != nil:
+			fmt.Println("\n" + tr("routes.error", r.Route.Name, r.err))
+		case plain:
+			fmt.Printf("%s: %.8f ETH; %s\n", plainText(r.Route.Name), r.ETH, plainText(strings.Join(r.Steps, "; ")))
+		default:
+			fmt.Printf("\n%s: %.8f ETH\n  %s\n", r.Route.Name, r.ETH, strings.Join(r.Steps, "\n  "))
+		}
+		if r.err != nil {
+			continue
+		}
+		if best < 0 || r.ETH > results[best].ETH {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return fmt.Errorf("no route could be priced")
+	}
+	if !plain {
+		fmt.Println()
+	}
+	fmt.Println(tr("routes.best", display(results[best].Route.Name), results[best].ETH))
+	return nil
+}
@@ -0,0 +1,217 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// keychainService is the service name every audeth secret is stored under in the OS keychain
+const keychainService = "audeth"
+
+// resolveSecret turns a secret reference from the config into its value
+// "env:NAME" reads an environment variable, "cmd:..." runs a command such as "pass show audeth/redis"
+// or "op read op://vault/item/field" and "keychain:NAME" reads the OS keychain
+// Anything else is taken literally, so existing plaintext configs keep working
+// Every value handed out is registered for redaction, wherever it later turns up
+func resolveSecret(ref string) (string, error) {
+	value, err := lookupSecret(ref)
+	if err == nil {
+		registerSecret(value)
+	}
+	return value, err
+}
+
+// isSecretRef reports whether resolveSecret would look ref up rather than take it literally
+func isSecretRef(ref string) bool {
+	kind, _, ok := strings.Cut(ref, ":")
+	return ok && (kind == "env" || kind == "cmd" || kind == "keychain")
+}
+
+func lookupSecret(ref string) (string, error) {
+	kind, arg, ok := strings.Cut(ref, ":")
+	if !ok {
+		return ref, nil
+	}
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(arg)
+		if !ok {
+			return "", fmt.Errorf("secret %s: environment variable %s is not set", ref, arg)
+		}
+		return value, nil
+	case "cmd":
+		out, err := runSecretCommand(shellCommand(arg), nil)
+		if err != nil {
+			return "", fmt.Errorf("secret command %q failed: %v", arg, err)
+		}
+		return out, nil
+	case "keychain":
+		value, err := keychainGet(arg)
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %v", ref, err)
+		}
+		return value, nil
+	default:
+		// e.g. "redis://..." or a password that happens to contain a colon
+		return ref, nil
+	}
+}
+
+// shellCommand runs line through the platform's shell, so pipes and quoting work as typed
+func shellCommand(line string) *exec.Cmd {
+	return shellCommandContext(context.Background(), line)
+}
+
+// shellCommandContext is shellCommand killed when ctx is done
+func shellCommandContext(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", line)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", line)
+}
+
+// runSecretCommand runs cmd with stdin, returning its output without the trailing newline
+// stderr is kept for the error message, since tools like pass explain failures there
+func runSecretCommand(cmd *exec.Cmd, stdin []byte) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// errNoKeychain is returned where no keychain tool is known
+var errNoKeychain = errors.New("no OS keychain support on " + runtime.GOOS + ", use env: or cmd: instead")
+
+// The keychain is reached through each OS's own command line tool rather than a library,
+// security on macOS and secret-tool (libsecret) on Linux and the BSDs
+
+func keychainGet(name string) (string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return runSecretCommand(exec.Command("security", "find-generic-password", "-s", keychainService, "-a", name, "-w"), nil)
+	case "windows":
+		return "", errNoKeychain
+	default:
+		return runSecretCommand(exec.Command("secret-tool", "lookup", "service", keychainService, "account", name), nil)
+	}
+}
+
+func keychainSet(name, value string) error {
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		// security only takes the value as an argument, so it is given in a command on stdin through -i
+		// rather than on the command line, where the process list would show it; -U updates an existing item
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("the value can't contain a line break")
+		}
+		line := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", securityQuote(keychainService), securityQuote(name), securityQuote(value))
+		if _, err = runSecretCommand(exec.Command("security", "-i"), []byte(line)); err != nil {
+			return err
+		}
+		// A command that fails under -i is only reported on stderr, so the item is read back to be sure it was saved
+		if saved, err := keychainGet(name); err != nil || saved != value {
+			return fmt.Errorf("security didn't save the item")
+		}
+	case "windows":
+		return errNoKeychain
+	default:
+		// secret-tool reads the value from stdin, so it never shows up in the process list
+		_, err = runSecretCommand(exec.Command("secret-tool", "store", "--label", keychainService+" "+name,
+			"service", keychainService, "account", name), []byte(value))
+	}
+	return err
+}
+
+// securityQuote quotes s as one argument of a security -i command line
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func keychainDelete(name string) error {
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		_, err = runSecretCommand(exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", name), nil)
+	case "windows":
+		return errNoKeychain
+	default:
+		_, err = runSecretCommand(exec.Command("secret-tool", "clear", "service", keychainService, "account", name), nil)
+	}
+	return err
+}
+
+// readSecretValue reads the value for name from stdin, without echoing it when stdin is a terminal
+func readSecretValue(name string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, tr("secrets.prompt", name))
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(value)), err
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return "", scanner.Err()
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
+
+// runSecrets implements the "secrets" command
+// set reads the value from stdin, so it stays out of shell history
+func runSecrets(args []string) error {
+	if len(args) != 2 {
+		return usageErrorf("usage: secrets set|delete NAME, or secrets check REF")
+	}
+	switch args[0] {
+	case "set":
+		value, err := readSecretValue(args[1])
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("no value given")
+		}
+		if err := keychainSet(args[1], value); err != nil {
+			return fmt.Errorf("saving to the keychain failed: %v", err)
+		}
+		fmt.Println(tr("secrets.saved", args[1]))
+	case "delete":
+		if err := keychainDelete(args[1]); err != nil {
+			return fmt.Errorf("deleting from the keychain failed: %v", err)
+		}
+		fmt.Println(tr("secrets.deleted", args[1]))
+	case "check":
+		// Only the length is printed, the point is to test a reference without revealing it
+		value, err := resolveSecret(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(tr("secrets.resolves", args[1], len(value)))
+	default:
+		return usageErrorf("unknown secrets command: %s", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import "testing"
+
+func TestSecurityQuote(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", `"plain"`},
+		{"two words", `"two words"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`C:\keys`, `"C:\\keys"`},
+	}
+	for _, tt := range tests {
+		if got := securityQuote(tt.in); got != tt.want {
+			t.Errorf("securityQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// parserNames says which function in the parsers package handles each source, for the report
+var parserNames = map[string]string{
+	"CoinGecko":            "ParseCoinGeckoSimple",
+	"CoinGecko FX":         "ParseCoinGeckoFX",
+	"Coinbase":             "ParseCoinbaseSpot",
+	"Bitstamp":             "ParseBitstampTicker",
+	"Kraken":               "ParseKrakenTicker",
+	"Bitfinex":             "ParseBitfinexTicker",
+	"Binance":              "ParseBinanceTicker",
+	"OKX":                  "ParseOKXTicker",
+	"Bybit":                "ParseBybitTickers",
+	"KuCoin":               "ParseKuCoinLevel1",
+	"Gemini":               "ParseGeminiPubticker",
+	"Crypto.com":           "ParseCryptoComTickers",
+	"Gate.io":              "ParseGateTickers",
+	"HTX":                  "ParseHTXMerged",
+	"Bitget":               "ParseBitgetTickers",
+	"MEXC":                 "ParseMEXCTicker24hr",
+	"BTC Markets":          "ParseBTCMarketsTicker",
+	"Independent Reserve":  "ParseIndependentReserveSummary",
+	"CoinSpot":             "ParseCoinSpotLatest",
+	"Swyftx":               "ParseSwyftxBasicInfo",
+	"CoinJar":              "ParseCoinJarTicker",
+	"Luno":                 "ParseLunoTicker",
+	"CoinMarketCap":        "ParseCoinMarketCapQuote",
+	"CoinMarketCap FX":     "ParseCoinMarketCapFX",
+	"CryptoCompare":        "ParseCryptoCompareQuote",
+	"CryptoCompare FX":     "ParseCryptoCompareFX",
+	"RBA FX":               "ParseRBAFX",
+	"Frankfurter FX":       "ParseFrankfurterFX",
+	"exchangerate.host FX": "ParseExchangerateHostFX",
+	"CoinAPI":              "ParseCoinAPIExchangeRate",
+}
+
+// contractCheck is one endpoint to call and the parser its body must satisfy
+type contractCheck struct {
+	name  string
+	url   string
+	parse func([]byte) (float64, error)
+}
+
+// contractResult says whether the endpoint answered and whether its parser still understands it
+type contractResult struct {
+	check     contractCheck
+	price     float64
+	transport error // the request itself failed, the API may just be down
+	schema    error // the API answered but the parser no longer understands it
+	body      []byte
+}
+
+// liveChecks builds one check per configured exchange plus the FX endpoint
+func liveChecks() []contractCheck {
+	var checks []contractCheck
+	for _, f := range append(defaultFetchers(), optionalFetchers()...) {
+		api, ok := f.(API)
+		// Sources needing an API key would only be refused without one
+		if _, keyed := sourceKeyHeaders[api.name]; !ok || keyed {
+			continue
+		}
+		// The same parsing as a fetch, so a check passing means fetching would too
+		checks = append(checks, contractCheck{name: api.name, url: api.url, parse: func(b []byte) (float64, error) {
+			q, err := api.parseQuote(bytes.NewReader(b))
+			return q.Last, err
+		}})
+	}
+	fx := NewCoinGeckoFX()
+	checks = append(checks, contractCheck{name: "CoinGecko FX", url: fx.url, parse: func(b []byte) (float64, error) {
+		return fx.parse(bytes.NewReader(b))
+	}})
+	return checks
+}
+
+// runContractCheck calls the endpoint once and validates the response
+func runContractCheck(c contractCheck) contractResult {
+	r := contractResult{check: c}
+	resp, err := newHTTPClient(10 * time.Second).Get(c.url)
+	if err != nil {
+		r.transport = err
+		return r
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		r.transport = fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+		return r
+	}
+	if r.body, err = io.ReadAll(resp.Body); err != nil {
+		r.transport = err
+		return r
+	}
+
+	r.price, err = c.parse(r.body)
+	switch {
+	case err != nil:
+		r.schema = err
+	case r.price <= 0:
+		r.schema = fmt.Errorf("parsed a non-positive price: %v", r.price)
+	}
+	return r
+}
+
+// runContractChecks runs the checks at once, each API being a different host
+func runContractChecks(checks []contractCheck) []contractResult {
+	results := make([]contractResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runContractCheck(c)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// snippet shortens a response body for the report
+func snippet(b []byte) string {
+	const limit = 160
+	s := string(b)
+	if len(b) > limit {
+		s = string(b[:limit]) + "…"
+	}
+	return display(s)
+}
+
+// selftestFlags are the flags of the "selftest" command
+type selftestFlags struct {
+	live bool
+}
+
+func (f *selftestFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("selftest")
+	fs.BoolVar(&f.live, "live", false, "call every configured API once and validate its response")
+	return fs
+}
+
+// runSelftest implements the "selftest" command
+// Without --live it checks every parser against its embedded fixture and the translations against English;
+// with it, the parsers against the real APIs
+func runSelftest(args []string) error {
+	var flags selftestFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if !flags.live {
+		failed := 0
+		fixtures := parsers.Fixtures()
+		for _, f := range fixtures {
+			if err := f.Check(); err != nil {
+				fmt.Printf("FAIL  %-13s %s: %v\n", f.Name, parserNames[f.Name], err)
+				failed++
+				continue
+			}
+			fmt.Printf("ok    %-13s %s\n", f.Name, f.File)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d fixtures failed", failed, len(fixtures))
+		}
+		return nil
+	}
+
+	results := runContractChecks(liveChecks())
+	broken, down := 0, 0
+	for _, r := range results {
+		switch {
+		case r.transport != nil:
+			down++
+			fmt.Printf("DOWN  %-13s %v\n", r.check.name, redactError(r.transport))
+		case r.schema != nil:
+			broken++
+			fmt.Printf("DRIFT %-13s parsers.%s broke: %v\n", r.check.name, parserNames[r.check.name], r.schema)
+			fmt.Printf("      %-13s got: %s\n", "", redact(snippet(r.body)))
+		default:
+			fmt.Printf("ok    %-13s %v\n", r.check.name, r.price)
+		}
+	}
+	if broken > 0 || down > 0 {
+		return fmt.Errorf("%d of %d APIs changed schema, %d unreachable", broken, len(results), down)
+	}
+	return nil
+}
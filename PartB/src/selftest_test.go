@@ -0,0 +1,37 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// TestContractChecks runs selftest's checks against the mock exchange, which answers in each source's own format,
+// and against an exchange that changed its format
+func TestContractChecks(t *testing.T) {
+	saved := httpTransport
+	t.Cleanup(func() { httpTransport = saved })
+
+	httpTransport = handlerTransport{handler: audethtest.NewExchange(3300, 1.52)}
+	for _, r := range runContractChecks(liveChecks()) {
+		if r.transport != nil || r.schema != nil || r.price <= 0 {
+			t.Errorf("%s: price %v, transport %v, schema %v", r.check.name, r.price, r.transport, r.schema)
+		}
+	}
+
+	httpTransport = handlerTransport{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"renamed": true}`)
+	})}
+	for _, r := range runContractChecks(liveChecks()) {
+		if r.transport != nil || r.schema == nil {
+			t.Errorf("%s against a changed format: transport %v, schema %v, want a schema error", r.check.name, r.transport, r.schema)
+		}
+	}
+}
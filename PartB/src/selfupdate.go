@@ -0,0 +1,395 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version and releasePublicKey are set by release builds:
+//
+//	go build -ldflags "-X main.version=v1.4.0 -X main.releasePublicKey=<base64 ed25519 key>"
+//
+// A build without a version is a development build, which self-update only replaces with -force
+// A build without the key checks releases with update.public_key from config.json instead, see releaseKey
+var (
+	version          = "dev"
+	releasePublicKey = ""
+)
+
+// defaultReleaseEndpoint is the latest release in GitHub's releases API
+const defaultReleaseEndpoint = "https://api.github.com/repos/paudis/go-AudToEthConverter/releases/latest"
+
+// maxReleaseBinary caps a downloaded binary; the real ones are about 30 MB
+const maxReleaseBinary = 200 << 20
+
+// UpdateConfig is the "update" section of config.json
+// PublicKey stands in for the release key of a build made without one, e.g. a release built from source
+type UpdateConfig struct {
+	PublicKey string `json:"public_key"` // base64 ed25519 key checksums.txt must be signed with
+}
+
+// release is the part of a GitHub release self-update reads
+// A release has a binary per platform named audeth_<os>_<arch> (.exe on Windows), checksums.txt listing
+// their SHA-256 as sha256sum prints it under a "# version v1.4.0" line, and checksums.txt.sig, the base64
+// ed25519 signature of checksums.txt
+type release struct {
+	Tag    string `json:"tag_name"`
+	Assets []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func (r release) asset(name string) (string, error) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, nil
+		}
+	}
+	return "", fmt.Errorf("release %s has no %s", r.Tag, name)
+}
+
+// releaseAssetName is this platform's binary in a release
+func releaseAssetName() string {
+	name := fmt.Sprintf("audeth_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// releaseKey is the key updates are checked with: -key, else the one built in, else update.public_key
+// The config never overrides a built-in key, or anyone able to write config.json could have their own binary installed
+func releaseKey(flagKey string) (ed25519.PublicKey, error) {
+	key := flagKey
+	if key == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		key = cmp.Or(releasePublicKey, cfg.Update.PublicKey)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("this build has no release key to check updates with: set update.public_key in config.json or pass -key, or download the release yourself")
+	}
+	pub, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("the release key isn't a base64 ed25519 public key")
+	}
+	return ed25519.PublicKey(pub), nil
+}
+
+// selfUpdateFlags are the flags of the "self-update" command
+type selfUpdateFlags struct {
+	check    bool
+	force    bool
+	endpoint string
+	key      string
+}
+
+func (f *selfUpdateFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("self-update")
+	fs.BoolVar(&f.check, "check", false, "only say whether there is a newer release")
+	fs.BoolVar(&f.force, "force", false, "reinstall the same version, or replace a development build; an older release is never installed")
+	fs.StringVar(&f.endpoint, "endpoint", defaultReleaseEndpoint, "release to install, in GitHub's releases API format")
+	fs.StringVar(&f.key, "key", "", "base64 ed25519 key the release's checksums must be signed with (default update.public_key, else the build's own)")
+	return fs
+}
+
+// runSelfUpdate implements the "self-update" command
+// The new binary is only installed once its SHA-256 matches checksums.txt and checksums.txt is signed with the
+// release key, so neither a changed download nor a changed checksum list gets installed
+// The version compared is the one signed into checksums.txt, not the releases API's tag, so an old release
+// served as the latest can't downgrade the program
+func runSelfUpdate(args []string) error {
+	var flags selfUpdateFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return usageErrorf("usage: self-update [-check] [-force] [-endpoint URL] [-key PUBLIC_KEY]")
+	}
+	pub, err := releaseKey(flags.key)
+	if err != nil {
+		return err
+	}
+
+	var rel release
+	if err := fetchUpdateJSON(flags.endpoint, &rel); err != nil {
+		return fmt.Errorf("checking for a release failed: %v", err)
+	}
+	if rel.Tag == "" {
+		return fmt.Errorf("checking for a release failed: no tag_name in the response")
+	}
+	sums, err := fetchSignedChecksums(rel, pub)
+	if err != nil {
+		return err
+	}
+	signed, err := releaseVersion(sums)
+	if err != nil {
+		return err
+	}
+	if signed != rel.Tag {
+		return fmt.Errorf("release %s was signed as %s, not updating", rel.Tag, signed)
+	}
+	switch order := compareVersions(signed, version); {
+	case version == "dev" && !flags.force:
+		fmt.Println(tr("update.dev_build", signed))
+		return nil
+	case order < 0 && version != "dev":
+		return fmt.Errorf("the latest release is %s, older than this %s, not downgrading", signed, version)
+	case order == 0 && !flags.force:
+		fmt.Println(tr("update.up_to_date", version))
+		return nil
+	case flags.check:
+		fmt.Println(tr("update.available", signed, version))
+		return nil
+	}
+
+	name := releaseAssetName()
+	binURL, err := rel.asset(name)
+	if err != nil {
+		return err
+	}
+	want, err := releaseChecksum(sums, name)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding this program failed: %v", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("finding this program failed: %v", err)
+	}
+	fmt.Println(tr("update.downloading", signed, runtime.GOOS, runtime.GOARCH))
+	tmp, err := downloadVerified(binURL, filepath.Dir(exe), want)
+	if err != nil {
+		return err
+	}
+	if err := replaceExecutable(exe, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("installing the update failed: %v", err)
+	}
+	fmt.Println(tr("update.done", exe, version, signed))
+	return nil
+}
+
+// fetchSignedChecksums downloads rel's checksums.txt and returns it once its signature checks out with pub
+func fetchSignedChecksums(rel release, pub ed25519.PublicKey) ([]byte, error) {
+	sumsURL, err := rel.asset("checksums.txt")
+	if err != nil {
+		return nil, err
+	}
+	sigURL, err := rel.asset("checksums.txt.sig")
+	if err != nil {
+		return nil, err
+	}
+	sums, err := fetchUpdateFile(sumsURL, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("downloading checksums.txt failed: %v", err)
+	}
+	sig, err := fetchUpdateFile(sigURL, 4096)
+	if err != nil {
+		return nil, fmt.Errorf("downloading checksums.txt.sig failed: %v", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || !ed25519.Verify(pub, sums, raw) {
+		return nil, fmt.Errorf("checksums.txt isn't signed with the release key, not updating")
+	}
+	return sums, nil
+}
+
+// checkUpdateURL refuses plain http except to this machine, where a release server can be tried out
+func checkUpdateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if u.Scheme == "https" || (u.Scheme == "http" && (host == "localhost" || host == "127.0.0.1" || host == "::1")) {
+		return nil
+	}
+	return fmt.Errorf("%s: only https is used for updates", redact(raw))
+}
+
+// getUpdate starts a download, checking the URL and the status
+func getUpdate(raw string) (*http.Response, error) {
+	if err := checkUpdateURL(raw); err != nil {
+		return nil, err
+	}
+	resp, err := newHTTPClient(5 * time.Minute).Get(raw)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("non-OK status code: %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func fetchUpdateJSON(raw string, v any) error {
+	data, err := fetchUpdateFile(raw, 1<<20)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// fetchUpdateFile downloads a small file, failing when it is over limit bytes
+func fetchUpdateFile(raw string, limit int64) ([]byte, error) {
+	resp, err := getUpdate(raw)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response is over %d bytes", limit)
+	}
+	return data, nil
+}
+
+// releaseVersion is the version a checksums.txt was signed for, from its "# version v1.4.0" line
+func releaseVersion(sums []byte) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(sums)))
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "# version "); ok && versionParts(v) != nil {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("checksums.txt has no signed version, not updating")
+}
+
+// releaseChecksum finds name's SHA-256 in a checksums.txt
+func releaseChecksum(sums []byte, name string) ([]byte, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(sums)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks binary mode with a * before the name
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			sum, err := hex.DecodeString(fields[0])
+			if err != nil || len(sum) != sha256.Size {
+				return nil, fmt.Errorf("checksums.txt: invalid checksum for %s", name)
+			}
+			return sum, nil
+		}
+	}
+	return nil, fmt.Errorf("checksums.txt has no checksum for %s", name)
+}
+
+// downloadVerified saves the binary next to the one it replaces, so the rename into place stays on one filesystem,
+// and removes it again unless its SHA-256 is want
+func downloadVerified(raw, dir string, want []byte) (string, error) {
+	resp, err := getUpdate(raw)
+	if err != nil {
+		return "", fmt.Errorf("downloading the update failed: %v", err)
+	}
+	defer resp.Body.Close()
+	f, err := os.CreateTemp(dir, ".audeth-update-*")
+	if err != nil {
+		return "", fmt.Errorf("can't write next to this program, run self-update as its owner: %v", err)
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(resp.Body, maxReleaseBinary+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	switch {
+	case err != nil:
+		err = fmt.Errorf("downloading the update failed: %v", err)
+	case n > maxReleaseBinary:
+		err = fmt.Errorf("downloading the update failed: over %d bytes", maxReleaseBinary)
+	case string(hash.Sum(nil)) != string(want):
+		err = fmt.Errorf("the download doesn't match its checksum, not updating")
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0o755)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// replaceExecutable moves the new binary over the running one
+// Windows won't replace a running program, but does let it be renamed, so the old one is moved aside first
+// and left as audeth.exe.old for the next update to clear away
+func replaceExecutable(exe, update string) error {
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return err
+		}
+		if err := os.Rename(update, exe); err != nil {
+			os.Rename(old, exe)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(update, exe)
+}
+
+// compareVersions orders "v1.10.0" after "v1.9.2"; anything that isn't a version, such as "dev", comes first
+// A pre-release like "v1.4.0-rc1" is taken as its version without the suffix
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range max(len(pa), len(pb)) {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	v, _, _ = strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,130 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// releaseServer serves one release in GitHub's format, its checksums signed with sign
+// signedVersion is the version line in checksums.txt, left out when empty
+type releaseServer struct {
+	tag, signedVersion string
+	sign               ed25519.PrivateKey
+}
+
+func (rs releaseServer) start(t *testing.T) string {
+	t.Helper()
+	binary := []byte("new audeth")
+	sum := sha256.Sum256(binary)
+	sums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), releaseAssetName())
+	if rs.signedVersion != "" {
+		sums = "# version " + rs.signedVersion + "\n" + sums
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(rs.sign, []byte(sums)))
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		rel := map[string]any{"tag_name": rs.tag, "assets": []map[string]string{
+			{"name": releaseAssetName(), "browser_download_url": srv.URL + "/binary"},
+			{"name": "checksums.txt", "browser_download_url": srv.URL + "/checksums.txt"},
+			{"name": "checksums.txt.sig", "browser_download_url": srv.URL + "/checksums.txt.sig"},
+		}}
+		json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) { w.Write(binary) })
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, sums) })
+	mux.HandleFunc("/checksums.txt.sig", func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, sig) })
+	return srv.URL + "/latest"
+}
+
+func TestSelfUpdateChecks(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	_, other, _ := ed25519.GenerateKey(rand.Reader)
+	key := base64.StdEncoding.EncodeToString(pub)
+	saved := version
+	version = "v1.4.0"
+	t.Cleanup(func() { version = saved })
+
+	tests := []struct {
+		name    string
+		rel     releaseServer
+		args    []string
+		wantErr string // "" for a check that passes, and would go on to install without -check
+	}{
+		{"newer", releaseServer{"v1.5.0", "v1.5.0", priv}, []string{"-check"}, ""},
+		{"same", releaseServer{"v1.4.0", "v1.4.0", priv}, nil, ""},
+		{"downgrade", releaseServer{"v1.3.0", "v1.3.0", priv}, []string{"-force"}, "older than this v1.4.0, not downgrading"},
+		// The tag isn't signed, so an old release can't pass itself off as a new one
+		{"old release under a new tag", releaseServer{"v9.0.0", "v1.3.0", priv}, []string{"-check"}, "release v9.0.0 was signed as v1.3.0"},
+		{"no signed version", releaseServer{"v1.5.0", "", priv}, []string{"-check"}, "no signed version"},
+		{"wrong key", releaseServer{"v1.5.0", "v1.5.0", other}, []string{"-check"}, "isn't signed with the release key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-endpoint", tt.rel.start(t), "-key", key}, tt.args...)
+			err := runSelfUpdate(args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReleaseKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AUDETH_HOME", home)
+	saved := releasePublicKey
+	t.Cleanup(func() { releasePublicKey = saved })
+
+	releasePublicKey = ""
+	if _, err := releaseKey(""); err == nil || !strings.Contains(err.Error(), "set update.public_key in config.json or pass -key") {
+		t.Errorf("got %v with no key anywhere", err)
+	}
+	if _, err := releaseKey("not a key"); err == nil {
+		t.Error("took a key that isn't base64 ed25519")
+	}
+
+	// A build without a key of its own uses the configured one
+	configured, _, _ := ed25519.GenerateKey(rand.Reader)
+	cfg := fmt.Sprintf(`{"update":{"public_key":%q}}`, base64.StdEncoding.EncodeToString(configured))
+	if err := os.WriteFile(filepath.Join(home, "config.json"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := releaseKey(""); err != nil || !got.Equal(configured) {
+		t.Errorf("got %v, %v, want update.public_key", got, err)
+	}
+
+	// The built-in key wins over the configured one
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	releasePublicKey = base64.StdEncoding.EncodeToString(pub)
+	if got, err := releaseKey(""); err != nil || !got.Equal(pub) {
+		t.Errorf("got %v, %v, want the built-in key over update.public_key", got, err)
+	}
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	if got, err := releaseKey(base64.StdEncoding.EncodeToString(other)); err != nil || !got.Equal(other) {
+		t.Errorf("got %v, %v, want the -key one", got, err)
+	}
+}
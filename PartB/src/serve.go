@@ -0,0 +1,396 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+)
+
+// warmRetryDelay is how long serve waits before retrying a failed warm-up
+const warmRetryDelay = 5 * time.Second
+
+// warmTimeout bounds one warm-up of every converter; a fetch still going then keeps filling its cache
+const warmTimeout = 30 * time.Second
+
+// Server exposes a shared Converter over HTTP
+// ready is an atomic flag so handlers can read it while the warm-up goroutine sets it
+type Server struct {
+	converter *Converter
+	store     Store
+	clock     Clock
+	signer    *Signer     // attests /rate responses when set
+	pairs     *Supervisor // the background refreshers, nil without a pairs section
+	ready     atomic.Bool
+}
+
+func NewServer(converter *Converter, store Store) *Server {
+	return &Server{converter: converter, store: store, clock: defaultClock}
+}
+
+// Handler returns the routes served by the server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rate", s.handleRate)
+	mux.HandleFunc("GET /quotes", s.handleQuotes)
+	mux.HandleFunc("GET /convert", s.handleConvert)
+	mux.HandleFunc("GET /snapshot", s.handleSnapshot)
+	mux.HandleFunc("GET /pairs", s.handlePairs)
+	mux.HandleFunc("GET /pairs/{base}/{quote}", s.handlePair)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.ready.Load() {
+			http.Error(w, "warming up", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ready")
+	})
+	s.grafanaRoutes(mux)
+	return mux
+}
+
+// warm fetches the exchange quotes and the USD/AUD rate for ETH/AUD and every configured pair,
+// all in parallel and under one warmTimeout
+// They land in their caches, so the first client request is answered without a cold fetch
+func (s *Server) warm() error {
+	names, converters := []string{"ETH/AUD"}, []*Converter{s.converter}
+	if s.pairs != nil {
+		for _, r := range s.pairs.refreshers {
+			if r.converter != s.converter {
+				names, converters = append(names, r.State().Pair), append(converters, r.converter)
+			}
+		}
+	}
+	errs := make(chan error, len(converters))
+	for i, c := range converters {
+		go func() {
+			if _, _, err := c.Rate(); err != nil {
+				errs <- fmt.Errorf("%s: %v", names[i], err)
+				return
+			}
+			errs <- nil
+		}()
+	}
+	deadline := s.clock.After(warmTimeout)
+	for range converters {
+		select {
+		case err := <-errs:
+			if err != nil {
+				return err
+			}
+		case <-deadline:
+			return fmt.Errorf("the rates took longer than %s", warmTimeout)
+		}
+	}
+	return nil
+}
+
+// warmUntilReady keeps warming until it succeeds, then marks the server ready
+func (s *Server) warmUntilReady() {
+	start := s.clock.Now()
+	for {
+		err := s.warm()
+		if err == nil {
+			break
+		}
+		fmt.Fprintln(progressOut(), tr("serve.warm_failed", warmRetryDelay, err))
+		s.clock.Sleep(warmRetryDelay)
+	}
+	s.ready.Store(true)
+	fmt.Fprintln(progressOut(), tr("serve.warmed", s.clock.Now().Sub(start).Round(time.Millisecond)))
+}
+
+func (s *Server) handleRate(w http.ResponseWriter, r *http.Request) {
+	sample, err := s.converter.Sample()
+	if err != nil {
+		http.Error(w, redact(err.Error()), http.StatusBadGateway)
+		return
+	}
+	sample.Time = s.clock.Now().UTC()
+	if s.signer != nil {
+		if sample, err = s.signer.Sign(sample); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	writeJSON(w, sample)
+}
+
+// handleQuotes serves the current Quote: the rate with its bid, ask, FX rate and confidence, and every source's quote behind it
+func (s *Server) handleQuotes(w http.ResponseWriter, r *http.Request) {
+	quote, err := s.converter.Quote()
+	if err != nil {
+		http.Error(w, redact(err.Error()), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, quote)
+}
+
+func (s *Server) handleConvert(w http.ResponseWriter, r *http.Request) {
+	aud, err := strconv.ParseFloat(r.URL.Query().Get("aud"), 64)
+	if err != nil || aud <= 0 {
+		http.Error(w, "aud must be a positive number", http.StatusBadRequest)
+		return
+	}
+	rate, _, err := s.converter.Rate()
+	if err != nil {
+		http.Error(w, redact(err.Error()), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, ConversionRecord{Time: s.clock.Now().UTC(), AUD: aud, ETH: aud / rate, RateAUD: rate})
+}
+
+// handlePairs lists the state of every pair's background refresher
+func (s *Server) handlePairs(w http.ResponseWriter, r *http.Request) {
+	if s.pairs == nil {
+		writeJSON(w, []PairState{})
+		return
+	}
+	writeJSON(w, s.pairs.States())
+}
+
+// handlePair is one pair's refresher, e.g. /pairs/ETH/USD
+func (s *Server) handlePair(w http.ResponseWriter, r *http.Request) {
+	pair := r.PathValue("base") + "/" + r.PathValue("quote")
+	if s.pairs != nil {
+		if state, ok := s.pairs.State(pair); ok {
+			writeJSON(w, state)
+			return
+		}
+	}
+	http.Error(w, "no refresher for "+pair+", see pairs in config.json", http.StatusNotFound)
+}
+
+// handleSnapshot dumps the running server's state, including its in-memory rate cache
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	snap, err := takeSnapshot(s.converter, s.store, s.clock.Now())
+	if err != nil {
+		http.Error(w, redact(err.Error()), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, snap)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// serveFlags are the flags of the "serve" command
+type serveFlags struct {
+	addr         string
+	restore      string
+	stream       bool
+	streamMaxAge time.Duration
+	tlsCert      string
+	tlsKey       string
+	clientCA     string
+	clientNames  string
+}
+
+func (f *serveFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("serve")
+	fs.StringVar(&f.addr, "addr", ":8080", "address to listen on")
+	fs.StringVar(&f.restore, "restore", "", "snapshot file to restore before warming up")
+	fs.BoolVar(&f.stream, "stream", false, "keep live prices from Kraken, Coinbase and Bitstamp WebSockets instead of polling them")
+	fs.DurationVar(&f.streamMaxAge, "stream-max-age", 30*time.Second, "fall back to polling when a streamed price is older than this")
+	fs.StringVar(&f.tlsCert, "tls-cert", "", "serve HTTPS with this certificate (PEM)")
+	fs.StringVar(&f.tlsKey, "tls-key", "", "private key for -tls-cert (PEM)")
+	fs.StringVar(&f.clientCA, "client-ca", "", "require client certificates signed by this CA (PEM), needs -tls-cert")
+	fs.StringVar(&f.clientNames, "client-names", "", "comma-separated common names or DNS names of the clients allowed, with -client-ca")
+	return fs
+}
+
+// runServe implements the "serve" command: an HTTP API over the converter
+// The listener starts straight away so /healthz answers; with --prefetch /readyz stays 503 until the rates are warm
+func runServe(args []string) error {
+	var flags serveFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	options := []ConverterOption{audeth.WithMinConfidence(minConfidence)}
+	if flags.stream {
+		options = append(options, audeth.WithFetcherWrapper(func(fetchers []PriceFetcher) []PriceFetcher {
+			return withStreams(context.Background(), fetchers, flags.streamMaxAge, cfg.SourcePolicy)
+		}))
+	}
+	pairs, err := parsePairs(cfg)
+	if err != nil {
+		return err
+	}
+	// ETH/AUD is the rate the server answers with, so a cache_ttl given for it is the server's
+	for _, p := range pairs {
+		if p.quote == "AUD" {
+			cfg = p.cfg
+		}
+	}
+	converter, err := newConverterFromConfig(cfg, options...)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if flags.restore != "" {
+		snap, err := readSnapshot(flags.restore)
+		if err != nil {
+			return err
+		}
+		if err := restoreSnapshot(snap, converter); err != nil {
+			return err
+		}
+		fmt.Println(tr("snapshot.restored", formatAgo(defaultClock.Now().Sub(snap.TakenAt))))
+	}
+
+	if flags.tlsCert == "" && (flags.tlsKey != "" || flags.clientCA != "") {
+		return usageErrorf("-tls-key and -client-ca need -tls-cert")
+	}
+	if flags.clientNames != "" && flags.clientCA == "" {
+		return usageErrorf("-client-names needs -client-ca")
+	}
+	server := NewServer(converter, store)
+	if server.signer, err = newSignerFromConfig(cfg.Signing); err != nil {
+		return err
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	if len(pairs) > 0 {
+		if server.pairs, err = newPairSupervisor(pairs, converter); err != nil {
+			return err
+		}
+		// With --prefetch the refreshers start once the warm-up has filled their caches
+		if !prefetch {
+			server.pairs.Run(ctx)
+		}
+	}
+	handler := server.Handler()
+	var tlsConf *tls.Config
+	if flags.tlsCert != "" {
+		if tlsConf, err = newServerTLSConfig(flags.tlsCert, flags.tlsKey, flags.clientCA, cfg.TLS); err != nil {
+			return err
+		}
+		if flags.clientCA != "" {
+			names := parseClientNames(flags.clientNames)
+			// A list of only commas would otherwise let every client in
+			if flags.clientNames != "" && len(names) == 0 {
+				return usageErrorf("-client-names has no names in it")
+			}
+			handler = requireClientCert(names, handler)
+		}
+	}
+
+	// Under a systemd .socket unit the sockets are already open and -addr is not used
+	listeners, err := systemdListeners()
+	if err != nil {
+		return err
+	}
+	if len(listeners) == 0 {
+		l, err := net.Listen("tcp", flags.addr)
+		if err != nil {
+			return err
+		}
+		listeners = []net.Listener{l}
+	}
+	for _, l := range listeners {
+		switch {
+		case tlsConf == nil:
+			fmt.Println(tr("serve.listening", l.Addr()))
+		case flags.clientCA != "":
+			fmt.Println(tr("serve.listening.mtls", l.Addr()))
+		default:
+			fmt.Println(tr("serve.listening.https", l.Addr()))
+		}
+	}
+
+	// Without --prefetch the first request fetches; ready then only means listening, and /readyz
+	// gating a load balancer on warm caches is what --prefetch is for
+	// systemd is told the same: with Type=notify, units ordered after this one wait for READY=1
+	if prefetch {
+		notifySystemd("STATUS=Warming up the rates")
+		go func() {
+			server.warmUntilReady()
+			if server.pairs != nil {
+				server.pairs.Run(ctx)
+			}
+			notifySystemd("READY=1\nSTATUS=Serving")
+		}()
+	} else {
+		server.ready.Store(true)
+		notifySystemd("READY=1\nSTATUS=Serving")
+	}
+	return serveUntilStopped(&http.Server{Handler: handler, TLSConfig: tlsConf}, listeners)
+}
+
+// shutdownTimeout is how long requests in flight get to finish once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
+// serveUntilStopped serves on every listener until SIGINT or SIGTERM, then lets requests in flight finish
+// and returns nil; a listener failing returns its error instead, so systemd's Restart=on-failure starts it again
+func serveUntilStopped(httpServer *http.Server, listeners []net.Listener) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	failed := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			if httpServer.TLSConfig != nil {
+				failed <- httpServer.ServeTLS(l, "", "")
+			} else {
+				failed <- httpServer.Serve(l)
+			}
+		}()
+	}
+
+	// The watchdog is only fed while every listener is still serving
+	var serving atomic.Bool
+	serving.Store(true)
+	interval, err := sdWatchdogInterval()
+	if err != nil {
+		fmt.Fprintln(progressOut(), tr("warning.systemd", err))
+	}
+	if interval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go runWatchdog(interval, serving.Load, done)
+	}
+
+	select {
+	case <-ctx.Done():
+	case err = <-failed:
+	}
+	serving.Store(false)
+	notifySystemd("STOPPING=1")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if shutdownErr := httpServer.Shutdown(shutdownCtx); err == nil && shutdownErr != nil {
+		return fmt.Errorf("stopping the server failed: %v", shutdownErr)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+)
+
+// blockedFetcher doesn't answer until release is closed
+type blockedFetcher struct{ release chan struct{} }
+
+func (f blockedFetcher) Name() string { return "Blocked" }
+
+func (f blockedFetcher) FetchPrice() (float64, error) {
+	<-f.release
+	return 3000, nil
+}
+
+func TestWarmCoversThePairs(t *testing.T) {
+	server := NewServer(newFakeConverter(t), nil)
+	pair := NewConverter([]PriceFetcher{audethtest.NewFakeFetcher("C", 3000).FailAlways(errors.New("down"))}, audethtest.FakeFX{Rate: 1}, time.Minute, nil)
+	server.pairs = &Supervisor{refreshers: []*refresher{
+		newRefresher("ETH/AUD", server.converter, time.Minute, 0),
+		newRefresher("ETH/USD", pair, time.Minute, 0),
+	}}
+	if err := server.warm(); err == nil || !strings.Contains(err.Error(), "ETH/USD") {
+		t.Fatalf("warm with a failing pair: %v, want an ETH/USD error", err)
+	}
+
+	server.pairs.refreshers[1].converter = newFakeConverter(t)
+	if err := server.warm(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range server.pairs.refreshers {
+		if _, ok := r.converter.Cached(); !ok {
+			t.Fatalf("%s is still cold after the warm-up", r.State().Pair)
+		}
+	}
+}
+
+func TestWarmDeadline(t *testing.T) {
+	clock := audethtest.NewFakeClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	release := make(chan struct{})
+	defer close(release)
+	server := NewServer(newFakeConverter(t), nil)
+	server.clock = clock
+	stuck := NewConverter([]PriceFetcher{blockedFetcher{release}}, audethtest.FakeFX{Rate: 1}, time.Minute, nil)
+	server.pairs = &Supervisor{refreshers: []*refresher{newRefresher("ETH/USD", stuck, time.Minute, 0)}}
+
+	done := make(chan error, 1)
+	go func() { done <- server.warm() }()
+	eventually(t, "the warm-up's deadline", func() bool { return clock.Waiters() == 1 })
+	clock.Advance(warmTimeout)
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("warm succeeded with a pair still fetching")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("warm didn't return at its deadline")
+	}
+}
@@ -0,0 +1,93 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// newServerTLSConfig builds the server side TLS config; with a clientCA, clients may present a certificate
+// signed by it, and requireClientCert then turns away the requests of those that don't
+// The config's tls.min_version applies here as well as to the outbound connections
+func newServerTLSConfig(certFile, keyFile, clientCA string, cfg TLSConfig) (*tls.Config, error) {
+	min, ok := tlsVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("tls.min_version: %q is not supported (use 1.2 or 1.3)", cfg.MinVersion)
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading the server certificate failed: %v", err)
+	}
+	conf := &tls.Config{MinVersion: min, Certificates: []tls.Certificate{cert}}
+	if clientCA != "" {
+		pem, err := os.ReadFile(clientCA)
+		if err != nil {
+			return nil, fmt.Errorf("reading the client CA failed: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", clientCA)
+		}
+		conf.ClientCAs = pool
+		// Verified if given rather than required, so health checks without a certificate still get through
+		conf.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return conf, nil
+}
+
+// unauthenticatedPaths answer without a client certificate, load balancers probe them
+var unauthenticatedPaths = []string{"/healthz", "/readyz"}
+
+// parseClientNames splits a comma-separated -client-names, so "a, b" allows b as well as a
+// Spaces around each name are dropped, and so are empty names
+func parseClientNames(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// requireClientCert rejects requests that didn't present a verified client certificate
+// A non-empty names also limits which certificates are accepted, by subject common name or DNS SAN
+func requireClientCert(names []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(unauthenticatedPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			http.Error(w, "a client certificate is required", http.StatusUnauthorized)
+			return
+		}
+		if len(names) > 0 && !clientNameAllowed(r.TLS.VerifiedChains[0][0], names) {
+			http.Error(w, "this client certificate is not allowed", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientNameAllowed reports whether the leaf certificate names one of the allowed clients
+func clientNameAllowed(cert *x509.Certificate, names []string) bool {
+	if slices.Contains(names, cert.Subject.CommonName) {
+		return true
+	}
+	for _, dns := range cert.DNSNames {
+		if slices.Contains(names, dns) {
+			return true
+		}
+	}
+	return false
+}
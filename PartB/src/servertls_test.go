@@ -0,0 +1,36 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"slices"
+	"testing"
+)
+
+func TestParseClientNames(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a,b", []string{"a", "b"}},
+		{"a, b", []string{"a", "b"}},
+		{" dashboard , alerts.internal.example ,", []string{"dashboard", "alerts.internal.example"}},
+		{", ,", nil},
+	}
+	for _, tt := range tests {
+		if got := parseClientNames(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("parseClientNames(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "b"}}
+	if !clientNameAllowed(cert, parseClientNames("a, b")) {
+		t.Error(`client b is refused by "a, b"`)
+	}
+}
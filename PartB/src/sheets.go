@@ -0,0 +1,365 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SheetsConfig is the Google Sheet "sheets append" adds rows to, see "Google Sheets" in the README
+type SheetsConfig struct {
+	SpreadsheetID string `json:"spreadsheet_id"` // the long id in the sheet's URL, /spreadsheets/d/<id>/edit
+	// Credentials is the service account's JSON key file; GOOGLE_APPLICATION_CREDENTIALS is used when it isn't set
+	Credentials      string `json:"credentials"`
+	RatesRange       string `json:"rates_range"`       // where rate rows go, default "Rates!A:D"
+	ConversionsRange string `json:"conversions_range"` // where conversion rows go, default "Conversions!A:E"
+}
+
+// sheetsScope is the OAuth scope for reading and writing spreadsheets
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// sheetsBatchSize is how many rows go in one append request, well inside the API's request size limit
+const sheetsBatchSize = 1000
+
+// serviceAccount is the part of a Google service account key file needed to sign in
+type serviceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// loadServiceAccount reads a service account key file; the private key is registered for redaction
+func loadServiceAccount(path string) (serviceAccount, *rsa.PrivateKey, error) {
+	var sa serviceAccount
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sa, nil, fmt.Errorf("reading credentials failed: %v", err)
+	}
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return sa, nil, fmt.Errorf("parsing credentials failed: %v", err)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return sa, nil, fmt.Errorf("%s is not a service account key: client_email or private_key is missing", path)
+	}
+	if sa.TokenURI == "" {
+		sa.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	registerSecret(sa.PrivateKey)
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return sa, nil, fmt.Errorf("private_key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return sa, nil, fmt.Errorf("parsing private_key failed: %v", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return sa, nil, fmt.Errorf("private_key is not an RSA key")
+	}
+	return sa, rsaKey, nil
+}
+
+// signedJWT is the RS256 assertion a service account exchanges for an access token
+func signedJWT(sa serviceAccount, key *rsa.PrivateKey, now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iss":   sa.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   sa.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("signing failed: %v", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// SheetsClient appends rows to one spreadsheet as a service account
+type SheetsClient struct {
+	base          string
+	spreadsheetID string
+	token         string
+	timeout       time.Duration
+}
+
+// newSheetsClient signs in with the configured service account
+func newSheetsClient(cfg SheetsConfig) (*SheetsClient, error) {
+	if cfg.SpreadsheetID == "" {
+		return nil, fmt.Errorf("sheets append needs sheets.spreadsheet_id in config.json")
+	}
+	path := cfg.Credentials
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("sheets append needs a service account key, set sheets.credentials in config.json or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	sa, key, err := loadServiceAccount(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &SheetsClient{base: "https://sheets.googleapis.com/v4/spreadsheets/", spreadsheetID: cfg.SpreadsheetID, timeout: 30 * time.Second}
+	if c.token, err = c.fetchToken(sa, key); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// fetchToken exchanges a signed assertion for an access token, valid for an hour
+func (c *SheetsClient) fetchToken(sa serviceAccount, key *rsa.PrivateKey) (string, error) {
+	assertion, err := signedJWT(sa, key, defaultClock.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"}, "assertion": {assertion}}
+	resp, err := newHTTPClient(c.timeout).PostForm(sa.TokenURI, form)
+	if err != nil {
+		return "", redactError(fmt.Errorf("google sign-in failed: %v", err))
+	}
+	defer resp.Body.Close()
+	var data struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&data); err != nil {
+		return "", fmt.Errorf("google sign-in failed: status code %d", resp.StatusCode)
+	}
+	if data.AccessToken == "" {
+		return "", fmt.Errorf("google sign-in failed: %s: %s", data.Error, data.Description)
+	}
+	registerSecret(data.AccessToken)
+	return data.AccessToken, nil
+}
+
+// Append adds rows after the last row of the table in rng, e.g. "Rates!A:D"
+// USER_ENTERED lets the sheet parse the times and numbers as it would typed ones
+func (c *SheetsClient) Append(rng string, rows [][]any) error {
+	for batch := range slices.Chunk(rows, sheetsBatchSize) {
+		body, err := json.Marshal(map[string]any{"values": batch})
+		if err != nil {
+			return err
+		}
+		u := c.base + url.PathEscape(c.spreadsheetID) + "/values/" + url.PathEscape(rng) +
+			":append?valueInputOption=USER_ENTERED&insertDataOption=INSERT_ROWS"
+		req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("sheets append failed: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := newHTTPClient(c.timeout).Do(req)
+		if err != nil {
+			return redactError(fmt.Errorf("sheets append failed: %v", err))
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			// Google explains a sheet that isn't shared with the service account, or a bad range, in the body
+			var e struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if json.Unmarshal(msg, &e) == nil && e.Error.Message != "" {
+				return fmt.Errorf("sheets append returned status code %d: %s", resp.StatusCode, e.Error.Message)
+			}
+			return fmt.Errorf("sheets append returned status code %d", resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// sheetsTime formats t as the sheet should read it with USER_ENTERED, in displayLocation's wall time like the xlsx export
+func sheetsTime(t time.Time) string {
+	return t.In(displayLocation).Format(time.DateTime)
+}
+
+// sheetRateRows are one row per sample: time, ETH/AUD, sources answering, sources asked
+func sheetRateRows(samples []Sample) [][]any {
+	rows := make([][]any, 0, len(samples))
+	for _, s := range samples {
+		ok := 0
+		for _, src := range s.Sources {
+			if src.Error == "" {
+				ok++
+			}
+		}
+		rows = append(rows, []any{sheetsTime(s.Time), s.RateAUD, ok, len(s.Sources)})
+	}
+	return rows
+}
+
+// sheetConversionRows are one row per conversion: time, AUD, ETH, ETH/AUD and the financial year
+func sheetConversionRows(conversions []ConversionRecord) [][]any {
+	rows := make([][]any, 0, len(conversions))
+	for _, c := range conversions {
+		rows = append(rows, []any{sheetsTime(c.Time), c.AUD, c.ETH, c.RateAUD, financialYear(c.Time)})
+	}
+	return rows
+}
+
+// sheetsState remembers the newest row appended of each kind, so a cron job only appends what is new
+type sheetsState struct {
+	Rates       time.Time `json:"rates,omitzero"`
+	Conversions time.Time `json:"conversions,omitzero"`
+}
+
+func sheetsStatePath() string {
+	return filepath.Join(dataDir(), "sheets.json")
+}
+
+func loadSheetsState() (sheetsState, error) {
+	var state sheetsState
+	data, err := os.ReadFile(sheetsStatePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	return state, json.Unmarshal(data, &state)
+}
+
+func saveSheetsState(state sheetsState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sheetsStatePath(), data, 0o600)
+}
+
+// sheetsAppendFlags are the flags of "sheets append"
+type sheetsAppendFlags struct {
+	kind    string
+	fromStr string
+	toStr   string
+}
+
+func (f *sheetsAppendFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("sheets append")
+	fs.StringVar(&f.kind, "kind", "conversions", "what to append: rates or conversions")
+	fs.StringVar(&f.fromStr, "from", "", "start date YYYY-MM-DD (default after the last row appended)")
+	fs.StringVar(&f.toStr, "to", "", "end date YYYY-MM-DD (default now)")
+	return fs
+}
+
+// runSheets implements "sheets append": rows recorded since the last append go to the configured sheet
+// -from and -to pick the rows instead, without moving the saved position
+func runSheets(args []string) error {
+	if len(args) == 0 || args[0] != "append" {
+		return usageErrorf("usage: sheets append [-kind rates|conversions] [-from YYYY-MM-DD] [-to YYYY-MM-DD]")
+	}
+	var flags sheetsAppendFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+	from, to, err := historyRange(flags.fromStr, flags.toStr)
+	if err != nil {
+		return UsageError{err}
+	}
+	if flags.kind != "rates" && flags.kind != "conversions" {
+		return usageErrorf("unknown kind: %s (use rates or conversions)", flags.kind)
+	}
+	explicit := flags.fromStr != "" || flags.toStr != ""
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	state, err := loadSheetsState()
+	if err != nil {
+		return fmt.Errorf("reading %s failed: %v", sheetsStatePath(), err)
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var rows [][]any
+	var rng string
+	var newest time.Time
+	switch flags.kind {
+	case "rates":
+		if !explicit && !state.Rates.IsZero() {
+			from = state.Rates.Add(time.Nanosecond)
+		}
+		samples, err := store.Samples(from, to)
+		if err != nil {
+			return err
+		}
+		if len(samples) > 0 {
+			newest = samples[len(samples)-1].Time
+		}
+		rows, rng = sheetRateRows(samples), cmp.Or(cfg.Sheets.RatesRange, "Rates!A:D")
+	case "conversions":
+		if !explicit && !state.Conversions.IsZero() {
+			from = state.Conversions.Add(time.Nanosecond)
+		}
+		conversions, err := store.Conversions(from, to)
+		if err != nil {
+			return err
+		}
+		if len(conversions) > 0 {
+			newest = conversions[len(conversions)-1].Time
+		}
+		rows, rng = sheetConversionRows(conversions), cmp.Or(cfg.Sheets.ConversionsRange, "Conversions!A:E")
+	}
+	if len(rows) == 0 {
+		fmt.Println(tr("sheets.nothing_new"))
+		return nil
+	}
+
+	client, err := newSheetsClient(cfg.Sheets)
+	if err != nil {
+		return err
+	}
+	if err := client.Append(rng, rows); err != nil {
+		return err
+	}
+	fmt.Println(tr("sheets.appended", len(rows), strings.SplitN(rng, "!", 2)[0]))
+	if explicit {
+		return nil
+	}
+	if flags.kind == "rates" {
+		state.Rates = newest
+	} else {
+		state.Conversions = newest
+	}
+	return saveSheetsState(state)
+}
@@ -0,0 +1,187 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion is bumped whenever the snapshot layout changes incompatibly
+const snapshotVersion = 1
+
+// Snapshot is the runtime state worth carrying across an upgrade or restart
+// Pointers and omitempty leave out the parts that don't exist yet, e.g. before the first fetch
+type Snapshot struct {
+	Version int       `json:"version"`
+	TakenAt time.Time `json:"taken_at"`
+
+	RateCache      *Sample             `json:"rate_cache,omitempty"`     // the converter's cached aggregate
+	LastKnownRate  *Sample             `json:"last_known,omitempty"`     // rate_cache.json, shown at startup
+	SourceHealth   []SourceReliability `json:"source_health"`            // per-source success over the last day
+	LastCompaction time.Time           `json:"last_compaction,omitzero"` // when history was last compacted
+}
+
+// takeSnapshot gathers the converter cache, last known rate, recent source health and compaction time
+func takeSnapshot(converter *Converter, store Store, now time.Time) (Snapshot, error) {
+	snap := Snapshot{Version: snapshotVersion, TakenAt: now.UTC()}
+
+	if s, ok := converter.Cached(); ok {
+		snap.RateCache = &s
+	}
+	last, ok, err := loadRateCache(rateCachePath())
+	if err != nil {
+		return snap, err
+	}
+	if ok {
+		snap.LastKnownRate = &last
+	}
+
+	samples, err := store.Samples(now.Add(-24*time.Hour), now.Add(time.Minute))
+	if err != nil {
+		return snap, err
+	}
+	if len(samples) > 0 {
+		summary, err := summarize("snapshot", now.Add(-24*time.Hour), now, samples)
+		if err != nil {
+			return snap, err
+		}
+		snap.SourceHealth = summary.Reliability
+	}
+
+	if info, err := os.Stat(filepath.Join(dataDir(), "last_compaction")); err == nil {
+		snap.LastCompaction = info.ModTime().UTC()
+	}
+	return snap, nil
+}
+
+// restoreSnapshot puts the saved state back
+// The cached aggregate only gets the part of its TTL it had left, so a stale snapshot can't serve old prices
+// Source health is derived from history, so it is kept in the file for comparison but not written back
+func restoreSnapshot(snap Snapshot, converter *Converter) error {
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d (want %d)", snap.Version, snapshotVersion)
+	}
+
+	if snap.RateCache != nil {
+		if err := converter.Restore(*snap.RateCache); err != nil {
+			return fmt.Errorf("restoring rate cache failed: %v", err)
+		}
+	}
+	if snap.LastKnownRate != nil {
+		if err := saveRateCache(rateCachePath(), *snap.LastKnownRate); err != nil {
+			return fmt.Errorf("restoring last known rate failed: %v", err)
+		}
+	}
+	if !snap.LastCompaction.IsZero() {
+		marker := filepath.Join(dataDir(), "last_compaction")
+		if err := os.MkdirAll(dataDir(), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(marker, []byte(snap.LastCompaction.Format(time.RFC3339)+"\n"), 0o644); err != nil {
+			return err
+		}
+		if err := os.Chtimes(marker, snap.LastCompaction, snap.LastCompaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSnapshot loads a snapshot file written by the snapshot command
+func readSnapshot(path string) (Snapshot, error) {
+	var snap Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snap, err
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snap, fmt.Errorf("parsing %s failed: %v", path, err)
+	}
+	return snap, nil
+}
+
+// snapshotFlags are the flags of the "snapshot" command
+type snapshotFlags struct {
+	out string
+}
+
+func (f *snapshotFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("snapshot")
+	fs.StringVar(&f.out, "o", "", "output file (default stdout)")
+	return fs
+}
+
+// runSnapshot implements "snapshot [-o FILE]" and "snapshot restore FILE"
+func runSnapshot(args []string) error {
+	if len(args) > 0 && args[0] == "restore" {
+		return runSnapshotRestore(args[1:])
+	}
+
+	var flags snapshotFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	converter, err := newConverterFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	snap, err := takeSnapshot(converter, store, defaultClock.Now())
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if flags.out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(flags.out, data, 0o644)
+}
+
+func runSnapshotRestore(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: snapshot restore FILE")
+	}
+	snap, err := readSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	converter, err := newConverterFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := restoreSnapshot(snap, converter); err != nil {
+		return err
+	}
+	fmt.Println(tr("snapshot.restored", formatAgo(defaultClock.Now().Sub(snap.TakenAt))))
+	return nil
+}
@@ -0,0 +1,243 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// SourceConfig points a built-in source at a different endpoint, such as a mirror or a caching proxy
+// The response is still read with that source's parser, so the endpoint must speak the same format
+// HMAC signs every request to it, the URL can then be left out to keep the built-in one
+// Naming one of the optional sources, even as just {}, adds it to the fetch; "enabled": false drops any source
+// Any other name needs a command, which adds it as a plugin source
+type SourceConfig struct {
+	URL     string      `json:"url"`
+	HMAC    *HMACConfig `json:"hmac"`
+	Enabled *bool       `json:"enabled"` // nil means enabled
+
+	// Command makes a new source out of a program printing quotes, see plugin.go
+	Command []string `json:"command"`
+	Quote   string   `json:"quote"` // what the plugin or parsed source quotes in: "USD" (default), "USDT" or "AUD"
+
+	// Parse reads the price out of the JSON response with an expression, e.g. "double(body.price)", see expr.go
+	// With a url it can also make a new source out of any endpoint
+	Parse string `json:"parse"`
+
+	// Format is how the response is decoded for parse: "json" (default), "xml", "csv" or "text", see decoders.go
+	// A text response needs no parse when it is just the price
+	Format string `json:"format"`
+
+	// Weight is the source's share of the average against the others' 1, e.g. 2 to count it twice
+	Weight float64 `json:"weight"`
+}
+
+// parseExpr is the source's parse expression; a plain text response is the price by default
+func (s SourceConfig) parseExpr() string {
+	if s.Parse == "" && s.Format == "text" {
+		return "double(body)"
+	}
+	return s.Parse
+}
+
+// URLPolicy limits where configured sources may point
+// A shared config file could otherwise send the price fetches anywhere, so only https is allowed by default
+// Hosts are matched case-insensitively, and "*.example.com" matches any subdomain of example.com
+type URLPolicy struct {
+	Schemes    []string `json:"schemes"`     // allowed schemes, default ["https"]
+	AllowHosts []string `json:"allow_hosts"` // if set, only these hosts may be used
+	DenyHosts  []string `json:"deny_hosts"`  // never used, even when also allowed
+}
+
+// hostMatches reports whether host matches any pattern in the list
+func hostMatches(host string, patterns []string) bool {
+	host = strings.ToLower(host)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if suffix, ok := strings.CutPrefix(p, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == p {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns an error if u isn't allowed by the policy
+func (p URLPolicy) check(u *url.URL) error {
+	schemes := p.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	if !slices.Contains(schemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("scheme %q is not allowed (allowed: %s)", u.Scheme, strings.Join(schemes, ", "))
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("no host in %s", redact(u.String()))
+	}
+	if hostMatches(host, p.DenyHosts) {
+		return fmt.Errorf("host %s is denied by source_policy.deny_hosts", host)
+	}
+	if len(p.AllowHosts) > 0 && !hostMatches(host, p.AllowHosts) {
+		return fmt.Errorf("host %s is not in source_policy.allow_hosts", host)
+	}
+	return nil
+}
+
+// checkRedirect applies the policy to every redirect, so an allowed host can't bounce the request elsewhere
+func (p URLPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if err := p.check(req.URL); err != nil {
+		return fmt.Errorf("redirect refused: %v", err)
+	}
+	return nil
+}
+
+// sourceKeyHeaders are the headers carrying the API key of the sources that need one
+// The key is read from api_keys under the source's name in lower case, e.g. api_keys.coinapi
+var sourceKeyHeaders = map[string]string{
+	"CoinAPI": "X-CoinAPI-Key",
+}
+
+// sourceWeights collects the configured weights by lower-case name, the way the converter looks them up
+func sourceWeights(sources map[string]SourceConfig) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for name, source := range sources {
+		if source.Weight < 0 {
+			return nil, fmt.Errorf("sources.%s: weight can't be negative", name)
+		}
+		if source.Weight > 0 {
+			weights[strings.ToLower(name)] = source.Weight
+		}
+	}
+	return weights, nil
+}
+
+// applySources replaces the built-in endpoints named in cfg.Sources with the configured URLs
+// Every configured URL is checked against cfg.SourcePolicy, and the policy also follows the fetcher's redirects
+// A CoinGecko override takes its quote out of the shared CoinGecko request, the FX rate still uses the default
+// Sources in optional are appended in name order, so the fetch order doesn't depend on map iteration
+func applySources(fetchers, optional []PriceFetcher, cfg Config) ([]PriceFetcher, error) {
+	byName := func(name string) func(PriceFetcher) bool {
+		return func(f PriceFetcher) bool { return strings.EqualFold(f.Name(), name) }
+	}
+	var disabled []string
+	for _, name := range slices.Sorted(maps.Keys(cfg.Sources)) {
+		source := cfg.Sources[name]
+		if source.Enabled != nil && !*source.Enabled {
+			disabled = append(disabled, name)
+			continue
+		}
+		i := slices.IndexFunc(fetchers, byName(name))
+		isBuiltin := i >= 0 || slices.ContainsFunc(optional, byName(name))
+		if source.Command != nil {
+			if isBuiltin {
+				return nil, fmt.Errorf("sources.%s: that is a built-in source, give the plugin another name", name)
+			}
+			plugin, err := newPluginFromConfig(name, source)
+			if err != nil {
+				return nil, err
+			}
+			fetchers = append(fetchers, plugin)
+			continue
+		}
+		if i < 0 {
+			j := slices.IndexFunc(optional, byName(name))
+			switch {
+			case j >= 0:
+				fetchers = append(fetchers, optional[j])
+			case source.parseExpr() != "" && source.URL != "":
+				// A new source is any JSON endpoint read with its parse expression
+				switch source.Quote {
+				case "", "USD", "USDT", "AUD":
+				default:
+					return nil, fmt.Errorf("sources.%s: unsupported quote currency %q (use USD, USDT or AUD)", name, source.Quote)
+				}
+				quote := source.Quote
+				if quote == "USD" {
+					quote = ""
+				}
+				fetchers = append(fetchers, NewAPI(name, source.URL).quotedIn(quote))
+			case source.URL != "":
+				return nil, fmt.Errorf("sources.%s: there is no built-in source by that name, add parse to read its response", name)
+			default:
+				return nil, fmt.Errorf("sources.%s: there is no built-in source by that name", name)
+			}
+			i = len(fetchers) - 1
+		}
+		api, builtin := fetchers[i].(API)
+		if source.URL != "" {
+			u, err := url.Parse(source.URL)
+			if err != nil {
+				return nil, fmt.Errorf("sources.%s: %v", name, redactError(err))
+			}
+			if err := cfg.SourcePolicy.check(u); err != nil {
+				return nil, fmt.Errorf("sources.%s: %v", name, err)
+			}
+			policy := cfg.SourcePolicy
+			api = NewAPI(fetchers[i].Name(), source.URL).quotedIn(api.quote)
+			api.policy = &policy
+		} else if !builtin {
+			// A source that isn't a plain endpoint, such as CoinMarketCap's batch, can only be enabled as it is
+			if source.HMAC != nil || source.Parse != "" || source.Format != "" {
+				return nil, fmt.Errorf("sources.%s: url is required for this source", name)
+			}
+			continue
+		}
+		if source.HMAC != nil {
+			signer, err := NewHMACSigner(*source.HMAC)
+			if err != nil {
+				return nil, fmt.Errorf("sources.%s: %v", name, err)
+			}
+			api.signer = signer
+		}
+		if header, ok := sourceKeyHeaders[api.name]; ok {
+			key, err := cfg.apiKey(strings.ToLower(api.name))
+			if err != nil {
+				return nil, err
+			}
+			api = api.withKey(header, key)
+		}
+		if expr := source.parseExpr(); expr != "" {
+			parse, err := compileExpr(expr)
+			if err != nil {
+				return nil, fmt.Errorf("sources.%s.parse: %v", name, err)
+			}
+			decode, err := decoderFor(source.Format)
+			if err != nil {
+				return nil, fmt.Errorf("sources.%s.format: %v", name, err)
+			}
+			api.parse, api.decode = parse, decode
+		} else if source.Format != "" {
+			return nil, fmt.Errorf("sources.%s: format needs parse, to say where the price is in the response", name)
+		}
+		fetchers[i] = api
+	}
+	for _, name := range disabled {
+		i := slices.IndexFunc(fetchers, byName(name))
+		if i < 0 && !slices.ContainsFunc(optional, byName(name)) && cfg.Sources[name].Command == nil && cfg.Sources[name].parseExpr() == "" {
+			return nil, fmt.Errorf("sources.%s: there is no built-in source by that name", name)
+		}
+		if i >= 0 {
+			fetchers = slices.Delete(fetchers, i, i+1)
+		}
+	}
+	if len(fetchers) == 0 {
+		return nil, fmt.Errorf("sources: every source is disabled")
+	}
+	return fetchers, nil
+}
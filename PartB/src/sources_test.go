@@ -0,0 +1,94 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestURLPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy URLPolicy
+		url    string
+		want   string // part of the error, "" when allowed
+	}{
+		{"https by default", URLPolicy{}, "https://prices.example.com/eth", ""},
+		{"http refused by default", URLPolicy{}, "http://prices.example.com/eth", `scheme "http" is not allowed`},
+		{"http allowed", URLPolicy{Schemes: []string{"http", "https"}}, "http://prices.example.com/eth", ""},
+		{"allowed host", URLPolicy{AllowHosts: []string{"prices.example.com"}}, "https://PRICES.example.com/eth", ""},
+		{"allowed subdomain", URLPolicy{AllowHosts: []string{"*.example.com"}}, "https://eu.prices.example.com/eth", ""},
+		{"wildcard isn't the domain", URLPolicy{AllowHosts: []string{"*.example.com"}}, "https://example.com/eth", "not in source_policy.allow_hosts"},
+		{"host not allowed", URLPolicy{AllowHosts: []string{"prices.example.com"}}, "https://169.254.169.254/latest", "not in source_policy.allow_hosts"},
+		{"denied host", URLPolicy{DenyHosts: []string{"*.internal"}}, "https://metadata.internal/eth", "denied by source_policy.deny_hosts"},
+		{"deny beats allow", URLPolicy{AllowHosts: []string{"*.example.com"}, DenyHosts: []string{"admin.example.com"}}, "https://admin.example.com/", "denied"},
+		{"no host", URLPolicy{}, "https:///eth", "no host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tt.policy.check(u)
+			if tt.want == "" && err != nil {
+				t.Fatalf("%s refused: %v", tt.url, err)
+			}
+			if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Fatalf("%s: %v, want an error with %q", tt.url, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySourcesDeniedHost(t *testing.T) {
+	fetchers := []PriceFetcher{NewAPI("Kraken", "https://api.kraken.com/0/public/Ticker?pair=ETHUSD")}
+	cfg := Config{
+		Sources:      map[string]SourceConfig{"Kraken": {URL: "https://prices.internal/kraken"}},
+		SourcePolicy: URLPolicy{DenyHosts: []string{"*.internal"}},
+	}
+	if _, err := applySources(fetchers, nil, cfg); err == nil || !strings.Contains(err.Error(), "sources.Kraken: host prices.internal is denied") {
+		t.Fatalf("applySources with a denied host: %v", err)
+	}
+}
+
+func TestRedirectRefusedByPolicy(t *testing.T) {
+	var reached atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached.Store(true)
+	}))
+	defer target.Close()
+	targetURL, _ := url.Parse(target.URL)
+	// The origin is reached by IP and bounces the request to the same server by name, which isn't allowed
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:"+targetURL.Port()+"/price", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	api := NewAPI("Kraken", origin.URL)
+	api.policy = &URLPolicy{Schemes: []string{"http"}, AllowHosts: []string{"127.0.0.1"}}
+	_, err := api.FetchPrice()
+	if err == nil || !strings.Contains(err.Error(), "redirect refused: host localhost is not in source_policy.allow_hosts") {
+		t.Fatalf("FetchPrice through a refused redirect: %v", err)
+	}
+	if reached.Load() {
+		t.Fatal("the request was sent to the host the policy refused")
+	}
+
+	// Redirects the policy allows are still followed
+	api.policy.AllowHosts = append(api.policy.AllowHosts, "localhost")
+	if _, err := api.FetchPrice(); err != nil && strings.Contains(err.Error(), "redirect refused") {
+		t.Fatalf("an allowed redirect was refused: %v", err)
+	}
+	if !reached.Load() {
+		t.Fatal("the allowed redirect wasn't followed")
+	}
+}
@@ -0,0 +1,323 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+)
+
+// SourceInfo is one line of the "sources" command: a price or FX source as the converter would use it,
+// and how it has done according to the history
+type SourceInfo struct {
+	Name    string  `json:"name"`
+	Kind    string  `json:"kind"`   // "price" or "fx"
+	Origin  string  `json:"origin"` // "built-in", "config", "remote" or "plugin"
+	URL     string  `json:"url,omitempty"`
+	Quote   string  `json:"quote,omitempty"`
+	Weight  float64 `json:"weight,omitempty"`
+	Enabled bool    `json:"enabled"`
+
+	// Status is "ok" when the last recorded attempt worked, "failing" when it didn't, "unknown" without
+	// history and "off" for a disabled source
+	Status      string    `json:"status"`
+	Failures    int       `json:"failures,omitempty"` // failed attempts since the last success
+	OK          int       `json:"ok_24h"`
+	Total       int       `json:"total_24h"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// sourceHealth is what the history says about one source
+type sourceHealth struct {
+	ok, total   int
+	failures    int
+	lastSuccess time.Time
+	lastError   string
+	seen        bool
+}
+
+// sourcesHistory is how far back the command looks for a source's last success
+const sourcesHistory = 7 * 24 * time.Hour
+
+// sourcesFlags are the flags of the "sources" command
+type sourcesFlags struct {
+	format string
+	all    bool
+}
+
+func (f *sourcesFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("sources")
+	fs.StringVar(&f.format, "format", "text", "output format: text or json")
+	fs.BoolVar(&f.all, "all", false, "also list the optional built-in sources that aren't turned on")
+	return fs
+}
+
+// runSources implements the "sources" command
+// Arguments narrow the list to the sources with those names or quote currencies, e.g. "sources kraken USDT"
+func runSources(args []string) error {
+	var flags sourcesFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if flags.format != "text" && flags.format != "json" {
+		return usageErrorf("unknown format: %s (use text or json)", flags.format)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	// The remote sources are merged here rather than in newConverterFromConfig, so they can be told apart from the local ones
+	merged, err := withRemoteSources(cfg)
+	if err != nil {
+		return err
+	}
+	merged.Remote = RemoteConfig{}
+	converter, err := newConverterFromConfig(merged)
+	if err != nil {
+		return err
+	}
+	health, err := loadSourceHealth(cfg)
+	if err != nil {
+		return err
+	}
+
+	origin := func(name string) string {
+		for configured := range cfg.Sources {
+			if strings.EqualFold(configured, name) {
+				return "config"
+			}
+		}
+		for configured := range merged.Sources {
+			if strings.EqualFold(configured, name) {
+				return "remote"
+			}
+		}
+		return "built-in"
+	}
+
+	var infos []SourceInfo
+	for _, f := range converter.Fetchers() {
+		info := SourceInfo{Name: f.Name(), Kind: "price", Origin: origin(f.Name()), URL: fetcherURL(f), Quote: audeth.QuoteCurrency(f), Weight: 1, Enabled: true}
+		if _, ok := f.(PluginFetcher); ok {
+			info.Origin = "plugin"
+		}
+		if w, ok := converter.FetchOptions().Weights[strings.ToLower(f.Name())]; ok {
+			info.Weight = w
+		}
+		infos = append(infos, withHealth(info, health[strings.ToLower(f.Name())]))
+	}
+	for _, name := range slices.Sorted(func(yield func(string) bool) {
+		for name, source := range merged.Sources {
+			if source.Enabled != nil && !*source.Enabled && !yield(name) {
+				return
+			}
+		}
+	}) {
+		infos = append(infos, withHealth(SourceInfo{Name: name, Kind: "price", Origin: origin(name), URL: redact(merged.Sources[name].URL)}, health[strings.ToLower(name)]))
+	}
+	if flags.all {
+		optional := append(optionalFetchers(), NewCoinMarketCapBatch("").Quote(), NewCryptoCompareBatch("").Quote())
+		for _, f := range optional {
+			listed := slices.ContainsFunc(infos, func(info SourceInfo) bool { return strings.EqualFold(info.Name, f.Name()) })
+			if !listed {
+				infos = append(infos, withHealth(SourceInfo{Name: f.Name(), Kind: "price", Origin: "built-in", URL: fetcherURL(f), Quote: audeth.QuoteCurrency(f)}, health[strings.ToLower(f.Name())]))
+			}
+		}
+	}
+
+	// FX rates aren't recorded per provider, so an FX provider's last success is that of the last rate, which needed one
+	fxHealth := health[""]
+	for _, p := range fxProviders(converter.FX()) {
+		info := SourceInfo{Name: p.Name(), Kind: "fx", Origin: "built-in", URL: fxURL(p), Enabled: true}
+		if _, ok := p.(ExprFX); ok {
+			info.Origin = "config"
+		}
+		infos = append(infos, withHealth(info, fxHealth))
+	}
+
+	if fs.NArg() > 0 {
+		infos = slices.DeleteFunc(infos, func(info SourceInfo) bool {
+			return !slices.ContainsFunc(fs.Args(), func(arg string) bool {
+				return strings.EqualFold(arg, info.Name) || strings.EqualFold(arg, info.Quote)
+			})
+		})
+	}
+
+	if flags.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(infos)
+	}
+	printSources(infos)
+	return nil
+}
+
+func printSources(infos []SourceInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tORIGIN\tSTATUS\t24H\tLAST SUCCESS\tWEIGHT\tQUOTE\tURL")
+	for _, info := range infos {
+		status := info.Status
+		if info.Failures > 0 {
+			status = fmt.Sprintf("%s (%d)", status, info.Failures)
+		}
+		last := "-"
+		if !info.LastSuccess.IsZero() {
+			last = info.LastSuccess.Local().Format("2006-01-02 15:04")
+		}
+		recent, weight, quote, url := "-", "-", "-", "-"
+		if info.Total > 0 {
+			recent = fmt.Sprintf("%d/%d", info.OK, info.Total)
+		}
+		if info.Weight > 0 {
+			weight = fmt.Sprintf("%g", info.Weight)
+		}
+		if info.Quote != "" {
+			quote = info.Quote
+		}
+		if info.URL != "" {
+			url = info.URL
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", plainText(info.Name), info.Kind, info.Origin, status, recent, last, weight, quote, url)
+	}
+	w.Flush()
+}
+
+// withHealth fills in the status columns; a disabled source keeps its history but is shown as off
+func withHealth(info SourceInfo, h sourceHealth) SourceInfo {
+	info.OK, info.Total = h.ok, h.total
+	info.LastSuccess, info.LastError, info.Failures = h.lastSuccess, h.lastError, h.failures
+	switch {
+	case !info.Enabled:
+		info.Status = "off"
+	case !h.seen:
+		info.Status = "unknown"
+	case h.failures > 0:
+		info.Status = "failing"
+	default:
+		info.Status = "ok"
+	}
+	return info
+}
+
+// loadSourceHealth reads the last week of samples into each source's record, keyed by lower-case name
+// The FX rate is under "": every sample needed one, so its last success is the newest sample
+func loadSourceHealth(cfg Config) (map[string]sourceHealth, error) {
+	store, err := openStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	now := defaultClock.Now()
+	samples, err := store.Samples(now.Add(-sourcesHistory), now)
+	if err != nil {
+		return nil, fmt.Errorf("reading history failed: %v", err)
+	}
+	slices.SortStableFunc(samples, func(a, b Sample) int { return a.Time.Compare(b.Time) })
+
+	health := make(map[string]sourceHealth)
+	for _, s := range samples {
+		fx := health[""]
+		fx.seen, fx.lastSuccess = true, s.Time
+		if now.Sub(s.Time) <= 24*time.Hour {
+			fx.ok++
+			fx.total++
+		}
+		health[""] = fx
+
+		for _, source := range s.Sources {
+			key := strings.ToLower(source.Name)
+			h := health[key]
+			h.seen = true
+			at := source.Time
+			if at.IsZero() {
+				at = s.Time
+			}
+			recent := now.Sub(at) <= 24*time.Hour
+			if recent {
+				h.total++
+			}
+			if source.Error == "" {
+				h.failures, h.lastSuccess, h.lastError = 0, at, ""
+				if recent {
+					h.ok++
+				}
+			} else {
+				h.failures++
+				h.lastError = source.Error
+			}
+			health[key] = h
+		}
+	}
+	return health, nil
+}
+
+// fetcherURL is where a price fetcher gets its quote, with any key in it hidden; a plugin shows its command
+func fetcherURL(f PriceFetcher) string {
+	switch f := f.(type) {
+	case API:
+		return redact(f.url)
+	case PluginFetcher:
+		return redact(strings.Join(f.command, " "))
+	case coinGeckoQuote:
+		return f.batch.base
+	case ethBatchQuote:
+		return ethBatchURL(f.batch)
+	}
+	return ""
+}
+
+// fxProviders lists the providers behind the converter's FX rate
+func fxProviders(fx FXProvider) []FXProvider {
+	switch p := fx.(type) {
+	case *cachedFX:
+		return fxProviders(p.FXProvider)
+	case averageFX:
+		return p.providers
+	}
+	return []FXProvider{fx}
+}
+
+func fxURL(p FXProvider) string {
+	switch p := p.(type) {
+	case coinGeckoBatchFX:
+		return p.batch.base
+	case ethBatchFX:
+		return ethBatchURL(p.batch)
+	case CoinGeckoFX:
+		return p.url
+	case RBAFX:
+		return p.url
+	case FrankfurterFX:
+		return p.url
+	case ExchangerateHostFX:
+		return redact(p.url)
+	case ExprFX:
+		return redact(p.url)
+	}
+	return ""
+}
+
+// ethBatchURL is the endpoint of a batch; the batch only keeps its request function
+func ethBatchURL(b *ETHBatch) string {
+	switch b.name {
+	case "CoinMarketCap":
+		return coinMarketCapURL
+	case "CryptoCompare":
+		return cryptoCompareURL
+	}
+	return ""
+}
@@ -0,0 +1,279 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure Go SQLite driver, registers itself as "sqlite"
+)
+
+// sqliteSchema creates the tables on first open
+// Times are stored as Unix milliseconds so range queries can use the indexes
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS rates (
+	id          INTEGER PRIMARY KEY,
+	time        INTEGER NOT NULL,
+	rate_aud    REAL    NOT NULL,
+	aggregated  INTEGER NOT NULL DEFAULT 0,
+	high        REAL    NOT NULL DEFAULT 0,
+	low         REAL    NOT NULL DEFAULT 0,
+	fx_rate     REAL    NOT NULL DEFAULT 0,
+	confidence  REAL    NOT NULL DEFAULT 0,
+	aggregation TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS rates_time ON rates(time);
+CREATE TABLE IF NOT EXISTS source_quotes (
+	rate_id INTEGER NOT NULL REFERENCES rates(id) ON DELETE CASCADE,
+	name    TEXT    NOT NULL,
+	usd     REAL,
+	aud     REAL,
+	error   TEXT
+);
+CREATE INDEX IF NOT EXISTS source_quotes_rate ON source_quotes(rate_id);
+CREATE TABLE IF NOT EXISTS conversions (
+	time     INTEGER NOT NULL,
+	aud      REAL    NOT NULL,
+	eth      REAL    NOT NULL,
+	rate_aud REAL    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS conversions_time ON conversions(time);
+`
+
+// SQLiteStore keeps recorded rates, per-source health and conversions in an embedded database
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func sqlitePath() string {
+	return filepath.Join(dataDir(), "history.db")
+}
+
+// OpenSQLiteStore opens (or creates) the database at path and applies the schema
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating data dir failed: %v", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening database failed: %v", err)
+	}
+	// SQLite allows a single writer; one connection avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating schema failed: %v", err)
+	}
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating schema failed: %v", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// sqliteColumns are the columns introduced after a database was first created, by table
+var sqliteColumns = []struct{ table, column string }{
+	{"rates", "aggregated INTEGER NOT NULL DEFAULT 0"},
+	{"rates", "high REAL NOT NULL DEFAULT 0"},
+	{"rates", "low REAL NOT NULL DEFAULT 0"},
+	{"source_quotes", "aud REAL"},
+	{"rates", "fx_rate REAL NOT NULL DEFAULT 0"},
+	{"rates", "confidence REAL NOT NULL DEFAULT 0"},
+	{"rates", "aggregation TEXT NOT NULL DEFAULT ''"},
+}
+
+// migrateSQLite adds the columns in sqliteColumns that a database doesn't have yet
+// SQLite has no "ADD COLUMN IF NOT EXISTS", so the existing columns are checked first
+func migrateSQLite(db *sql.DB) error {
+	have := make(map[string]bool)
+	for _, table := range []string{"rates", "source_quotes"} {
+		rows, err := db.Query(`SELECT name FROM pragma_table_info('` + table + `')`)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return err
+			}
+			have[table+"."+name] = true
+		}
+		rows.Close()
+	}
+
+	for _, col := range sqliteColumns {
+		name, _, _ := strings.Cut(col.column, " ")
+		if have[col.table+"."+name] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE ` + col.table + ` ADD COLUMN ` + col.column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// AddSample inserts the rate and its source quotes in one transaction
+func (s *SQLiteStore) AddSample(sample Sample) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	// Rollback after a successful Commit is a no-op, so deferring it covers every error path
+	defer tx.Rollback()
+
+	if err := insertSample(tx, sample); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertSample writes one sample inside an open transaction
+func insertSample(tx *sql.Tx, sample Sample) error {
+	res, err := tx.Exec(`INSERT INTO rates (time, rate_aud, aggregated, high, low, fx_rate, confidence, aggregation) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		sample.Time.UnixMilli(), sample.RateAUD, sample.Aggregated, sample.High, sample.Low, sample.FXRate, sample.Confidence, sample.Aggregation)
+	if err != nil {
+		return fmt.Errorf("inserting rate failed: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for _, src := range sample.Sources {
+		if _, err := tx.Exec(`INSERT INTO source_quotes (rate_id, name, usd, aud, error) VALUES (?, ?, ?, ?, ?)`,
+			id, src.Name, src.USD, src.AUD, src.Error); err != nil {
+			return fmt.Errorf("inserting source quote failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// ReplaceSamples deletes the samples in [from, to) and inserts replacement in one transaction
+func (s *SQLiteStore) ReplaceSamples(from, to time.Time, replacement []Sample) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Foreign keys are off by default in SQLite, so quotes are deleted explicitly rather than by cascade
+	if _, err := tx.Exec(`DELETE FROM source_quotes WHERE rate_id IN (SELECT id FROM rates WHERE time >= ? AND time < ?)`,
+		from.UnixMilli(), to.UnixMilli()); err != nil {
+		return fmt.Errorf("deleting source quotes failed: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM rates WHERE time >= ? AND time < ?`, from.UnixMilli(), to.UnixMilli()); err != nil {
+		return fmt.Errorf("deleting rates failed: %v", err)
+	}
+	for _, sample := range replacement {
+		if err := insertSample(tx, sample); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Samples returns the recorded samples with from <= Time < to, oldest first
+func (s *SQLiteStore) Samples(from, to time.Time) ([]Sample, error) {
+	rows, err := s.db.Query(`
+		SELECT r.id, r.time, r.rate_aud, r.aggregated, r.high, r.low, r.fx_rate, r.confidence, r.aggregation, q.name, q.usd, q.aud, q.error
+		FROM rates r LEFT JOIN source_quotes q ON q.rate_id = r.id
+		WHERE r.time >= ? AND r.time < ?
+		ORDER BY r.time, r.id`, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("querying rates failed: %v", err)
+	}
+	defer rows.Close()
+
+	var samples []Sample
+	lastID := int64(-1)
+	for rows.Next() {
+		var id, ms int64
+		var cur Sample
+		var name, errText sql.NullString
+		var usd, aud sql.NullFloat64
+		if err := rows.Scan(&id, &ms, &cur.RateAUD, &cur.Aggregated, &cur.High, &cur.Low, &cur.FXRate, &cur.Confidence, &cur.Aggregation, &name, &usd, &aud, &errText); err != nil {
+			return nil, err
+		}
+		// The join returns one row per source, so start a new sample when the rate id changes
+		if id != lastID {
+			cur.Time = time.UnixMilli(ms).UTC()
+			samples = append(samples, cur)
+			lastID = id
+		}
+		if name.Valid {
+			cur := &samples[len(samples)-1]
+			cur.Sources = append(cur.Sources, SourceSample{Name: name.String, USD: usd.Float64, AUD: aud.Float64, Error: errText.String})
+		}
+	}
+	return samples, rows.Err()
+}
+
+// AddConversion records a conversion the user performed
+func (s *SQLiteStore) AddConversion(c ConversionRecord) error {
+	_, err := s.db.Exec(`INSERT INTO conversions (time, aud, eth, rate_aud) VALUES (?, ?, ?, ?)`,
+		c.Time.UnixMilli(), c.AUD, c.ETH, c.RateAUD)
+	if err != nil {
+		return fmt.Errorf("inserting conversion failed: %v", err)
+	}
+	return nil
+}
+
+// Conversions returns the conversions with from <= Time < to, oldest first
+func (s *SQLiteStore) Conversions(from, to time.Time) ([]ConversionRecord, error) {
+	rows, err := s.db.Query(`SELECT time, aud, eth, rate_aud FROM conversions
+		WHERE time >= ? AND time < ? ORDER BY time`, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("querying conversions failed: %v", err)
+	}
+	defer rows.Close()
+
+	var out []ConversionRecord
+	for rows.Next() {
+		var ms int64
+		var c ConversionRecord
+		if err := rows.Scan(&ms, &c.AUD, &c.ETH, &c.RateAUD); err != nil {
+			return nil, err
+		}
+		c.Time = time.UnixMilli(ms).UTC()
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// SourceHealth counts successful and failed quotes per source with from <= Time < to
+// The aggregation is done in SQL instead of loading every sample into memory
+func (s *SQLiteStore) SourceHealth(from, to time.Time) ([]SourceReliability, error) {
+	rows, err := s.db.Query(`
+		SELECT q.name, SUM(CASE WHEN q.error = '' THEN 1 ELSE 0 END), COUNT(*)
+		FROM source_quotes q JOIN rates r ON r.id = q.rate_id
+		WHERE r.time >= ? AND r.time < ?
+		GROUP BY q.name ORDER BY q.name`, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("querying source health failed: %v", err)
+	}
+	defer rows.Close()
+
+	var out []SourceReliability
+	for rows.Next() {
+		var r SourceReliability
+		if err := rows.Scan(&r.Name, &r.OK, &r.Total); err != nil {
+			return nil, err
+		}
+		r.Percent = float64(r.OK) / float64(r.Total) * 100
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
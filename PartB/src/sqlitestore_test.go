@@ -0,0 +1,106 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: at, RateAUD: 5012.25, FXRate: 1.566, Confidence: 0.93, Aggregation: "median", Sources: []SourceSample{
+			{Name: "Kraken", USD: 3200.5, AUD: 5012.25},
+			{Name: "Binance", Error: "timeout"},
+		}},
+		{Time: at.Add(time.Minute), RateAUD: 5000, Aggregated: 24, High: 5100, Low: 4900},
+	}
+	for _, s := range samples {
+		if err := store.AddSample(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conversion := ConversionRecord{Time: at, AUD: 100, ETH: 0.019951, RateAUD: 5012.25}
+	if err := store.AddConversion(conversion); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// What was written is all there after opening the file again
+	store, err = OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	got, err := store.Samples(at, at.Add(time.Hour))
+	if err != nil || !reflect.DeepEqual(got, samples) {
+		t.Fatalf("after reopening: %+v, %v\nwant %+v", got, err, samples)
+	}
+	if got, err := store.Conversions(at, at.Add(time.Hour)); err != nil || !reflect.DeepEqual(got, []ConversionRecord{conversion}) {
+		t.Fatalf("conversions after reopening: %+v, %v", got, err)
+	}
+
+	health, err := store.SourceHealth(at, at.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SourceReliability{{Name: "Binance", OK: 0, Total: 1, Percent: 0}, {Name: "Kraken", OK: 1, Total: 1, Percent: 100}}
+	if !reflect.DeepEqual(health, want) {
+		t.Fatalf("SourceHealth = %+v, want %+v", health, want)
+	}
+}
+
+func TestSQLiteStoreMigrates(t *testing.T) {
+	// The schema as the first release created it, before aggregates, AUD quotes and confidence were kept
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, stmt := range []string{
+		`CREATE TABLE rates (id INTEGER PRIMARY KEY, time INTEGER NOT NULL, rate_aud REAL NOT NULL)`,
+		`CREATE TABLE source_quotes (rate_id INTEGER NOT NULL, name TEXT NOT NULL, usd REAL, error TEXT)`,
+		`CREATE TABLE conversions (time INTEGER NOT NULL, aud REAL NOT NULL, eth REAL NOT NULL, rate_aud REAL NOT NULL)`,
+		`INSERT INTO rates (id, time, rate_aud) VALUES (1, ` + strconv.FormatInt(at.UnixMilli(), 10) + `, 4100)`,
+		`INSERT INTO source_quotes (rate_id, name, usd, error) VALUES (1, 'Kraken', 2650, '')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	store, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.AddSample(Sample{Time: at.Add(time.Hour), RateAUD: 4200, FXRate: 1.55, Sources: []SourceSample{{Name: "Kraken", USD: 2700, AUD: 4200}}}); err != nil {
+		t.Fatalf("adding a sample after the migration: %v", err)
+	}
+	got, err := store.Samples(at, at.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Sample{
+		{Time: at, RateAUD: 4100, Sources: []SourceSample{{Name: "Kraken", USD: 2650}}},
+		{Time: at.Add(time.Hour), RateAUD: 4200, FXRate: 1.55, Sources: []SourceSample{{Name: "Kraken", USD: 2700, AUD: 4200}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("after the migration: %+v\nwant %+v", got, want)
+	}
+}
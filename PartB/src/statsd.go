@@ -0,0 +1,161 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatsDConfig is a StatsD or DogStatsD agent each rate refresh is reported to, see "StatsD" in the README
+type StatsDConfig struct {
+	Address string   `json:"address"` // "host:port" of the agent, e.g. "localhost:8125"
+	Prefix  string   `json:"prefix"`  // prepended to every metric name, default "audeth"
+	Flavor  string   `json:"flavor"`  // "statsd" (default) puts the source in the metric name, "dogstatsd" in a tag
+	Tags    []string `json:"tags"`    // DogStatsD tags added to every metric, e.g. ["env:home"]
+}
+
+// statsdPacketSize keeps each datagram inside one Ethernet frame, which is what agents expect
+const statsdPacketSize = 1432
+
+// statsdName turns a source name into a metric name segment, e.g. "Crypto.com" into "crypto_com"
+func statsdName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, s)
+}
+
+// StatsDEmitter sends metrics over UDP; nothing is sent back, so a missing agent only shows up as missing metrics
+type StatsDEmitter struct {
+	addr   string
+	prefix string
+	dog    bool
+	tags   []string
+}
+
+// newStatsDEmitterFromConfig returns the configured emitter, or false when statsd.address isn't set
+func newStatsDEmitterFromConfig(cfg Config) (StatsDEmitter, bool, error) {
+	s := cfg.StatsD
+	if s.Address == "" {
+		return StatsDEmitter{}, false, nil
+	}
+	if _, _, err := net.SplitHostPort(s.Address); err != nil {
+		return StatsDEmitter{}, false, fmt.Errorf("statsd.address: use host:port, e.g. localhost:8125")
+	}
+	e := StatsDEmitter{addr: s.Address, prefix: s.Prefix, tags: s.Tags}
+	if e.prefix == "" {
+		e.prefix = "audeth"
+	}
+	switch s.Flavor {
+	case "", "statsd":
+		if len(s.Tags) > 0 {
+			return StatsDEmitter{}, false, fmt.Errorf("statsd.tags need \"flavor\": \"dogstatsd\"")
+		}
+	case "dogstatsd":
+		e.dog = true
+	default:
+		return StatsDEmitter{}, false, fmt.Errorf("statsd.flavor: unknown flavor %q (use statsd or dogstatsd)", s.Flavor)
+	}
+	return e, true, nil
+}
+
+// metric formats one line, e.g. "audeth.rate_aud:5016|g" or "audeth.source.usd:3300|g|#source:kraken"
+// With plain StatsD the source tag becomes part of the name instead, "audeth.source.kraken.usd"
+func (e StatsDEmitter) metric(name, value, kind, source string) string {
+	tags := e.tags
+	if source != "" {
+		if e.dog {
+			tags = append(tags[:len(tags):len(tags)], "source:"+statsdName(source))
+		} else {
+			prefix, field, _ := strings.Cut(name, ".")
+			name = prefix + "." + statsdName(source) + "." + field
+		}
+	}
+	line := e.prefix + "." + name + ":" + value + "|" + kind
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line
+}
+
+// sampleMetrics are the metrics for one fresh sample:
+// rate_aud, spread_pct and sources gauges, refreshes and errors counters, plus each source's quote gauge or error counter
+func (e StatsDEmitter) sampleMetrics(s Sample) []string {
+	gauge := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	lines := []string{e.metric("rate_aud", gauge(s.RateAUD), "g", ""), e.metric("refreshes", "1", "c", "")}
+	ok, errs := 0, 0
+	for _, src := range s.Sources {
+		if src.Error != "" {
+			errs++
+			lines = append(lines, e.metric("source.errors", "1", "c", src.Name))
+			continue
+		}
+		ok++
+		lines = append(lines, e.metric("source.usd", gauge(src.USD), "g", src.Name))
+	}
+	lines = append(lines, e.metric("sources", strconv.Itoa(ok), "g", ""), e.metric("errors", strconv.Itoa(errs), "c", ""))
+	if spread, found := sampleSpread(s); found {
+		lines = append(lines, e.metric("spread_pct", gauge(spread), "g", ""))
+	}
+	return lines
+}
+
+// Emit sends the sample's metrics, packing as many lines into each datagram as fit
+func (e StatsDEmitter) Emit(s Sample) error {
+	conn, err := net.DialTimeout("udp", e.addr, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("connecting to %s failed: %v", e.addr, err)
+	}
+	defer conn.Close()
+	var packet []byte
+	flush := func() error {
+		if len(packet) == 0 {
+			return nil
+		}
+		_, err := conn.Write(packet)
+		packet = packet[:0]
+		return err
+	}
+	for _, line := range e.sampleMetrics(s) {
+		if len(packet) > 0 && len(packet)+1+len(line) > statsdPacketSize {
+			if err := flush(); err != nil {
+				return fmt.Errorf("sending to %s failed: %v", e.addr, err)
+			}
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	if err := flush(); err != nil {
+		return fmt.Errorf("sending to %s failed: %v", e.addr, err)
+	}
+	return nil
+}
+
+// emitStatsD reports a fresh sample to the configured agent; a failure is only a warning
+func emitStatsD(sample Sample) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return
+	}
+	e, ok, err := newStatsDEmitterFromConfig(cfg)
+	if err == nil && ok {
+		err = e.Emit(sample)
+	}
+	if err != nil {
+		fmt.Fprintln(progressOut(), tr("warning.statsd", redactError(err)))
+	}
+}
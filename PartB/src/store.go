@@ -0,0 +1,116 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store is the storage backend for recorded rates and conversions
+// Features only talk to this interface, so new backends can be added without touching them
+// Range queries return values with from <= Time < to, oldest first
+type Store interface {
+	AddSample(Sample) error
+	Samples(from, to time.Time) ([]Sample, error)
+	// ReplaceSamples removes every sample in [from, to) and stores replacement instead
+	ReplaceSamples(from, to time.Time, replacement []Sample) error
+	AddConversion(ConversionRecord) error
+	Conversions(from, to time.Time) ([]ConversionRecord, error)
+	Close() error
+}
+
+// ConversionRecord is one AUD to ETH conversion done by the user
+type ConversionRecord struct {
+	Time    time.Time `json:"time"`
+	AUD     float64   `json:"aud"`
+	ETH     float64   `json:"eth"`
+	RateAUD float64   `json:"rate_aud"`
+}
+
+// openStore returns the backend selected by the "history" config key
+func openStore(cfg Config) (Store, error) {
+	switch cfg.History {
+	case "", "jsonl":
+		return NewFileStore(dataDir()), nil
+	case "sqlite":
+		return OpenSQLiteStore(sqlitePath())
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown history backend: %s (use jsonl, sqlite or memory)", cfg.History)
+	}
+}
+
+// MemoryStore keeps everything in slices, nothing survives the process
+// Useful when embedding the converter or when history isn't wanted
+type MemoryStore struct {
+	mu          sync.Mutex
+	samples     []Sample
+	conversions []ConversionRecord
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (m *MemoryStore) AddSample(s Sample) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples = append(m.samples, s)
+	return nil
+}
+
+func (m *MemoryStore) Samples(from, to time.Time) ([]Sample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Sample
+	for _, s := range m.samples {
+		if !s.Time.Before(from) && s.Time.Before(to) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) ReplaceSamples(from, to time.Time, replacement []Sample) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.samples[:0]
+	for _, s := range m.samples {
+		if s.Time.Before(from) || !s.Time.Before(to) {
+			kept = append(kept, s)
+		}
+	}
+	m.samples = append(kept, replacement...)
+	sort.SliceStable(m.samples, func(i, j int) bool { return m.samples[i].Time.Before(m.samples[j].Time) })
+	return nil
+}
+
+func (m *MemoryStore) AddConversion(c ConversionRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.conversions = append(m.conversions, c)
+	return nil
+}
+
+func (m *MemoryStore) Conversions(from, to time.Time) ([]ConversionRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []ConversionRecord
+	for _, c := range m.conversions {
+		if !c.Time.Before(from) && c.Time.Before(to) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
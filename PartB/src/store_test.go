@@ -0,0 +1,109 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestStores holds every backend to the Store contract, which is all the features rely on
+func TestStores(t *testing.T) {
+	backends := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"jsonl":  func(t *testing.T) Store { return NewFileStore(t.TempDir()) },
+		"sqlite": func(t *testing.T) Store {
+			s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			defer store.Close()
+			testStore(t, store)
+		})
+	}
+}
+
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	// Whole milliseconds, the finest SQLite keeps
+	at := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	sample := func(minutes int, rate float64) Sample {
+		return Sample{Time: at.Add(time.Duration(minutes) * time.Minute), RateAUD: rate,
+			Sources: []SourceSample{{Name: "Kraken", USD: rate / 1.5}}}
+	}
+	for i := range 4 {
+		if err := store.AddSample(sample(i, 5000+float64(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ranges include from and leave out to
+	got, err := store.Samples(at.Add(time.Minute), at.Add(3*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Sample{sample(1, 5001), sample(2, 5002)}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Samples(1m, 3m) = %+v, want %+v", got, want)
+	}
+
+	// The replaced range is swapped out and the rest kept, oldest first
+	replacement := []Sample{{Time: at.Add(90 * time.Second), RateAUD: 5001.5, Aggregated: 2, High: 5002, Low: 5001}}
+	if err := store.ReplaceSamples(at.Add(time.Minute), at.Add(3*time.Minute), replacement); err != nil {
+		t.Fatal(err)
+	}
+	got, err = store.Samples(time.Time{}, at.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Sample{sample(0, 5000), replacement[0], sample(3, 5003)}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after ReplaceSamples: %+v, want %+v", got, want)
+	}
+
+	conversions := []ConversionRecord{
+		{Time: at, AUD: 100, ETH: 0.02, RateAUD: 5000},
+		{Time: at.Add(time.Minute), AUD: 50, ETH: 0.01, RateAUD: 5000},
+	}
+	for _, c := range conversions {
+		if err := store.AddConversion(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := store.Conversions(at, at.Add(time.Minute)); err != nil || !reflect.DeepEqual(got, conversions[:1]) {
+		t.Fatalf("Conversions(0, 1m) = %+v, %v, want %+v", got, err, conversions[:1])
+	}
+	if got, err := store.Conversions(at.Add(time.Hour), at.Add(2*time.Hour)); err != nil || len(got) != 0 {
+		t.Fatalf("Conversions of an empty range = %+v, %v", got, err)
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	t.Setenv("AUDETH_HOME", t.TempDir())
+	for backend, want := range map[string]Store{"": &FileStore{}, "jsonl": &FileStore{}, "memory": &MemoryStore{}, "sqlite": &SQLiteStore{}} {
+		store, err := openStore(Config{History: backend})
+		if err != nil {
+			t.Fatalf("history %q: %v", backend, err)
+		}
+		if reflect.TypeOf(store) != reflect.TypeOf(want) {
+			t.Errorf("history %q opened a %T", backend, store)
+		}
+		store.Close()
+	}
+	if _, err := openStore(Config{History: "postgres"}); err == nil {
+		t.Fatal("history \"postgres\" was accepted")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir(), "history.db")); err != nil {
+		t.Errorf("history \"sqlite\" didn't create its database: %v", err)
+	}
+}
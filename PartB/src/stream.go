@@ -0,0 +1,243 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// streamDialer opens the WebSocket connections; --replay and --demo set it to nil so nothing is dialled
+var streamDialer = &websocket.Dialer{HandshakeTimeout: 10 * time.Second}
+
+// streamBase replaces the host of every stream URL, set by --api-base like the REST requests
+var streamBase *url.URL
+
+// streamReadTimeout drops a connection that has gone quiet, the exchanges send heartbeats well within it
+const streamReadTimeout = 90 * time.Second
+
+// streamSource describes one exchange's ticker stream
+type streamSource struct {
+	url       string
+	subscribe any // sent once after connecting
+	parse     func([]byte) (float64, bool, error)
+}
+
+// streamSources lists the exchanges that push ETH/USD tickers over WebSockets, keyed by fetcher name
+var streamSources = map[string]streamSource{
+	"Kraken": {
+		url: "wss://ws.kraken.com/v2",
+		subscribe: map[string]any{"method": "subscribe",
+			"params": map[string]any{"channel": "ticker", "symbol": []string{"ETH/USD"}}},
+		parse: parsers.ParseKrakenStream,
+	},
+	"Coinbase": {
+		url:       "wss://ws-feed.exchange.coinbase.com",
+		subscribe: map[string]any{"type": "subscribe", "product_ids": []string{"ETH-USD"}, "channels": []string{"ticker"}},
+		parse:     parsers.ParseCoinbaseStream,
+	},
+	"Bitstamp": {
+		url:       "wss://ws.bitstamp.net",
+		subscribe: map[string]any{"event": "bts:subscribe", "data": map[string]string{"channel": "live_trades_ethusd"}},
+		parse:     parsers.ParseBitstampStream,
+	},
+}
+
+// StreamFetcher keeps the latest price pushed over an exchange's WebSocket
+// FetchPrice answers from memory while that price is younger than maxAge and falls back to the REST API
+// otherwise, so a dropped connection degrades to polling instead of failing
+type StreamFetcher struct {
+	rest   PriceFetcher
+	source streamSource
+	maxAge time.Duration
+	clock  Clock
+
+	mu    sync.Mutex
+	price float64
+	at    time.Time
+}
+
+// NewStreamFetcher wraps rest with the stream for the same exchange; Run must be started to receive prices
+func NewStreamFetcher(rest PriceFetcher, source streamSource, maxAge time.Duration) *StreamFetcher {
+	return &StreamFetcher{rest: rest, source: source, maxAge: maxAge, clock: defaultClock}
+}
+
+func (f *StreamFetcher) Name() string {
+	return f.rest.Name()
+}
+
+// latest returns the streamed price if it is recent enough
+func (f *StreamFetcher) latest() (float64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.price <= 0 || f.clock.Now().Sub(f.at) > f.maxAge {
+		return 0, false
+	}
+	return f.price, true
+}
+
+func (f *StreamFetcher) FetchPrice() (float64, error) {
+	return f.FetchPriceContext(context.Background())
+}
+
+func (f *StreamFetcher) FetchPriceContext(ctx context.Context) (float64, error) {
+	q, err := f.FetchQuoteContext(ctx)
+	return q.Last, err
+}
+
+// FetchQuoteContext is the streamed price, which comes without a bid and ask, or the REST quote with them
+func (f *StreamFetcher) FetchQuoteContext(ctx context.Context) (parsers.Quote, error) {
+	if price, ok := f.latest(); ok {
+		return parsers.Quote{Last: price}, nil
+	}
+	if qf, ok := f.rest.(audeth.QuoteFetcher); ok {
+		return qf.FetchQuoteContext(ctx)
+	}
+	var q parsers.Quote
+	var err error
+	if cf, ok := f.rest.(ContextFetcher); ok {
+		q.Last, err = cf.FetchPriceContext(ctx)
+	} else {
+		q.Last, err = f.rest.FetchPrice()
+	}
+	return q, err
+}
+
+// QuoteCurrency is the REST fetcher's, the streams all quote in USD like it
+func (f *StreamFetcher) QuoteCurrency() string {
+	return audeth.QuoteCurrency(f.rest)
+}
+
+// Run keeps the stream connected until ctx ends, reconnecting with exponential backoff
+func (f *StreamFetcher) Run(ctx context.Context) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		received, err := f.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = time.Second
+		}
+		fmt.Fprintln(progressOut(), tr("stream.disconnected", f.Name(), redactError(err), backoff))
+		select {
+		case <-ctx.Done():
+			return
+		case <-f.clock.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// stream connects once and reads tickers until the connection fails
+// received reports whether any price arrived, only then is the backoff reset,
+// so a server that accepts and immediately drops connections isn't hammered
+func (f *StreamFetcher) stream(ctx context.Context) (received bool, err error) {
+	conn, _, err := streamDialer.DialContext(ctx, streamURL(f.source.url), nil)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	// Closing the connection is the only way to interrupt a blocked read, so ctx is watched separately
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := conn.WriteJSON(f.source.subscribe); err != nil {
+		return false, fmt.Errorf("subscribing failed: %v", err)
+	}
+	fmt.Fprintln(progressOut(), tr("stream.connected", f.Name()))
+	for {
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return received, err
+		}
+		price, ok, err := f.source.parse(msg)
+		if err != nil {
+			fmt.Fprintln(progressOut(), tr("stream.skipping", f.Name(), err))
+			continue
+		}
+		if ok {
+			f.mu.Lock()
+			f.price, f.at = price, f.clock.Now()
+			f.mu.Unlock()
+			received = true
+		}
+	}
+}
+
+// checkStreamURL applies source_policy to a stream, a WebSocket being an upgraded HTTP request:
+// wss is checked as https and ws as http
+func checkStreamURL(raw string, policy URLPolicy) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	checked := *u
+	switch u.Scheme {
+	case "wss":
+		checked.Scheme = "https"
+	case "ws":
+		checked.Scheme = "http"
+	}
+	return policy.check(&checked)
+}
+
+// streamURL applies --api-base, turning http into ws and https into wss
+func streamURL(raw string) string {
+	if streamBase == nil {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Host = streamBase.Host
+	u.Scheme = "wss"
+	if streamBase.Scheme == "http" {
+		u.Scheme = "ws"
+	}
+	return u.String()
+}
+
+// withStreams swaps every fetcher that has a stream for a StreamFetcher and starts its connection
+// Fetchers without a stream are returned unchanged, and so are those the config points at another URL,
+// as the stream would go around it, and those whose stream policy refuses
+func withStreams(ctx context.Context, fetchers []PriceFetcher, maxAge time.Duration, policy URLPolicy) []PriceFetcher {
+	if streamDialer == nil {
+		fmt.Fprintln(progressOut(), tr("stream.off"))
+		return fetchers
+	}
+	out := make([]PriceFetcher, len(fetchers))
+	for i, f := range fetchers {
+		out[i] = f
+		source, ok := streamSources[f.Name()]
+		if !ok {
+			continue
+		}
+		// Only configured sources have a policy, see applySources
+		if api, ok := f.(API); !ok || api.policy != nil {
+			fmt.Fprintln(progressOut(), tr("stream.configured", f.Name()))
+			continue
+		}
+		if err := checkStreamURL(source.url, policy); err != nil {
+			fmt.Fprintln(progressOut(), tr("stream.refused", f.Name(), err))
+			continue
+		}
+		sf := NewStreamFetcher(f, source, maxAge)
+		go sf.Run(ctx)
+		out[i] = sf
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audethtest"
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/parsers"
+)
+
+// quoteFetcher is a REST source with a bid and ask
+type quoteFetcher struct{ q parsers.Quote }
+
+func (f quoteFetcher) Name() string                 { return "Kraken" }
+func (f quoteFetcher) FetchPrice() (float64, error) { return f.q.Last, nil }
+func (f quoteFetcher) FetchQuoteContext(ctx context.Context) (parsers.Quote, error) {
+	return f.q, nil
+}
+
+func TestStreamFallbackKeepsBidAndAsk(t *testing.T) {
+	want := parsers.Quote{Last: 3000, Bid: 2999.5, Ask: 3000.5}
+	sf := NewStreamFetcher(quoteFetcher{want}, streamSources["Kraken"], time.Minute)
+	if got, err := sf.FetchQuoteContext(context.Background()); err != nil || got != want {
+		t.Errorf("with nothing streamed got %+v, %v, want the REST quote %+v", got, err, want)
+	}
+	sf.price, sf.at = 3010, sf.clock.Now()
+	if got, _ := sf.FetchQuoteContext(context.Background()); got.Last != 3010 {
+		t.Errorf("got %+v, want the streamed 3010", got)
+	}
+}
+
+func TestWithStreamsPolicy(t *testing.T) {
+	// A cancelled context keeps the streams that are started from dialling
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	builtin := NewAPI("Kraken", "https://api.kraken.com/0/public/Ticker?pair=ETHUSD")
+	configured := NewAPI("Kraken", "https://kraken.example.test/ticker")
+	configured.policy = &URLPolicy{}
+
+	tests := []struct {
+		name     string
+		fetcher  PriceFetcher
+		policy   URLPolicy
+		streamed bool
+	}{
+		{"built in", builtin, URLPolicy{}, true},
+		{"allowed host", builtin, URLPolicy{AllowHosts: []string{"*.kraken.com"}}, true},
+		{"denied host", builtin, URLPolicy{DenyHosts: []string{"ws.kraken.com"}}, false},
+		{"host not allowed", builtin, URLPolicy{AllowHosts: []string{"api.kraken.com"}}, false},
+		{"scheme not allowed", builtin, URLPolicy{Schemes: []string{"http"}}, false},
+		{"url from the config", configured, URLPolicy{}, false},
+	}
+	for _, tt := range tests {
+		out := withStreams(ctx, []PriceFetcher{tt.fetcher}, time.Minute, tt.policy)
+		if _, streamed := out[0].(*StreamFetcher); streamed != tt.streamed {
+			t.Errorf("%s: streamed is %v, want %v", tt.name, streamed, tt.streamed)
+		}
+	}
+}
+
+func TestStreamReconnectsOnTheClock(t *testing.T) {
+	// The server accepts every connection and drops it straight away
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		connections.Add(1)
+		conn.Close()
+	}))
+	defer srv.Close()
+	saved := streamBase
+	streamBase, _ = url.Parse(srv.URL)
+	t.Cleanup(func() { streamBase = saved })
+
+	clock := audethtest.NewFakeClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	sf := NewStreamFetcher(NewAPI("Kraken", "https://api.kraken.com/"), streamSources["Kraken"], time.Minute)
+	sf.clock = clock
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sf.Run(ctx)
+
+	waitFor := func(what string, done func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !done(); time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+		}
+	}
+	waitFor("the first backoff", func() bool { return clock.Waiters() == 1 })
+	if n := connections.Load(); n != 1 {
+		t.Fatalf("got %d connections before the backoff ended, want 1", n)
+	}
+	clock.Advance(time.Second)
+	waitFor("the second connection", func() bool { return connections.Load() == 2 && clock.Waiters() == 1 })
+
+	// Nothing was received, so the backoff has doubled to 2s
+	clock.Advance(time.Second)
+	time.Sleep(20 * time.Millisecond)
+	if n := connections.Load(); n != 2 {
+		t.Errorf("reconnected after 1s of a 2s backoff, %d connections", n)
+	}
+	clock.Advance(time.Second)
+	waitFor("the third connection", func() bool { return connections.Load() == 3 })
+}
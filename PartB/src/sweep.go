@@ -0,0 +1,118 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxSweepRows caps convert --range; a longer table would be mistyped rather than wanted
+const maxSweepRows = 10000
+
+// amountRange is convert --range FROM:TO:STEP, e.g. 100:1000:100
+type amountRange struct {
+	from, to, step float64
+}
+
+// parseRange reads FROM:TO:STEP, where TO is included when the steps land on it
+func parseRange(s string) (amountRange, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return amountRange{}, fmt.Errorf("want FROM:TO:STEP, e.g. 100:1000:100")
+	}
+	var nums [3]float64
+	for i, p := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return amountRange{}, fmt.Errorf("%q isn't a number", p)
+		}
+		nums[i] = n
+	}
+	r := amountRange{from: nums[0], to: nums[1], step: nums[2]}
+	switch {
+	case r.from <= 0:
+		return r, fmt.Errorf("FROM must be positive")
+	case r.to < r.from:
+		return r, fmt.Errorf("TO can't be less than FROM")
+	case r.step <= 0:
+		return r, fmt.Errorf("STEP must be positive")
+	}
+	if rows := (r.to-r.from)/r.step + 1; rows > maxSweepRows {
+		return r, fmt.Errorf("that is %.0f amounts, the most is %d", rows, maxSweepRows)
+	}
+	return r, nil
+}
+
+// amounts lists the range; each one is worked out from FROM so the steps don't add up rounding errors
+func (r amountRange) amounts() []float64 {
+	// The tolerance keeps TO when the division lands a hair under a whole number, e.g. 0.1:0.3:0.1
+	n := int(math.Floor((r.to-r.from)/r.step+1e-9)) + 1
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = r.from + float64(i)*r.step
+	}
+	return out
+}
+
+// runSweep converts every amount in r at one rate, fetched once
+// The amounts are only worked out for planning, so no conversion is recorded; the rate is saved like any other
+func runSweep(converter *Converter, store Store, r amountRange) error {
+	start := defaultClock.Now()
+	sample, err := converter.Sample()
+	if err != nil {
+		return err
+	}
+	cached := sample.Time.Before(start)
+	if !cached {
+		persistSample(store, sample)
+	}
+	var results []ConversionResult
+	for _, aud := range r.amounts() {
+		result := newConversionResult(defaultClock.Now(), aud, sample, cached, nil)
+		result.LockedUntil = converter.LockedUntil().UTC()
+		results = append(results, result)
+	}
+
+	if outputFormat != "text" {
+		out := newResultWriter(os.Stdout)
+		for _, result := range results {
+			if err := out.Write(result); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}
+	printSweep(results)
+	return nil
+}
+
+// printSweep is the text table of convert --range; --detailed adds gwei and US dollar columns
+func printSweep(results []ConversionResult) {
+	label := ""
+	if results[0].Cached {
+		label = tr("result.cached")
+	}
+	fmt.Println(tr("sweep.title", formatMoney(results[0].Rate), label))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if detailed {
+		fmt.Fprintln(w, "AUD\tETH\tgwei\tUSD\t")
+	} else {
+		fmt.Fprintln(w, "AUD\tETH\t")
+	}
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%.8f\t", formatMoney(r.AUD), r.ETH)
+		if detailed {
+			fmt.Fprintf(w, "%s\t%s\t", formatGrouped(r.Gwei, 0), formatMoney(r.USD))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
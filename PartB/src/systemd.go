@@ -0,0 +1,118 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The systemd side of "serve": readiness, the watchdog and socket activation, see "Running under systemd"
+// in the README and the units in systemd/. Each one does nothing unless systemd set its variables,
+// so the server runs the same anywhere else
+
+// sdListenFDsStart is the first file descriptor systemd passes, after stdin, stdout and stderr
+const sdListenFDsStart = 3
+
+// sdNotify sends one state, e.g. "READY=1", to the service manager over $NOTIFY_SOCKET
+// It reports false without an error when the service isn't run with Type=notify
+func sdNotify(state string) (bool, error) {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return false, nil
+	}
+	// A leading @ is a socket in the abstract namespace
+	if strings.HasPrefix(path, "@") {
+		path = "\x00" + path[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("connecting to NOTIFY_SOCKET failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("notifying systemd failed: %v", err)
+	}
+	return true, nil
+}
+
+// notifySystemd is sdNotify for states whose failure only needs a warning
+func notifySystemd(state string) {
+	if _, err := sdNotify(state); err != nil {
+		fmt.Fprintln(progressOut(), tr("warning.systemd", err))
+	}
+}
+
+// sdWatchdogInterval is how often systemd expects WATCHDOG=1, from WatchdogSec=; 0 means no watchdog
+func sdWatchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	// WATCHDOG_PID, when set, says which process the watchdog is for; a child started by us isn't
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC %q", usec)
+	}
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// runWatchdog pings the watchdog at half its interval until stop is closed, as sd_watchdog_enabled(3) advises
+// alive is asked first, so a server that has stopped serving is restarted rather than kept alive by this goroutine
+func runWatchdog(interval time.Duration, alive func() bool, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if alive() {
+				notifySystemd("WATCHDOG=1")
+			}
+		}
+	}
+}
+
+// systemdListeners returns the sockets systemd opened for a .socket unit, none when not socket activated
+// The variables are cleared so a program started from here doesn't take the sockets for its own
+func systemdListeners() ([]net.Listener, error) {
+	pid, fds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	if fds == "" || pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", fds)
+	}
+	var listeners []net.Listener
+	for i := range n {
+		name := "LISTEN_FD_" + strconv.Itoa(sdListenFDsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(sdListenFDsStart+i), name)
+		l, err := net.FileListener(f)
+		// FileListener duplicates the descriptor, so the original is closed either way
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket %s from systemd: %v", name, err)
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
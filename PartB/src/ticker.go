@@ -0,0 +1,146 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/paudis/go-AudToEthConverter.git/PartB/src/audeth"
+)
+
+// Ticker mode is set by --ticker: one line with the current rate, rewritten every tickerRefresh
+// for status bars such as polybar, i3blocks and tmux; see "Status bar ticker" in the README
+var (
+	tickerMode     bool
+	tickerRefresh  time.Duration
+	tickerTemplate *template.Template
+)
+
+// defaultTickerFormat is the ticker line without --format, e.g. "ETH $5,016.00 ▲1.4%"
+const defaultTickerFormat = `{{if .Error}}ETH ?{{else}}ETH ${{money .Rate}}{{if .Change}} {{change .Change}}{{end}}{{end}}`
+
+// TickerLine is what a --ticker template renders
+type TickerLine struct {
+	Time    time.Time // when the line was rendered
+	Rate    float64   // AUD per ETH
+	USD     float64   // the mean ETH/USD quote
+	Change  float64   // percent since the oldest sample of the last 24 hours, 0 without one
+	Sources int       // sources that answered
+	Cached  bool      // the rate came from a cache rather than this refresh
+	Error   string    // set when no rate could be had; Rate is then the last one known, or 0
+}
+
+// tickerFuncs are templateFuncs plus {{change .Change}}, which gives e.g. "▲1.4%" or "▼0.3%"
+var tickerFuncs = template.FuncMap{
+	"change": func(pct float64) string {
+		arrow := "▲"
+		switch {
+		case pct < 0:
+			arrow = "▼"
+		case pct == 0:
+			arrow = "="
+		}
+		return fmt.Sprintf("%s%.1f%%", arrow, math.Abs(pct))
+	},
+}
+
+// setTicker applies --ticker, with --format as the line's template instead of each conversion's
+func setTicker(refresh time.Duration, format string) error {
+	if outputFormat != "text" {
+		return fmt.Errorf("--ticker can't be combined with --output %s", outputFormat)
+	}
+	if refresh < 0 {
+		return fmt.Errorf("--refresh can't be negative")
+	}
+	if format == "" {
+		format = defaultTickerFormat
+	}
+	t, err := template.New("ticker").Funcs(templateFuncs).Funcs(tickerFuncs).Option("missingkey=error").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %v", err)
+	}
+	if err := t.Execute(io.Discard, TickerLine{}); err != nil {
+		return fmt.Errorf("invalid --format: %v", err)
+	}
+	tickerMode, tickerRefresh, tickerTemplate = true, refresh, t
+	return nil
+}
+
+// newTickerLine describes sample, and the change since day, the oldest sample of the last 24 hours
+func newTickerLine(now time.Time, sample Sample, day []Sample, cached bool, err error) TickerLine {
+	line := TickerLine{Time: now, Rate: sample.RateAUD, USD: sample.Quote().USD, Cached: cached}
+	if err != nil {
+		line.Error = redact(err.Error())
+	}
+	for _, src := range sample.Sources {
+		if src.Error == "" {
+			line.Sources++
+		}
+	}
+	if len(day) > 0 && day[0].RateAUD > 0 && sample.RateAUD > 0 {
+		line.Change = (sample.RateAUD - day[0].RateAUD) / day[0].RateAUD * 100
+	}
+	return line
+}
+
+// runTicker prints the ticker line until the program is stopped; --refresh 0 prints it once, for tmux's #(...)
+// and i3blocks' interval, which run the command again themselves
+// On a terminal the line is rewritten in place; in a pipe every update is a new line, which is what
+// polybar's tail = true and i3blocks' interval = persist read
+func runTicker() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	converter, err := newConverterFromConfig(cfg, audeth.WithMinConfidence(minConfidence))
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	inPlace := isTerminal(os.Stdout)
+	var last Sample // shown with the error when a refresh fails
+	for {
+		start := defaultClock.Now()
+		sample, err := converter.Sample()
+		cached := sample.Time.Before(start)
+		if err == nil {
+			if !cached {
+				persistSample(store, sample)
+			}
+			last = sample
+		} else {
+			sample = last
+		}
+		now := defaultClock.Now()
+		// Without the history the line still shows, just without the change
+		day, _ := store.Samples(now.Add(-24*time.Hour), now)
+		var b strings.Builder
+		if err := tickerTemplate.Execute(&b, newTickerLine(now, sample, day, cached, err)); err != nil {
+			return fmt.Errorf("rendering --format failed: %v", err)
+		}
+		text := strings.ReplaceAll(b.String(), "\n", " ")
+		if inPlace && tickerRefresh > 0 {
+			fmt.Printf("\r\x1b[K%s", text)
+		} else {
+			fmt.Println(text)
+		}
+		if tickerRefresh == 0 {
+			return nil
+		}
+		defaultClock.Sleep(tickerRefresh)
+	}
+}
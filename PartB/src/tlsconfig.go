@@ -0,0 +1,132 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TLSConfig hardens the HTTPS connections to the exchanges
+// Pins map a host to the public keys it may present, written "sha256/<base64 of the SPKI hash>"
+// as in HPKP; the connection is accepted when any certificate in the verified chain matches one
+type TLSConfig struct {
+	MinVersion string              `json:"min_version"` // "1.2" (default) or "1.3"
+	Pins       map[string][]string `json:"pins"`        // e.g. "api.kraken.com": ["sha256/..."]
+}
+
+// tlsVersions maps the config spelling onto crypto/tls constants
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// spkiPin returns the pin of a certificate's public key
+// Hashing the key rather than the whole certificate keeps the pin valid across renewals with the same key
+func spkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// newTLSClientConfig builds the tls.Config for cfg
+// Pinning runs in VerifyConnection, after the normal chain verification, so it only ever narrows what is trusted
+func newTLSClientConfig(cfg TLSConfig) (*tls.Config, error) {
+	min, ok := tlsVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("tls.min_version: %q is not supported (use 1.2 or 1.3)", cfg.MinVersion)
+	}
+	pins := make(map[string]map[string]bool)
+	for host, list := range cfg.Pins {
+		if len(list) == 0 {
+			return nil, fmt.Errorf("tls.pins: %s has no pins", host)
+		}
+		// Pins are looked up by the name the connection was made to, and a connection to an address has none
+		if net.ParseIP(host) != nil {
+			return nil, fmt.Errorf("tls.pins: %s is an IP address, pin the host name instead", host)
+		}
+		set := make(map[string]bool)
+		for _, pin := range list {
+			if !strings.HasPrefix(pin, "sha256/") {
+				return nil, fmt.Errorf("tls.pins: %s: %q should start with sha256/", host, pin)
+			}
+			set[pin] = true
+		}
+		pins[strings.ToLower(host)] = set
+	}
+
+	conf := &tls.Config{MinVersion: min}
+	if len(pins) > 0 {
+		conf.VerifyConnection = func(cs tls.ConnectionState) error {
+			want, ok := pins[strings.ToLower(cs.ServerName)]
+			if !ok {
+				return nil
+			}
+			for _, chain := range cs.VerifiedChains {
+				for _, cert := range chain {
+					if want[spkiPin(cert)] {
+						return nil
+					}
+				}
+			}
+			return fmt.Errorf("certificate for %s doesn't match any pinned key", cs.ServerName)
+		}
+	}
+	return conf, nil
+}
+
+// configureTLS applies the config's tls section to the shared transport and the stream dialer
+// It runs before --record, --api-base and the other wrappers, which all sit on top of this transport
+func configureTLS() error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	conf, err := newTLSClientConfig(cfg.TLS)
+	if err != nil {
+		return err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = conf
+	httpTransport = transport
+	streamDialer.TLSClientConfig = conf
+	return nil
+}
+
+// runPins implements the "pins" command: it connects to each host and prints the pins of its chain,
+// ready to paste into tls.pins
+func runPins(args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("usage: pins HOST...")
+	}
+	for _, host := range args {
+		addr := host
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			addr = net.JoinHostPort(host, "443")
+		}
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{MinVersion: tls.VersionTLS12})
+		if err != nil {
+			return fmt.Errorf("connecting to %s failed: %v", host, err)
+		}
+		fmt.Printf("%s (TLS %s)\n", host, strings.TrimPrefix(tls.VersionName(conn.ConnectionState().Version), "TLS "))
+		for _, chain := range conn.ConnectionState().VerifiedChains {
+			for _, cert := range chain {
+				fmt.Printf("  %s  %s\n", spkiPin(cert), cert.Subject.CommonName)
+			}
+			break
+		}
+		conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newQuietTLSServer starts an HTTPS test server that doesn't log the handshakes the tests make fail
+func newQuietTLSServer(conf *tls.Config) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = conf
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	return server
+}
+
+func TestTLSPins(t *testing.T) {
+	server := newQuietTLSServer(nil)
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	pin := spkiPin(server.Certificate())
+
+	get := func(cfg TLSConfig) error {
+		t.Helper()
+		conf, err := newTLSClientConfig(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The test certificate is for example.com, which is what the pins are looked up by
+		conf.RootCAs, conf.ServerName = roots, "example.com"
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: conf}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(TLSConfig{Pins: map[string][]string{"example.com": {pin}}}); err != nil {
+		t.Fatalf("the pinned key was refused: %v", err)
+	}
+	if err := get(TLSConfig{Pins: map[string][]string{"api.kraken.com": {"sha256/AAAA"}}}); err != nil {
+		t.Fatalf("a host without pins was refused: %v", err)
+	}
+	other := "sha256/" + strings.Repeat("A", 43) + "="
+	if err := get(TLSConfig{Pins: map[string][]string{"example.com": {other}}}); err == nil || !strings.Contains(err.Error(), "doesn't match any pinned key") {
+		t.Fatalf("a pin mismatch: %v, want it refused", err)
+	}
+}
+
+func TestTLSMinVersion(t *testing.T) {
+	server := newQuietTLSServer(&tls.Config{MaxVersion: tls.VersionTLS12})
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	conf, err := newTLSClientConfig(TLSConfig{MinVersion: "1.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.RootCAs = roots
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: conf}}
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("a TLS 1.2 server was accepted with min_version 1.3")
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	tests := []struct {
+		cfg  TLSConfig
+		want string
+	}{
+		{TLSConfig{MinVersion: "1.1"}, "not supported"},
+		{TLSConfig{Pins: map[string][]string{"api.kraken.com": {}}}, "has no pins"},
+		{TLSConfig{Pins: map[string][]string{"api.kraken.com": {"md5/abc"}}}, "should start with sha256/"},
+		{TLSConfig{Pins: map[string][]string{"104.16.0.1": {"sha256/AAAA"}}}, "is an IP address"},
+	}
+	for _, tt := range tests {
+		if _, err := newTLSClientConfig(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("newTLSClientConfig(%+v) = %v, want an error with %q", tt.cfg, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// pathTransport answers every request, keeping the fixture path each one would be recorded under
+type pathTransport struct{ paths *[]string }
+
+func (t pathTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	*t.paths = append(*t.paths, fixturePath("fixtures", req))
+	return httptest.NewRecorder().Result(), nil
+}
+
+func TestFixturePathSigning(t *testing.T) {
+	var paths []string
+	get := func(transport http.RoundTripper, url string) {
+		t.Helper()
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A timestamp of the request's own picks what it asks for
+	plain := pathTransport{&paths}
+	get(plain, "https://api.example.com/klines?symbol=ETHUSDT&timestamp=1000")
+	get(plain, "https://api.example.com/klines?symbol=ETHUSDT&timestamp=2000")
+	if paths[0] == paths[1] {
+		t.Fatalf("requests for different timestamps share the fixture %s", paths[0])
+	}
+
+	// The signer's timestamp and signature change on every request, signed requests still share one fixture
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	signer := &HMACSigner{scheme: hmacSchemes["binance"], key: "key", secret: []byte("secret"), now: func() time.Time { return now }}
+	signed := signingTransport{signer: signer, next: plain}
+	get(signed, "https://api.example.com/account?symbol=ETHUSDT")
+	now = now.Add(time.Minute)
+	get(signed, "https://api.example.com/account?symbol=ETHUSDT")
+	if paths[2] != paths[3] {
+		t.Fatalf("one signed request recorded as %s and %s", paths[2], paths[3])
+	}
+	get(plain, "https://api.example.com/account?symbol=ETHUSDT")
+	if paths[2] != paths[4] {
+		t.Fatalf("signed request recorded as %s, unsigned as %s", paths[2], paths[4])
+	}
+}
@@ -0,0 +1,191 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// BuildInfo is what the "version" command reports about this binary
+type BuildInfo struct {
+	Version    string    `json:"version"`
+	Commit     string    `json:"commit,omitempty"`
+	CommitTime time.Time `json:"commit_time,omitzero"`
+	Modified   bool      `json:"modified,omitempty"` // built from a checkout with uncommitted changes
+	Go         string    `json:"go"`
+	OS         string    `json:"os"`
+	Arch       string    `json:"arch"`
+	Module     string    `json:"module,omitempty"`
+
+	// Set by -check-apis
+	APIs   []APICompat `json:"apis,omitempty"`
+	Latest string      `json:"latest_release,omitempty"` // only looked up when an API has changed
+}
+
+// APICompat is how one exchange's live response went with this binary's parser
+type APICompat struct {
+	Name       string `json:"name"`
+	APIVersion string `json:"api_version,omitempty"` // from the endpoint's path, e.g. "v3"
+	Status     string `json:"status"`                // "ok", "changed" or "down"
+	Error      string `json:"error,omitempty"`
+}
+
+// readBuildInfo fills in the version from -ldflags and the rest from what the Go toolchain embeds
+// go build in a git checkout records the commit; go install of a tagged release records its version,
+// which is used when the build has no version of its own
+func readBuildInfo() BuildInfo {
+	info := BuildInfo{Version: version, Go: runtime.Version(), OS: runtime.GOOS, Arch: runtime.GOARCH}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = bi.Main.Path
+	// go build in a checkout stamps a pseudo-version such as v0.0.0-20261014083444-8e41f5c50157, which is still a development build
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" && !pseudoVersion.MatchString(bi.Main.Version) {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.CommitTime, _ = time.Parse(time.RFC3339, s.Value)
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}|\+dirty$`)
+
+// apiVersionPattern finds the version segment of an endpoint's path: /api/v3/, /v2/, Kraken's /0/ or Luno's /api/1/
+var apiVersionPattern = regexp.MustCompile(`/(v\d+|\d+)/`)
+
+// versionFlags are the flags of the "version" command
+type versionFlags struct {
+	format    string
+	checkAPIs bool
+	endpoint  string
+}
+
+func (f *versionFlags) flagSet() *flag.FlagSet {
+	fs := newFlagSet("version")
+	fs.StringVar(&f.format, "format", "text", "output format: text or json")
+	fs.BoolVar(&f.checkAPIs, "check-apis", false, "call every exchange once and check this binary's parsers still read what it sends")
+	fs.StringVar(&f.endpoint, "endpoint", defaultReleaseEndpoint, "where -check-apis looks for a newer release, in GitHub's releases API format")
+	return fs
+}
+
+// runVersion implements the "version" command
+func runVersion(args []string) error {
+	var flags versionFlags
+	fs := flags.flagSet()
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if flags.format != "text" && flags.format != "json" {
+		return usageErrorf("unknown format: %s (use text or json)", flags.format)
+	}
+
+	info := readBuildInfo()
+	changed := 0
+	if flags.checkAPIs {
+		for _, r := range runContractChecks(liveChecks()) {
+			api := APICompat{Name: r.check.name, Status: "ok"}
+			if m := apiVersionPattern.FindStringSubmatch(r.check.url); m != nil {
+				api.APIVersion = m[1]
+			}
+			switch {
+			case r.transport != nil:
+				api.Status, api.Error = "down", redactError(r.transport).Error()
+			case r.schema != nil:
+				api.Status, api.Error = "changed", r.schema.Error()
+				changed++
+			}
+			info.APIs = append(info.APIs, api)
+		}
+		// A newer release is only worth asking for when this one can't read an exchange any more
+		if changed > 0 {
+			var rel release
+			if err := fetchUpdateJSON(flags.endpoint, &rel); err != nil {
+				fmt.Fprintln(os.Stderr, tr("warning.release_check", redactError(err)))
+			}
+			info.Latest = rel.Tag
+		}
+	}
+
+	if flags.format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			return err
+		}
+	} else {
+		printBuildInfo(info)
+	}
+	if changed > 0 {
+		return fmt.Errorf("%d of %d APIs changed what they send", changed, len(info.APIs))
+	}
+	return nil
+}
+
+func printBuildInfo(info BuildInfo) {
+	fmt.Printf("audeth %s\n", info.Version)
+	if info.Commit != "" {
+		commit := info.Commit
+		if info.Modified {
+			commit += " (modified)"
+		}
+		fmt.Printf("commit   %s\n", commit)
+	}
+	if !info.CommitTime.IsZero() {
+		fmt.Printf("date     %s\n", info.CommitTime.Format(time.RFC3339))
+	}
+	fmt.Printf("go       %s %s/%s\n", info.Go, info.OS, info.Arch)
+	if info.APIs == nil {
+		return
+	}
+
+	fmt.Println()
+	changed := 0
+	for _, api := range info.APIs {
+		v := api.APIVersion
+		if v == "" {
+			v = "-"
+		}
+		switch api.Status {
+		case "ok":
+			fmt.Printf("ok       %-20s %s\n", api.Name, v)
+		case "down":
+			fmt.Printf("DOWN     %-20s %-4s %s\n", api.Name, v, api.Error)
+		default:
+			changed++
+			fmt.Printf("CHANGED  %-20s %-4s %s\n", api.Name, v, api.Error)
+		}
+	}
+	if changed == 0 {
+		fmt.Println("\n" + tr("version.apis_ok"))
+		return
+	}
+	fmt.Println("\n" + tr("version.apis_changed", changed, len(info.APIs)))
+	if info.Latest != "" && compareVersions(info.Latest, info.Version) > 0 {
+		update := "self-update"
+		if info.Version == "dev" {
+			update += " -force" // self-update leaves a development build alone otherwise
+		}
+		fmt.Println(tr("version.upgrade", info.Latest, update))
+		return
+	}
+	fmt.Println(tr("version.no_upgrade"))
+}
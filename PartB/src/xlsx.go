@@ -0,0 +1,233 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An .xlsx file is a zip of XML parts; this writes the few a workbook needs, with inline strings
+// instead of a shared string table, which Excel, LibreOffice and Google Sheets all read
+
+// Cell styles, indexes into cellXfs in xlsxStyles
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleTime
+	xlsxStyleMoney
+	xlsxStyleETH
+	xlsxStylePercent
+)
+
+// xlsxCell is one typed cell: numbers stay numbers, so the spreadsheet can sum and chart them
+type xlsxCell struct {
+	kind  byte // 'n' number, 's' text, 'b' bool, 0 empty
+	num   float64
+	text  string
+	style int
+}
+
+func xlsxNumber(v float64) xlsxCell { return xlsxCell{kind: 'n', num: v} }
+func xlsxMoney(v float64) xlsxCell  { return xlsxCell{kind: 'n', num: v, style: xlsxStyleMoney} }
+func xlsxETH(v float64) xlsxCell    { return xlsxCell{kind: 'n', num: v, style: xlsxStyleETH} }
+func xlsxText(s string) xlsxCell    { return xlsxCell{kind: 's', text: s} }
+func xlsxBool(b bool) xlsxCell {
+	if b {
+		return xlsxCell{kind: 'b', num: 1}
+	}
+	return xlsxCell{kind: 'b'}
+}
+
+// xlsxPercent takes a percentage such as 2.5 and stores 0.025, which the cell shows as 2.50%
+func xlsxPercent(pct float64) xlsxCell {
+	return xlsxCell{kind: 'n', num: pct / 100, style: xlsxStylePercent}
+}
+
+// xlsxTime stores t as a spreadsheet date: days since 1899-12-30, in displayLocation's wall time
+// Spreadsheets have no time zones, so the times show as they would on screen; a zero time is an empty cell
+func xlsxTime(t time.Time) xlsxCell {
+	if t.IsZero() {
+		return xlsxCell{}
+	}
+	local := t.In(displayLocation)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return xlsxCell{kind: 'n', num: wall.Sub(epoch).Hours() / 24, style: xlsxStyleTime}
+}
+
+// xlsxSheet is one worksheet; with a header, the header row is bold and stays in view when scrolling
+type xlsxSheet struct {
+	name   string
+	header []string
+	rows   [][]xlsxCell
+}
+
+// AddRow appends a row of cells
+func (s *xlsxSheet) AddRow(cells ...xlsxCell) {
+	s.rows = append(s.rows, cells)
+}
+
+// xlsxWorkbook collects sheets and writes them as one .xlsx file
+type xlsxWorkbook struct {
+	sheets []*xlsxSheet
+}
+
+// AddSheet adds a sheet; names must be unique and at most 31 characters, which the callers' fixed names are
+func (wb *xlsxWorkbook) AddSheet(name string, header ...string) *xlsxSheet {
+	s := &xlsxSheet{name: name, header: header}
+	wb.sheets = append(wb.sheets, s)
+	return s
+}
+
+// Write writes the workbook
+// The zip entries carry no modification time, so the same data always gives the same bytes
+func (wb *xlsxWorkbook) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	var workbook, rels, types strings.Builder
+	for i, s := range wb.sheets {
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(s.name), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	styles := len(wb.sheets) + 1
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, styles)
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + workbook.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for i, s := range wb.sheets {
+		parts = append(parts, struct{ name, body string }{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+	for _, p := range parts {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: p.name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xml renders the worksheet part
+func (s *xlsxSheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	rows := s.rows
+	if s.header != nil {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+		header := make([]xlsxCell, len(s.header))
+		for i, h := range s.header {
+			header[i] = xlsxCell{kind: 's', text: h, style: xlsxStyleHeader}
+		}
+		rows = append([][]xlsxCell{header}, rows...)
+	}
+
+	// Column widths from the longest text in each column; dates and numbers get a fixed width that fits them
+	var widths []int
+	for _, row := range rows {
+		for i, c := range row {
+			w := 14
+			if c.kind == 's' {
+				w = len(c.text) + 2
+			}
+			if c.style == xlsxStyleTime {
+				w = 20
+			}
+			for len(widths) <= i {
+				widths = append(widths, 8)
+			}
+			widths[i] = max(widths[i], min(w, 60))
+		}
+	}
+	if len(widths) > 0 {
+		b.WriteString("<cols>")
+		for i, w := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString("</cols>")
+	}
+
+	b.WriteString("<sheetData>")
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for i, c := range row {
+			ref := xlsxColumn(i) + strconv.Itoa(r+1)
+			style := ""
+			if c.style != xlsxStyleDefault {
+				style = fmt.Sprintf(` s="%d"`, c.style)
+			}
+			switch c.kind {
+			case 'n':
+				if math.IsNaN(c.num) || math.IsInf(c.num, 0) {
+					continue
+				}
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, ref, style, strconv.FormatFloat(c.num, 'f', -1, 64))
+			case 'b':
+				fmt.Fprintf(&b, `<c r="%s"%s t="b"><v>%d</v></c>`, ref, style, int(c.num))
+			case 's':
+				fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, xlsxEscape(c.text))
+			}
+		}
+		b.WriteString("</row>")
+	}
+	b.WriteString("</sheetData></worksheet>")
+	return b.String()
+}
+
+// xlsxColumn turns a zero-based column index into its letters: 0 is A, 25 is Z, 26 is AA
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxEscape escapes text for an XML element or attribute
+func xlsxEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// xlsxStyles defines the number formats behind the xlsxStyle constants, in the same order
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="3"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/>` +
+	`<numFmt numFmtId="165" formatCode="#,##0.00"/><numFmt numFmtId="166" formatCode="0.00000000"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="6">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="166" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`
@@ -0,0 +1,206 @@
+// Thanh Vu | 10582614 | Online
+// Program summary: Convert Australian dollars to Ethereum using Go
+// CSP3341 Programming Languages and Paradigms | Sem 1 2025
+// Ali Hur
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// The workbooks written by "report -format xlsx", "history export -format xlsx" and "--output xlsx"
+// Each starts with a Summary sheet; the other sheets have one typed row per sample, quote or conversion
+
+// xlsxHeading is a bold text cell, for headings inside a Summary sheet
+func xlsxHeading(s string) xlsxCell {
+	return xlsxCell{kind: 's', text: s, style: xlsxStyleHeader}
+}
+
+// financialYear is the Australian financial year t falls in, 1 July to 30 June, named by
+// both calendar years as the ATO does, e.g. "2025-26"
+func financialYear(t time.Time) string {
+	local := t.In(displayLocation)
+	end := local.Year()
+	if local.Month() >= time.July {
+		end++
+	}
+	return fmt.Sprintf("%d-%02d", end-1, end%100)
+}
+
+// addSummaryRows writes the report summary as label and value rows
+func addSummaryRows(sheet *xlsxSheet, s Summary) {
+	sheet.AddRow(xlsxText("From"), xlsxTime(s.From))
+	sheet.AddRow(xlsxText("To"), xlsxTime(s.To))
+	sheet.AddRow(xlsxText("Samples"), xlsxNumber(float64(s.Samples)))
+	sheet.AddRow(xlsxText("Open (AUD)"), xlsxMoney(s.Open))
+	sheet.AddRow(xlsxText("Close (AUD)"), xlsxMoney(s.Close))
+	sheet.AddRow(xlsxText("High (AUD)"), xlsxMoney(s.High))
+	sheet.AddRow(xlsxText("Low (AUD)"), xlsxMoney(s.Low))
+	sheet.AddRow(xlsxText("Average spread"), xlsxPercent(s.AvgSpreadPc))
+	sheet.AddRow()
+	sheet.AddRow(xlsxHeading("Source"), xlsxHeading("Reliability"), xlsxHeading("OK"), xlsxHeading("Total"))
+	for _, r := range s.Reliability {
+		sheet.AddRow(xlsxText(r.Name), xlsxPercent(r.Percent), xlsxNumber(float64(r.OK)), xlsxNumber(float64(r.Total)))
+	}
+}
+
+// addRateSheets adds a Rates sheet with one row per sample and a Quotes sheet with one row per source quote
+func addRateSheets(wb *xlsxWorkbook, samples []Sample) {
+	rates := wb.AddSheet("Rates", "Time", "ETH/AUD", "Sources OK", "Sources", "Daily samples", "High", "Low")
+	quotes := wb.AddSheet("Quotes", "Time", "Source", "ETH/USD", "Error")
+	for _, s := range samples {
+		ok := 0
+		for _, src := range s.Sources {
+			if src.Error == "" {
+				ok++
+			}
+			at := src.Time
+			if at.IsZero() {
+				at = s.Time
+			}
+			if src.Error != "" {
+				quotes.AddRow(xlsxTime(at), xlsxText(src.Name), xlsxCell{}, xlsxText(src.Error))
+			} else {
+				quotes.AddRow(xlsxTime(at), xlsxText(src.Name), xlsxMoney(src.USD))
+			}
+		}
+		row := []xlsxCell{xlsxTime(s.Time), xlsxMoney(s.RateAUD), xlsxNumber(float64(ok)), xlsxNumber(float64(len(s.Sources)))}
+		if s.Aggregated > 0 {
+			row = append(row, xlsxNumber(float64(s.Aggregated)), xlsxMoney(s.High), xlsxMoney(s.Low))
+		}
+		rates.AddRow(row...)
+	}
+}
+
+// summaryWorkbook is "report -format xlsx": the summary, then the rates it was built from
+func summaryWorkbook(s Summary) *xlsxWorkbook {
+	wb := &xlsxWorkbook{}
+	sheet := wb.AddSheet("Summary")
+	sheet.AddRow(xlsxHeading(fmt.Sprintf("ETH/AUD %s summary", s.Period)))
+	addSummaryRows(sheet, s)
+	addRateSheets(wb, s.series)
+	return wb
+}
+
+// sampleWorkbook is "history export -kind rates -format xlsx"
+func sampleWorkbook(samples []Sample) *xlsxWorkbook {
+	wb := &xlsxWorkbook{}
+	sheet := wb.AddSheet("Summary")
+	sheet.AddRow(xlsxHeading("ETH/AUD rate history"))
+	if len(samples) == 0 {
+		sheet.AddRow(xlsxText("Samples"), xlsxNumber(0))
+	} else {
+		s, _ := summarize("history", samples[0].Time, samples[len(samples)-1].Time, samples)
+		addSummaryRows(sheet, s)
+	}
+	addRateSheets(wb, samples)
+	return wb
+}
+
+// fyTotals adds up the conversions of one financial year
+type fyTotals struct {
+	year     string
+	count    int
+	aud, eth float64
+}
+
+// addFinancialYears writes the per financial year totals an accountant needs for the cost base:
+// AUD spent, ETH bought and the average AUD paid per ETH
+func addFinancialYears(sheet *xlsxSheet, conversions []ConversionRecord) {
+	var years []*fyTotals
+	for _, c := range conversions {
+		year := financialYear(c.Time)
+		if len(years) == 0 || years[len(years)-1].year != year {
+			years = append(years, &fyTotals{year: year})
+		}
+		t := years[len(years)-1]
+		t.count++
+		t.aud += c.AUD
+		t.eth += c.ETH
+	}
+	sheet.AddRow(xlsxHeading("Financial year"), xlsxHeading("Conversions"), xlsxHeading("AUD"), xlsxHeading("ETH"), xlsxHeading("Average AUD per ETH"))
+	for _, t := range years {
+		avg := xlsxCell{}
+		if t.eth > 0 {
+			avg = xlsxMoney(t.aud / t.eth)
+		}
+		sheet.AddRow(xlsxText(t.year), xlsxNumber(float64(t.count)), xlsxMoney(t.aud), xlsxETH(t.eth), avg)
+	}
+}
+
+// conversionWorkbook is "history export -kind conversions -format xlsx", with financial year totals on the Summary sheet
+// The conversions are in time order, as the store returns them, so each financial year is one run of rows
+func conversionWorkbook(conversions []ConversionRecord) *xlsxWorkbook {
+	wb := &xlsxWorkbook{}
+	summary := wb.AddSheet("Summary")
+	summary.AddRow(xlsxHeading("ETH/AUD conversions"))
+	if len(conversions) > 0 {
+		summary.AddRow(xlsxText("From"), xlsxTime(conversions[0].Time))
+		summary.AddRow(xlsxText("To"), xlsxTime(conversions[len(conversions)-1].Time))
+	}
+	summary.AddRow(xlsxText("Conversions"), xlsxNumber(float64(len(conversions))))
+	summary.AddRow()
+	addFinancialYears(summary, conversions)
+
+	sheet := wb.AddSheet("Conversions", "Time", "AUD", "ETH", "ETH/AUD", "Financial year")
+	for _, c := range conversions {
+		sheet.AddRow(xlsxTime(c.Time), xlsxMoney(c.AUD), xlsxETH(c.ETH), xlsxMoney(c.RateAUD), xlsxText(financialYear(c.Time)))
+	}
+	return wb
+}
+
+// resultWorkbook is "--output xlsx convert ...": the batch's totals, each conversion and the quotes behind it
+func resultWorkbook(results []ConversionResult) *xlsxWorkbook {
+	wb := &xlsxWorkbook{}
+	summary := wb.AddSheet("Summary")
+	conversions := wb.AddSheet("Conversions", "Time", "AUD", "ETH", "Gwei", "USD", "ETH/AUD", "Rate time", "Cached", "USD/AUD", "Confidence", "Error")
+	quotes := wb.AddSheet("Quotes", "Conversion time", "Source", "ETH/USD", "Quote time", "Error")
+
+	var failed int
+	var records []ConversionRecord
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+			conversions.AddRow(xlsxTime(r.Time), xlsxMoney(r.AUD), xlsxCell{}, xlsxCell{}, xlsxCell{}, xlsxCell{},
+				xlsxCell{}, xlsxCell{}, xlsxCell{}, xlsxCell{}, xlsxText(r.Error))
+		} else {
+			usd := xlsxCell{}
+			if r.USD > 0 {
+				usd = xlsxMoney(r.USD)
+			}
+			fx := xlsxCell{}
+			if r.FXRate > 0 {
+				fx = xlsxNumber(r.FXRate)
+			}
+			conversions.AddRow(xlsxTime(r.Time), xlsxMoney(r.AUD), xlsxETH(r.ETH), xlsxNumber(r.Gwei), usd, xlsxMoney(r.Rate),
+				xlsxTime(r.RateTime), xlsxBool(r.Cached), fx, xlsxPercent(r.Confidence*100))
+			records = append(records, ConversionRecord{Time: r.Time, AUD: r.AUD, ETH: r.ETH, RateAUD: r.Rate})
+		}
+		for _, q := range r.Sources {
+			if q.Error != "" {
+				quotes.AddRow(xlsxTime(r.Time), xlsxText(q.Name), xlsxCell{}, xlsxTime(q.Time), xlsxText(q.Error))
+			} else {
+				quotes.AddRow(xlsxTime(r.Time), xlsxText(q.Name), xlsxMoney(q.USD), xlsxTime(q.Time))
+			}
+		}
+	}
+
+	summary.AddRow(xlsxHeading("ETH/AUD conversions"))
+	summary.AddRow(xlsxText("Conversions"), xlsxNumber(float64(len(results))))
+	summary.AddRow(xlsxText("Failed"), xlsxNumber(float64(failed)))
+	summary.AddRow()
+	addFinancialYears(summary, records)
+	return wb
+}
+
+// checkBinaryOut refuses to write a workbook straight onto a terminal, where it would only be garbage
+func checkBinaryOut(w io.Writer) error {
+	if f, ok := w.(*os.File); ok && isTerminal(f) {
+		return fmt.Errorf("xlsx is a binary file, redirect the output to one, e.g. > rates.xlsx")
+	}
+	return nil
+}